@@ -0,0 +1,352 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"github.com/redis/go-redis/v9"
+	"math"
+	"strconv"
+)
+
+// maxBitOffset is the greatest offset of a bitmap, redis limits the strings to 512MB.
+const maxBitOffset = math.MaxUint32
+
+// BitFieldType type of the integers of a BitField, signed (BitFieldInt) or unsigned (BitFieldUint), with its
+// number of bits, ex: "i8", "u16".
+type BitFieldType string
+
+// BitFieldOutput result of each GET, SET and INCRBY of a BitField, in the order they were added.
+type BitFieldOutput struct {
+	// Value read by GET, previous value replaced by SET or new value of INCRBY.
+	Value int64
+	// Failed is true when the SET or INCRBY was not executed by the option.BitFieldOverflowFail mode.
+	Failed bool
+}
+
+// BitField builds the sub-commands of the redis BITFIELD command, executed by Template.BitField, ex:
+//
+//	NewBitField().Overflow(option.BitFieldOverflowSat).IncrBy(BitFieldUint(8), BitFieldUint(8).Offset(3), 1)
+type BitField struct {
+	args       []any
+	idempotent bool
+	err        error
+}
+
+// BitFieldInt returns the type of the signed integers with the number of bits, from 1 to 64.
+func BitFieldInt(bits int) BitFieldType {
+	return BitFieldType("i" + strconv.Itoa(bits))
+}
+
+// BitFieldUint returns the type of the unsigned integers with the number of bits, from 1 to 63.
+func BitFieldUint(bits int) BitFieldType {
+	return BitFieldType("u" + strconv.Itoa(bits))
+}
+
+// Bits returns the number of bits of the type, or 0 if the type is invalid.
+func (b BitFieldType) Bits() int {
+	if len(b) < 2 || (b[0] != 'i' && b[0] != 'u') {
+		return 0
+	}
+	bits, err := strconv.Atoi(string(b[1:]))
+	if helper.IsNotNil(err) || bits < 1 || (b[0] == 'i' && bits > 64) || (b[0] == 'u' && bits > 63) {
+		return 0
+	}
+	return bits
+}
+
+// Offset returns the offset of the integer at the index, considering a bitmap as an array of integers of the type.
+func (b BitFieldType) Offset(index int64) int64 {
+	return index * int64(b.Bits())
+}
+
+func (b BitFieldType) String() string {
+	return string(b)
+}
+
+// NewBitField creates a new BitField instance.
+func NewBitField() *BitField {
+	return &BitField{idempotent: true}
+}
+
+// Get adds the sub-command that reads the integer of the type at the offset.
+func (b *BitField) Get(typ BitFieldType, offset int64) *BitField {
+	return b.add("GET", typ, offset)
+}
+
+// Set adds the sub-command that writes the value at the offset, returning the previous integer.
+func (b *BitField) Set(typ BitFieldType, offset, value int64) *BitField {
+	return b.add("SET", typ, offset, value)
+}
+
+// IncrBy adds the sub-command that increments the integer at the offset, returning the new integer.
+func (b *BitField) IncrBy(typ BitFieldType, offset, increment int64) *BitField {
+	b.idempotent = false
+	return b.add("INCRBY", typ, offset, increment)
+}
+
+// Overflow sets the overflow mode of the next SET and INCRBY sub-commands.
+func (b *BitField) Overflow(mode option.BitFieldOverflow) *BitField {
+	b.args = append(b.args, "OVERFLOW", mode.String())
+	return b
+}
+
+func (b *BitField) add(subCommand string, typ BitFieldType, offset int64, values ...int64) *BitField {
+	if helper.Equals(typ.Bits(), 0) {
+		b.err = ErrInvalidBitFieldType
+	} else if offset < 0 || offset > maxBitOffset {
+		b.err = ErrInvalidBitOffset
+	}
+	b.args = append(b.args, subCommand, typ.String(), offset)
+	for _, value := range values {
+		b.args = append(b.args, value)
+	}
+	return b
+}
+
+// BitOffset converts the integer ID, ex: the ID of a user, to the offset of its bit in a bitmap.
+//
+// The id parameter can be of any integer type, or a string with an integer, if it is not an integer between 0 and
+// 2^32-1, the error returned is ErrInvalidBitOffset.
+func BitOffset(id any) (int64, error) {
+	sId, err := helper.ConvertToString(id)
+	if helper.IsNotNil(err) {
+		return 0, ErrInvalidBitOffset
+	}
+	offset, err := strconv.ParseInt(sId, 10, 64)
+	if helper.IsNotNil(err) || offset < 0 || offset > maxBitOffset {
+		return 0, ErrInvalidBitOffset
+	}
+	return offset, nil
+}
+
+// SetBit redis `SETBIT key offset value` command, sets or clears the bit at the offset, returning the previous value
+// of the bit.
+//
+// The key parameter can be of any type, but cannot be null, in case an error occurs when converting, the error
+// returned is ErrConvertKey. The offset must be between 0 and 2^32-1, otherwise ErrInvalidBitOffset is returned, to
+// use integer IDs as offsets, see BitOffset.
+//
+// The bitmaps are stored as raw strings, without the compression and encryption of the template.
+func (t *Template) SetBit(ctx context.Context, key any, offset int64, value bool) (bool, error) {
+	var previous bool
+	op := &Operation{Name: "SetBit", Keys: []any{key}, Value: value, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := convertBitKey(key, offset)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		bit := 0
+		if value {
+			bit = 1
+		}
+		result, err := t.client.SetBit(ctx, sKey, offset, bit).Result()
+		previous = helper.Equals(result, int64(1))
+		return err
+	})
+	return previous, err
+}
+
+// GetBit redis `GETBIT key offset` command, returns the value of the bit at the offset, the bits beyond the length
+// of the bitmap, or of keys that do not exist, are false.
+//
+// The key and offset parameters follow the SetBit documentation.
+func (t *Template) GetBit(ctx context.Context, key any, offset int64) (bool, error) {
+	var bit bool
+	op := &Operation{Name: "GetBit", Keys: []any{key}, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := convertBitKey(key, offset)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		result, err := t.client.GetBit(ctx, sKey, offset).Result()
+		bit = helper.Equals(result, int64(1))
+		return err
+	})
+	return bit, err
+}
+
+// BitCount redis `BITCOUNT key [start end [BYTE | BIT]]` command, returns the number of bits set in the bitmap.
+//
+// The key parameter can be of any type, but cannot be null, in case an error occurs when converting, the error
+// returned is ErrConvertKey.
+//
+// To count a range of the bitmap, use the opts parameter (option.BitRange), the option.BitUnitBit unit requires
+// redis >= 7.0, otherwise ErrUnsupportedOption is returned.
+func (t *Template) BitCount(ctx context.Context, key any, opts ...*option.BitRange) (int64, error) {
+	var count int64
+	op := &Operation{Name: "BitCount", Keys: []any{key}, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		rangeArgs, err := t.bitRangeArgs(ctx, option.GetOptionBitRangeByParams(opts), true)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		count, err = t.client.Do(ctx, append([]any{"BITCOUNT", sKey}, rangeArgs...)...).Int64()
+		return err
+	})
+	return count, err
+}
+
+// BitPos redis `BITPOS key bit [start [end [BYTE | BIT]]]` command, returns the offset of the first bit set (true)
+// or clear (false) in the bitmap.
+//
+// If no bit set is found the return is -1. If no bit clear is found, the return is the offset after the end of the
+// bitmap, or -1 if the end of the range is informed, since redis considers the bits beyond the bitmap as clear.
+//
+// The key parameter and the opts parameter (option.BitRange) follow the BitCount documentation.
+func (t *Template) BitPos(ctx context.Context, key any, bit bool, opts ...*option.BitRange) (int64, error) {
+	var pos int64
+	op := &Operation{Name: "BitPos", Keys: []any{key}, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		rangeArgs, err := t.bitRangeArgs(ctx, option.GetOptionBitRangeByParams(opts), false)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		args := []any{"BITPOS", sKey, 0}
+		if bit {
+			args[2] = 1
+		}
+		pos, err = t.client.Do(ctx, append(args, rangeArgs...)...).Int64()
+		return err
+	})
+	return pos, err
+}
+
+// BitOp redis `BITOP operation destkey key [key ...]` command, stores the result of the bitwise operation between
+// the bitmaps of the keys in the destKey, returning its length in bytes, ex: the users active on all days of the week
+// with option.BitOperationAnd. The option.BitOperationNot accepts a single key.
+//
+// The destKey and keys parameters can be of any type, but cannot be null, in case an error occurs when converting,
+// the error returned is ErrConvertKey.
+func (t *Template) BitOp(ctx context.Context, operation option.BitOperation, destKey any, keys ...any) (int64, error) {
+	var length int64
+	op := &Operation{Name: "BitOp", Keys: append([]any{destKey}, keys...), Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKeys, err := convertKeys(op.Keys)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		for _, sKey := range sKeys[1:] {
+			op.Idempotent = op.Idempotent && helper.IsNotEqualTo(sKey, sKeys[0])
+		}
+		args := []any{"BITOP", operation.String()}
+		for _, sKey := range sKeys {
+			args = append(args, sKey)
+		}
+		length, err = t.client.Do(ctx, args...).Int64()
+		return err
+	})
+	return length, err
+}
+
+// BitField redis `BITFIELD key [GET | SET | INCRBY | OVERFLOW ...]` command, executes the sub-commands of the
+// bitField, returning a BitFieldOutput for each GET, SET and INCRBY, ex: counters of 8 bits per hour of the day.
+//
+// The key parameter can be of any type, but cannot be null, in case an error occurs when converting, the error
+// returned is ErrConvertKey. If a sub-command has an invalid type or offset, the error returned is
+// ErrInvalidBitFieldType or ErrInvalidBitOffset, and no sub-command is executed.
+func (t *Template) BitField(ctx context.Context, key any, bitField *BitField) ([]BitFieldOutput, error) {
+	var output []BitFieldOutput
+	if helper.IsNil(bitField) {
+		bitField = NewBitField()
+	}
+	op := &Operation{Name: "BitField", Keys: []any{key}, Idempotent: bitField.idempotent}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		} else if helper.IsNotNil(bitField.err) {
+			return bitField.err
+		}
+		result, err := t.client.Do(ctx, append([]any{"BITFIELD", sKey}, bitField.args...)...).Slice()
+		if helper.IsNotNil(err) {
+			return err
+		}
+		output = make([]BitFieldOutput, len(result))
+		for i, v := range result {
+			value, ok := v.(int64)
+			output[i] = BitFieldOutput{Value: value, Failed: !ok}
+		}
+		return nil
+	})
+	return output, err
+}
+
+// BitOffsets returns the offsets of the bits set in the bitmap, in ascending order, ex: the IDs of the users
+// registered with SetBit. If the key does not exist, the return is empty.
+//
+// The key parameter can be of any type, but cannot be null, in case an error occurs when converting, the error
+// returned is ErrConvertKey.
+func (t *Template) BitOffsets(ctx context.Context, key any) ([]int64, error) {
+	var offsets []int64
+	op := &Operation{Name: "BitOffsets", Keys: []any{key}, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		bitmap, err := t.client.Get(ctx, sKey).Result()
+		if errors.Is(err, redis.Nil) {
+			return nil
+		} else if helper.IsNotNil(err) {
+			return err
+		}
+		op.ValueSize = len(bitmap)
+		offsets = bitOffsets(bitmap)
+		return nil
+	})
+	return offsets, err
+}
+
+// bitRangeArgs returns the arguments of the range of BITCOUNT and BITPOS, the end is always informed if requireEnd
+// is true, and the unit only if it is option.BitUnitBit, so the byte ranges are supported by redis < 7.0.
+func (t *Template) bitRangeArgs(ctx context.Context, opt *option.BitRange, requireEnd bool) ([]any, error) {
+	var args []any
+	if helper.IsNil(opt.Start) {
+		return args, nil
+	}
+	args = append(args, *opt.Start)
+	if helper.IsNil(opt.End) && !requireEnd {
+		return args, nil
+	}
+	args = append(args, helper.IfNilReturns(opt.End, -1))
+	if *opt.Unit != option.BitUnitBit {
+		return args, nil
+	} else if err := t.checkCapability(ctx, capabilityBitUnit); helper.IsNotNil(err) {
+		return nil, err
+	}
+	return append(args, opt.Unit.String()), nil
+}
+
+func convertBitKey(key any, offset int64) (string, error) {
+	sKey, err := helper.ConvertToString(key)
+	if helper.IsNotNil(err) {
+		return "", ErrConvertKey
+	} else if offset < 0 || offset > maxBitOffset {
+		return "", ErrInvalidBitOffset
+	}
+	return sKey, nil
+}
+
+// bitOffsets returns the offsets of the bits set, the first bit of the bitmap is the most significant bit of the
+// first byte.
+func bitOffsets(bitmap string) []int64 {
+	var offsets []int64
+	for i := 0; i < len(bitmap); i++ {
+		for j := 0; j < 8; j++ {
+			if bitmap[i]&(0x80>>j) != 0 {
+				offsets = append(offsets, int64(i*8+j))
+			}
+		}
+	}
+	return offsets
+}
@@ -0,0 +1,119 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"github.com/GabrielHCataldo/go-redis-template/redis/redistest"
+	"testing"
+)
+
+func TestTemplateBitmap(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	key := redisTemplate.SprintKey("active", "2024-01-01")
+	for _, id := range []any{7, "100", int64(7)} {
+		offset, err := redis.BitOffset(id)
+		if helper.IsNotNil(err) {
+			logger.Error("BitOffset() err =", err)
+			t.Fail()
+		}
+		_, err = redisTemplate.SetBit(ctx, key, offset, true)
+		if helper.IsNotNil(err) {
+			logger.Error("SetBit() err =", err)
+			t.Fail()
+		}
+	}
+	previous, err := redisTemplate.SetBit(ctx, key, 7, true)
+	bit, _ := redisTemplate.GetBit(ctx, key, 8)
+	if helper.IsNotNil(err) || !previous || bit {
+		logger.Errorf("SetBit() previous = %v GetBit() = %v err = %v", previous, bit, err)
+		t.Fail()
+	}
+	count, err := redisTemplate.BitCount(ctx, key)
+	byteCount, _ := redisTemplate.BitCount(ctx, key, option.NewBitRange().SetStart(0).SetEnd(0))
+	bitCount, _ := redisTemplate.BitCount(ctx, key, option.NewBitRange().SetStart(8).SetUnit(option.BitUnitBit))
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(count, int64(2)) || helper.IsNotEqualTo(byteCount, int64(1)) ||
+		helper.IsNotEqualTo(bitCount, int64(1)) {
+		logger.Errorf("BitCount() result = %v %v %v err = %v", count, byteCount, bitCount, err)
+		t.Fail()
+	}
+	pos, err := redisTemplate.BitPos(ctx, key, true)
+	clearPos, _ := redisTemplate.BitPos(ctx, key, false, option.NewBitRange().SetStart(1))
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(pos, int64(7)) || helper.IsNotEqualTo(clearPos, int64(8)) {
+		logger.Errorf("BitPos() result = %v %v err = %v", pos, clearPos, err)
+		t.Fail()
+	}
+	_, _ = redisTemplate.SetBit(ctx, "active:2024-01-02", 7, true)
+	length, err := redisTemplate.BitOp(ctx, option.BitOperationAnd, "active:both", key, "active:2024-01-02")
+	offsets, _ := redisTemplate.BitOffsets(ctx, "active:both")
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(length, int64(13)) || helper.IsNotEqualTo(offsets, []int64{7}) {
+		logger.Errorf("BitOp() length = %v offsets = %v err = %v", length, offsets, err)
+		t.Fail()
+	}
+	offsets, err = redisTemplate.BitOffsets(ctx, key)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(offsets, []int64{7, 100}) {
+		logger.Errorf("BitOffsets() result = %v err = %v", offsets, err)
+		t.Fail()
+	}
+	_, err = redisTemplate.BitOp(ctx, option.BitOperationNot, "active:none", key, "active:2024-01-02")
+	if helper.IsNil(err) {
+		logger.Error("BitOp() NOT with two keys err = nil")
+		t.Fail()
+	}
+	_, err = redisTemplate.SetBit(ctx, key, -1, true)
+	if !errors.Is(err, redis.ErrInvalidBitOffset) {
+		logger.Errorf("SetBit() err = %v, want = %v", err, redis.ErrInvalidBitOffset)
+		t.Fail()
+	}
+	_, err = redis.BitOffset(uint64(1) << 33)
+	if !errors.Is(err, redis.ErrInvalidBitOffset) {
+		logger.Errorf("BitOffset() err = %v, want = %v", err, redis.ErrInvalidBitOffset)
+		t.Fail()
+	}
+}
+
+func TestTemplateBitField(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	u8 := redis.BitFieldUint(8)
+	bitField := redis.NewBitField().
+		Set(u8, u8.Offset(0), 255).
+		IncrBy(u8, u8.Offset(0), 10).
+		Overflow(option.BitFieldOverflowSat).
+		IncrBy(u8, u8.Offset(1), 300).
+		Overflow(option.BitFieldOverflowFail).
+		IncrBy(redis.BitFieldInt(8), 16, 200).
+		Get(redis.BitFieldInt(8), 16)
+	output, err := redisTemplate.BitField(ctx, "counters", bitField)
+	want := []redis.BitFieldOutput{{Value: 0}, {Value: 9}, {Value: 255}, {Failed: true}, {Value: 0}}
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(output, want) {
+		logger.Errorf("BitField() result = %v err = %v, want = %v", output, err, want)
+		t.Fail()
+	}
+	_, err = redisTemplate.BitField(ctx, "counters", redis.NewBitField().Get(redis.BitFieldUint(64), 0))
+	if !errors.Is(err, redis.ErrInvalidBitFieldType) {
+		logger.Errorf("BitField() err = %v, want = %v", err, redis.ErrInvalidBitFieldType)
+		t.Fail()
+	}
+	server.SetVersion("6.2.14")
+	oldTemplate := redis.NewTemplate(server.ClientOptions())
+	defer oldTemplate.SimpleDisconnect()
+	_, err = oldTemplate.BitCount(ctx, "counters", option.NewBitRange().SetUnit(option.BitUnitBit))
+	if !errors.Is(err, redis.ErrUnsupportedOption) {
+		logger.Errorf("BitCount() err = %v, want = %v", err, redis.ErrUnsupportedOption)
+		t.Fail()
+	}
+	count, err := oldTemplate.BitCount(ctx, "counters", option.NewBitRange().SetStart(1))
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(count, int64(8)) {
+		logger.Errorf("BitCount() result = %v err = %v", count, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,177 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"github.com/redis/go-redis/v9"
+	"hash/fnv"
+	"math"
+	"strings"
+	"sync/atomic"
+)
+
+// maxBloomFilterBits is the number of bits addressed by the BITFIELD command, offsets from 0 to 2^32-1.
+const maxBloomFilterBits = 1 << 32
+
+// BloomFilter is a Bloom filter sized for the capacity and the error rate of its options. With the RedisBloom
+// module it uses the BF.INSERT and BF.MEXISTS commands, otherwise a plain redis bitmap of the key, where each item
+// sets the bits of its hashes with a single BITFIELD command, which is atomic without a Lua script and works where
+// the scripts are disabled. The bitmap uses Bits() / 8 bytes of memory, whatever the number of items added.
+type BloomFilter struct {
+	template  *Template
+	key       any
+	capacity  int64
+	errorRate float64
+	mode      option.BloomFilterMode
+	bits      uint64
+	hashes    int
+	fallback  atomic.Bool
+}
+
+// NewBloomFilter creates a new Bloom filter of the template stored in the key, ex:
+// NewBloomFilter(template, "emails:sent", option.NewBloomFilter().SetCapacity(1000000).SetErrorRate(0.001)).
+//
+// In option.BloomFilterModeAuto, the filter switches to the bitmap on the first "unknown command" error of the
+// module commands, so the servers with and without the module must not share the key.
+//
+// To customize the filter, use the opts parameter (option.BloomFilter).
+func NewBloomFilter(t *Template, key any, opts ...*option.BloomFilter) *BloomFilter {
+	opt := option.GetOptionBloomFilterByParams(opts)
+	bits := math.Ceil(-float64(*opt.Capacity) * math.Log(*opt.ErrorRate) / (math.Ln2 * math.Ln2))
+	bits = min(bits, maxBloomFilterBits)
+	return &BloomFilter{
+		template:  t,
+		key:       key,
+		capacity:  *opt.Capacity,
+		errorRate: *opt.ErrorRate,
+		mode:      *opt.Mode,
+		bits:      uint64(bits),
+		hashes:    max(int(math.Round(bits/float64(*opt.Capacity)*math.Ln2)), 1),
+	}
+}
+
+// Bits returns the number of bits of the bitmap of the filter.
+func (b *BloomFilter) Bits() uint64 {
+	return b.bits
+}
+
+// Hashes returns the number of bits set by each item in the bitmap of the filter.
+func (b *BloomFilter) Hashes() int {
+	return b.hashes
+}
+
+// Add adds the items to the filter, returning for each item, in the same order, false if the item may already
+// exist. The items follow the Template.BFAdd documentation.
+func (b *BloomFilter) Add(ctx context.Context, items ...any) ([]bool, error) {
+	var added []bool
+	op := &Operation{Name: "BloomFilterAdd", Keys: []any{b.key}, Value: items, Idempotent: true}
+	err := b.template.process(ctx, op, func(ctx context.Context) error {
+		sKey, sItems, err := convertKeyAndMembers(op, b.key, items)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		if b.useModule() {
+			added, err = b.template.client.BFInsert(ctx, sKey, &redis.BFInsertOptions{
+				Capacity: b.capacity,
+				Error:    b.errorRate,
+			}, sItems...).Result()
+			if !b.switchToBitmap(err) {
+				return probabilisticError(err)
+			}
+		}
+		var args []any
+		for _, sItem := range sItems {
+			for _, offset := range b.offsets(sItem.(string)) {
+				args = append(args, "SET", "u1", offset, 1)
+			}
+		}
+		previous, err := b.template.client.BitField(ctx, sKey, args...).Result()
+		if helper.IsNotNil(err) {
+			return err
+		}
+		added = b.collect(previous, len(sItems), false)
+		return nil
+	})
+	return added, err
+}
+
+// Exists returns for each item, in the same order, false if the item certainly was not added to the filter, and
+// true if it may have been added, with the false positive rate of the options while the capacity is not exceeded.
+func (b *BloomFilter) Exists(ctx context.Context, items ...any) ([]bool, error) {
+	var exists []bool
+	op := &Operation{Name: "BloomFilterExists", Keys: []any{b.key}, Value: items, Idempotent: true}
+	err := b.template.process(ctx, op, func(ctx context.Context) error {
+		sKey, sItems, err := convertKeyAndMembers(op, b.key, items)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		if b.useModule() {
+			exists, err = b.template.client.BFMExists(ctx, sKey, sItems...).Result()
+			if !b.switchToBitmap(err) {
+				return probabilisticError(err)
+			}
+		}
+		var args []any
+		for _, sItem := range sItems {
+			for _, offset := range b.offsets(sItem.(string)) {
+				args = append(args, "GET", "u1", offset)
+			}
+		}
+		bits, err := b.template.client.BitField(ctx, sKey, args...).Result()
+		if helper.IsNotNil(err) {
+			return err
+		}
+		exists = b.collect(bits, len(sItems), true)
+		return nil
+	})
+	return exists, err
+}
+
+// useModule returns true if the filter must use the module commands.
+func (b *BloomFilter) useModule() bool {
+	return b.mode == option.BloomFilterModeModule || (b.mode == option.BloomFilterModeAuto && !b.fallback.Load())
+}
+
+// switchToBitmap returns true, switching the filter to the bitmap, if the error shows that the server does not have
+// the module and the mode is option.BloomFilterModeAuto.
+func (b *BloomFilter) switchToBitmap(err error) bool {
+	var redisErr redis.Error
+	if b.mode != option.BloomFilterModeAuto || !errors.As(err, &redisErr) ||
+		!strings.HasPrefix(strings.ToLower(err.Error()), "err unknown command") {
+		return false
+	}
+	b.fallback.Store(true)
+	return true
+}
+
+// offsets returns the bits of the item in the bitmap, combining two hashes of the FNV-1a of the item (Kirsch and
+// Mitzenmacher).
+func (b *BloomFilter) offsets(item string) []uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(item))
+	sum := h.Sum64()
+	h1, h2 := sum&math.MaxUint32, sum>>32|1
+	offsets := make([]uint64, b.hashes)
+	for i := range offsets {
+		offsets[i] = (h1 + uint64(i)*h2) % b.bits
+	}
+	return offsets
+}
+
+// collect reduces the bits of the BITFIELD reply, Hashes() per item, to a result per item, true if all the bits of
+// the item are set when all is true, or if any bit of the item was clear when all is false.
+func (b *BloomFilter) collect(bits []int64, items int, all bool) []bool {
+	result := make([]bool, items)
+	for i := range result {
+		result[i] = all
+		for _, bit := range bits[min(i*b.hashes, len(bits)):min((i+1)*b.hashes, len(bits))] {
+			if helper.Equals(bit, int64(0)) {
+				result[i] = !all
+				break
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,81 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// capability is an option or command that requires a minimum redis version.
+type capability struct {
+	name  string
+	major int
+	minor int
+}
+
+var (
+	capabilityKeepTTL = capability{name: "KEEPTTL", major: 6, minor: 0}
+	capabilityGet     = capability{name: "SET GET", major: 6, minor: 2}
+	capabilityNxGet   = capability{name: "SET NX GET", major: 7, minor: 0}
+	capabilityGetDel  = capability{name: "GETDEL", major: 6, minor: 2}
+	capabilityBitUnit = capability{name: "BIT range unit", major: 7, minor: 0}
+)
+
+// serverVersion is the version of the server, detected with the INFO command on the first use of a capability.
+type serverVersion struct {
+	mutex    sync.Mutex
+	detected bool
+	version  string
+}
+
+// checkCapability returns ErrUnsupportedOption if the server version is lower than the one required by the
+// capability. If the version cannot be detected, ex: the INFO command is not allowed by the ACL, the capability is
+// assumed to be supported, and redis returns its own error.
+func (t *Template) checkCapability(ctx context.Context, c capability) error {
+	version, err := t.detectVersion(ctx)
+	if helper.IsNotNil(err) {
+		return err
+	} else if helper.IsEmpty(version) || versionAtLeast(version, c.major, c.minor) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s requires redis >= %d.%d, the server version is %s", ErrUnsupportedOption, c.name,
+		c.major, c.minor, version)
+}
+
+// detectVersion returns the version of the server, reading it once with the INFO command. Connection errors are
+// returned and the detection is tried again in the next call, the error replies leave the version empty.
+func (t *Template) detectVersion(ctx context.Context) (string, error) {
+	t.serverVersion.mutex.Lock()
+	defer t.serverVersion.mutex.Unlock()
+	if t.serverVersion.detected {
+		return t.serverVersion.version, nil
+	}
+	raw, err := t.client.Info(ctx, "server").Result()
+	var redisErr redis.Error
+	if helper.IsNotNil(err) && !errors.As(err, &redisErr) {
+		return "", err
+	} else if helper.IsNil(err) {
+		t.serverVersion.version = ParseInfo(raw).Server.RedisVersion
+	}
+	t.serverVersion.detected = true
+	return t.serverVersion.version, nil
+}
+
+// versionAtLeast returns true if the version, ex: "6.2.14", is greater than or equal to major.minor.
+func versionAtLeast(version string, major, minor int) bool {
+	parts := strings.SplitN(version, ".", 3)
+	vMajor, err := strconv.Atoi(parts[0])
+	if helper.IsNotNil(err) {
+		return true
+	}
+	vMinor := 0
+	if len(parts) > 1 {
+		vMinor, _ = strconv.Atoi(parts[1])
+	}
+	return vMajor > major || (vMajor == major && vMinor >= minor)
+}
@@ -0,0 +1,90 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"github.com/GabrielHCataldo/go-redis-template/redis/redistest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTemplateSetValidate(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	err := redisTemplate.Set(ctx, "test-key", "value", option.NewSet().SetKeepTTL(true).SetTTL(time.Minute))
+	if !errors.Is(err, option.ErrConflictingExpiry) {
+		logger.Errorf("Set() err = %v, want = %v", err, option.ErrConflictingExpiry)
+		t.Fail()
+	}
+	output := redisTemplate.MSet(ctx, redis.MSetInput{
+		Key:   "test-key",
+		Value: "value",
+		Opt:   option.NewSet().SetMode(option.SetModeNx).SetMode(option.SetModeXx).SetTTL(-time.Second),
+	})
+	if !errors.Is(output[0].Err, option.ErrInvalidTTL) {
+		logger.Errorf("MSet() err = %v, want = %v", output[0].Err, option.ErrInvalidTTL)
+		t.Fail()
+	}
+	if helper.IsNotEqualTo(server.Connections(), 0) {
+		logger.Error("Set() sent the invalid options to redis")
+		t.Fail()
+	}
+}
+
+func TestTemplateCapability(t *testing.T) {
+	server := redistest.NewServer(t)
+	server.SetVersion("5.0.7")
+	opts := server.ClientOptions()
+	opts.Protocol = 2
+	redisTemplate := redis.NewTemplate(opts)
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	_ = redisTemplate.Set(ctx, "test-key", "value", option.NewSet().SetTTL(time.Minute))
+	err := redisTemplate.Set(ctx, "test-key", "value", option.NewSet().SetKeepTTL(true))
+	if !errors.Is(err, redis.ErrUnsupportedOption) || !strings.Contains(err.Error(), "KEEPTTL requires redis >= 6.0") {
+		logger.Errorf("Set() err = %v, want = %v", err, redis.ErrUnsupportedOption)
+		t.Fail()
+	}
+	var dest string
+	err = redisTemplate.GetDel(ctx, "test-key", &dest)
+	if !errors.Is(err, redis.ErrUnsupportedOption) {
+		logger.Errorf("GetDel() err = %v, want = %v", err, redis.ErrUnsupportedOption)
+		t.Fail()
+	}
+	server.SetVersion("6.0.16")
+	newTemplate := redis.NewTemplate(opts)
+	defer newTemplate.SimpleDisconnect()
+	err = newTemplate.Set(ctx, "test-key", "new value", option.NewSet().SetKeepTTL(true))
+	if helper.IsNotNil(err) {
+		logger.Error("Set() keep ttl err =", err)
+		t.Fail()
+	}
+	err = newTemplate.SetGet(ctx, "test-key", "value", &dest)
+	if !errors.Is(err, redis.ErrUnsupportedOption) || !strings.Contains(err.Error(), "server version is 6.0.16") {
+		logger.Errorf("SetGet() err = %v, want = %v", err, redis.ErrUnsupportedOption)
+		t.Fail()
+	}
+	server.SetVersion("6.2.14")
+	nxTemplate := redis.NewTemplate(opts)
+	defer nxTemplate.SimpleDisconnect()
+	err = nxTemplate.SetGet(ctx, "test-key", "value", &dest, option.NewSet().SetMode(option.SetModeNx))
+	if !errors.Is(err, redis.ErrUnsupportedOption) || !strings.Contains(err.Error(), "SET NX GET requires redis >= 7.0") {
+		logger.Errorf("SetGet() nx err = %v, want = %v", err, redis.ErrUnsupportedOption)
+		t.Fail()
+	}
+	server.SetError("info", "NOPERM this user has no permissions to run the 'info' command")
+	aclTemplate := redis.NewTemplate(opts)
+	defer aclTemplate.SimpleDisconnect()
+	err = aclTemplate.SetGet(ctx, "test-key", "value", &dest)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(dest, "new value") {
+		logger.Errorf("SetGet() without INFO result = %v err = %v", dest, err)
+		t.Fail()
+	}
+}
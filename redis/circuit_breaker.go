@@ -0,0 +1,254 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"github.com/redis/go-redis/v9"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CircuitBreakerStats is a snapshot of the CircuitBreaker counters.
+type CircuitBreakerStats struct {
+	// State current state of the circuit.
+	State option.CircuitState
+	// Requests number of operations reported inside the rolling window.
+	Requests int64
+	// Failures number of failed operations reported inside the rolling window.
+	Failures int64
+	// FailureRatio ratio between Failures and Requests inside the rolling window.
+	FailureRatio float64
+	// Rejected total number of operations rejected with ErrCircuitOpen since the breaker was created.
+	Rejected uint64
+	// LastStateChange time of the last state transition.
+	LastStateChange time.Time
+}
+
+// CircuitBreaker is an implementation of option.Limiter with closed, open and half-open states.
+//
+// While closed, the results reported by the client are counted in a rolling window, when the failure ratio reaches
+// the threshold the circuit is opened and every operation fails fast with ErrCircuitOpen. After the cooldown, the
+// circuit moves to half-open and allows a limited number of probes, if all of them succeed the circuit is closed,
+// otherwise it is opened again.
+type CircuitBreaker struct {
+	opt *option.CircuitBreaker
+	now func() time.Time
+
+	mutex           sync.Mutex
+	state           option.CircuitState
+	buckets         []circuitBucket
+	bucketWidth     time.Duration
+	openedAt        time.Time
+	lastStateChange time.Time
+	probes          int64
+	probeSuccesses  int64
+	rejected        uint64
+}
+
+type circuitBucket struct {
+	start     time.Time
+	successes int64
+	failures  int64
+}
+
+type circuitTransition struct {
+	from option.CircuitState
+	to   option.CircuitState
+}
+
+var _ option.Limiter = (*CircuitBreaker)(nil)
+
+// NewCircuitBreaker creates a new circuit breaker, to use it, fill in the option.Client.Limiter field.
+//
+// To customize the breaker, use the opts parameter (option.CircuitBreaker), fields not informed use the default
+// values documented in option.CircuitBreaker.
+func NewCircuitBreaker(opts ...*option.CircuitBreaker) *CircuitBreaker {
+	opt := option.GetOptionCircuitBreakerByParams(opts)
+	buckets := *opt.WindowBuckets
+	return &CircuitBreaker{
+		opt:             opt,
+		now:             time.Now,
+		state:           option.CircuitStateClosed,
+		buckets:         make([]circuitBucket, buckets),
+		bucketWidth:     *opt.Window / time.Duration(buckets),
+		lastStateChange: time.Now(),
+	}
+}
+
+// Allow returns nil if the operation is allowed or ErrCircuitOpen otherwise.
+func (c *CircuitBreaker) Allow() error {
+	c.mutex.Lock()
+	now := c.now()
+	var transitions []circuitTransition
+	if helper.IsNotEqualTo(c.state, c.currentState(now)) {
+		transitions = append(transitions, c.setState(option.CircuitStateHalfOpen, now))
+	}
+	var err error
+	switch c.state {
+	case option.CircuitStateOpen:
+		err = ErrCircuitOpen
+	case option.CircuitStateHalfOpen:
+		if c.probes >= *c.opt.HalfOpenProbes {
+			err = ErrCircuitOpen
+		} else {
+			c.probes++
+		}
+	}
+	if helper.IsNotNil(err) {
+		c.rejected++
+	}
+	c.mutex.Unlock()
+	c.notify(transitions)
+	return err
+}
+
+// ReportResult reports the result of the previously allowed operation.
+func (c *CircuitBreaker) ReportResult(result error) {
+	failure := c.isFailure(result)
+	c.mutex.Lock()
+	now := c.now()
+	var transitions []circuitTransition
+	switch c.state {
+	case option.CircuitStateClosed:
+		bucket := c.bucket(now)
+		if failure {
+			bucket.failures++
+		} else {
+			bucket.successes++
+		}
+		requests, failures := c.count(now)
+		if requests >= *c.opt.MinRequests && float64(failures)/float64(requests) >= *c.opt.FailureRatio {
+			transitions = append(transitions, c.setState(option.CircuitStateOpen, now))
+		}
+	case option.CircuitStateHalfOpen:
+		if failure {
+			transitions = append(transitions, c.setState(option.CircuitStateOpen, now))
+		} else {
+			c.probeSuccesses++
+			if c.probeSuccesses >= *c.opt.HalfOpenProbes {
+				transitions = append(transitions, c.setState(option.CircuitStateClosed, now))
+			}
+		}
+	}
+	c.mutex.Unlock()
+	c.notify(transitions)
+}
+
+// State returns the current state of the circuit.
+func (c *CircuitBreaker) State() option.CircuitState {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.currentState(c.now())
+}
+
+// Stats returns a snapshot of the breaker counters.
+func (c *CircuitBreaker) Stats() CircuitBreakerStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	now := c.now()
+	requests, failures := c.count(now)
+	var ratio float64
+	if helper.IsGreaterThan(requests, 0) {
+		ratio = float64(failures) / float64(requests)
+	}
+	return CircuitBreakerStats{
+		State:           c.currentState(now),
+		Requests:        requests,
+		Failures:        failures,
+		FailureRatio:    ratio,
+		Rejected:        c.rejected,
+		LastStateChange: c.lastStateChange,
+	}
+}
+
+// Reset closes the circuit and clears the rolling window.
+func (c *CircuitBreaker) Reset() {
+	c.mutex.Lock()
+	var transitions []circuitTransition
+	if helper.IsNotEqualTo(c.state, option.CircuitStateClosed) {
+		transitions = append(transitions, c.setState(option.CircuitStateClosed, c.now()))
+	}
+	c.mutex.Unlock()
+	c.notify(transitions)
+}
+
+func (c *CircuitBreaker) currentState(now time.Time) option.CircuitState {
+	if helper.Equals(c.state, option.CircuitStateOpen) && !now.Before(c.openedAt.Add(*c.opt.Cooldown)) {
+		return option.CircuitStateHalfOpen
+	}
+	return c.state
+}
+
+func (c *CircuitBreaker) setState(state option.CircuitState, now time.Time) circuitTransition {
+	transition := circuitTransition{from: c.state, to: state}
+	c.state = state
+	c.lastStateChange = now
+	c.probes = 0
+	c.probeSuccesses = 0
+	switch state {
+	case option.CircuitStateOpen:
+		c.openedAt = now
+	case option.CircuitStateClosed:
+		for i := range c.buckets {
+			c.buckets[i] = circuitBucket{}
+		}
+	}
+	return transition
+}
+
+func (c *CircuitBreaker) notify(transitions []circuitTransition) {
+	if c.opt.OnStateChange == nil {
+		return
+	}
+	for _, transition := range transitions {
+		c.opt.OnStateChange(transition.from, transition.to)
+	}
+}
+
+func (c *CircuitBreaker) bucket(now time.Time) *circuitBucket {
+	start := now.Truncate(c.bucketWidth)
+	index := int((start.UnixNano() / int64(c.bucketWidth)) % int64(len(c.buckets)))
+	bucket := &c.buckets[index]
+	if !bucket.start.Equal(start) {
+		*bucket = circuitBucket{start: start}
+	}
+	return bucket
+}
+
+func (c *CircuitBreaker) count(now time.Time) (requests, failures int64) {
+	windowStart := now.Add(-*c.opt.Window)
+	for _, bucket := range c.buckets {
+		if bucket.start.After(windowStart) {
+			requests += bucket.successes + bucket.failures
+			failures += bucket.failures
+		}
+	}
+	return requests, failures
+}
+
+func (c *CircuitBreaker) isFailure(err error) bool {
+	if c.opt.IsFailure != nil {
+		return c.opt.IsFailure(err)
+	}
+	return isCircuitFailure(err)
+}
+
+func isCircuitFailure(err error) bool {
+	if helper.IsNil(err) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var redisErr redis.Error
+	if errors.As(err, &redisErr) {
+		msg := strings.TrimPrefix(redisErr.Error(), "ERR ")
+		for _, prefix := range []string{"LOADING ", "READONLY ", "CLUSTERDOWN ", "MASTERDOWN ", "max number of clients"} {
+			if strings.HasPrefix(msg, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
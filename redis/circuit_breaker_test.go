@@ -0,0 +1,151 @@
+package redis
+
+import (
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"github.com/redis/go-redis/v9"
+	"net"
+	"testing"
+	"time"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func initCircuitBreaker(clock *testClock, transitions *[]option.CircuitState) *CircuitBreaker {
+	cb := NewCircuitBreaker(option.NewCircuitBreaker().
+		SetFailureRatio(0.5).
+		SetMinRequests(4).
+		SetWindow(10 * time.Second).
+		SetWindowBuckets(10).
+		SetCooldown(5 * time.Second).
+		SetHalfOpenProbes(2).
+		SetOnStateChange(func(from, to option.CircuitState) {
+			*transitions = append(*transitions, to)
+		}))
+	cb.now = clock.Now
+	return cb
+}
+
+func reportCircuitBreaker(cb *CircuitBreaker, results ...error) {
+	for _, result := range results {
+		if helper.IsNil(cb.Allow()) {
+			cb.ReportResult(result)
+		}
+	}
+}
+
+func TestCircuitBreakerOpen(t *testing.T) {
+	clock := &testClock{now: time.Now()}
+	var transitions []option.CircuitState
+	cb := initCircuitBreaker(clock, &transitions)
+	failure := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+	reportCircuitBreaker(cb, nil, redis.Nil, failure)
+	if helper.IsNotEqualTo(cb.State(), option.CircuitStateClosed) {
+		logger.Errorf("State() = %v, want = %v", cb.State(), option.CircuitStateClosed)
+		t.Fail()
+		return
+	}
+	reportCircuitBreaker(cb, failure)
+	if helper.IsNotEqualTo(cb.State(), option.CircuitStateOpen) {
+		logger.Errorf("State() = %v, want = %v", cb.State(), option.CircuitStateOpen)
+		t.Fail()
+		return
+	}
+	err := cb.Allow()
+	if !errors.Is(err, ErrCircuitOpen) {
+		logger.Errorf("Allow() err = %v, want = %v", err, ErrCircuitOpen)
+		t.Fail()
+		return
+	}
+	logger.Infof("Stats() result = %v transitions = %v", cb.Stats(), transitions)
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	clock := &testClock{now: time.Now()}
+	var transitions []option.CircuitState
+	cb := initCircuitBreaker(clock, &transitions)
+	failure := errors.New("i/o timeout")
+	reportCircuitBreaker(cb, failure, failure, failure, failure)
+	clock.Advance(5 * time.Second)
+	if helper.IsNotEqualTo(cb.State(), option.CircuitStateHalfOpen) {
+		logger.Errorf("State() = %v, want = %v", cb.State(), option.CircuitStateHalfOpen)
+		t.Fail()
+		return
+	}
+	if helper.IsNotNil(cb.Allow(), cb.Allow()) || helper.IsNil(cb.Allow()) {
+		logger.Error("Allow() expected only 2 probes allowed")
+		t.Fail()
+		return
+	}
+	cb.ReportResult(nil)
+	cb.ReportResult(failure)
+	if helper.IsNotEqualTo(cb.State(), option.CircuitStateOpen) {
+		logger.Errorf("State() = %v, want = %v", cb.State(), option.CircuitStateOpen)
+		t.Fail()
+		return
+	}
+	clock.Advance(5 * time.Second)
+	reportCircuitBreaker(cb, nil, nil)
+	if helper.IsNotEqualTo(cb.State(), option.CircuitStateClosed) {
+		logger.Errorf("State() = %v, want = %v", cb.State(), option.CircuitStateClosed)
+		t.Fail()
+		return
+	}
+	want := []option.CircuitState{
+		option.CircuitStateOpen,
+		option.CircuitStateHalfOpen,
+		option.CircuitStateOpen,
+		option.CircuitStateHalfOpen,
+		option.CircuitStateClosed,
+	}
+	if helper.IsNotEqualTo(transitions, want) {
+		logger.Errorf("transitions = %v, want = %v", transitions, want)
+		t.Fail()
+		return
+	}
+	logger.Infof("Stats() result = %v", cb.Stats())
+}
+
+func TestCircuitBreakerWindow(t *testing.T) {
+	clock := &testClock{now: time.Now()}
+	var transitions []option.CircuitState
+	cb := initCircuitBreaker(clock, &transitions)
+	failure := errors.New("i/o timeout")
+	reportCircuitBreaker(cb, failure, failure, failure)
+	clock.Advance(11 * time.Second)
+	reportCircuitBreaker(cb, failure, nil, nil)
+	stats := cb.Stats()
+	if helper.IsNotEqualTo(stats.State, option.CircuitStateClosed) || helper.IsNotEqualTo(stats.Requests, int64(3)) {
+		logger.Errorf("Stats() result = %v, want state closed with 3 requests", stats)
+		t.Fail()
+		return
+	}
+	cb.Reset()
+	logger.Infof("Stats() result = %v", cb.Stats())
+}
+
+func TestCircuitBreakerTinyWindow(t *testing.T) {
+	cb := NewCircuitBreaker(option.NewCircuitBreaker().SetWindow(5 * time.Nanosecond).SetWindowBuckets(10))
+	if err := cb.Allow(); helper.IsNotNil(err) {
+		logger.Errorf("Allow() err = %v", err)
+		t.Fail()
+		return
+	}
+	cb.ReportResult(nil)
+	if helper.IsNotEqualTo(len(cb.buckets), 5) || helper.IsNotEqualTo(cb.bucketWidth, time.Nanosecond) {
+		logger.Errorf("NewCircuitBreaker() buckets = %v width = %v", len(cb.buckets), cb.bucketWidth)
+		t.Fail()
+	}
+}
@@ -0,0 +1,180 @@
+package redis
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"io"
+	"sync"
+)
+
+// headerCompressed prefixes the compressed values, it is followed by the byte of the algorithm, the size of the
+// value as uvarint and the compressed value: 0xF9 "RTC" | algorithm | uvarint(size) | compressed. The values
+// without a valid header, ex: binary values written without compression, are read as they are, and the size must
+// match the decompressed value.
+const headerCompressed = "\xf9RTC"
+
+const (
+	algorithmGzip byte = iota + 1
+	algorithmZstd
+	algorithmSnappy
+	algorithmLz4
+)
+
+// maxDecompressedSize is the maximum size of a decompressed value, the maximum size of a redis string, larger
+// sizes in the header are rejected before decompressing.
+const maxDecompressedSize = 512 << 20
+
+var zstdOnce sync.Once
+var zstdEncoder *zstd.Encoder
+var zstdDecoder *zstd.Decoder
+
+// compressValue compresses the value with the algorithm of the option.Set, or of the template when the option is
+// not informed, values smaller than the threshold are returned without compression.
+func (t *Template) compressValue(value string, opt *option.Set) (string, error) {
+	compression := helper.IfNilReturns(opt.Compression, t.compression)
+	threshold := helper.IfNilReturns(opt.CompressionThreshold, t.compressionThreshold)
+	if helper.Equals(compression, option.CompressionNone) || len(value) < threshold {
+		return value, nil
+	}
+	var algorithm byte
+	var compressed []byte
+	var err error
+	switch compression {
+	case option.CompressionGzip:
+		algorithm = algorithmGzip
+		compressed, err = gzipCompress([]byte(value))
+	case option.CompressionZstd:
+		algorithm = algorithmZstd
+		encoder, _ := zstdCodec()
+		compressed = encoder.EncodeAll([]byte(value), nil)
+	case option.CompressionSnappy:
+		algorithm = algorithmSnappy
+		compressed = snappy.Encode(nil, []byte(value))
+	case option.CompressionLz4:
+		algorithm = algorithmLz4
+		compressed, err = lz4Compress([]byte(value))
+	default:
+		return "", ErrUnknownCompression
+	}
+	if helper.IsNotNil(err) {
+		return "", err
+	}
+	envelope := binary.AppendUvarint(append([]byte(headerCompressed), algorithm), uint64(len(value)))
+	return string(append(envelope, compressed...)), nil
+}
+
+// decompressValue decompresses the value according to its header, values without header are returned as they are.
+// If the value cannot be decompressed, or its size is not the size of the header, the error returned is
+// ErrDecompressValue.
+func decompressValue(value string) (string, error) {
+	algorithm, size, payload, ok := compressionHeader(value)
+	if !ok {
+		return value, nil
+	} else if size > maxDecompressedSize {
+		return "", ErrDecompressValue
+	}
+	var result []byte
+	var err error
+	switch algorithm {
+	case algorithmGzip:
+		result, err = gzipDecompress(payload, size)
+	case algorithmZstd:
+		_, decoder := zstdCodec()
+		result, err = decoder.DecodeAll(payload, make([]byte, 0, size))
+	case algorithmSnappy:
+		if n, _ := snappy.DecodedLen(payload); helper.IsNotEqualTo(uint64(n), size) {
+			return "", ErrDecompressValue
+		}
+		result, err = snappy.Decode(nil, payload)
+	case algorithmLz4:
+		result, err = io.ReadAll(io.LimitReader(lz4.NewReader(bytes.NewReader(payload)), int64(size)+1))
+	}
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(uint64(len(result)), size) {
+		return "", ErrDecompressValue
+	}
+	return string(result), nil
+}
+
+// compressionHeader returns the algorithm, the size and the compressed payload of the value, and false if the value
+// has no valid header.
+func compressionHeader(value string) (byte, uint64, []byte, bool) {
+	if len(value) <= len(headerCompressed) || helper.IsNotEqualTo(value[:len(headerCompressed)], headerCompressed) {
+		return 0, 0, nil, false
+	}
+	algorithm := value[len(headerCompressed)]
+	if algorithm < algorithmGzip || algorithm > algorithmLz4 {
+		return 0, 0, nil, false
+	}
+	envelope := []byte(value[len(headerCompressed)+1:])
+	size, n := binary.Uvarint(envelope)
+	if n <= 0 {
+		return 0, 0, nil, false
+	}
+	return algorithm, size, envelope[n:], true
+}
+
+// compressionOf returns the compression the value was written with, option.CompressionNone if it has no valid
+// header.
+func compressionOf(value string) option.Compression {
+	algorithm, _, _, ok := compressionHeader(value)
+	if !ok {
+		return option.CompressionNone
+	}
+	switch algorithm {
+	case algorithmGzip:
+		return option.CompressionGzip
+	case algorithmZstd:
+		return option.CompressionZstd
+	case algorithmSnappy:
+		return option.CompressionSnappy
+	default:
+		return option.CompressionLz4
+	}
+}
+
+func gzipCompress(value []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write(value); helper.IsNotNil(err) {
+		return nil, err
+	} else if err = writer.Close(); helper.IsNotNil(err) {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func gzipDecompress(value []byte, size uint64) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(value))
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(io.LimitReader(reader, int64(size)+1))
+}
+
+func lz4Compress(value []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := lz4.NewWriter(&buffer)
+	if _, err := writer.Write(value); helper.IsNotNil(err) {
+		return nil, err
+	} else if err = writer.Close(); helper.IsNotNil(err) {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// zstdCodec creates the zstd encoder and decoder once, both are safe for concurrent use with EncodeAll and
+// DecodeAll, the decoder is limited to maxDecompressedSize.
+func zstdCodec() (*zstd.Encoder, *zstd.Decoder) {
+	zstdOnce.Do(func() {
+		zstdEncoder, _ = zstd.NewWriter(nil)
+		zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecompressedSize))
+	})
+	return zstdEncoder, zstdDecoder
+}
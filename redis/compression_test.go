@@ -0,0 +1,147 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"github.com/GabrielHCataldo/go-redis-template/redis/redistest"
+	redisdriver "github.com/redis/go-redis/v9"
+	"strings"
+	"testing"
+)
+
+type compressionPayload struct {
+	Name  string
+	Items []string
+}
+
+func initCompressionPayload() compressionPayload {
+	var items []string
+	for i := 0; i < 200; i++ {
+		items = append(items, "item of the cached payload")
+	}
+	return compressionPayload{Name: "Foo Bar", Items: items}
+}
+
+func TestTemplateCompression(t *testing.T) {
+	server := redistest.NewServer(t)
+	opts := server.ClientOptions()
+	opts.Compression = option.CompressionZstd
+	opts.CompressionThreshold = 512
+	redisTemplate := redis.NewTemplate(opts)
+	defer redisTemplate.SimpleDisconnect()
+	client := redisdriver.NewClient(server.ClientOptions().ParseToRedisOptions())
+	defer client.Close()
+	ctx := context.TODO()
+	payload := initCompressionPayload()
+	raw, _ := helper.ConvertToString(payload)
+	for _, compression := range []option.Compression{
+		option.CompressionGzip,
+		option.CompressionZstd,
+		option.CompressionSnappy,
+		option.CompressionLz4,
+	} {
+		err := redisTemplate.Set(ctx, "test-key", payload, option.NewSet().SetCompression(compression))
+		if helper.IsNotNil(err) {
+			logger.Errorf("Set() %s err = %v", compression, err)
+			t.Fail()
+			continue
+		}
+		stored, _ := client.Get(ctx, "test-key").Result()
+		if len(stored) >= len(raw) {
+			logger.Errorf("Set() %s stored size = %v, want < %v", compression, len(stored), len(raw))
+			t.Fail()
+		}
+		var dest compressionPayload
+		err = redisTemplate.Get(ctx, "test-key", &dest)
+		if helper.IsNotNil(err) || helper.IsNotEqualTo(dest, payload) {
+			logger.Errorf("Get() %s err = %v", compression, err)
+			t.Fail()
+		}
+	}
+	err := redisTemplate.Set(ctx, "small-key", "small value")
+	stored, _ := client.Get(ctx, "small-key").Result()
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(stored, "small value") {
+		logger.Errorf("Set() below threshold stored = %v err = %v", stored, err)
+		t.Fail()
+	}
+	var old compressionPayload
+	err = redisTemplate.SetGet(ctx, "test-key", "new value", &old)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(old, payload) {
+		logger.Error("SetGet() err =", err)
+		t.Fail()
+	}
+	var dest string
+	err = redisTemplate.GetDel(ctx, "small-key", &dest)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(dest, "small value") {
+		logger.Errorf("GetDel() result = %v err = %v", dest, err)
+		t.Fail()
+	}
+}
+
+func TestTemplateCompressionThreshold(t *testing.T) {
+	server := redistest.NewServer(t)
+	opts := server.ClientOptions()
+	opts.Compression = option.CompressionGzip
+	redisTemplate := redis.NewTemplate(opts)
+	defer redisTemplate.SimpleDisconnect()
+	client := redisdriver.NewClient(server.ClientOptions().ParseToRedisOptions())
+	defer client.Close()
+	ctx := context.TODO()
+	small := strings.Repeat("x", 1023)
+	_ = redisTemplate.Set(ctx, "small-key", small)
+	_ = redisTemplate.Set(ctx, "large-key", strings.Repeat("x", 1024))
+	storedSmall, _ := client.Get(ctx, "small-key").Result()
+	storedLarge, _ := client.Get(ctx, "large-key").Result()
+	if helper.IsNotEqualTo(storedSmall, small) || !strings.HasPrefix(storedLarge, "\xf9RTC") {
+		logger.Errorf("Set() default threshold small = %q large = %q", storedSmall, storedLarge)
+		t.Fail()
+	}
+}
+
+func TestTemplateCompressionRollout(t *testing.T) {
+	server := redistest.NewServer(t)
+	client := redisdriver.NewClient(server.ClientOptions().ParseToRedisOptions())
+	defer client.Close()
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	payload := initCompressionPayload()
+	_ = redisTemplate.Set(ctx, "old-key", payload)
+	_ = redisTemplate.Set(ctx, "new-key", payload, option.NewSet().SetCompression(option.CompressionGzip))
+	for _, key := range []string{"old-key", "new-key"} {
+		var dest compressionPayload
+		err := redisTemplate.Get(ctx, key, &dest)
+		if helper.IsNotNil(err) || helper.IsNotEqualTo(dest, payload) {
+			logger.Errorf("Get(%s) err = %v", key, err)
+			t.Fail()
+		}
+	}
+	binaryValue := string([]byte{0xF9, 0xFA, 0x00, 0x01}) + strings.Repeat("x", 10)
+	client.Set(ctx, "binary-key", binaryValue, 0)
+	var dest string
+	err := redisTemplate.Get(ctx, "binary-key", &dest)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(dest, binaryValue) {
+		logger.Errorf("Get() binary result = %v err = %v", []byte(dest), err)
+		t.Fail()
+	}
+	for _, value := range []string{
+		"\xf9RTC\x01\x0a" + strings.Repeat("x", 10),
+		"\xf9RTC\x03\xff\xff\xff\xff\xff\x01" + strings.Repeat("x", 10),
+	} {
+		client.Set(ctx, "corrupted-key", value, 0)
+		err = redisTemplate.Get(ctx, "corrupted-key", &dest)
+		if !errors.Is(err, redis.ErrDecompressValue) {
+			logger.Errorf("Get() %q err = %v, want = %v", value, err, redis.ErrDecompressValue)
+			t.Fail()
+		}
+	}
+	err = redisTemplate.Set(ctx, "test-key", payload, option.NewSet().SetCompression("brotli"))
+	if !errors.Is(err, redis.ErrUnknownCompression) {
+		logger.Errorf("Set() err = %v, want = %v", err, redis.ErrUnknownCompression)
+		t.Fail()
+	}
+}
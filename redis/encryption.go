@@ -0,0 +1,210 @@
+package redis
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"golang.org/x/crypto/chacha20poly1305"
+	"time"
+)
+
+// headerEncrypted prefixes the encrypted values, it is followed by the size of the key ID, the key ID, the nonce
+// and the ciphertext: 0xFD "RTE" | len(id) | id | nonce | ciphertext. The header is authenticated with the value,
+// so a value without the header is never taken as encrypted, and a value with the header that does not decrypt
+// is reported.
+const headerEncrypted = "\xfdRTE"
+
+// ReEncryptOutput is the result of the re-encryption (Template.ReEncrypt).
+type ReEncryptOutput struct {
+	// Scanned number of keys scanned.
+	Scanned int64
+	// ReEncrypted number of values rewritten with the primary key.
+	ReEncrypted int64
+}
+
+// keyring keeps the AEAD of each key, err is the error of the configuration, returned by every encryption.
+type keyring struct {
+	primary string
+	bindKey bool
+	aeads   map[string]cipher.AEAD
+	err     error
+}
+
+func newKeyring(opt *option.Encryption) *keyring {
+	if helper.IsNil(opt) {
+		return nil
+	}
+	opt = option.GetOptionEncryptionByParams([]*option.Encryption{opt})
+	k := &keyring{
+		primary: helper.IfNilReturns(opt.PrimaryKeyID, ""),
+		bindKey: *opt.BindKey,
+		aeads:   map[string]cipher.AEAD{},
+	}
+	for _, key := range opt.Keys {
+		if helper.IsEmpty(key.ID) || len(key.ID) > 255 {
+			k.err = ErrInvalidEncryptionKey
+			return k
+		}
+		aead, err := newAEAD(key)
+		if helper.IsNotNil(err) {
+			k.err = ErrInvalidEncryptionKey
+			return k
+		}
+		k.aeads[key.ID] = aead
+	}
+	if _, ok := k.aeads[k.primary]; !ok {
+		k.err = ErrEncryptionKeyNotFound
+	}
+	return k
+}
+
+func newAEAD(key option.EncryptionKey) (cipher.AEAD, error) {
+	switch key.Cipher {
+	case option.CipherAESGCM:
+		block, err := aes.NewCipher(key.Secret)
+		if helper.IsNotNil(err) {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case option.CipherChaCha20Poly1305:
+		return chacha20poly1305.New(key.Secret)
+	}
+	return nil, ErrInvalidEncryptionKey
+}
+
+// encrypt encrypts the value with the primary key, the header and the key ID are authenticated with the value, and
+// the redis key too if bindKey is true.
+func (k *keyring) encrypt(key, value string) (string, error) {
+	if helper.IsNotNil(k.err) {
+		return "", k.err
+	}
+	aead := k.aeads[k.primary]
+	envelope := append(append([]byte(headerEncrypted), byte(len(k.primary))), k.primary...)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); helper.IsNotNil(err) {
+		return "", err
+	}
+	ad := k.associatedData(key, envelope)
+	envelope = append(envelope, nonce...)
+	return string(aead.Seal(envelope, nonce, []byte(value), ad)), nil
+}
+
+// decrypt decrypts the value with the key of its envelope, values without the header are returned as they are.
+func (k *keyring) decrypt(key, value string) (string, error) {
+	id, ok := encryptionKeyID(value)
+	if !ok {
+		return value, nil
+	} else if helper.IsNil(k) {
+		return "", ErrEncryptionKeyNotFound
+	} else if helper.IsNotNil(k.err) {
+		return "", k.err
+	}
+	aead, ok := k.aeads[id]
+	if !ok {
+		return "", ErrEncryptionKeyNotFound
+	}
+	headerSize := len(headerEncrypted) + 1 + len(id)
+	if len(value) < headerSize+aead.NonceSize() {
+		return "", ErrDecryptValue
+	}
+	envelope := []byte(value)
+	nonce := envelope[headerSize : headerSize+aead.NonceSize()]
+	ciphertext := envelope[headerSize+aead.NonceSize():]
+	result, err := aead.Open(nil, nonce, ciphertext, k.associatedData(key, envelope[:headerSize]))
+	if helper.IsNotNil(err) {
+		return "", ErrDecryptValue
+	}
+	return string(result), nil
+}
+
+func (k *keyring) associatedData(key string, header []byte) []byte {
+	ad := append([]byte(nil), header...)
+	if k.bindKey {
+		ad = append(ad, key...)
+	}
+	return ad
+}
+
+// encryptionKeyID returns the key ID of the envelope, and false if the value is not encrypted.
+func encryptionKeyID(value string) (string, bool) {
+	size := len(headerEncrypted) + 1
+	if len(value) < size || helper.IsNotEqualTo(value[:len(headerEncrypted)], headerEncrypted) ||
+		len(value) < size+int(value[size-1]) {
+		return "", false
+	}
+	return value[size : size+int(value[size-1])], true
+}
+
+// encryptValue encrypts the value when the template has encryption, unless option.Set.Encrypt is false.
+func (t *Template) encryptValue(key, value string, opt *option.Set) (string, error) {
+	if helper.IsNil(t.keyring) || !helper.IfNilReturns(opt.Encrypt, true) {
+		return value, nil
+	}
+	return t.keyring.encrypt(key, value)
+}
+
+// ReEncrypt rewrites with the primary key the values of the keys matching the glob-style pattern that were
+// encrypted with another key of the keyring, so the old keys can be removed from the keyring after the rotation.
+// The TTL of the values is kept. The values written without encryption are kept in plain text, unless
+// option.ReEncrypt.EncryptPlaintext is true, in this case make sure the pattern does not match values that must stay
+// in plain text, like counters, bitmaps and HyperLogLogs.
+//
+// The keys are iterated with Scan, so the operation can be run in background, each value is rewritten in a
+// transaction watching the key, if the key is written concurrently, it is skipped. Keys that are not strings are
+// skipped.
+//
+// To customize the operation, use the opts parameter (option.ReEncrypt).
+func (t *Template) ReEncrypt(ctx context.Context, match string, opts ...*option.ReEncrypt) (ReEncryptOutput, error) {
+	opt := option.GetOptionReEncryptByParams(opts)
+	var output ReEncryptOutput
+	if helper.IsNil(t.keyring) {
+		return output, ErrEncryptionDisabled
+	} else if helper.IsNotNil(t.keyring.err) {
+		return output, t.keyring.err
+	}
+	var cursor uint64
+	for {
+		page, err := t.Scan(ctx, cursor, match, *opt.Count)
+		if helper.IsNotNil(err) {
+			return output, err
+		}
+		for _, key := range page.Page {
+			output.Scanned++
+			reEncrypted, err := t.reEncryptKey(ctx, key, *opt.EncryptPlaintext)
+			if helper.IsNotNil(err) {
+				return output, err
+			} else if reEncrypted {
+				output.ReEncrypted++
+			}
+		}
+		cursor = page.Cursor
+		if helper.IsEmpty(cursor) {
+			return output, nil
+		}
+		select {
+		case <-ctx.Done():
+			return output, ctx.Err()
+		case <-time.After(*opt.Delay):
+		}
+	}
+}
+
+func (t *Template) reEncryptKey(ctx context.Context, key string, encryptPlaintext bool) (bool, error) {
+	return t.rewrite(ctx, "ReEncrypt", key, func(value string) (string, bool, error) {
+		if id, ok := encryptionKeyID(value); (ok && helper.Equals(id, t.keyring.primary)) || (!ok && !encryptPlaintext) {
+			return "", false, nil
+		}
+		plain, err := t.keyring.decrypt(key, value)
+		if helper.IsNotNil(err) {
+			return "", false, err
+		}
+		encrypted, err := t.keyring.encrypt(key, plain)
+		if helper.IsNotNil(err) {
+			return "", false, err
+		}
+		return encrypted, true, nil
+	})
+}
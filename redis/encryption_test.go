@@ -0,0 +1,165 @@
+package redis_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"github.com/GabrielHCataldo/go-redis-template/redis/redistest"
+	redisdriver "github.com/redis/go-redis/v9"
+	"strings"
+	"testing"
+	"time"
+)
+
+type encryptionPayload struct {
+	Name     string
+	Document string
+}
+
+func initEncryptionPayload() encryptionPayload {
+	return encryptionPayload{Name: "Foo Bar", Document: "123.456.789-00"}
+}
+
+func initEncryptionTemplate(server *redistest.Server, encryption *option.Encryption) *redis.Template {
+	opts := server.ClientOptions()
+	opts.Encryption = encryption
+	return redis.NewTemplate(opts)
+}
+
+func TestTemplateEncryption(t *testing.T) {
+	server := redistest.NewServer(t)
+	client := redisdriver.NewClient(server.ClientOptions().ParseToRedisOptions())
+	defer client.Close()
+	ctx := context.TODO()
+	payload := initEncryptionPayload()
+	for _, key := range []option.EncryptionKey{
+		{ID: "aes", Cipher: option.CipherAESGCM, Secret: bytes.Repeat([]byte{1}, 32)},
+		{ID: "chacha", Cipher: option.CipherChaCha20Poly1305, Secret: bytes.Repeat([]byte{2}, 32)},
+	} {
+		redisTemplate := initEncryptionTemplate(server, option.NewEncryption().AddKey(key.ID, key.Cipher, key.Secret))
+		err := redisTemplate.Set(ctx, "test-key", payload, option.NewSet().SetTTL(time.Minute))
+		if helper.IsNotNil(err) {
+			logger.Errorf("Set() %s err = %v", key.Cipher, err)
+			t.Fail()
+			continue
+		}
+		stored, _ := client.Get(ctx, "test-key").Result()
+		if strings.Contains(stored, payload.Document) {
+			logger.Errorf("Set() %s stored value in plain text", key.Cipher)
+			t.Fail()
+		}
+		var dest encryptionPayload
+		err = redisTemplate.Get(ctx, "test-key", &dest)
+		if helper.IsNotNil(err) || helper.IsNotEqualTo(dest, payload) {
+			logger.Errorf("Get() %s result = %v err = %v", key.Cipher, dest, err)
+			t.Fail()
+		}
+		redisTemplate.MSet(ctx, redis.MSetInput{Key: "m-key", Value: payload})
+		var old encryptionPayload
+		err = redisTemplate.SetGet(ctx, "m-key", "v2", &old)
+		if helper.IsNotNil(err) || helper.IsNotEqualTo(old, payload) {
+			logger.Errorf("SetGet() %s result = %v err = %v", key.Cipher, old, err)
+			t.Fail()
+		}
+		redisTemplate.SimpleDisconnect()
+	}
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	var dest encryptionPayload
+	err := redisTemplate.Get(ctx, "test-key", &dest)
+	if !errors.Is(err, redis.ErrEncryptionKeyNotFound) {
+		logger.Errorf("Get() without keyring err = %v, want = %v", err, redis.ErrEncryptionKeyNotFound)
+		t.Fail()
+	}
+	binaryValue := string([]byte{0xFD, 0x03, 'a', 'e', 's'}) + strings.Repeat("x", 20)
+	client.Set(ctx, "binary-key", binaryValue, 0)
+	var binaryDest string
+	err = redisTemplate.Get(ctx, "binary-key", &binaryDest)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(binaryDest, binaryValue) {
+		logger.Errorf("Get() binary result = %v err = %v", []byte(binaryDest), err)
+		t.Fail()
+	}
+}
+
+func TestTemplateEncryptionBindKey(t *testing.T) {
+	server := redistest.NewServer(t)
+	ctx := context.TODO()
+	encryption := option.NewEncryption().
+		AddKey("k1", option.CipherAESGCM, bytes.Repeat([]byte{1}, 16)).
+		SetBindKey(true)
+	redisTemplate := initEncryptionTemplate(server, encryption)
+	defer redisTemplate.SimpleDisconnect()
+	_ = redisTemplate.Set(ctx, "test-key", initEncryptionPayload(),
+		option.NewSet().SetCompression(option.CompressionGzip))
+	_ = redisTemplate.Rename(ctx, "test-key", "other-key")
+	var dest encryptionPayload
+	err := redisTemplate.Get(ctx, "other-key", &dest)
+	if !errors.Is(err, redis.ErrDecryptValue) {
+		logger.Errorf("Get() err = %v, want = %v", err, redis.ErrDecryptValue)
+		t.Fail()
+	}
+	invalid := initEncryptionTemplate(server, option.NewEncryption().AddKey("k1", option.CipherAESGCM, []byte("short")))
+	defer invalid.SimpleDisconnect()
+	err = invalid.Set(ctx, "test-key", "value")
+	if !errors.Is(err, redis.ErrInvalidEncryptionKey) {
+		logger.Errorf("Set() err = %v, want = %v", err, redis.ErrInvalidEncryptionKey)
+		t.Fail()
+	}
+}
+
+func TestTemplateReEncrypt(t *testing.T) {
+	server := redistest.NewServer(t)
+	client := redisdriver.NewClient(server.ClientOptions().ParseToRedisOptions())
+	defer client.Close()
+	ctx := context.TODO()
+	oldSecret := bytes.Repeat([]byte{1}, 32)
+	newSecret := bytes.Repeat([]byte{2}, 32)
+	oldTemplate := initEncryptionTemplate(server, option.NewEncryption().
+		AddKey("k1", option.CipherAESGCM, oldSecret))
+	defer oldTemplate.SimpleDisconnect()
+	for i := 0; i < 5; i++ {
+		_ = oldTemplate.Set(ctx, oldTemplate.SprintKey("user", i), initEncryptionPayload(),
+			option.NewSet().SetTTL(time.Hour))
+	}
+	_ = oldTemplate.Set(ctx, "user:plain", initEncryptionPayload(), option.NewSet().SetEncrypt(false))
+	client.SAdd(ctx, "user:set", "member")
+	client.Incr(ctx, "user:visits")
+	rotated := initEncryptionTemplate(server, option.NewEncryption().
+		AddKey("k2", option.CipherChaCha20Poly1305, newSecret).
+		AddKey("k1", option.CipherAESGCM, oldSecret))
+	defer rotated.SimpleDisconnect()
+	output, err := rotated.ReEncrypt(ctx, "user:*", option.NewReEncrypt().SetCount(2))
+	visits, _ := client.Incr(ctx, "user:visits").Result()
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(output.Scanned, 8) || helper.IsNotEqualTo(output.ReEncrypted, 5) ||
+		helper.IsNotEqualTo(visits, int64(2)) {
+		logger.Errorf("ReEncrypt() result = %+v visits = %v err = %v", output, visits, err)
+		t.Fail()
+		return
+	}
+	output, err = rotated.ReEncrypt(ctx, "user:plain", option.NewReEncrypt().SetEncryptPlaintext(true))
+	stored, _ := client.Get(ctx, "user:plain").Result()
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(output.ReEncrypted, 1) ||
+		strings.Contains(stored, initEncryptionPayload().Document) {
+		logger.Errorf("ReEncrypt() plaintext result = %+v err = %v", output, err)
+		t.Fail()
+	}
+	newTemplate := initEncryptionTemplate(server, option.NewEncryption().
+		AddKey("k2", option.CipherChaCha20Poly1305, newSecret))
+	defer newTemplate.SimpleDisconnect()
+	var dest encryptionPayload
+	err = newTemplate.Get(ctx, "user:3", &dest)
+	ttl := client.TTL(ctx, "user:3").Val()
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(dest, initEncryptionPayload()) || ttl <= 0 {
+		logger.Errorf("Get() after ReEncrypt result = %v ttl = %v err = %v", dest, ttl, err)
+		t.Fail()
+	}
+	output, err = rotated.ReEncrypt(ctx, "user:*")
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(output.ReEncrypted, 0) {
+		logger.Errorf("ReEncrypt() again result = %+v err = %v", output, err)
+		t.Fail()
+	}
+}
@@ -1,7 +1,14 @@
 package redis
 
 import (
+	"context"
 	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/redis/go-redis/v9"
+	"io"
+	"net"
+	"strings"
+	"syscall"
 )
 
 var MsgErrConvertKey = "redis: error convert key to string"
@@ -9,9 +16,209 @@ var MsgErrConvertNewKey = "redis: error convert new key to string"
 var MsgErrConvertValue = "redis: error convert value"
 var MsgErrDestIsNotPointer = "redis: dest is not pointer"
 var MsgErrKeyNotFound = "redis: key not found"
+var MsgErrCircuitOpen = "redis: circuit breaker is open"
+var MsgErrUnknownCompression = "redis: unknown compression algorithm"
+var MsgErrDecompressValue = "redis: error decompress value"
+var MsgErrInvalidEncryptionKey = "redis: invalid encryption key"
+var MsgErrEncryptionKeyNotFound = "redis: encryption key not found in the keyring"
+var MsgErrDecryptValue = "redis: error decrypt value"
+var MsgErrEncryptionDisabled = "redis: encryption is not configured"
+var MsgErrSchemaVersion = "redis: value version is newer than the schema version"
+var MsgErrUpgradeNotFound = "redis: upgrade function not found for the value version"
+var MsgErrTimeout = "redis: operation timed out"
+var MsgErrPoolExhausted = "redis: connection pool exhausted"
+var MsgErrConnectionRefused = "redis: connection refused"
+var MsgErrReadOnly = "redis: server is read only"
+var MsgErrMoved = "redis: key moved to another node"
+var MsgErrAsk = "redis: key is migrating to another node"
+var MsgErrWrongType = "redis: operation against a key holding the wrong kind of value"
+var MsgErrNoScript = "redis: script not found"
+var MsgErrOOM = "redis: server out of memory"
+var MsgErrTemplateNotFound = "redis: template not found in the registry"
+var MsgErrTemplateAlreadyRegistered = "redis: template already registered"
+var MsgErrRegistryClosed = "redis: registry is closed"
+var MsgErrUnsupportedOption = "redis: option not supported by the server version"
+var MsgErrInvalidBitOffset = "redis: bit offset must be an integer between 0 and 2^32-1"
+var MsgErrInvalidBitFieldType = "redis: invalid bitfield type, use i1 to i64 or u1 to u63"
+var MsgErrMemberNotFound = "redis: member not found"
+var MsgErrDestIsNotSlicePointer = "redis: dest is not a pointer to a slice"
+var MsgErrJSONPathNotFound = "redis: json path not found or not of the expected type"
+var MsgErrInvalidSearchTag = "redis: invalid search tag"
+var MsgErrIndexNotFound = "redis: search index not found"
+var MsgErrIndexAlreadyExists = "redis: search index already exists"
+var MsgErrVectorDimension = "redis: vector dimension does not match the index"
+var MsgErrKeyAlreadyExists = "redis: key already exists"
+var MsgErrDuplicateSample = "redis: time series already has a sample with the timestamp"
 
 var ErrConvertKey = errors.New(MsgErrConvertKey)
 var ErrConvertNewKey = errors.New(MsgErrConvertNewKey)
 var ErrConvertValue = errors.New(MsgErrConvertValue)
 var ErrDestIsNotPointer = errors.New(MsgErrDestIsNotPointer)
 var ErrKeyNotFound = errors.New(MsgErrKeyNotFound)
+var ErrCircuitOpen = errors.New(MsgErrCircuitOpen)
+var ErrUnknownCompression = errors.New(MsgErrUnknownCompression)
+var ErrDecompressValue = errors.New(MsgErrDecompressValue)
+var ErrInvalidEncryptionKey = errors.New(MsgErrInvalidEncryptionKey)
+var ErrEncryptionKeyNotFound = errors.New(MsgErrEncryptionKeyNotFound)
+var ErrDecryptValue = errors.New(MsgErrDecryptValue)
+var ErrEncryptionDisabled = errors.New(MsgErrEncryptionDisabled)
+var ErrSchemaVersion = errors.New(MsgErrSchemaVersion)
+var ErrUpgradeNotFound = errors.New(MsgErrUpgradeNotFound)
+var ErrTimeout = errors.New(MsgErrTimeout)
+var ErrPoolExhausted = errors.New(MsgErrPoolExhausted)
+var ErrConnectionRefused = errors.New(MsgErrConnectionRefused)
+var ErrReadOnly = errors.New(MsgErrReadOnly)
+var ErrMoved = errors.New(MsgErrMoved)
+var ErrAsk = errors.New(MsgErrAsk)
+var ErrWrongType = errors.New(MsgErrWrongType)
+var ErrNoScript = errors.New(MsgErrNoScript)
+var ErrOOM = errors.New(MsgErrOOM)
+var ErrTemplateNotFound = errors.New(MsgErrTemplateNotFound)
+var ErrTemplateAlreadyRegistered = errors.New(MsgErrTemplateAlreadyRegistered)
+var ErrRegistryClosed = errors.New(MsgErrRegistryClosed)
+var ErrUnsupportedOption = errors.New(MsgErrUnsupportedOption)
+var ErrInvalidBitOffset = errors.New(MsgErrInvalidBitOffset)
+var ErrInvalidBitFieldType = errors.New(MsgErrInvalidBitFieldType)
+var ErrMemberNotFound = errors.New(MsgErrMemberNotFound)
+var ErrDestIsNotSlicePointer = errors.New(MsgErrDestIsNotSlicePointer)
+var ErrJSONPathNotFound = errors.New(MsgErrJSONPathNotFound)
+var ErrInvalidSearchTag = errors.New(MsgErrInvalidSearchTag)
+var ErrIndexNotFound = errors.New(MsgErrIndexNotFound)
+var ErrIndexAlreadyExists = errors.New(MsgErrIndexAlreadyExists)
+var ErrVectorDimension = errors.New(MsgErrVectorDimension)
+var ErrKeyAlreadyExists = errors.New(MsgErrKeyAlreadyExists)
+var ErrDuplicateSample = errors.New(MsgErrDuplicateSample)
+
+// redisErrorKinds maps the prefixes of the redis error replies to the sentinel errors.
+var redisErrorKinds = []struct {
+	prefix string
+	kind   error
+}{
+	{"READONLY ", ErrReadOnly},
+	{"MOVED ", ErrMoved},
+	{"ASK ", ErrAsk},
+	{"WRONGTYPE ", ErrWrongType},
+	{"NOSCRIPT ", ErrNoScript},
+	{"OOM ", ErrOOM},
+}
+
+// OpError is the error returned by the template operations, with the operation and the key, it matches with
+// errors.Is both the original error and its class (ErrTimeout, ErrPoolExhausted, ErrConnectionRefused,
+// ErrReadOnly, ErrMoved, ErrAsk, ErrWrongType, ErrNoScript or ErrOOM), ex:
+//
+//	var opErr *redis.OpError
+//	if errors.As(err, &opErr) && errors.Is(err, redis.ErrTimeout) {
+//		logger.Error("timeout on", opErr.Op, opErr.Key)
+//	}
+type OpError struct {
+	// Op name of the operation, ex: "Get".
+	Op string
+	// Key first key of the operation converted to string, empty if the operation has no key.
+	Key string
+	// Err original error.
+	Err error
+}
+
+// NewOpError wraps the error with the operation and the key, nil errors and errors already wrapped are returned
+// as they are.
+func NewOpError(op string, key any, err error) error {
+	var opErr *OpError
+	if helper.IsNil(err) || errors.As(err, &opErr) {
+		return err
+	}
+	var sKey string
+	if helper.IsNotNil(key) {
+		sKey = helper.SimpleConvertToString(key)
+	}
+	return &OpError{Op: op, Key: sKey, Err: err}
+}
+
+func (e *OpError) Error() string {
+	if helper.IsEmpty(e.Key) {
+		return e.Op + ": " + e.Err.Error()
+	}
+	return e.Op + " " + e.Key + ": " + e.Err.Error()
+}
+
+// Unwrap returns the original error and its class, if any.
+func (e *OpError) Unwrap() []error {
+	if kind := ErrorKind(e.Err); helper.IsNotNil(kind) && !errors.Is(e.Err, kind) {
+		return []error{e.Err, kind}
+	}
+	return []error{e.Err}
+}
+
+// IsRetryable returns true if the operation can succeed when executed again: timeouts, exhausted pool, refused or
+// closed connections, READONLY, MOVED and ASK replies (failover or resharding in progress), and the LOADING,
+// TRYAGAIN, CLUSTERDOWN and MASTERDOWN replies. Canceled contexts and errors of the operation itself, like
+// WRONGTYPE, NOSCRIPT, OOM, ErrKeyNotFound or conversion errors, are not retryable.
+func IsRetryable(err error) bool {
+	if helper.IsNil(err) || errors.Is(err, context.Canceled) || errors.Is(err, redis.ErrClosed) {
+		return false
+	}
+	switch ErrorKind(err) {
+	case ErrTimeout, ErrPoolExhausted, ErrConnectionRefused, ErrReadOnly, ErrMoved, ErrAsk:
+		return true
+	case nil:
+	default:
+		return false
+	}
+	var redisErr redis.Error
+	if errors.As(err, &redisErr) {
+		msg := redisErr.Error()
+		for _, prefix := range []string{"LOADING ", "TRYAGAIN ", "CLUSTERDOWN ", "MASTERDOWN "} {
+			if strings.HasPrefix(msg, prefix) {
+				return true
+			}
+		}
+		return helper.Equals(msg, "ERR max number of clients reached")
+	}
+	var netErr net.Error
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) || errors.As(err, &netErr)
+}
+
+// errorKinds are the sentinel errors of the classes of the errors, see ErrorKind.
+var errorKinds = []error{
+	ErrTimeout,
+	ErrPoolExhausted,
+	ErrConnectionRefused,
+	ErrReadOnly,
+	ErrMoved,
+	ErrAsk,
+	ErrWrongType,
+	ErrNoScript,
+	ErrOOM,
+}
+
+// ErrorKind returns the class of the error: ErrTimeout, ErrPoolExhausted, ErrConnectionRefused, ErrReadOnly,
+// ErrMoved, ErrAsk, ErrWrongType, ErrNoScript or ErrOOM, or nil if the error has no class. It is the class matched
+// by errors.Is on OpError, and the one reported by the otel and prometheus packages.
+func ErrorKind(err error) error {
+	var netErr net.Error
+	var redisErr redis.Error
+	if helper.IsNil(err) {
+		return nil
+	}
+	for _, kind := range errorKinds {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+	msg := err.Error()
+	if strings.HasSuffix(msg, "redis: connection pool exhausted") ||
+		strings.HasSuffix(msg, "redis: connection pool timeout") {
+		return ErrPoolExhausted
+	} else if errors.Is(err, syscall.ECONNREFUSED) {
+		return ErrConnectionRefused
+	} else if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrTimeout
+	} else if errors.As(err, &redisErr) {
+		for _, kind := range redisErrorKinds {
+			if strings.HasPrefix(redisErr.Error(), kind.prefix) {
+				return kind.kind
+			}
+		}
+	}
+	return nil
+}
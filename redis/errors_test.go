@@ -0,0 +1,75 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/redis/go-redis/v9"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+)
+
+type replyError string
+
+func (e replyError) Error() string {
+	return string(e)
+}
+
+func (replyError) RedisError() {}
+
+func TestOpError(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		err       error
+		kind      error
+		retryable bool
+	}{
+		{name: "timeout", err: context.DeadlineExceeded, kind: ErrTimeout, retryable: true},
+		{name: "pool timeout", err: errors.New("redis: connection pool timeout"), kind: ErrPoolExhausted, retryable: true},
+		{
+			name:      "connection refused",
+			err:       &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED},
+			kind:      ErrConnectionRefused,
+			retryable: true,
+		},
+		{name: "readonly", err: replyError("READONLY You can't write"), kind: ErrReadOnly, retryable: true},
+		{name: "moved", err: replyError("MOVED 3999 127.0.0.1:6381"), kind: ErrMoved, retryable: true},
+		{name: "ask", err: replyError("ASK 3999 127.0.0.1:6381"), kind: ErrAsk, retryable: true},
+		{name: "wrong type", err: replyError("WRONGTYPE Operation against a key"), kind: ErrWrongType},
+		{name: "no script", err: replyError("NOSCRIPT No matching script"), kind: ErrNoScript},
+		{name: "oom", err: replyError("OOM command not allowed"), kind: ErrOOM},
+		{name: "loading", err: replyError("LOADING Redis is loading"), retryable: true},
+		{name: "eof", err: io.EOF, retryable: true},
+		{name: "canceled", err: context.Canceled},
+		{name: "closed", err: redis.ErrClosed},
+		{name: "not found", err: ErrKeyNotFound},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewOpError("Get", "test-key", tt.err)
+			var opErr *OpError
+			if !errors.As(err, &opErr) || helper.IsNotEqualTo(opErr.Key, "test-key") || !errors.Is(err, tt.err) {
+				logger.Errorf("NewOpError() err = %v", err)
+				t.Fail()
+			}
+			if helper.IsNotNil(tt.kind) && !errors.Is(err, tt.kind) {
+				logger.Errorf("errors.Is() err = %v, want = %v", err, tt.kind)
+				t.Fail()
+			}
+			if kind := ErrorKind(err); kind != tt.kind || ErrorKind(tt.err) != tt.kind {
+				logger.Errorf("ErrorKind() result = %v, want = %v", kind, tt.kind)
+				t.Fail()
+			}
+			if IsRetryable(err) != tt.retryable {
+				logger.Errorf("IsRetryable() err = %v, want = %v", err, tt.retryable)
+				t.Fail()
+			}
+		})
+	}
+	if helper.IsNotNil(NewOpError("Get", "test-key", nil)) {
+		logger.Error("NewOpError() nil err is not nil")
+		t.Fail()
+	}
+}
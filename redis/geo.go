@@ -0,0 +1,304 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"github.com/redis/go-redis/v9"
+	"reflect"
+)
+
+// GeoLocation is a member of a geospatial index with its position, added by GeoAdd.
+//
+// It can also be used as the element of the dest of GeoSearch, since its fields have the geo tags.
+type GeoLocation struct {
+	// Member can be of any type, but cannot be null, and must be compatible with conversion to string
+	// (helper.ConvertToString), ex: a struct with the ID and the name of a courier, stored as JSON.
+	Member any `geo:"member"`
+	// Longitude of the position, from -180 to 180 degrees.
+	Longitude float64 `geo:"lon"`
+	// Latitude of the position, from -85.05112878 to 85.05112878 degrees.
+	Latitude float64 `geo:"lat"`
+	// Distance to the center of the search, filled by GeoSearch with option.GeoSearch.WithDist.
+	Distance float64 `geo:"dist"`
+}
+
+// GeoPosition is the position of a member of a geospatial index, returned by GeoPos.
+type GeoPosition struct {
+	Longitude float64
+	Latitude  float64
+}
+
+// GeoAdd redis `GEOADD key longitude latitude member [...]` command, adds the members with their positions to the
+// geospatial index of the key, or updates their positions, returning the number of members added.
+//
+// The key parameter can be of any type, but cannot be null, in case an error occurs when converting, the error
+// returned is ErrConvertKey. The members are converted like the values of Set, without compression and encryption,
+// if an error occurs when converting, the error returned is ErrConvertValue.
+func (t *Template) GeoAdd(ctx context.Context, key any, locations ...GeoLocation) (int64, error) {
+	var added int64
+	op := &Operation{Name: "GeoAdd", Keys: []any{key}, Value: locations, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		var geoLocations []*redis.GeoLocation
+		for _, location := range locations {
+			sMember, err := helper.ConvertToString(location.Member)
+			if helper.IsNotNil(err) {
+				return ErrConvertValue
+			}
+			op.ValueSize += len(sMember)
+			geoLocations = append(geoLocations, &redis.GeoLocation{
+				Name:      sMember,
+				Longitude: location.Longitude,
+				Latitude:  location.Latitude,
+			})
+		}
+		added, err = t.client.GeoAdd(ctx, sKey, geoLocations...).Result()
+		return err
+	})
+	return added, err
+}
+
+// GeoPos redis `GEOPOS key [member ...]` command, returns the position of each member, in the order informed, the
+// position of the members that do not exist is nil.
+//
+// The key and members parameters follow the GeoAdd documentation.
+func (t *Template) GeoPos(ctx context.Context, key any, members ...any) ([]*GeoPosition, error) {
+	var positions []*GeoPosition
+	op := &Operation{Name: "GeoPos", Keys: []any{key}, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		sMembers, err := convertStrings(op, members)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		result, err := t.client.GeoPos(ctx, sKey, sMembers...).Result()
+		if helper.IsNotNil(err) {
+			return err
+		}
+		positions = make([]*GeoPosition, len(result))
+		for i, pos := range result {
+			if helper.IsNotNil(pos) {
+				positions[i] = &GeoPosition{Longitude: pos.Longitude, Latitude: pos.Latitude}
+			}
+		}
+		return nil
+	})
+	return positions, err
+}
+
+// GeoDist redis `GEODIST key member1 member2 [unit]` command, returns the distance between the members in the
+// unit. If one of the members does not exist, the error returned is ErrMemberNotFound.
+//
+// The key and members parameters follow the GeoAdd documentation.
+func (t *Template) GeoDist(ctx context.Context, key, member1, member2 any, unit option.GeoUnit) (float64, error) {
+	var dist float64
+	op := &Operation{Name: "GeoDist", Keys: []any{key}, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		sMembers, err := convertStrings(op, []any{member1, member2})
+		if helper.IsNotNil(err) {
+			return err
+		}
+		dist, err = t.client.GeoDist(ctx, sKey, sMembers[0], sMembers[1], unit.String()).Result()
+		if errors.Is(err, redis.Nil) {
+			return ErrMemberNotFound
+		}
+		return err
+	})
+	return dist, err
+}
+
+// GeoSearch redis `GEOSEARCH key FROMMEMBER | FROMLONLAT BYRADIUS | BYBOX ...` command, searches the members in the
+// area of the opts parameter (option.GeoSearch), converting the results to dest.
+//
+// The dest parameter must be a pointer to a slice, if its elements are structs, the fields with the geo tags are
+// filled with the result: "member", "dist", "lon" and "lat", and if there is no field with the "member" tag, the
+// member is converted to the struct, ex: the member stored as JSON. Otherwise, the members are converted to the
+// elements, ex: *[]string. The "dist" field is filled with option.GeoSearch.WithDist, and the "lon" and "lat" with
+// option.GeoSearch.WithCoord.
+//
+// The key parameter follows the GeoAdd documentation. If the options are invalid, the error returned is
+// option.ErrInvalidGeoSearch, and if the from member does not exist, redis returns an error.
+func (t *Template) GeoSearch(ctx context.Context, key, dest any, opts ...*option.GeoSearch) error {
+	op := &Operation{Name: "GeoSearch", Keys: []any{key}, Idempotent: true}
+	return t.process(ctx, op, func(ctx context.Context) error {
+		if !isSlicePointer(dest) {
+			return ErrDestIsNotSlicePointer
+		}
+		sKey, query, opt, err := geoSearchQuery(op, key, opts)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		var result []redis.GeoLocation
+		if *opt.WithCoord || *opt.WithDist {
+			result, err = t.client.GeoSearchLocation(ctx, sKey, &redis.GeoSearchLocationQuery{
+				GeoSearchQuery: query,
+				WithCoord:      *opt.WithCoord,
+				WithDist:       *opt.WithDist,
+			}).Result()
+		} else {
+			var members []string
+			members, err = t.client.GeoSearch(ctx, sKey, &query).Result()
+			for _, member := range members {
+				result = append(result, redis.GeoLocation{Name: member})
+			}
+		}
+		if helper.IsNotNil(err) {
+			return err
+		}
+		return convertGeoLocations(result, dest)
+	})
+}
+
+// GeoSearchStore redis `GEOSEARCHSTORE destination source ...` command, stores in the destKey the members found in
+// the geospatial index of the key, returning the number of members stored. The destKey is a geospatial index,
+// or a sorted set with the distances as scores with option.GeoSearch.StoreDist.
+//
+// The destKey and key parameters, and the opts parameter (option.GeoSearch), follow the GeoSearch documentation.
+func (t *Template) GeoSearchStore(ctx context.Context, destKey, key any, opts ...*option.GeoSearch) (int64, error) {
+	var stored int64
+	op := &Operation{Name: "GeoSearchStore", Keys: []any{destKey, key}, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sDestKey, err := helper.ConvertToString(destKey)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		sKey, query, opt, err := geoSearchQuery(op, key, opts)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		stored, err = t.client.GeoSearchStore(ctx, sKey, sDestKey, &redis.GeoSearchStoreQuery{
+			GeoSearchQuery: query,
+			StoreDist:      *opt.StoreDist,
+		}).Result()
+		return err
+	})
+	return stored, err
+}
+
+// geoSearchQuery validates the options of GeoSearch and GeoSearchStore, returning the converted key and the query.
+func geoSearchQuery(
+	op *Operation,
+	key any,
+	opts []*option.GeoSearch,
+) (string, redis.GeoSearchQuery, *option.GeoSearch, error) {
+	var query redis.GeoSearchQuery
+	sKey, err := helper.ConvertToString(key)
+	if helper.IsNotNil(err) {
+		return "", query, nil, ErrConvertKey
+	}
+	opt := option.GetOptionGeoSearchByParams(opts)
+	if err = opt.Validate(); helper.IsNotNil(err) {
+		return "", query, nil, err
+	}
+	if helper.IsNotNil(opt.FromMember) {
+		sMembers, err := convertStrings(op, []any{opt.FromMember})
+		if helper.IsNotNil(err) {
+			return "", query, nil, err
+		}
+		query.Member = sMembers[0]
+	} else {
+		query.Longitude, query.Latitude = *opt.FromLongitude, *opt.FromLatitude
+	}
+	if helper.IsNotNil(opt.Radius) {
+		query.Radius, query.RadiusUnit = *opt.Radius, opt.Unit.String()
+	} else {
+		query.BoxWidth, query.BoxHeight, query.BoxUnit = *opt.Width, *opt.Height, opt.Unit.String()
+	}
+	query.Sort = opt.Sort.String()
+	query.Count = *opt.Count
+	query.CountAny = *opt.Any
+	return sKey, query, opt, nil
+}
+
+// convertStrings converts the members to string, like convertMembers, for the commands that receive []string.
+func convertStrings(op *Operation, members []any) ([]string, error) {
+	converted, err := convertMembers(op, members)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	sMembers := make([]string, len(converted))
+	for i, member := range converted {
+		sMembers[i] = member.(string)
+	}
+	return sMembers, nil
+}
+
+// convertGeoLocations converts the results of GeoSearch to the elements of the dest slice, see Template.GeoSearch.
+func convertGeoLocations(locations []redis.GeoLocation, dest any) error {
+	slice := reflect.ValueOf(dest).Elem()
+	elemType := slice.Type().Elem()
+	result := reflect.MakeSlice(slice.Type(), 0, len(locations))
+	for _, location := range locations {
+		elem := reflect.New(elemType).Elem()
+		if err := convertGeoLocation(location, elem); helper.IsNotNil(err) {
+			return err
+		}
+		result = reflect.Append(result, elem)
+	}
+	slice.Set(result)
+	return nil
+}
+
+func convertGeoLocation(location redis.GeoLocation, elem reflect.Value) error {
+	target := elem
+	if elem.Kind() == reflect.Pointer {
+		elem.Set(reflect.New(elem.Type().Elem()))
+		target = elem.Elem()
+	}
+	if target.Kind() != reflect.Struct {
+		return helper.ConvertToDest(location.Name, elem.Addr().Interface())
+	}
+	hasMember := false
+	for i := 0; i < target.NumField(); i++ {
+		hasMember = hasMember || helper.Equals(target.Type().Field(i).Tag.Get("geo"), "member")
+	}
+	if !hasMember {
+		if err := helper.ConvertToDest(location.Name, target.Addr().Interface()); helper.IsNotNil(err) {
+			return err
+		}
+	}
+	for i := 0; i < target.NumField(); i++ {
+		field := target.Field(i)
+		if !target.Type().Field(i).IsExported() {
+			continue
+		}
+		switch target.Type().Field(i).Tag.Get("geo") {
+		case "member":
+			if field.Kind() == reflect.Interface {
+				field.Set(reflect.ValueOf(location.Name))
+			} else if err := helper.ConvertToDest(location.Name, field.Addr().Interface()); helper.IsNotNil(err) {
+				return err
+			}
+		case "dist":
+			setGeoFloat(field, location.Dist)
+		case "lon":
+			setGeoFloat(field, location.Longitude)
+		case "lat":
+			setGeoFloat(field, location.Latitude)
+		}
+	}
+	return nil
+}
+
+func setGeoFloat(field reflect.Value, value float64) {
+	if field.CanFloat() {
+		field.SetFloat(value)
+	}
+}
+
+func isSlicePointer(dest any) bool {
+	value := reflect.ValueOf(dest)
+	return value.Kind() == reflect.Pointer && !value.IsNil() && value.Elem().Kind() == reflect.Slice
+}
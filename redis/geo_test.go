@@ -0,0 +1,122 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"github.com/GabrielHCataldo/go-redis-template/redis/redistest"
+	"math"
+	"testing"
+)
+
+type courier struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type courierNearby struct {
+	courier
+	Distance  float64 `json:"-" geo:"dist"`
+	Longitude float64 `json:"-" geo:"lon"`
+	Latitude  float64 `json:"-" geo:"lat"`
+}
+
+func initCouriers(t *testing.T) *redis.Template {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	added, err := redisTemplate.GeoAdd(context.TODO(), "couriers",
+		redis.GeoLocation{Member: courier{ID: 1, Name: "Ana"}, Longitude: -46.6333, Latitude: -23.5505},
+		redis.GeoLocation{Member: courier{ID: 2, Name: "Bruno"}, Longitude: -46.6520, Latitude: -23.5610},
+		redis.GeoLocation{Member: courier{ID: 3, Name: "Carla"}, Longitude: -43.1729, Latitude: -22.9068},
+	)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(added, int64(3)) {
+		logger.Errorf("GeoAdd() result = %v err = %v", added, err)
+		t.Fail()
+	}
+	return redisTemplate
+}
+
+func TestTemplateGeo(t *testing.T) {
+	redisTemplate := initCouriers(t)
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	positions, err := redisTemplate.GeoPos(ctx, "couriers", courier{ID: 1, Name: "Ana"}, "unknown")
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(len(positions), 2) || helper.IsNil(positions[0]) ||
+		math.Abs(positions[0].Longitude+46.6333) > 0.0001 || math.Abs(positions[0].Latitude+23.5505) > 0.0001 ||
+		helper.IsNotNil(positions[1]) {
+		logger.Errorf("GeoPos() result = %v err = %v", positions, err)
+		t.Fail()
+	}
+	dist, err := redisTemplate.GeoDist(ctx, "couriers", courier{ID: 1, Name: "Ana"}, courier{ID: 3, Name: "Carla"},
+		option.GeoUnitKilometers)
+	if helper.IsNotNil(err) || dist < 355 || dist > 365 {
+		logger.Errorf("GeoDist() result = %v err = %v", dist, err)
+		t.Fail()
+	}
+	_, err = redisTemplate.GeoDist(ctx, "couriers", courier{ID: 1, Name: "Ana"}, "unknown", option.GeoUnitMeters)
+	if !errors.Is(err, redis.ErrMemberNotFound) {
+		logger.Errorf("GeoDist() err = %v, want = %v", err, redis.ErrMemberNotFound)
+		t.Fail()
+	}
+}
+
+func TestTemplateGeoSearch(t *testing.T) {
+	redisTemplate := initCouriers(t)
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	var nearby []courierNearby
+	err := redisTemplate.GeoSearch(ctx, "couriers", &nearby, option.NewGeoSearch().
+		SetFromLonLat(-46.6333, -23.5505).
+		SetByRadius(10).
+		SetUnit(option.GeoUnitKilometers).
+		SetSort(option.SortOrderDesc).
+		SetWithDist(true).
+		SetWithCoord(true))
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(len(nearby), 2) || helper.IsNotEqualTo(nearby[0].Name, "Bruno") ||
+		nearby[0].Distance < 2 || nearby[0].Distance > 2.5 || math.Abs(nearby[0].Longitude+46.6520) > 0.0001 ||
+		helper.IsNotEqualTo(nearby[1].ID, 1) || nearby[1].Distance > 0.001 {
+		logger.Errorf("GeoSearch() result = %+v err = %v", nearby, err)
+		t.Fail()
+	}
+	var locations []redis.GeoLocation
+	err = redisTemplate.GeoSearch(ctx, "couriers", &locations, option.NewGeoSearch().
+		SetFromMember(courier{ID: 1, Name: "Ana"}).
+		SetByBox(1000, 1000).
+		SetUnit(option.GeoUnitKilometers).
+		SetCount(1).
+		SetAny(true))
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(len(locations), 1) || helper.IsEmpty(locations[0].Member) {
+		logger.Errorf("GeoSearch() result = %v err = %v", locations, err)
+		t.Fail()
+	}
+	var names []courier
+	err = redisTemplate.GeoSearch(ctx, "couriers", &names, option.NewGeoSearch().
+		SetFromMember(courier{ID: 3, Name: "Carla"}).
+		SetByRadius(1))
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(names, []courier{{ID: 3, Name: "Carla"}}) {
+		logger.Errorf("GeoSearch() result = %v err = %v", names, err)
+		t.Fail()
+	}
+	stored, err := redisTemplate.GeoSearchStore(ctx, "couriers:sp", "couriers", option.NewGeoSearch().
+		SetFromLonLat(-46.6333, -23.5505).
+		SetByRadius(50).
+		SetUnit(option.GeoUnitKilometers).
+		SetStoreDist(true))
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(stored, int64(2)) {
+		logger.Errorf("GeoSearchStore() result = %v err = %v", stored, err)
+		t.Fail()
+	}
+	err = redisTemplate.GeoSearch(ctx, "couriers", &names, option.NewGeoSearch().SetByRadius(1))
+	if !errors.Is(err, option.ErrInvalidGeoSearch) {
+		logger.Errorf("GeoSearch() err = %v, want = %v", err, option.ErrInvalidGeoSearch)
+		t.Fail()
+	}
+	err = redisTemplate.GeoSearch(ctx, "couriers", names, option.NewGeoSearch())
+	if !errors.Is(err, redis.ErrDestIsNotSlicePointer) {
+		logger.Errorf("GeoSearch() err = %v, want = %v", err, redis.ErrDestIsNotSlicePointer)
+		t.Fail()
+	}
+}
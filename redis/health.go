@@ -0,0 +1,171 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"net/http"
+	"time"
+)
+
+type HealthStatus string
+
+const (
+	// HealthStatusUp redis answered and no threshold was exceeded.
+	HealthStatusUp HealthStatus = "up"
+	// HealthStatusDegraded redis answered, but the latency, memory usage or replication lag exceeded the threshold,
+	// or the replica lost the link with its master.
+	HealthStatusDegraded HealthStatus = "degraded"
+	// HealthStatusDown redis did not answer.
+	HealthStatusDown HealthStatus = "down"
+)
+
+func (h HealthStatus) String() string {
+	return string(h)
+}
+
+// Health is the result of the health check (Template.HealthCheck).
+type Health struct {
+	// Status up, degraded or down.
+	Status HealthStatus `json:"status"`
+	// Reasons why the status is degraded or down.
+	Reasons []string `json:"reasons,omitempty"`
+	// Latency round-trip latency of the PING command.
+	Latency time.Duration `json:"latency"`
+	// Role of the server, master or slave.
+	Role string `json:"role,omitempty"`
+	// ReplicationLag for replicas, time since the last interaction with the master, zero when the link with the
+	// master is down, for masters, the highest lag of the connected replicas.
+	ReplicationLag time.Duration `json:"replicationLag"`
+	// UsedMemory memory allocated by redis, in bytes.
+	UsedMemory int64 `json:"usedMemory"`
+	// MaxMemory memory limit configured on the server, in bytes, zero means no limit.
+	MaxMemory int64 `json:"maxMemory"`
+	// MemoryUsage ratio between UsedMemory and MaxMemory, zero when there is no limit.
+	MemoryUsage float64 `json:"memoryUsage"`
+}
+
+// Ping redis `PING` command.
+//
+// If the return is null, redis answered successfully, otherwise an error occurred in the operation.
+func (t *Template) Ping(ctx context.Context) error {
+	op := &Operation{Name: "Ping", Idempotent: true}
+	return t.process(ctx, op, func(ctx context.Context) error {
+		return t.client.Ping(ctx).Err()
+	})
+}
+
+// HealthCheck measures the round-trip latency with the PING command and reads the role, replication lag and
+// memory usage from the `INFO` command.
+//
+// If redis does not answer, the error of the operation is returned. To report the degraded status, use the opts
+// parameter (option.Health) to inform the thresholds.
+func (t *Template) HealthCheck(ctx context.Context, opts ...*option.Health) (*Health, error) {
+	opt := option.GetOptionHealthByParams(opts)
+	startedAt := time.Now()
+	err := t.Ping(ctx)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	health := &Health{
+		Status:  HealthStatusUp,
+		Latency: time.Since(startedAt),
+	}
+	info, err := t.Info(ctx)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	checkInfo(health, info, opt)
+	return health, nil
+}
+
+// checkInfo fills the health with the role, replication lag and memory usage of the info, and sets the degraded
+// status if a threshold of the option is exceeded or the replica lost the link with its master.
+func checkInfo(health *Health, info *Info, opt *option.Health) {
+	health.Role = info.Replication.Role
+	if helper.Equals(info.Replication.Role, "slave") {
+		// while the link is down, redis reports master_last_io_seconds_ago as -1.
+		if helper.Equals(info.Replication.MasterLinkStatus, "down") || info.Replication.MasterLastIOSecondsAgo < 0 {
+			health.Reasons = append(health.Reasons, "master link down")
+		} else {
+			health.ReplicationLag = time.Duration(info.Replication.MasterLastIOSecondsAgo) * time.Second
+		}
+	}
+	for _, replica := range info.Replication.Replicas {
+		lag := time.Duration(replica.Lag) * time.Second
+		if lag > health.ReplicationLag {
+			health.ReplicationLag = lag
+		}
+	}
+	health.UsedMemory = info.Memory.UsedMemory
+	health.MaxMemory = info.Memory.MaxMemory
+	if helper.IsGreaterThan(health.MaxMemory, 0) {
+		health.MemoryUsage = float64(health.UsedMemory) / float64(health.MaxMemory)
+	}
+	if helper.IsGreaterThan(*opt.MaxLatency, 0) && health.Latency > *opt.MaxLatency {
+		health.Reasons = append(health.Reasons, fmt.Sprint("latency ", health.Latency, " above ", *opt.MaxLatency))
+	}
+	if helper.IsGreaterThan(*opt.MaxMemoryUsage, 0) && health.MemoryUsage > *opt.MaxMemoryUsage {
+		health.Reasons = append(health.Reasons, fmt.Sprintf("memory usage %.2f above %.2f", health.MemoryUsage,
+			*opt.MaxMemoryUsage))
+	}
+	if helper.IsGreaterThan(*opt.MaxReplicationLag, 0) && health.ReplicationLag > *opt.MaxReplicationLag {
+		health.Reasons = append(health.Reasons, fmt.Sprint("replication lag ", health.ReplicationLag, " above ",
+			*opt.MaxReplicationLag))
+	}
+	if helper.IsNotEmpty(health.Reasons) {
+		health.Status = HealthStatusDegraded
+	}
+}
+
+// LivenessHandler returns a http.Handler that answers 200 when redis answers the PING command, and 503 otherwise.
+//
+// To customize the timeout of the check, use the opts parameter (option.Health).
+func (t *Template) LivenessHandler(opts ...*option.Health) http.Handler {
+	opt := option.GetOptionHealthByParams(opts)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), *opt.Timeout)
+		defer cancel()
+		health := &Health{Status: HealthStatusUp}
+		startedAt := time.Now()
+		err := t.Ping(ctx)
+		health.Latency = time.Since(startedAt)
+		if helper.IsNotNil(err) {
+			health.Status = HealthStatusDown
+			health.Reasons = []string{err.Error()}
+		}
+		writeHealth(w, health)
+	})
+}
+
+// ReadinessHandler returns a http.Handler that runs the HealthCheck, answering 200 when the status is up, and 503
+// when it is degraded or down, the body is the Health in JSON.
+//
+// To customize the timeout and the thresholds of the check, use the opts parameter (option.Health).
+func (t *Template) ReadinessHandler(opts ...*option.Health) http.Handler {
+	opt := option.GetOptionHealthByParams(opts)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), *opt.Timeout)
+		defer cancel()
+		health, err := t.HealthCheck(ctx, opt)
+		if helper.IsNotNil(err) {
+			health = &Health{
+				Status:  HealthStatusDown,
+				Reasons: []string{err.Error()},
+			}
+		}
+		writeHealth(w, health)
+	})
+}
+
+func writeHealth(w http.ResponseWriter, health *Health) {
+	w.Header().Set("Content-Type", "application/json")
+	if helper.IsNotEqualTo(health.Status, HealthStatusUp) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	_ = json.NewEncoder(w).Encode(health)
+}
@@ -0,0 +1,95 @@
+package redis
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTemplatePing(t *testing.T) {
+	initTemplate()
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	err := redisTemplate.Ping(ctx)
+	if helper.IsNotNil(err) {
+		logger.Error("Ping() err =", err)
+		t.Fail()
+		return
+	}
+	logger.Info("Ping() err =", err)
+}
+
+func TestTemplateHealthCheck(t *testing.T) {
+	initTemplate()
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	result, err := redisTemplate.HealthCheck(ctx, option.NewHealth().SetMaxLatency(time.Nanosecond))
+	if helper.IsNotNil(err) {
+		logger.Error("HealthCheck() err =", err)
+		t.Fail()
+		return
+	} else if helper.IsNotEqualTo(result.Status, HealthStatusDegraded) {
+		logger.Errorf("HealthCheck() status = %v, want = %v", result.Status, HealthStatusDegraded)
+		t.Fail()
+		return
+	}
+	logger.Infof("HealthCheck() result = %v", result)
+}
+
+func TestTemplateLivenessHandler(t *testing.T) {
+	initTemplate()
+	redisTemplate.SimpleDisconnect()
+	recorder := httptest.NewRecorder()
+	redisTemplate.LivenessHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/live", nil))
+	if helper.IsNotEqualTo(recorder.Code, http.StatusServiceUnavailable) {
+		logger.Errorf("LivenessHandler() code = %v, want = %v", recorder.Code, http.StatusServiceUnavailable)
+		t.Fail()
+		return
+	}
+	logger.Infof("LivenessHandler() body = %v", recorder.Body.String())
+}
+
+func TestTemplateReadinessHandler(t *testing.T) {
+	initTemplate()
+	redisTemplate.SimpleDisconnect()
+	recorder := httptest.NewRecorder()
+	handler := redisTemplate.ReadinessHandler(option.NewHealth().SetTimeout(time.Second).SetMaxMemoryUsage(0.9))
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if helper.IsNotEqualTo(recorder.Code, http.StatusServiceUnavailable) {
+		logger.Errorf("ReadinessHandler() code = %v, want = %v", recorder.Code, http.StatusServiceUnavailable)
+		t.Fail()
+		return
+	}
+	logger.Infof("ReadinessHandler() body = %v", recorder.Body.String())
+}
+
+func TestHealthReplication(t *testing.T) {
+	opt := option.GetOptionHealthByParams([]*option.Health{option.NewHealth().SetMaxReplicationLag(time.Minute)})
+	for _, tt := range []struct {
+		name   string
+		raw    string
+		status HealthStatus
+		lag    time.Duration
+	}{
+		{"link up", "role:slave\r\nmaster_link_status:up\r\nmaster_last_io_seconds_ago:2\r\n", HealthStatusUp,
+			2 * time.Second},
+		{"link down", "role:slave\r\nmaster_link_status:down\r\nmaster_last_io_seconds_ago:-1\r\n",
+			HealthStatusDegraded, 0},
+		{"no io", "role:slave\r\nmaster_last_io_seconds_ago:-1\r\n", HealthStatusDegraded, 0},
+		{"lag", "role:slave\r\nmaster_link_status:up\r\nmaster_last_io_seconds_ago:120\r\n", HealthStatusDegraded,
+			2 * time.Minute},
+	} {
+		health := &Health{Status: HealthStatusUp}
+		checkInfo(health, ParseInfo("# Replication\r\n"+tt.raw), opt)
+		if helper.IsNotEqualTo(health.Status, tt.status) || helper.IsNotEqualTo(health.ReplicationLag, tt.lag) {
+			logger.Errorf("HealthCheck() %s status = %v lag = %v reasons = %v", tt.name, health.Status,
+				health.ReplicationLag, health.Reasons)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package redis
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/redis/go-redis/v9"
+	"net"
+	"time"
+)
+
+type OperationType string
+
+const (
+	// OperationTypeTemplate operation called on the Template, ex: Set, Get.
+	OperationTypeTemplate OperationType = "template"
+	// OperationTypeCommand command sent to redis by the underlying client.
+	OperationTypeCommand OperationType = "command"
+	// OperationTypePipeline pipeline or transaction sent to redis by the underlying client.
+	OperationTypePipeline OperationType = "pipeline"
+	// OperationTypeDial new connection established by the underlying client.
+	OperationTypeDial OperationType = "dial"
+)
+
+func (o OperationType) String() string {
+	return string(o)
+}
+
+// Operation describes an operation intercepted by a Hook.
+type Operation struct {
+	// Type of the operation.
+	Type OperationType
+	// Name of the operation, ex: "Set" for template operations, "set" for commands, "pipeline" and "dial".
+	Name string
+	// Keys original keys informed to the template operation, before conversion (only template operations).
+	Keys []any
+	// Value original value informed to the template operation, before conversion (only template operations).
+	Value any
+	// Attempt number of the attempt of the template operation, starting at 1, each attempt of the retry policy
+	// (option.RetryPolicy) is intercepted separately (only template operations).
+	Attempt int
+	// Idempotent true if the template operation has the same effect when executed more than once, the operations
+	// that are not idempotent are not retried by default (only template operations).
+	Idempotent bool
+	// Args arguments sent to redis, for pipelines they are the command names and for dials the network and
+	// address (only command, pipeline and dial operations).
+	Args []any
+	// ValueSize size in bytes of the encoded value sent to or received from redis, filled in Hook.After.
+	ValueSize int
+	// Duration of the operation, filled in Hook.After.
+	Duration time.Duration
+	// Err result of the operation, filled in Hook.After.
+	Err error
+}
+
+// Hook intercepts the operations of a Template, to register it, use Template.AddHook.
+//
+// The same hook receives the template operations, with the original key and value, and the commands, pipelines and
+// dials executed by the underlying client, use the Operation.Type field to distinguish them.
+type Hook interface {
+	// Before is called before the operation is executed, the returned context is passed on to the operation and
+	// to After. If an error is returned, the operation is not executed and the error is returned to the caller,
+	// which can be used for fault injection.
+	Before(ctx context.Context, op Operation) (context.Context, error)
+	// After is called after the operation is executed, with the Duration and Err fields filled in.
+	After(ctx context.Context, op Operation)
+}
+
+// redisHook is registered once on the underlying client and calls the hooks of the template, so hooks added later
+// are seen by the client without changing its hook chain.
+type redisHook struct {
+	template *Template
+}
+
+// AddHook registers a hook on the template and on the underlying client.
+//
+// Hooks are called in the order they were added in Hook.Before and in reverse order in Hook.After. AddHook is safe
+// for concurrent use, the operations already running keep the hooks they started with, and the hook is called from
+// the next operation.
+func (t *Template) AddHook(hook Hook) {
+	if helper.IsNil(hook) {
+		return
+	}
+	t.hooksMutex.Lock()
+	defer t.hooksMutex.Unlock()
+	current := t.loadHooks()
+	hooks := make([]Hook, len(current), len(current)+1)
+	copy(hooks, current)
+	hooks = append(hooks, hook)
+	t.hooks.Store(&hooks)
+}
+
+// loadHooks returns the hooks registered on the template, the returned slice is never modified.
+func (t *Template) loadHooks() []Hook {
+	hooks := t.hooks.Load()
+	if helper.IsNil(hooks) {
+		return nil
+	}
+	return *hooks
+}
+
+// process executes the template operation with the hooks, retrying it according to the retry policy of the
+// template or of the context, the errors are wrapped in an OpError with the name and the first key of the operation.
+func (t *Template) process(ctx context.Context, op *Operation, fn func(ctx context.Context) error) error {
+	op.Type = OperationTypeTemplate
+	var key any
+	if helper.IsNotEmpty(op.Keys) {
+		key = op.Keys[0]
+	}
+	policy := t.retryPolicy(ctx)
+	hooks := t.loadHooks()
+	for {
+		op.Attempt++
+		op.ValueSize = 0
+		err := processHooks(ctx, hooks, op, func(ctx context.Context) error {
+			return NewOpError(op.Name, key, fn(ctx))
+		})
+		err = NewOpError(op.Name, key, err)
+		if !shouldRetry(ctx, policy, op, err) || helper.IsNotNil(sleepContext(ctx, retryBackoff(policy, op.Attempt))) {
+			return err
+		}
+	}
+}
+
+func (r redisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		var conn net.Conn
+		op := &Operation{
+			Type: OperationTypeDial,
+			Name: "dial",
+			Args: []any{network, addr},
+		}
+		err := processHooks(ctx, r.template.loadHooks(), op, func(ctx context.Context) error {
+			var err error
+			conn, err = next(ctx, network, addr)
+			return err
+		})
+		return conn, err
+	}
+}
+
+func (r redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		op := &Operation{
+			Type:      OperationTypeCommand,
+			Name:      cmd.Name(),
+			Args:      cmd.Args(),
+			ValueSize: argsSize(cmd.Args()),
+		}
+		return processHooks(ctx, r.template.loadHooks(), op, func(ctx context.Context) error {
+			return next(ctx, cmd)
+		})
+	}
+}
+
+func (r redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		op := &Operation{
+			Type: OperationTypePipeline,
+			Name: "pipeline",
+		}
+		for _, cmd := range cmds {
+			op.Args = append(op.Args, cmd.Name())
+			op.ValueSize += argsSize(cmd.Args())
+		}
+		return processHooks(ctx, r.template.loadHooks(), op, func(ctx context.Context) error {
+			return next(ctx, cmds)
+		})
+	}
+}
+
+func processHooks(ctx context.Context, hooks []Hook, op *Operation, fn func(ctx context.Context) error) error {
+	var err error
+	called := 0
+	for _, hook := range hooks {
+		var hookCtx context.Context
+		hookCtx, err = hook.Before(ctx, *op)
+		if helper.IsNotNil(err) {
+			break
+		} else if helper.IsNotNil(hookCtx) {
+			ctx = hookCtx
+		}
+		called++
+	}
+	startedAt := time.Now()
+	if helper.IsNil(err) {
+		err = fn(ctx)
+	}
+	op.Duration = time.Since(startedAt)
+	op.Err = err
+	for i := called - 1; i >= 0; i-- {
+		hooks[i].After(ctx, *op)
+	}
+	return err
+}
+
+func argsSize(args []any) int {
+	size := 0
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case string:
+			size += len(v)
+		case []byte:
+			size += len(v)
+		}
+	}
+	return size
+}
@@ -0,0 +1,94 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testHook struct {
+	mutex  sync.Mutex
+	err    error
+	before []Operation
+	after  []Operation
+}
+
+func (h *testHook) Before(ctx context.Context, op Operation) (context.Context, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.before = append(h.before, op)
+	if helper.Equals(op.Type, OperationTypeTemplate) {
+		return ctx, h.err
+	}
+	return ctx, nil
+}
+
+func (h *testHook) After(_ context.Context, op Operation) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.after = append(h.after, op)
+}
+
+func (h *testHook) find(opType OperationType) *Operation {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for _, op := range h.after {
+		if helper.Equals(op.Type, opType) {
+			return &op
+		}
+	}
+	return nil
+}
+
+func TestTemplateAddHook(t *testing.T) {
+	initTemplate()
+	hook := &testHook{}
+	redisTemplate.AddHook(hook)
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	value := initTestStruct()
+	err := redisTemplate.Set(ctx, redisKeyDefault, value, initOptionSet())
+	op := hook.find(OperationTypeTemplate)
+	if helper.IsNil(op) || helper.IsNotEqualTo(op.Name, "Set") || helper.IsNotEqualTo(op.Keys, []any{redisKeyDefault}) ||
+		helper.IsNotEqualTo(op.Value, value) || helper.IsLessThanOrEqual(op.ValueSize, 0) ||
+		helper.IsNotEqualTo(op.Err, err) {
+		logger.Errorf("AddHook() template operation = %v, err = %v", op, err)
+		t.Fail()
+		return
+	}
+	if helper.IsNil(hook.find(OperationTypeCommand)) || helper.IsNil(hook.find(OperationTypeDial)) {
+		logger.Errorf("AddHook() command and dial operations not intercepted: %v", hook.after)
+		t.Fail()
+		return
+	}
+	logger.Infof("AddHook() operations = %v", hook.after)
+}
+
+func TestTemplateAddHookFault(t *testing.T) {
+	initTemplate()
+	errFault := errors.New("fault injection")
+	hook := &testHook{err: errFault}
+	redisTemplate.AddHook(nil)
+	redisTemplate.AddHook(hook)
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	err := redisTemplate.Get(ctx, redisKeyDefault, &testStruct{})
+	if !errors.Is(err, errFault) || helper.IsNotNil(hook.find(OperationTypeCommand)) {
+		logger.Errorf("Get() err = %v, want = %v", err, errFault)
+		t.Fail()
+		return
+	}
+	result := redisTemplate.MSet(ctx, initMSetInputs()...)
+	for _, output := range result {
+		if !errors.Is(output.Err, errFault) {
+			logger.Errorf("MSet() err = %v, want = %v", output.Err, errFault)
+			t.Fail()
+			return
+		}
+	}
+	logger.Infof("Get() err = %v", err)
+}
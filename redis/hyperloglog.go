@@ -0,0 +1,67 @@
+package redis
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+)
+
+// PFAdd redis `PFADD key [element ...]` command, adds the members to the HyperLogLog of the key, returning true if
+// the estimated cardinality changed.
+//
+// The key and members parameters can be of any type, but cannot be null, in case an error occurs when converting the
+// key or a member, the error returned is ErrConvertKey or ErrConvertValue respectively. The members are converted
+// like the values of Set, without compression and encryption, since the same member must always have the same
+// encoding to be counted once.
+func (t *Template) PFAdd(ctx context.Context, key any, members ...any) (bool, error) {
+	var changed bool
+	op := &Operation{Name: "PFAdd", Keys: []any{key}, Value: members, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		sMembers, err := convertMembers(op, members)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		result, err := t.client.PFAdd(ctx, sKey, sMembers...).Result()
+		changed = helper.Equals(result, int64(1))
+		return err
+	})
+	return changed, err
+}
+
+// PFCount redis `PFCOUNT key [key ...]` command, returns the estimated number of unique members of the HyperLogLog
+// of the key, or of the union of the HyperLogLogs of the keys, with a standard error of 0.81%.
+//
+// The keys parameter can be of any type, but cannot be empty, in case an error occurs when converting, the error
+// returned is ErrConvertKey. On redis cluster, the keys must have the same hash slot, ex: "{visitors}:1", to be
+// counted together.
+func (t *Template) PFCount(ctx context.Context, keys ...any) (int64, error) {
+	var count int64
+	op := &Operation{Name: "PFCount", Keys: keys, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKeys, err := convertKeys(keys)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		count, err = t.client.PFCount(ctx, sKeys...).Result()
+		return err
+	})
+	return count, err
+}
+
+// PFMerge redis `PFMERGE destkey [sourcekey ...]` command, stores in the destKey the union of the HyperLogLogs of
+// the keys and of the destKey.
+//
+// The destKey and keys parameters follow the PFCount documentation.
+func (t *Template) PFMerge(ctx context.Context, destKey any, keys ...any) error {
+	op := &Operation{Name: "PFMerge", Keys: append([]any{destKey}, keys...), Idempotent: true}
+	return t.process(ctx, op, func(ctx context.Context) error {
+		sKeys, err := convertKeys(op.Keys)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		return t.client.PFMerge(ctx, sKeys[0], sKeys[1:]...).Err()
+	})
+}
@@ -0,0 +1,101 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"github.com/GabrielHCataldo/go-redis-template/redis/redistest"
+	redisdriver "github.com/redis/go-redis/v9"
+	"testing"
+	"time"
+)
+
+func TestTemplateHyperLogLog(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	changed, err := redisTemplate.PFAdd(ctx, "visitors:1", 1, 2, "3")
+	if helper.IsNotNil(err) || !changed {
+		logger.Errorf("PFAdd() result = %v err = %v", changed, err)
+		t.Fail()
+	}
+	changed, err = redisTemplate.PFAdd(ctx, "visitors:1", 2, 3)
+	if helper.IsNotNil(err) || changed {
+		logger.Errorf("PFAdd() repeated result = %v err = %v", changed, err)
+		t.Fail()
+	}
+	_, _ = redisTemplate.PFAdd(ctx, "visitors:2", 3, 4)
+	count, err := redisTemplate.PFCount(ctx, "visitors:1", "visitors:2")
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(count, int64(4)) {
+		logger.Errorf("PFCount() result = %v err = %v", count, err)
+		t.Fail()
+	}
+	err = redisTemplate.PFMerge(ctx, "visitors:all", "visitors:1", "visitors:2")
+	count, _ = redisTemplate.PFCount(ctx, "visitors:all")
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(count, int64(4)) {
+		logger.Errorf("PFMerge() count = %v err = %v", count, err)
+		t.Fail()
+	}
+	_, err = redisTemplate.PFAdd(ctx, "visitors:1", nil)
+	if !errors.Is(err, redis.ErrConvertValue) {
+		logger.Errorf("PFAdd() err = %v, want = %v", err, redis.ErrConvertValue)
+		t.Fail()
+	}
+	_ = redisTemplate.Set(ctx, "visitors:string", "value")
+	_, err = redisTemplate.PFCount(ctx, "visitors:string")
+	if !errors.Is(err, redis.ErrWrongType) {
+		logger.Errorf("PFCount() err = %v, want = %v", err, redis.ErrWrongType)
+		t.Fail()
+	}
+}
+
+func TestUniqueCounter(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	counter := redis.NewUniqueCounter(redisTemplate, []any{"visitors", "home"},
+		option.NewUniqueCounter().SetBucket(24*time.Hour).SetTTL(7*24*time.Hour))
+	day := time.Date(2024, 1, 10, 15, 0, 0, 0, time.UTC)
+	if helper.IsNotEqualTo(counter.Key(day), "visitors:home:1704844800") {
+		logger.Error("Key() result =", counter.Key(day))
+		t.Fail()
+	}
+	_, _ = counter.AddAt(ctx, day.Add(-48*time.Hour), "a", "b")
+	_, _ = counter.AddAt(ctx, day.Add(-24*time.Hour), "b", "c")
+	changed, err := counter.AddAt(ctx, day, "c", "d")
+	if helper.IsNotNil(err) || !changed {
+		logger.Errorf("AddAt() result = %v err = %v", changed, err)
+		t.Fail()
+	}
+	for buckets, want := range map[int]int64{0: 2, 1: 2, 2: 3, 3: 4} {
+		count, err := counter.CountAt(ctx, day, buckets)
+		if helper.IsNotNil(err) || helper.IsNotEqualTo(count, want) {
+			logger.Errorf("CountAt() %d buckets result = %v err = %v, want = %v", buckets, count, err, want)
+			t.Fail()
+		}
+	}
+	err = counter.MergeAt(ctx, day, "visitors:home:week", 3)
+	count, _ := redisTemplate.PFCount(ctx, "visitors:home:week")
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(count, int64(4)) {
+		logger.Errorf("MergeAt() count = %v err = %v", count, err)
+		t.Fail()
+	}
+	client := redisdriver.NewClient(server.ClientOptions().ParseToRedisOptions())
+	defer client.Close()
+	ttl, _ := client.TTL(ctx, counter.Key(day)).Result()
+	if ttl <= 0 || ttl > 7*24*time.Hour {
+		logger.Error("AddAt() ttl =", ttl)
+		t.Fail()
+	}
+	_, err = counter.Add(ctx, "e")
+	count, _ = counter.Count(ctx, 1)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(count, int64(1)) {
+		logger.Errorf("Count() result = %v err = %v", count, err)
+		t.Fail()
+	}
+}
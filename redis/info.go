@@ -0,0 +1,224 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"strconv"
+	"strings"
+)
+
+// Info is the typed result of the redis `INFO` command.
+type Info struct {
+	// Server fields of the server section.
+	Server InfoServer
+	// Clients fields of the clients section.
+	Clients InfoClients
+	// Memory fields of the memory section.
+	Memory InfoMemory
+	// Replication fields of the replication section.
+	Replication InfoReplication
+	// Keyspace statistics by database number.
+	Keyspace map[int]InfoKeyspace
+	// Sections all raw fields by section name (lower case), ex: Sections["server"]["redis_version"].
+	Sections map[string]map[string]string
+}
+
+type InfoServer struct {
+	RedisVersion    string
+	RedisMode       string
+	OS              string
+	TCPPort         int64
+	UptimeInSeconds int64
+}
+
+type InfoClients struct {
+	ConnectedClients int64
+	BlockedClients   int64
+	MaxClients       int64
+}
+
+type InfoMemory struct {
+	UsedMemory            int64
+	UsedMemoryRss         int64
+	UsedMemoryPeak        int64
+	MaxMemory             int64
+	MaxMemoryPolicy       string
+	MemFragmentationRatio float64
+}
+
+type InfoReplication struct {
+	// Role can be master or slave.
+	Role                   string
+	ConnectedSlaves        int64
+	MasterReplOffset       int64
+	MasterHost             string
+	MasterPort             int64
+	MasterLinkStatus       string
+	MasterLastIOSecondsAgo int64
+	SlaveReplOffset        int64
+	// Replicas connected to the master, parsed from the slaveN fields.
+	Replicas []InfoReplica
+}
+
+type InfoReplica struct {
+	IP     string
+	Port   int64
+	State  string
+	Offset int64
+	// Lag seconds since the last interaction with the replica.
+	Lag int64
+}
+
+type InfoKeyspace struct {
+	Keys    int64
+	Expires int64
+	AvgTTL  int64
+}
+
+// Info redis `INFO [section ...]` command, parsed into the typed Info struct.
+//
+// If no section is informed, the default sections are returned by redis.
+func (t *Template) Info(ctx context.Context, sections ...string) (*Info, error) {
+	var info *Info
+	op := &Operation{Name: "Info", Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		result, err := t.client.Info(ctx, sections...).Result()
+		if helper.IsNotNil(err) {
+			return err
+		}
+		op.ValueSize = len(result)
+		info = ParseInfo(result)
+		return nil
+	})
+	return info, err
+}
+
+// ParseInfo parses the raw result of the redis `INFO` command.
+func ParseInfo(raw string) *Info {
+	info := &Info{
+		Keyspace: map[int]InfoKeyspace{},
+		Sections: map[string]map[string]string{},
+	}
+	section := ""
+	scanner := bufio.NewScanner(strings.NewReader(raw))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if helper.IsEmpty(line) {
+			continue
+		} else if strings.HasPrefix(line, "#") {
+			section = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(line, "#")))
+			info.Sections[section] = map[string]string{}
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		if helper.IsNil(info.Sections[section]) {
+			info.Sections[section] = map[string]string{}
+		}
+		info.Sections[section][key] = value
+		info.parseField(section, key, value)
+	}
+	return info
+}
+
+func (i *Info) parseField(section, key, value string) {
+	switch section {
+	case "server":
+		switch key {
+		case "redis_version":
+			i.Server.RedisVersion = value
+		case "redis_mode":
+			i.Server.RedisMode = value
+		case "os":
+			i.Server.OS = value
+		case "tcp_port":
+			i.Server.TCPPort = parseInfoInt(value)
+		case "uptime_in_seconds":
+			i.Server.UptimeInSeconds = parseInfoInt(value)
+		}
+	case "clients":
+		switch key {
+		case "connected_clients":
+			i.Clients.ConnectedClients = parseInfoInt(value)
+		case "blocked_clients":
+			i.Clients.BlockedClients = parseInfoInt(value)
+		case "maxclients":
+			i.Clients.MaxClients = parseInfoInt(value)
+		}
+	case "memory":
+		switch key {
+		case "used_memory":
+			i.Memory.UsedMemory = parseInfoInt(value)
+		case "used_memory_rss":
+			i.Memory.UsedMemoryRss = parseInfoInt(value)
+		case "used_memory_peak":
+			i.Memory.UsedMemoryPeak = parseInfoInt(value)
+		case "maxmemory":
+			i.Memory.MaxMemory = parseInfoInt(value)
+		case "maxmemory_policy":
+			i.Memory.MaxMemoryPolicy = value
+		case "mem_fragmentation_ratio":
+			i.Memory.MemFragmentationRatio, _ = strconv.ParseFloat(value, 64)
+		}
+	case "replication":
+		switch key {
+		case "role":
+			i.Replication.Role = value
+		case "connected_slaves":
+			i.Replication.ConnectedSlaves = parseInfoInt(value)
+		case "master_repl_offset":
+			i.Replication.MasterReplOffset = parseInfoInt(value)
+		case "master_host":
+			i.Replication.MasterHost = value
+		case "master_port":
+			i.Replication.MasterPort = parseInfoInt(value)
+		case "master_link_status":
+			i.Replication.MasterLinkStatus = value
+		case "master_last_io_seconds_ago":
+			i.Replication.MasterLastIOSecondsAgo = parseInfoInt(value)
+		case "slave_repl_offset":
+			i.Replication.SlaveReplOffset = parseInfoInt(value)
+		default:
+			if _, err := strconv.Atoi(strings.TrimPrefix(key, "slave")); strings.HasPrefix(key, "slave") &&
+				helper.IsNil(err) {
+				fields := parseInfoFields(value)
+				i.Replication.Replicas = append(i.Replication.Replicas, InfoReplica{
+					IP:     fields["ip"],
+					Port:   parseInfoInt(fields["port"]),
+					State:  fields["state"],
+					Offset: parseInfoInt(fields["offset"]),
+					Lag:    parseInfoInt(fields["lag"]),
+				})
+			}
+		}
+	case "keyspace":
+		db, err := strconv.Atoi(strings.TrimPrefix(key, "db"))
+		if helper.IsNil(err) {
+			fields := parseInfoFields(value)
+			i.Keyspace[db] = InfoKeyspace{
+				Keys:    parseInfoInt(fields["keys"]),
+				Expires: parseInfoInt(fields["expires"]),
+				AvgTTL:  parseInfoInt(fields["avg_ttl"]),
+			}
+		}
+	}
+}
+
+func parseInfoFields(value string) map[string]string {
+	fields := map[string]string{}
+	for _, field := range strings.Split(value, ",") {
+		k, v, ok := strings.Cut(field, "=")
+		if ok {
+			fields[k] = v
+		}
+	}
+	return fields
+}
+
+func parseInfoInt(value string) int64 {
+	i, _ := strconv.ParseInt(value, 10, 64)
+	return i
+}
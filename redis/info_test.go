@@ -0,0 +1,43 @@
+package redis
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"testing"
+	"time"
+)
+
+const infoRaw = "# Server\r\nredis_version:7.2.4\r\nredis_mode:standalone\r\nos:Linux 6.5.0 x86_64\r\n" +
+	"tcp_port:6379\r\nuptime_in_seconds:3600\r\n\r\n# Clients\r\nconnected_clients:2\r\nblocked_clients:0\r\n" +
+	"maxclients:10000\r\n\r\n# Memory\r\nused_memory:1048576\r\nused_memory_rss:2097152\r\n" +
+	"used_memory_peak:3145728\r\nmaxmemory:4194304\r\nmaxmemory_policy:allkeys-lru\r\n" +
+	"mem_fragmentation_ratio:2.00\r\n\r\n# Replication\r\nrole:master\r\nconnected_slaves:1\r\n" +
+	"slave0:ip=10.0.0.2,port=6379,state=online,offset=1024,lag=3\r\nmaster_repl_offset:1030\r\n\r\n" +
+	"# Keyspace\r\ndb0:keys=10,expires=2,avg_ttl=5000\r\n"
+
+func TestParseInfo(t *testing.T) {
+	info := ParseInfo(infoRaw)
+	if helper.IsNotEqualTo(info.Server.RedisVersion, "7.2.4") || helper.IsNotEqualTo(info.Memory.MaxMemory, 4194304) ||
+		helper.IsNotEqualTo(info.Replication.Role, "master") || helper.IsNotEqualTo(len(info.Replication.Replicas), 1) ||
+		helper.IsNotEqualTo(info.Replication.Replicas[0].Lag, 3) || helper.IsNotEqualTo(info.Keyspace[0].Keys, 10) ||
+		helper.IsNotEqualTo(info.Sections["clients"]["maxclients"], "10000") {
+		logger.Errorf("ParseInfo() result = %v", info)
+		t.Fail()
+		return
+	}
+	logger.Infof("ParseInfo() result = %v", info)
+}
+
+func TestTemplateInfo(t *testing.T) {
+	initTemplate()
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	result, err := redisTemplate.Info(ctx, "server")
+	if helper.IsNotNil(err) {
+		logger.Error("Info() err =", err)
+		t.Fail()
+		return
+	}
+	logger.Infof("Info() result = %v", result.Server)
+}
@@ -0,0 +1,54 @@
+package redis
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"net/http"
+)
+
+// Interface contains the operations of the Template, depend on it in your services so that the template can be
+// replaced by the in-memory implementation of the redistest package in unit tests.
+type Interface interface {
+	// Set supports all options that the SET command supports, see Template.Set.
+	Set(ctx context.Context, key, value any, opts ...*option.Set) error
+	// MSet defines N values, see Template.MSet.
+	MSet(ctx context.Context, values ...MSetInput) []MSetOutput
+	// SetGet sets the value and converts the previous value to dest, see Template.SetGet.
+	SetGet(ctx context.Context, key, value, dest any, opts ...*option.Set) error
+	// Rename redis key, see Template.Rename.
+	Rename(ctx context.Context, key, newKey any) error
+	// Get redis `GET key` command, see Template.Get.
+	Get(ctx context.Context, key, dest any) error
+	// GetDel get and delete value by key, see Template.GetDel.
+	GetDel(ctx context.Context, key, dest any) error
+	// Exists redis values by key, see Template.Exists.
+	Exists(ctx context.Context, key any) (bool, error)
+	// Keys return list of keys by pattern, see Template.Keys.
+	Keys(ctx context.Context, pattern string) ([]string, error)
+	// Scan return list keys pageable by match, see Template.Scan.
+	Scan(ctx context.Context, cursor uint64, match string, count int64) (ScanOutput, error)
+	// Del delete redis keys, see Template.Del.
+	Del(ctx context.Context, keys ...any) error
+	// SprintKey format values as prefix in string for a future redis key, see Template.SprintKey.
+	SprintKey(vs ...any) string
+	// Ping redis `PING` command, see Template.Ping.
+	Ping(ctx context.Context) error
+	// HealthCheck measures the latency and reads the replication and memory state, see Template.HealthCheck.
+	HealthCheck(ctx context.Context, opts ...*option.Health) (*Health, error)
+	// Info redis `INFO [section ...]` command, see Template.Info.
+	Info(ctx context.Context, sections ...string) (*Info, error)
+	// AddHook registers a hook on the operations, see Template.AddHook.
+	AddHook(hook Hook)
+	// PoolStats returns the connection pool stats, see Template.PoolStats.
+	PoolStats() PoolStats
+	// LivenessHandler returns a http.Handler answering with the result of Ping, see Template.LivenessHandler.
+	LivenessHandler(opts ...*option.Health) http.Handler
+	// ReadinessHandler returns a http.Handler answering with the HealthCheck, see Template.ReadinessHandler.
+	ReadinessHandler(opts ...*option.Health) http.Handler
+	// Disconnect close connection to redis, see Template.Disconnect.
+	Disconnect() error
+	// SimpleDisconnect close connection to redis without error, see Template.SimpleDisconnect.
+	SimpleDisconnect()
+}
+
+var _ Interface = (*Template)(nil)
@@ -0,0 +1,292 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"github.com/redis/go-redis/v9"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// jsonRootPath is the path used by the RedisJSON operations when the path is empty.
+const jsonRootPath = "$"
+
+// jsonCodec is the default option.JSONCodec, with the encoding/json package.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func newJSONCodec(codec option.JSONCodec) option.JSONCodec {
+	if helper.IsNil(codec) {
+		return jsonCodec{}
+	}
+	return codec
+}
+
+// JSONSet redis `JSON.SET key path value [NX | XX]` command, sets the value encoded as JSON at the path of the
+// document of the key, returning false if the value was not set because of the option.JSONSet.Mode.
+//
+// The key parameter can be of any type, but cannot be null, in case an error occurs when converting, the error
+// returned is ErrConvertKey. The path is a JSONPath, ex: "$.address.city", empty means the root, and a new
+// document must be set at the root.
+//
+// The value is encoded with the option.Client.JSONCodec of the template, without compression and encryption, in case
+// an error occurs when encoding, the error returned is ErrConvertValue.
+func (t *Template) JSONSet(ctx context.Context, key any, path string, value any, opts ...*option.JSONSet) (bool,
+	error) {
+	var set bool
+	opt := option.GetOptionJSONSetByParams(opts)
+	op := &Operation{Name: "JSONSet", Keys: []any{key}, Value: value, Idempotent: *opt.Mode != option.SetModeNx}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		switch *opt.Mode {
+		case option.SetModeDefault, option.SetModeNx, option.SetModeXx:
+		default:
+			return option.ErrInvalidSetMode
+		}
+		sValue, err := t.encodeJSON(op, value)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		err = t.client.JSONSetMode(ctx, sKey, jsonPath(path), sValue, opt.Mode.String()).Err()
+		if errors.Is(err, redis.Nil) {
+			set = false
+			return nil
+		}
+		set = helper.IsNil(err)
+		return err
+	})
+	return set, err
+}
+
+// JSONGet redis `JSON.GET key path` command, decodes the first value found at the path of the document of the key
+// into dest, with the option.Client.JSONCodec of the template.
+//
+// The key and path parameters follow the JSONSet documentation. If the key does not exist, the error returned is
+// ErrKeyNotFound, and if the path has no value, the error returned is ErrJSONPathNotFound.
+//
+// The dest parameter must be a pointer, ex: a pointer to the struct that was set.
+func (t *Template) JSONGet(ctx context.Context, key any, path string, dest any) error {
+	op := &Operation{Name: "JSONGet", Keys: []any{key}, Idempotent: true}
+	return t.process(ctx, op, func(ctx context.Context) error {
+		if !helper.IsPointerType(dest) {
+			return ErrDestIsNotPointer
+		}
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		sPath := jsonPath(path)
+		result, err := t.client.JSONGet(ctx, sKey, sPath).Result()
+		if errors.Is(err, redis.Nil) || (helper.IsNil(err) && helper.IsEmpty(result)) {
+			return ErrKeyNotFound
+		} else if helper.IsNotNil(err) {
+			return err
+		}
+		op.ValueSize = len(result)
+		value, err := firstJSONValue(sPath, result)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		return t.jsonCodec.Unmarshal(value, dest)
+	})
+}
+
+// JSONMGet redis `JSON.MGET key [key ...] path` command, decodes the first value found at the path of the document
+// of each key into the element of dest at the same position.
+//
+// The dest parameter must be a pointer to a slice, the elements of the keys that do not exist or have no value at
+// the path are zero, ex: nil with *[]*Product. The path and keys parameters follow the JSONSet documentation.
+func (t *Template) JSONMGet(ctx context.Context, path string, dest any, keys ...any) error {
+	op := &Operation{Name: "JSONMGet", Keys: keys, Idempotent: true}
+	return t.process(ctx, op, func(ctx context.Context) error {
+		if !isSlicePointer(dest) {
+			return ErrDestIsNotSlicePointer
+		}
+		sKeys, err := convertKeys(keys)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		sPath := jsonPath(path)
+		args := []any{"JSON.MGET"}
+		for _, sKey := range sKeys {
+			args = append(args, sKey)
+		}
+		results, err := t.client.Do(ctx, append(args, sPath)...).Slice()
+		if helper.IsNotNil(err) {
+			return err
+		}
+		slice := reflect.ValueOf(dest).Elem()
+		values := reflect.MakeSlice(slice.Type(), len(results), len(results))
+		for i, result := range results {
+			sResult, ok := result.(string)
+			if !ok {
+				continue
+			}
+			op.ValueSize += len(sResult)
+			value, err := firstJSONValue(sPath, sResult)
+			if errors.Is(err, ErrJSONPathNotFound) {
+				continue
+			} else if helper.IsNotNil(err) {
+				return err
+			} else if err = t.jsonCodec.Unmarshal(value, values.Index(i).Addr().Interface()); helper.IsNotNil(err) {
+				return err
+			}
+		}
+		slice.Set(values)
+		return nil
+	})
+}
+
+// JSONDel redis `JSON.DEL key path` command, deletes the values at the path of the document of the key, returning
+// the number of values deleted, deleting the root deletes the key.
+//
+// The key and path parameters follow the JSONSet documentation.
+func (t *Template) JSONDel(ctx context.Context, key any, path string) (int64, error) {
+	var deleted int64
+	op := &Operation{Name: "JSONDel", Keys: []any{key}, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		deleted, err = t.client.JSONDel(ctx, sKey, jsonPath(path)).Result()
+		return err
+	})
+	return deleted, err
+}
+
+// JSONArrAppend redis `JSON.ARRAPPEND key path value [value ...]` command, appends the values to the arrays at the
+// path of the document of the key, returning the new length of the first array. If the path has no array, the
+// error returned is ErrJSONPathNotFound.
+//
+// The key, path and values parameters follow the JSONSet documentation.
+func (t *Template) JSONArrAppend(ctx context.Context, key any, path string, values ...any) (int64, error) {
+	var length int64
+	op := &Operation{Name: "JSONArrAppend", Keys: []any{key}, Value: values}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		args := []any{"JSON.ARRAPPEND", sKey, jsonPath(path)}
+		for _, value := range values {
+			sValue, err := t.encodeJSON(op, value)
+			if helper.IsNotNil(err) {
+				return err
+			}
+			args = append(args, sValue)
+		}
+		result, err := t.client.Do(ctx, args...).Result()
+		if helper.IsNotNil(err) {
+			return err
+		} else if results, ok := result.([]any); ok {
+			result = nil
+			if helper.IsNotEmpty(results) {
+				result = results[0]
+			}
+		}
+		var ok bool
+		if length, ok = result.(int64); !ok {
+			return ErrJSONPathNotFound
+		}
+		return nil
+	})
+	return length, err
+}
+
+// JSONNumIncrBy redis `JSON.NUMINCRBY key path value` command, increments the numbers at the path of the document
+// of the key, returning the new value of the first number. If the path has no number, the error returned is
+// ErrJSONPathNotFound.
+//
+// The key and path parameters follow the JSONSet documentation.
+func (t *Template) JSONNumIncrBy(ctx context.Context, key any, path string, increment float64) (float64, error) {
+	var number float64
+	op := &Operation{Name: "JSONNumIncrBy", Keys: []any{key}, Value: increment}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		sPath := jsonPath(path)
+		result, err := t.client.JSONNumIncrBy(ctx, sKey, sPath, increment).Result()
+		if helper.IsNotNil(err) {
+			return err
+		}
+		value, err := firstJSONValue(sPath, result)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		number, err = strconv.ParseFloat(string(value), 64)
+		if helper.IsNotNil(err) {
+			return ErrJSONPathNotFound
+		}
+		return nil
+	})
+	return number, err
+}
+
+// JSONMerge redis `JSON.MERGE key path value` command, merges the value encoded as JSON into the values at the path
+// of the document of the key, following the RFC 7396 (JSON Merge Patch): the fields of the value are set, and the
+// null fields are deleted, ex: a map with the fields to update. It requires RedisJSON version >= 2.6.
+//
+// The key, path and value parameters follow the JSONSet documentation.
+func (t *Template) JSONMerge(ctx context.Context, key any, path string, value any) error {
+	op := &Operation{Name: "JSONMerge", Keys: []any{key}, Value: value, Idempotent: true}
+	return t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		sValue, err := t.encodeJSON(op, value)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		return t.client.JSONMerge(ctx, sKey, jsonPath(path), sValue).Err()
+	})
+}
+
+// encodeJSON encodes the value with the codec of the template, adding its size to the operation.
+func (t *Template) encodeJSON(op *Operation, value any) (string, error) {
+	data, err := t.jsonCodec.Marshal(value)
+	if helper.IsNotNil(err) {
+		return "", ErrConvertValue
+	}
+	op.ValueSize += len(data)
+	return string(data), nil
+}
+
+func jsonPath(path string) string {
+	if helper.IsEmpty(path) {
+		return jsonRootPath
+	}
+	return path
+}
+
+// firstJSONValue returns the first value of the result of the path, the JSONPath ($) results are arrays with the
+// values found, and the legacy paths results are the value itself.
+func firstJSONValue(path, result string) ([]byte, error) {
+	if !strings.HasPrefix(path, jsonRootPath) {
+		return []byte(result), nil
+	}
+	var values []json.RawMessage
+	if err := json.Unmarshal([]byte(result), &values); helper.IsNotNil(err) {
+		return nil, err
+	} else if helper.IsEmpty(values) {
+		return nil, ErrJSONPathNotFound
+	}
+	return values[0], nil
+}
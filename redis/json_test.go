@@ -0,0 +1,157 @@
+package redis_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"github.com/GabrielHCataldo/go-redis-template/redis/redistest"
+	"reflect"
+	"testing"
+)
+
+type product struct {
+	Name  string   `json:"name"`
+	Price float64  `json:"price"`
+	Stock int      `json:"stock"`
+	Tags  []string `json:"tags"`
+}
+
+// countingCodec counts the documents encoded and decoded with encoding/json.
+type countingCodec struct {
+	marshaled   int
+	unmarshaled int
+}
+
+func (c *countingCodec) Marshal(v any) ([]byte, error) {
+	c.marshaled++
+	return json.Marshal(v)
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v any) error {
+	c.unmarshaled++
+	return json.Unmarshal(data, v)
+}
+
+func TestTemplateJSON(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	keyboard := product{Name: "keyboard", Price: 49.9, Stock: 10, Tags: []string{"usb"}}
+	set, err := redisTemplate.JSONSet(ctx, "product:1", "", keyboard)
+	if helper.IsNotNil(err) || !set {
+		logger.Errorf("JSONSet() result = %v err = %v", set, err)
+		t.Fail()
+	}
+	var result product
+	err = redisTemplate.JSONGet(ctx, "product:1", "$", &result)
+	if helper.IsNotNil(err) || !reflect.DeepEqual(result, keyboard) {
+		logger.Errorf("JSONGet() result = %v err = %v", result, err)
+		t.Fail()
+	}
+	set, err = redisTemplate.JSONSet(ctx, "product:1", "$", keyboard, option.NewJSONSet().SetMode(option.SetModeNx))
+	if helper.IsNotNil(err) || set {
+		logger.Errorf("JSONSet() NX result = %v err = %v", set, err)
+		t.Fail()
+	}
+	_, _ = redisTemplate.JSONSet(ctx, "product:1", "$.name", "mechanical keyboard")
+	var name string
+	err = redisTemplate.JSONGet(ctx, "product:1", "$.name", &name)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(name, "mechanical keyboard") {
+		logger.Errorf("JSONGet() path result = %v err = %v", name, err)
+		t.Fail()
+	}
+	length, err := redisTemplate.JSONArrAppend(ctx, "product:1", "$.tags", "wireless", "rgb")
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(length, int64(3)) {
+		logger.Errorf("JSONArrAppend() result = %v err = %v", length, err)
+		t.Fail()
+	}
+	stock, err := redisTemplate.JSONNumIncrBy(ctx, "product:1", "$.stock", -3)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(stock, float64(7)) {
+		logger.Errorf("JSONNumIncrBy() result = %v err = %v", stock, err)
+		t.Fail()
+	}
+	_, err = redisTemplate.JSONNumIncrBy(ctx, "product:1", "$.name", 1)
+	if !errors.Is(err, redis.ErrJSONPathNotFound) {
+		logger.Errorf("JSONNumIncrBy() err = %v, want = %v", err, redis.ErrJSONPathNotFound)
+		t.Fail()
+	}
+	err = redisTemplate.JSONMerge(ctx, "product:1", "$", map[string]any{"price": 39.9, "tags": nil})
+	result = product{}
+	_ = redisTemplate.JSONGet(ctx, "product:1", "", &result)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(result.Price, 39.9) || helper.IsNotNil(result.Tags) {
+		logger.Errorf("JSONMerge() result = %v err = %v", result, err)
+		t.Fail()
+	}
+	deleted, err := redisTemplate.JSONDel(ctx, "product:1", "$.stock")
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(deleted, int64(1)) {
+		logger.Errorf("JSONDel() result = %v err = %v", deleted, err)
+		t.Fail()
+	}
+	err = redisTemplate.JSONGet(ctx, "product:1", "$.stock", &stock)
+	if !errors.Is(err, redis.ErrJSONPathNotFound) {
+		logger.Errorf("JSONGet() err = %v, want = %v", err, redis.ErrJSONPathNotFound)
+		t.Fail()
+	}
+	err = redisTemplate.JSONGet(ctx, "product:2", "", &result)
+	if !errors.Is(err, redis.ErrKeyNotFound) {
+		logger.Errorf("JSONGet() err = %v, want = %v", err, redis.ErrKeyNotFound)
+		t.Fail()
+	}
+}
+
+func TestTemplateJSONMGet(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	_, _ = redisTemplate.JSONSet(ctx, "product:1", "", product{Name: "keyboard", Price: 49.9})
+	_, _ = redisTemplate.JSONSet(ctx, "product:3", "", product{Name: "mouse", Price: 19.9})
+	var products []*product
+	err := redisTemplate.JSONMGet(ctx, "$", &products, "product:1", "product:2", "product:3")
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(len(products), 3) || helper.IsNotNil(products[1]) ||
+		helper.IsNotEqualTo(products[2].Name, "mouse") {
+		logger.Errorf("JSONMGet() result = %v err = %v", products, err)
+		t.Fail()
+	}
+	var prices []float64
+	err = redisTemplate.JSONMGet(ctx, "$.price", &prices, "product:1", "product:3")
+	if helper.IsNotNil(err) || !reflect.DeepEqual(prices, []float64{49.9, 19.9}) {
+		logger.Errorf("JSONMGet() path result = %v err = %v", prices, err)
+		t.Fail()
+	}
+	err = redisTemplate.JSONMGet(ctx, "$", products, "product:1")
+	if !errors.Is(err, redis.ErrDestIsNotSlicePointer) {
+		logger.Errorf("JSONMGet() err = %v, want = %v", err, redis.ErrDestIsNotSlicePointer)
+		t.Fail()
+	}
+}
+
+func TestTemplateJSONCodec(t *testing.T) {
+	server := redistest.NewServer(t)
+	codec := &countingCodec{}
+	opts := server.ClientOptions()
+	opts.JSONCodec = codec
+	redisTemplate := redis.NewTemplate(opts)
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	_, err := redisTemplate.JSONSet(ctx, "product:1", "", product{Name: "keyboard"})
+	var result product
+	if helper.IsNil(err) {
+		err = redisTemplate.JSONGet(ctx, "product:1", "", &result)
+	}
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(codec.marshaled, 1) || helper.IsNotEqualTo(codec.unmarshaled, 1) {
+		logger.Errorf("JSONCodec() marshaled = %v unmarshaled = %v err = %v", codec.marshaled, codec.unmarshaled,
+			err)
+		t.Fail()
+	}
+	_, err = redisTemplate.JSONSet(ctx, "product:1", "", make(chan int))
+	if !errors.Is(err, redis.ErrConvertValue) {
+		logger.Errorf("JSONSet() err = %v, want = %v", err, redis.ErrConvertValue)
+		t.Fail()
+	}
+}
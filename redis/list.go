@@ -0,0 +1,132 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+// LPush redis `LPUSH key element [element ...]` command, inserts the values at the head of the list of the key,
+// creating it if it does not exist, and returns the length of the list.
+//
+// The key parameter can be of any type, but cannot be null, in case an error occurs when converting, the error
+// returned is ErrConvertKey. The values are converted like the members of PFAdd, without compression and encryption,
+// since LRem finds them by their exact value (see option.Client.Encryption), if an error occurs when converting,
+// the error returned is ErrConvertValue.
+func (t *Template) LPush(ctx context.Context, key any, values ...any) (int64, error) {
+	var length int64
+	op := &Operation{Name: "LPush", Keys: []any{key}, Value: values}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, sValues, err := convertKeyAndMembers(op, key, values)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		length, err = t.client.LPush(ctx, sKey, sValues...).Result()
+		return err
+	})
+	return length, err
+}
+
+// LMove redis `LMOVE source destination <LEFT | RIGHT> <LEFT | RIGHT>` command, atomically pops the element of the
+// source list from the side of srcDirection and pushes it to the destination list on the side of destDirection,
+// returning the element. If the source list is empty, the error returned is ErrKeyNotFound.
+//
+// The source and destination parameters can be of any type, but cannot be null, in case an error occurs when
+// converting, the error returned is ErrConvertKey. On redis cluster, the keys must have the same hash slot.
+func (t *Template) LMove(ctx context.Context, source, destination any, srcDirection,
+	destDirection option.ListDirection) (string, error) {
+	return t.lMove(ctx, "LMove", source, destination, srcDirection, destDirection, -1)
+}
+
+// BLMove redis `BLMOVE source destination <LEFT | RIGHT> <LEFT | RIGHT> timeout` command, the blocking variant of
+// LMove, waits up to the timeout for an element when the source list is empty, zero waits forever. If the timeout
+// expires, the error returned is ErrKeyNotFound.
+//
+// The parameters follow the LMove documentation.
+func (t *Template) BLMove(ctx context.Context, source, destination any, srcDirection,
+	destDirection option.ListDirection, timeout time.Duration) (string, error) {
+	return t.lMove(ctx, "BLMove", source, destination, srcDirection, destDirection, max(timeout, 0))
+}
+
+// LRem redis `LREM key count element` command, removes the occurrences of the value from the list of the key,
+// returning the number of elements removed. A positive count removes up to count occurrences from the head, a
+// negative count from the tail, and zero all the occurrences.
+//
+// The key and value parameters follow the LPush documentation.
+func (t *Template) LRem(ctx context.Context, key any, count int64, value any) (int64, error) {
+	var removed int64
+	op := &Operation{Name: "LRem", Keys: []any{key}, Value: value, Idempotent: helper.IsEmpty(count)}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, sValues, err := convertKeyAndMembers(op, key, []any{value})
+		if helper.IsNotNil(err) {
+			return err
+		}
+		removed, err = t.client.LRem(ctx, sKey, count, sValues[0]).Result()
+		return err
+	})
+	return removed, err
+}
+
+// LRange redis `LRANGE key start stop` command, returns the elements of the list of the key between the indexes,
+// inclusive, negative indexes count from the tail, ex: LRange(ctx, key, 0, -1) returns the whole list. If the key
+// does not exist, the return is empty.
+//
+// The key parameter follows the LPush documentation.
+func (t *Template) LRange(ctx context.Context, key any, start, stop int64) ([]string, error) {
+	var elements []string
+	op := &Operation{Name: "LRange", Keys: []any{key}, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		elements, err = t.client.LRange(ctx, sKey, start, stop).Result()
+		return err
+	})
+	return elements, err
+}
+
+// LLen redis `LLEN key` command, returns the length of the list of the key, zero if the key does not exist.
+//
+// The key parameter follows the LPush documentation.
+func (t *Template) LLen(ctx context.Context, key any) (int64, error) {
+	var length int64
+	op := &Operation{Name: "LLen", Keys: []any{key}, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		length, err = t.client.LLen(ctx, sKey).Result()
+		return err
+	})
+	return length, err
+}
+
+// lMove runs LMOVE, or BLMOVE when the timeout is not negative.
+func (t *Template) lMove(ctx context.Context, name string, source, destination any, srcDirection,
+	destDirection option.ListDirection, timeout time.Duration) (string, error) {
+	var element string
+	op := &Operation{Name: name, Keys: []any{source, destination}}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKeys, err := convertKeys(op.Keys)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		if timeout < 0 {
+			element, err = t.client.LMove(ctx, sKeys[0], sKeys[1], srcDirection.String(),
+				destDirection.String()).Result()
+		} else {
+			element, err = t.client.BLMove(ctx, sKeys[0], sKeys[1], srcDirection.String(), destDirection.String(),
+				timeout).Result()
+		}
+		if errors.Is(err, redis.Nil) {
+			return ErrKeyNotFound
+		}
+		return err
+	})
+	return element, err
+}
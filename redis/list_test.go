@@ -0,0 +1,52 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"github.com/GabrielHCataldo/go-redis-template/redis/redistest"
+	"testing"
+	"time"
+)
+
+func TestTemplateList(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	length, err := redisTemplate.LPush(ctx, "jobs", "a", "b", 3)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(length, int64(3)) {
+		logger.Errorf("LPush() result = %v err = %v", length, err)
+		t.Fail()
+	}
+	element, err := redisTemplate.LMove(ctx, "jobs", "done", option.ListDirectionRight, option.ListDirectionLeft)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(element, "a") {
+		logger.Errorf("LMove() result = %v err = %v", element, err)
+		t.Fail()
+	}
+	elements, err := redisTemplate.LRange(ctx, "jobs", 0, -1)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(elements, []string{"3", "b"}) {
+		logger.Errorf("LRange() result = %v err = %v", elements, err)
+		t.Fail()
+	}
+	removed, err := redisTemplate.LRem(ctx, "jobs", 0, 3)
+	length, _ = redisTemplate.LLen(ctx, "jobs")
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(removed, int64(1)) || helper.IsNotEqualTo(length, int64(1)) {
+		logger.Errorf("LRem() result = %v length = %v err = %v", removed, length, err)
+		t.Fail()
+	}
+	_, err = redisTemplate.BLMove(ctx, "empty", "done", option.ListDirectionRight, option.ListDirectionLeft,
+		time.Second)
+	if !errors.Is(err, redis.ErrKeyNotFound) {
+		logger.Errorf("BLMove() err = %v, want = %v", err, redis.ErrKeyNotFound)
+		t.Fail()
+	}
+	_, err = redisTemplate.LPush(ctx, "jobs", nil)
+	if !errors.Is(err, redis.ErrConvertValue) {
+		logger.Errorf("LPush() err = %v, want = %v", err, redis.ErrConvertValue)
+		t.Fail()
+	}
+}
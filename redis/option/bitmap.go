@@ -0,0 +1,73 @@
+package option
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+)
+
+// BitRange represents options that can be used to configure the range of the 'BitCount' and 'BitPos' operations.
+type BitRange struct {
+	// Start index of the range, negative counts from the end.
+	// Default is 0.
+	Start *int64
+	// End index of the range, inclusive, negative counts from the end.
+	// Default is -1 (the end of the bitmap).
+	End *int64
+	// Unit of the indexes, BitUnitByte or BitUnitBit.
+	// Default is BitUnitByte.
+	Unit *BitUnit
+}
+
+// NewBitRange creates a new BitRange instance.
+func NewBitRange() *BitRange {
+	return &BitRange{}
+}
+
+// SetStart sets value for the Start field.
+func (b *BitRange) SetStart(start int64) *BitRange {
+	b.Start = &start
+	return b
+}
+
+// SetEnd sets value for the End field.
+func (b *BitRange) SetEnd(end int64) *BitRange {
+	b.End = &end
+	return b
+}
+
+// SetUnit sets value for the Unit field.
+func (b *BitRange) SetUnit(unit BitUnit) *BitRange {
+	b.Unit = &unit
+	return b
+}
+
+// GetOptionBitRangeByParams assembles the BitRange object from optional parameters, the fields remain nil if no
+// range is informed, so that the operation considers the whole bitmap.
+func GetOptionBitRangeByParams(opts []*BitRange) *BitRange {
+	result := &BitRange{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotNil(opt.Start) {
+			result.Start = opt.Start
+		}
+		if helper.IsNotNil(opt.End) {
+			result.End = opt.End
+		}
+		if helper.IsNotNil(opt.Unit) {
+			result.Unit = opt.Unit
+		}
+	}
+	if helper.IsNotNil(result.End) || helper.IsNotNil(result.Unit) {
+		if helper.IsNil(result.Start) {
+			result.Start = helper.ConvertToPointer(int64(0))
+		}
+		if helper.IsNil(result.End) {
+			result.End = helper.ConvertToPointer(int64(-1))
+		}
+	}
+	if helper.IsNotNil(result.Start) && helper.IsNil(result.Unit) {
+		result.Unit = helper.ConvertToPointer(BitUnitByte)
+	}
+	return result
+}
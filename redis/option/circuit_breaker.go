@@ -0,0 +1,147 @@
+package option
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"time"
+)
+
+// CircuitBreaker represents options that can be used to configure a circuit breaker (redis.NewCircuitBreaker).
+type CircuitBreaker struct {
+	// FailureRatio is the ratio of failed operations, inside the rolling window, that opens the circuit.
+	// Default is 0.5.
+	FailureRatio *float64
+	// MinRequests is the minimum number of operations inside the rolling window before the FailureRatio is evaluated.
+	// Default is 20.
+	MinRequests *int64
+	// Window is the duration of the rolling window used to count successes and failures.
+	// Default is 10 seconds.
+	Window *time.Duration
+	// WindowBuckets is the number of buckets the Window is divided into, the oldest bucket is discarded as time goes.
+	// It is limited to the nanoseconds of the Window, so each bucket lasts at least 1 nanosecond.
+	// Default is 10.
+	WindowBuckets *int
+	// Cooldown is the time the circuit stays open before moving to half-open.
+	// Default is 5 seconds.
+	Cooldown *time.Duration
+	// HalfOpenProbes is the number of probe operations allowed in half-open state, if all of them succeed the
+	// circuit is closed, if any of them fails the circuit is opened again.
+	// Default is 1.
+	HalfOpenProbes *int64
+	// OnStateChange is called after each state transition (not required).
+	OnStateChange func(from, to CircuitState)
+	// IsFailure decides if the result reported by the client counts as a failure (not required).
+	//
+	// By default, only connection errors, timeouts and the redis replies LOADING, READONLY, CLUSTERDOWN,
+	// MASTERDOWN and "max number of clients reached" are counted as failures.
+	IsFailure func(err error) bool
+}
+
+// NewCircuitBreaker creates a new CircuitBreaker instance.
+func NewCircuitBreaker() *CircuitBreaker {
+	return &CircuitBreaker{}
+}
+
+// SetFailureRatio sets value for the FailureRatio field.
+func (c *CircuitBreaker) SetFailureRatio(ratio float64) *CircuitBreaker {
+	c.FailureRatio = &ratio
+	return c
+}
+
+// SetMinRequests sets value for the MinRequests field.
+func (c *CircuitBreaker) SetMinRequests(minRequests int64) *CircuitBreaker {
+	c.MinRequests = &minRequests
+	return c
+}
+
+// SetWindow sets value for the Window field.
+func (c *CircuitBreaker) SetWindow(window time.Duration) *CircuitBreaker {
+	c.Window = &window
+	return c
+}
+
+// SetWindowBuckets sets value for the WindowBuckets field.
+func (c *CircuitBreaker) SetWindowBuckets(buckets int) *CircuitBreaker {
+	c.WindowBuckets = &buckets
+	return c
+}
+
+// SetCooldown sets value for the Cooldown field.
+func (c *CircuitBreaker) SetCooldown(cooldown time.Duration) *CircuitBreaker {
+	c.Cooldown = &cooldown
+	return c
+}
+
+// SetHalfOpenProbes sets value for the HalfOpenProbes field.
+func (c *CircuitBreaker) SetHalfOpenProbes(probes int64) *CircuitBreaker {
+	c.HalfOpenProbes = &probes
+	return c
+}
+
+// SetOnStateChange sets value for the OnStateChange field.
+func (c *CircuitBreaker) SetOnStateChange(f func(from, to CircuitState)) *CircuitBreaker {
+	c.OnStateChange = f
+	return c
+}
+
+// SetIsFailure sets value for the IsFailure field.
+func (c *CircuitBreaker) SetIsFailure(f func(err error) bool) *CircuitBreaker {
+	c.IsFailure = f
+	return c
+}
+
+// GetOptionCircuitBreakerByParams assembles the CircuitBreaker object from optional parameters, filling in the
+// default values for the fields not informed.
+func GetOptionCircuitBreakerByParams(opts []*CircuitBreaker) *CircuitBreaker {
+	result := &CircuitBreaker{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotNil(opt.FailureRatio) {
+			result.FailureRatio = opt.FailureRatio
+		}
+		if helper.IsNotNil(opt.MinRequests) {
+			result.MinRequests = opt.MinRequests
+		}
+		if helper.IsNotNil(opt.Window) {
+			result.Window = opt.Window
+		}
+		if helper.IsNotNil(opt.WindowBuckets) {
+			result.WindowBuckets = opt.WindowBuckets
+		}
+		if helper.IsNotNil(opt.Cooldown) {
+			result.Cooldown = opt.Cooldown
+		}
+		if helper.IsNotNil(opt.HalfOpenProbes) {
+			result.HalfOpenProbes = opt.HalfOpenProbes
+		}
+		if opt.OnStateChange != nil {
+			result.OnStateChange = opt.OnStateChange
+		}
+		if opt.IsFailure != nil {
+			result.IsFailure = opt.IsFailure
+		}
+	}
+	if helper.IsNil(result.FailureRatio) || *result.FailureRatio <= 0 {
+		result.FailureRatio = helper.ConvertToPointer(0.5)
+	}
+	if helper.IsNil(result.MinRequests) || *result.MinRequests <= 0 {
+		result.MinRequests = helper.ConvertToPointer(int64(20))
+	}
+	if helper.IsNil(result.Window) || *result.Window <= 0 {
+		result.Window = helper.ConvertToPointer(10 * time.Second)
+	}
+	if helper.IsNil(result.WindowBuckets) || *result.WindowBuckets <= 0 {
+		result.WindowBuckets = helper.ConvertToPointer(10)
+	}
+	if int64(*result.WindowBuckets) > int64(*result.Window) {
+		result.WindowBuckets = helper.ConvertToPointer(int(*result.Window))
+	}
+	if helper.IsNil(result.Cooldown) || *result.Cooldown <= 0 {
+		result.Cooldown = helper.ConvertToPointer(5 * time.Second)
+	}
+	if helper.IsNil(result.HalfOpenProbes) || *result.HalfOpenProbes <= 0 {
+		result.HalfOpenProbes = helper.ConvertToPointer(int64(1))
+	}
+	return result
+}
@@ -113,6 +113,30 @@ type Client struct {
 	readOnly bool
 	// Disable set-lib on connect. Default is false.
 	DisableIndentity bool
+	// Compression algorithm applied by the template to the values written, the values are decompressed
+	// transparently when read, whatever the algorithm they were written with.
+	// Default is CompressionNone.
+	Compression Compression
+	// CompressionThreshold minimum size in bytes of the value to be compressed, smaller values are stored without
+	// compression, since the gain does not pay the cost, to compress every value, use 1.
+	// Default is 1 KiB.
+	CompressionThreshold int
+	// Encryption of the values written by the template, the values are compressed before being encrypted and
+	// decrypted transparently when read.
+	//
+	// Only the string values are encrypted (Set, MSet, SetGet and the reads of Get and GetDel). The elements of
+	// lists, sorted sets, hashes, HyperLogLogs and probabilistic structures, and so the jobs of the queue and the
+	// scheduler packages, are stored in plain text, since each encryption uses a random nonce and these elements are
+	// found by their exact value, ex: LRem, ZRem and the Lua scripts of the scheduler, so encrypt the sensitive
+	// fields before adding them.
+	// Default is nil, the values are not encrypted.
+	Encryption *Encryption
+	// RetryPolicy of the template operations, it can be overridden per call with redis.WithRetryPolicy.
+	// Default is nil, the operations are not retried by the template.
+	RetryPolicy *RetryPolicy
+	// JSONCodec encodes the values and decodes the results of the RedisJSON operations of the template.
+	// Default is nil, the encoding/json package is used.
+	JSONCodec JSONCodec
 }
 
 // Limiter is the interface of a rate limiter or a circuit breaker.
@@ -137,7 +161,7 @@ func (c Client) ParseToRedisOptions() *redis.Options {
 		Username:              c.Username,
 		Password:              c.Password,
 		CredentialsProvider:   c.CredentialsProvider,
-		DB:                    c.MaxIdleConns,
+		DB:                    c.DB,
 		MaxRetries:            c.MaxRetries,
 		MinRetryBackoff:       c.MinRetryBackoff,
 		MaxRetryBackoff:       c.MaxRetryBackoff,
@@ -149,7 +173,7 @@ func (c Client) ParseToRedisOptions() *redis.Options {
 		PoolSize:              c.PoolSize,
 		PoolTimeout:           c.PoolTimeout,
 		MinIdleConns:          c.MinIdleConns,
-		MaxIdleConns:          c.MaxActiveConns,
+		MaxIdleConns:          c.MaxIdleConns,
 		MaxActiveConns:        c.MaxActiveConns,
 		ConnMaxIdleTime:       c.ConnMaxIdleTime,
 		ConnMaxLifetime:       c.ConnMaxLifetime,
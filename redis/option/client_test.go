@@ -0,0 +1,16 @@
+package option
+
+import (
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"testing"
+)
+
+func TestClientParseToRedisOptions(t *testing.T) {
+	c := Client{DB: 3, MaxIdleConns: 5, MaxActiveConns: 8}
+	result := c.ParseToRedisOptions()
+	if result.DB != 3 || result.MaxIdleConns != 5 || result.MaxActiveConns != 8 {
+		logger.Errorf("ParseToRedisOptions() DB = %v MaxIdleConns = %v MaxActiveConns = %v", result.DB,
+			result.MaxIdleConns, result.MaxActiveConns)
+		t.Fail()
+	}
+}
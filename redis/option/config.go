@@ -0,0 +1,317 @@
+package option
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ClientConfig is the representation of the Client in configuration files and environment variables, see
+// LoadClient and ClientFromEnv, the fields have the same meaning as the ones of the Client. The fields that cannot
+// be expressed in configuration, like the Dialer, the Limiter and the encryption keys, must be set in code on the
+// Client returned.
+type ClientConfig struct {
+	Network               string      `json:"network" yaml:"network" env:"NETWORK"`
+	Addr                  string      `json:"addr" yaml:"addr" env:"ADDR"`
+	ClientName            string      `json:"client_name" yaml:"client_name" env:"CLIENT_NAME"`
+	Protocol              int         `json:"protocol" yaml:"protocol" env:"PROTOCOL"`
+	Username              string      `json:"username" yaml:"username" env:"USERNAME"`
+	Password              string      `json:"password" yaml:"password" env:"PASSWORD"`
+	DB                    int         `json:"db" yaml:"db" env:"DB"`
+	MaxRetries            int         `json:"max_retries" yaml:"max_retries" env:"MAX_RETRIES"`
+	MinRetryBackoff       Duration    `json:"min_retry_backoff" yaml:"min_retry_backoff" env:"MIN_RETRY_BACKOFF"`
+	MaxRetryBackoff       Duration    `json:"max_retry_backoff" yaml:"max_retry_backoff" env:"MAX_RETRY_BACKOFF"`
+	DialTimeout           Duration    `json:"dial_timeout" yaml:"dial_timeout" env:"DIAL_TIMEOUT"`
+	ReadTimeout           Duration    `json:"read_timeout" yaml:"read_timeout" env:"READ_TIMEOUT"`
+	WriteTimeout          Duration    `json:"write_timeout" yaml:"write_timeout" env:"WRITE_TIMEOUT"`
+	ContextTimeoutEnabled bool        `json:"context_timeout_enabled" yaml:"context_timeout_enabled" env:"CONTEXT_TIMEOUT_ENABLED"`
+	PoolFIFO              bool        `json:"pool_fifo" yaml:"pool_fifo" env:"POOL_FIFO"`
+	PoolSize              int         `json:"pool_size" yaml:"pool_size" env:"POOL_SIZE"`
+	PoolTimeout           Duration    `json:"pool_timeout" yaml:"pool_timeout" env:"POOL_TIMEOUT"`
+	MinIdleConns          int         `json:"min_idle_conns" yaml:"min_idle_conns" env:"MIN_IDLE_CONNS"`
+	MaxIdleConns          int         `json:"max_idle_conns" yaml:"max_idle_conns" env:"MAX_IDLE_CONNS"`
+	MaxActiveConns        int         `json:"max_active_conns" yaml:"max_active_conns" env:"MAX_ACTIVE_CONNS"`
+	ConnMaxIdleTime       Duration    `json:"conn_max_idle_time" yaml:"conn_max_idle_time" env:"CONN_MAX_IDLE_TIME"`
+	ConnMaxLifetime       Duration    `json:"conn_max_lifetime" yaml:"conn_max_lifetime" env:"CONN_MAX_LIFETIME"`
+	DisableIndentity      bool        `json:"disable_identity" yaml:"disable_identity" env:"DISABLE_IDENTITY"`
+	Compression           Compression `json:"compression" yaml:"compression" env:"COMPRESSION"`
+	CompressionThreshold  int         `json:"compression_threshold" yaml:"compression_threshold" env:"COMPRESSION_THRESHOLD"`
+	TLS                   *TLS        `json:"tls" yaml:"tls" env:"TLS"`
+}
+
+// TLS settings of the connection, converted to the Client.TLSConfig.
+type TLS struct {
+	// Enabled negotiates TLS with the system root CAs, it is implied when any file is informed.
+	Enabled bool `json:"enabled" yaml:"enabled" env:"ENABLED"`
+	// CAFile PEM file with the CAs that verify the server certificate, instead of the system root CAs.
+	CAFile string `json:"ca_file" yaml:"ca_file" env:"CA_FILE"`
+	// CertFile PEM file with the client certificate, for mutual TLS, it requires the KeyFile.
+	CertFile string `json:"cert_file" yaml:"cert_file" env:"CERT_FILE"`
+	// KeyFile PEM file with the private key of the client certificate, it requires the CertFile.
+	KeyFile string `json:"key_file" yaml:"key_file" env:"KEY_FILE"`
+	// ServerName verified in the server certificate, by default the host of the address.
+	ServerName string `json:"server_name" yaml:"server_name" env:"SERVER_NAME"`
+	// InsecureSkipVerify does not verify the server certificate, it cannot be used with the CAFile.
+	InsecureSkipVerify bool `json:"insecure_skip_verify" yaml:"insecure_skip_verify" env:"INSECURE_SKIP_VERIFY"`
+	// MinVersion minimum TLS version, "1.2" or "1.3".
+	// Default is 1.2.
+	MinVersion string `json:"min_version" yaml:"min_version" env:"MIN_VERSION"`
+}
+
+// Duration is a time.Duration expressed in configuration as a duration string, ex: "500ms", "5s", or as an integer
+// of nanoseconds, so the special values -1 and -2 of the timeouts can be used.
+type Duration time.Duration
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	if n, err := strconv.ParseInt(s, 10, 64); helper.IsNil(err) {
+		*d = Duration(n)
+		return nil
+	}
+	duration, err := time.ParseDuration(s)
+	if helper.IsNotNil(err) {
+		return fmt.Errorf("%w: invalid duration %q", ErrInvalidClient, s)
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting strings and numbers, null keeps the value.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	if helper.Equals(string(data), "null") {
+		return nil
+	}
+	return d.UnmarshalText(bytes.Trim(data, `"`))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// LoadClient reads the Client from a configuration (ClientConfig) in the format, ConfigFormatJSON or
+// ConfigFormatYAML, unknown fields are rejected and the Client returned is validated (Client.Validate).
+func LoadClient(from io.Reader, format ConfigFormat) (Client, error) {
+	var config ClientConfig
+	var err error
+	switch format {
+	case ConfigFormatJSON:
+		decoder := json.NewDecoder(from)
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&config)
+	case ConfigFormatYAML:
+		decoder := yaml.NewDecoder(from)
+		decoder.KnownFields(true)
+		err = decoder.Decode(&config)
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+	default:
+		return Client{}, ErrUnknownConfigFormat
+	}
+	if helper.IsNotNil(err) {
+		return Client{}, fmt.Errorf("%w: %w", ErrInvalidClient, err)
+	}
+	return config.Client()
+}
+
+// ClientFromEnv reads the Client from the environment variables with the prefix, named by the env tag of the
+// ClientConfig fields, ex: with the prefix "REDIS", REDIS_ADDR, REDIS_DIAL_TIMEOUT and REDIS_TLS_CA_FILE. An empty
+// prefix is "REDIS", so generic variables like USERNAME are not read. The Client returned is validated
+// (Client.Validate).
+func ClientFromEnv(prefix string) (Client, error) {
+	var config ClientConfig
+	prefix = strings.TrimSuffix(helper.IfEmptyReturns(prefix, "REDIS"), "_") + "_"
+	if _, err := loadEnv(prefix, reflect.ValueOf(&config).Elem()); helper.IsNotNil(err) {
+		return Client{}, err
+	}
+	return config.Client()
+}
+
+// Client converts the configuration to a Client, loading the TLS files, and validates it (Client.Validate).
+func (c ClientConfig) Client() (Client, error) {
+	tlsConfig, err := c.TLS.tlsConfig()
+	if helper.IsNotNil(err) {
+		return Client{}, err
+	}
+	client := Client{
+		Network:               c.Network,
+		Addr:                  c.Addr,
+		ClientName:            c.ClientName,
+		Protocol:              c.Protocol,
+		Username:              c.Username,
+		Password:              c.Password,
+		DB:                    c.DB,
+		MaxRetries:            c.MaxRetries,
+		MinRetryBackoff:       time.Duration(c.MinRetryBackoff),
+		MaxRetryBackoff:       time.Duration(c.MaxRetryBackoff),
+		DialTimeout:           time.Duration(c.DialTimeout),
+		ReadTimeout:           time.Duration(c.ReadTimeout),
+		WriteTimeout:          time.Duration(c.WriteTimeout),
+		ContextTimeoutEnabled: c.ContextTimeoutEnabled,
+		PoolFIFO:              c.PoolFIFO,
+		PoolSize:              c.PoolSize,
+		PoolTimeout:           time.Duration(c.PoolTimeout),
+		MinIdleConns:          c.MinIdleConns,
+		MaxIdleConns:          c.MaxIdleConns,
+		MaxActiveConns:        c.MaxActiveConns,
+		ConnMaxIdleTime:       time.Duration(c.ConnMaxIdleTime),
+		ConnMaxLifetime:       time.Duration(c.ConnMaxLifetime),
+		TLSConfig:             tlsConfig,
+		DisableIndentity:      c.DisableIndentity,
+		Compression:           c.Compression,
+		CompressionThreshold:  c.CompressionThreshold,
+	}
+	return client, client.Validate()
+}
+
+// Validate returns the combinations of settings that cannot work, joined, nil if the Client is valid.
+func (c Client) Validate() error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]any{ErrInvalidClient}, args...)...))
+	}
+	if helper.IsNotEmpty(c.Network) && helper.IsNotEqualTo(c.Network, "tcp") && helper.IsNotEqualTo(c.Network, "unix") {
+		invalid("network %q must be tcp or unix", c.Network)
+	}
+	if c.Protocol != 0 && c.Protocol != 2 && c.Protocol != 3 {
+		invalid("protocol %d must be 2 or 3", c.Protocol)
+	}
+	if c.DB < 0 {
+		invalid("db %d cannot be negative", c.DB)
+	}
+	if c.MinRetryBackoff > 0 && c.MaxRetryBackoff > 0 && c.MinRetryBackoff > c.MaxRetryBackoff {
+		invalid("min retry backoff %s is greater than max retry backoff %s", c.MinRetryBackoff, c.MaxRetryBackoff)
+	}
+	if c.PoolSize < 0 || c.MinIdleConns < 0 || c.MaxIdleConns < 0 || c.MaxActiveConns < 0 {
+		invalid("pool size and connections cannot be negative")
+	}
+	if c.MaxIdleConns > 0 && c.MinIdleConns > c.MaxIdleConns {
+		invalid("min idle conns %d is greater than max idle conns %d", c.MinIdleConns, c.MaxIdleConns)
+	}
+	if c.MaxActiveConns > 0 && c.MinIdleConns > c.MaxActiveConns {
+		invalid("min idle conns %d is greater than max active conns %d", c.MinIdleConns, c.MaxActiveConns)
+	}
+	if c.CompressionThreshold < 0 {
+		invalid("compression threshold %d cannot be negative", c.CompressionThreshold)
+	}
+	switch c.Compression {
+	case CompressionNone, CompressionGzip, CompressionZstd, CompressionSnappy, CompressionLz4:
+	default:
+		invalid("unknown compression %q", c.Compression)
+	}
+	if helper.IsNotNil(c.TLSConfig) && helper.Equals(c.Network, "unix") {
+		invalid("tls cannot be used with the unix network")
+	}
+	return errors.Join(errs...)
+}
+
+func (t *TLS) tlsConfig() (*tls.Config, error) {
+	if helper.IsNil(t) || (!t.Enabled && helper.IsEmpty(t.CAFile) && helper.IsEmpty(t.CertFile) &&
+		helper.IsEmpty(t.KeyFile)) {
+		return nil, nil
+	}
+	config := &tls.Config{
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	switch t.MinVersion {
+	case "", "1.2":
+	case "1.3":
+		config.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("%w: min version %q must be 1.2 or 1.3", ErrInvalidTLS, t.MinVersion)
+	}
+	if t.InsecureSkipVerify && helper.IsNotEmpty(t.CAFile) {
+		return nil, fmt.Errorf("%w: insecure skip verify cannot be used with the ca file", ErrInvalidTLS)
+	} else if helper.IsEmpty(t.CertFile) != helper.IsEmpty(t.KeyFile) {
+		return nil, fmt.Errorf("%w: cert file and key file must be informed together", ErrInvalidTLS)
+	}
+	if helper.IsNotEmpty(t.CAFile) {
+		pem, err := os.ReadFile(t.CAFile)
+		if helper.IsNotNil(err) {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidTLS, err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%w: no certificate found in the ca file %s", ErrInvalidTLS, t.CAFile)
+		}
+	}
+	if helper.IsNotEmpty(t.CertFile) {
+		certificate, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if helper.IsNotNil(err) {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidTLS, err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+	return config, nil
+}
+
+// loadEnv fills the fields of the struct with the environment variables named by the env tags, returning true if
+// any variable was found.
+func loadEnv(prefix string, v reflect.Value) (bool, error) {
+	found := false
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Tag.Get("env")
+		field := v.Field(i)
+		if helper.IsEmpty(name) {
+			continue
+		} else if field.Kind() == reflect.Pointer && field.Type().Elem().Kind() == reflect.Struct {
+			nested := reflect.New(field.Type().Elem())
+			ok, err := loadEnv(prefix+name+"_", nested.Elem())
+			if helper.IsNotNil(err) {
+				return false, err
+			} else if ok {
+				field.Set(nested)
+				found = true
+			}
+			continue
+		}
+		value, ok := os.LookupEnv(prefix + name)
+		if !ok {
+			continue
+		}
+		found = true
+		if err := setEnvValue(field, value); helper.IsNotNil(err) {
+			return false, fmt.Errorf("%w: env %s: %w", ErrInvalidClient, prefix+name, err)
+		}
+	}
+	return found, nil
+}
+
+func setEnvValue(field reflect.Value, value string) error {
+	if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(value))
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		field.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		field.SetBool(b)
+	}
+	return nil
+}
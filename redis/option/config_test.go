@@ -0,0 +1,179 @@
+package option
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// initCertificate writes a self-signed certificate and its key in the directory, returning the file paths.
+func initCertificate(t *testing.T, dir string) (string, string) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "redis"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if helper.IsNotNil(err) {
+		t.Fatal(err)
+	}
+	keyDer, _ := x509.MarshalECPrivateKey(key)
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	_ = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	_ = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	return certFile, keyFile
+}
+
+func TestLoadClient(t *testing.T) {
+	certFile, keyFile := initCertificate(t, t.TempDir())
+	for _, tt := range []struct {
+		format ConfigFormat
+		config string
+	}{
+		{
+			format: ConfigFormatYAML,
+			config: `
+addr: redis:6380
+username: reader
+db: 2
+read_timeout: -1
+dial_timeout: 2s
+pool_size: 20
+compression: zstd
+tls:
+  ca_file: ` + certFile + `
+  cert_file: ` + certFile + `
+  key_file: ` + keyFile + `
+  server_name: redis
+  min_version: "1.3"
+`,
+		},
+		{
+			format: ConfigFormatJSON,
+			config: `{"addr": "redis:6380", "username": "reader", "db": 2, "read_timeout": -1, "dial_timeout": "2s",
+"write_timeout": null, "pool_size": 20,
+"compression": "zstd", "tls": {"ca_file": "` + certFile + `", "cert_file": "` + certFile + `",
+"key_file": "` + keyFile + `", "server_name": "redis", "min_version": "1.3"}}`,
+		},
+	} {
+		client, err := LoadClient(strings.NewReader(tt.config), tt.format)
+		if helper.IsNotNil(err) {
+			logger.Errorf("LoadClient() %s err = %v", tt.format, err)
+			t.Fail()
+			continue
+		}
+		if helper.IsNotEqualTo(client.Addr, "redis:6380") || helper.IsNotEqualTo(client.Username, "reader") ||
+			helper.IsNotEqualTo(client.DB, 2) || client.ReadTimeout != -1 || client.DialTimeout != 2*time.Second ||
+			client.WriteTimeout != 0 || helper.IsNotEqualTo(client.PoolSize, 20) ||
+			helper.IsNotEqualTo(client.Compression, CompressionZstd) {
+			logger.Errorf("LoadClient() %s result = %+v", tt.format, client)
+			t.Fail()
+		}
+		if helper.IsNil(client.TLSConfig) || helper.IsNil(client.TLSConfig.RootCAs) ||
+			helper.IsNotEqualTo(len(client.TLSConfig.Certificates), 1) ||
+			client.TLSConfig.MinVersion != tls.VersionTLS13 || helper.IsNotEqualTo(client.TLSConfig.ServerName, "redis") {
+			logger.Errorf("LoadClient() %s tls = %+v", tt.format, client.TLSConfig)
+			t.Fail()
+		}
+	}
+}
+
+func TestLoadClientErrors(t *testing.T) {
+	certFile, _ := initCertificate(t, t.TempDir())
+	for _, tt := range []struct {
+		name   string
+		format ConfigFormat
+		config string
+		want   error
+		msg    string
+	}{
+		{name: "format", format: "toml", config: "", want: ErrUnknownConfigFormat},
+		{name: "unknown field", format: ConfigFormatYAML, config: "address: redis", want: ErrInvalidClient},
+		{name: "duration", format: ConfigFormatJSON, config: `{"dial_timeout": "2 seconds"}`, want: ErrInvalidClient},
+		{
+			name:   "backoff",
+			format: ConfigFormatYAML,
+			config: "min_retry_backoff: 1s\nmax_retry_backoff: 10ms",
+			want:   ErrInvalidClient,
+			msg:    "min retry backoff 1s is greater than max retry backoff 10ms",
+		},
+		{
+			name:   "idle conns",
+			format: ConfigFormatYAML,
+			config: "min_idle_conns: 10\nmax_idle_conns: 5\nprotocol: 4",
+			want:   ErrInvalidClient,
+			msg:    "protocol 4 must be 2 or 3",
+		},
+		{
+			name:   "insecure with ca",
+			format: ConfigFormatYAML,
+			config: "tls:\n  ca_file: " + certFile + "\n  insecure_skip_verify: true",
+			want:   ErrInvalidTLS,
+		},
+		{
+			name:   "cert without key",
+			format: ConfigFormatYAML,
+			config: "tls:\n  cert_file: " + certFile,
+			want:   ErrInvalidTLS,
+			msg:    "cert file and key file must be informed together",
+		},
+		{
+			name:   "tls with unix",
+			format: ConfigFormatYAML,
+			config: "network: unix\naddr: /tmp/redis.sock\ntls:\n  enabled: true",
+			want:   ErrInvalidClient,
+		},
+	} {
+		_, err := LoadClient(strings.NewReader(tt.config), tt.format)
+		if !errors.Is(err, tt.want) || (helper.IsNotEmpty(tt.msg) && !strings.Contains(err.Error(), tt.msg)) {
+			logger.Errorf("LoadClient() %s err = %v, want = %v %s", tt.name, err, tt.want, tt.msg)
+			t.Fail()
+		}
+	}
+}
+
+func TestClientFromEnv(t *testing.T) {
+	t.Setenv("CACHE_REDIS_ADDR", "cache:6379")
+	t.Setenv("CACHE_REDIS_POOL_TIMEOUT", "1500ms")
+	t.Setenv("CACHE_REDIS_CONTEXT_TIMEOUT_ENABLED", "true")
+	t.Setenv("CACHE_REDIS_TLS_INSECURE_SKIP_VERIFY", "true")
+	t.Setenv("CACHE_REDIS_TLS_ENABLED", "1")
+	client, err := ClientFromEnv("CACHE_REDIS")
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(client.Addr, "cache:6379") ||
+		client.PoolTimeout != 1500*time.Millisecond || !client.ContextTimeoutEnabled ||
+		helper.IsNil(client.TLSConfig) || !client.TLSConfig.InsecureSkipVerify {
+		logger.Errorf("ClientFromEnv() result = %+v err = %v", client, err)
+		t.Fail()
+	}
+	t.Setenv("CACHE_REDIS_DB", "one")
+	_, err = ClientFromEnv("CACHE_REDIS")
+	if !errors.Is(err, ErrInvalidClient) || !strings.Contains(err.Error(), "CACHE_REDIS_DB") {
+		logger.Errorf("ClientFromEnv() err = %v, want = %v", err, ErrInvalidClient)
+		t.Fail()
+	}
+	t.Setenv("REDIS_ADDR", "default:6379")
+	t.Setenv("USERNAME", "root")
+	t.Setenv("PROTOCOL", "https")
+	client, err = ClientFromEnv("")
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(client.Addr, "default:6379") || helper.IsNotEmpty(client.Username) {
+		logger.Errorf("ClientFromEnv() default prefix result = %+v err = %v", client, err)
+		t.Fail()
+	}
+}
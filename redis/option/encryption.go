@@ -0,0 +1,147 @@
+package option
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"time"
+)
+
+// EncryptionKey is a key of the keyring, the ID is written in the envelope of the encrypted values, so the values
+// can be decrypted after the key rotation.
+type EncryptionKey struct {
+	// ID identifies the key in the envelope, it must have between 1 and 255 bytes.
+	ID string
+	// Cipher algorithm of the key, CipherAESGCM or CipherChaCha20Poly1305.
+	Cipher Cipher
+	// Secret of the key, its size must match the Cipher.
+	Secret []byte
+}
+
+// Encryption represents options that can be used to configure the encryption of the values of the template
+// (Client.Encryption).
+type Encryption struct {
+	// Keys is the keyring used to decrypt the values, the values encrypted with a key that is not in the keyring
+	// cannot be read.
+	Keys []EncryptionKey
+	// PrimaryKeyID is the ID of the key used to encrypt the values.
+	// Default is the ID of the first key.
+	PrimaryKeyID *string
+	// BindKey binds the redis key name to the value as associated data, so an encrypted value cannot be read from
+	// another key, note that Rename makes the value unreadable.
+	// Default is false.
+	BindKey *bool
+}
+
+// ReEncrypt represents options that can be used to configure the re-encryption of the values
+// (redis.Template.ReEncrypt).
+type ReEncrypt struct {
+	// Count of keys requested on each SCAN page.
+	// Default is 100.
+	Count *int64
+	// Delay between the SCAN pages, to reduce the load on redis.
+	// Default is 0.
+	Delay *time.Duration
+	// EncryptPlaintext encrypts the values written without encryption too, by default only the values encrypted
+	// with another key are rewritten, since the plain text values can be counters, bitmaps or HyperLogLogs that
+	// redis must read.
+	// Default is false.
+	EncryptPlaintext *bool
+}
+
+// NewEncryption creates a new Encryption instance.
+func NewEncryption() *Encryption {
+	return &Encryption{}
+}
+
+// AddKey adds a key to the Keys field.
+func (e *Encryption) AddKey(id string, cipher Cipher, secret []byte) *Encryption {
+	e.Keys = append(e.Keys, EncryptionKey{ID: id, Cipher: cipher, Secret: secret})
+	return e
+}
+
+// SetPrimaryKeyID sets value for the PrimaryKeyID field.
+func (e *Encryption) SetPrimaryKeyID(id string) *Encryption {
+	e.PrimaryKeyID = &id
+	return e
+}
+
+// SetBindKey sets value for the BindKey field.
+func (e *Encryption) SetBindKey(bindKey bool) *Encryption {
+	e.BindKey = &bindKey
+	return e
+}
+
+// NewReEncrypt creates a new ReEncrypt instance.
+func NewReEncrypt() *ReEncrypt {
+	return &ReEncrypt{}
+}
+
+// SetCount sets value for the Count field.
+func (r *ReEncrypt) SetCount(count int64) *ReEncrypt {
+	r.Count = &count
+	return r
+}
+
+// SetDelay sets value for the Delay field.
+func (r *ReEncrypt) SetDelay(delay time.Duration) *ReEncrypt {
+	r.Delay = &delay
+	return r
+}
+
+// SetEncryptPlaintext sets value for the EncryptPlaintext field.
+func (r *ReEncrypt) SetEncryptPlaintext(encryptPlaintext bool) *ReEncrypt {
+	r.EncryptPlaintext = &encryptPlaintext
+	return r
+}
+
+// GetOptionEncryptionByParams assembles the Encryption object from optional parameters, the keys are accumulated.
+func GetOptionEncryptionByParams(opts []*Encryption) *Encryption {
+	result := &Encryption{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		result.Keys = append(result.Keys, opt.Keys...)
+		if helper.IsNotNil(opt.PrimaryKeyID) {
+			result.PrimaryKeyID = opt.PrimaryKeyID
+		}
+		if helper.IsNotNil(opt.BindKey) {
+			result.BindKey = opt.BindKey
+		}
+	}
+	if helper.IsNil(result.PrimaryKeyID) && helper.IsNotEmpty(result.Keys) {
+		result.PrimaryKeyID = helper.ConvertToPointer(result.Keys[0].ID)
+	}
+	if helper.IsNil(result.BindKey) {
+		result.BindKey = helper.ConvertToPointer(false)
+	}
+	return result
+}
+
+// GetOptionReEncryptByParams assembles the ReEncrypt object from optional parameters.
+func GetOptionReEncryptByParams(opts []*ReEncrypt) *ReEncrypt {
+	result := &ReEncrypt{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotNil(opt.Count) {
+			result.Count = opt.Count
+		}
+		if helper.IsNotNil(opt.Delay) {
+			result.Delay = opt.Delay
+		}
+		if helper.IsNotNil(opt.EncryptPlaintext) {
+			result.EncryptPlaintext = opt.EncryptPlaintext
+		}
+	}
+	if helper.IsNil(result.Count) || *result.Count <= 0 {
+		result.Count = helper.ConvertToPointer(int64(100))
+	}
+	if helper.IsNil(result.Delay) {
+		result.Delay = helper.ConvertToPointer(time.Duration(0))
+	}
+	if helper.IsNil(result.EncryptPlaintext) {
+		result.EncryptPlaintext = helper.ConvertToPointer(false)
+	}
+	return result
+}
@@ -14,3 +14,309 @@ const (
 func (s SetMode) String() string {
 	return string(s)
 }
+
+type CircuitState string
+
+const (
+	// CircuitStateClosed operations are allowed and their results are recorded in the rolling window.
+	CircuitStateClosed CircuitState = "closed"
+	// CircuitStateOpen operations are rejected until the cooldown elapses.
+	CircuitStateOpen CircuitState = "open"
+	// CircuitStateHalfOpen a limited number of probe operations are allowed to test if redis has recovered.
+	CircuitStateHalfOpen CircuitState = "half-open"
+)
+
+func (c CircuitState) String() string {
+	return string(c)
+}
+
+type Compression string
+
+const (
+	// CompressionNone values are stored without compression.
+	CompressionNone Compression = ""
+	// CompressionGzip values are compressed with gzip, the best ratio for JSON payloads, but the slowest.
+	CompressionGzip Compression = "gzip"
+	// CompressionZstd values are compressed with zstd, ratio close to gzip and much faster.
+	CompressionZstd Compression = "zstd"
+	// CompressionSnappy values are compressed with snappy, the fastest, with the lowest ratio.
+	CompressionSnappy Compression = "snappy"
+	// CompressionLz4 values are compressed with lz4, fast with a better ratio than snappy.
+	CompressionLz4 Compression = "lz4"
+)
+
+func (c Compression) String() string {
+	return string(c)
+}
+
+type Cipher string
+
+const (
+	// CipherAESGCM AES in Galois/Counter Mode, the secret must have 16, 24 or 32 bytes (AES-128, AES-192 or AES-256).
+	CipherAESGCM Cipher = "aes-gcm"
+	// CipherChaCha20Poly1305 ChaCha20-Poly1305, the secret must have 32 bytes, faster than AES-GCM without AES
+	// hardware acceleration.
+	CipherChaCha20Poly1305 Cipher = "chacha20-poly1305"
+)
+
+func (c Cipher) String() string {
+	return string(c)
+}
+
+type ConfigFormat string
+
+const (
+	// ConfigFormatJSON configuration in JSON.
+	ConfigFormatJSON ConfigFormat = "json"
+	// ConfigFormatYAML configuration in YAML.
+	ConfigFormatYAML ConfigFormat = "yaml"
+)
+
+func (c ConfigFormat) String() string {
+	return string(c)
+}
+
+type BitUnit string
+
+const (
+	// BitUnitByte the start and end of the range are byte indexes.
+	BitUnitByte BitUnit = "BYTE"
+	// BitUnitBit the start and end of the range are bit indexes, it requires your redis-server version >= 7.0.
+	BitUnitBit BitUnit = "BIT"
+)
+
+func (b BitUnit) String() string {
+	return string(b)
+}
+
+type BitOperation string
+
+const (
+	// BitOperationAnd stores the bitwise AND of the source keys.
+	BitOperationAnd BitOperation = "AND"
+	// BitOperationOr stores the bitwise OR of the source keys.
+	BitOperationOr BitOperation = "OR"
+	// BitOperationXor stores the bitwise XOR of the source keys.
+	BitOperationXor BitOperation = "XOR"
+	// BitOperationNot stores the bitwise NOT of a single source key.
+	BitOperationNot BitOperation = "NOT"
+)
+
+func (b BitOperation) String() string {
+	return string(b)
+}
+
+type BitFieldOverflow string
+
+const (
+	// BitFieldOverflowWrap wraps around the integer on overflow and underflow, the default of redis.
+	BitFieldOverflowWrap BitFieldOverflow = "WRAP"
+	// BitFieldOverflowSat saturates the integer to the minimum or maximum value of its type.
+	BitFieldOverflowSat BitFieldOverflow = "SAT"
+	// BitFieldOverflowFail does not write the integer on overflow and underflow.
+	BitFieldOverflowFail BitFieldOverflow = "FAIL"
+)
+
+func (b BitFieldOverflow) String() string {
+	return string(b)
+}
+
+type GeoUnit string
+
+const (
+	// GeoUnitMeters distances in meters.
+	GeoUnitMeters GeoUnit = "m"
+	// GeoUnitKilometers distances in kilometers.
+	GeoUnitKilometers GeoUnit = "km"
+	// GeoUnitMiles distances in miles.
+	GeoUnitMiles GeoUnit = "mi"
+	// GeoUnitFeet distances in feet.
+	GeoUnitFeet GeoUnit = "ft"
+)
+
+func (g GeoUnit) String() string {
+	return string(g)
+}
+
+type SortOrder string
+
+const (
+	// SortOrderDefault results in the order returned by redis.
+	SortOrderDefault SortOrder = ""
+	// SortOrderAsc results in ascending order.
+	SortOrderAsc SortOrder = "ASC"
+	// SortOrderDesc results in descending order.
+	SortOrderDesc SortOrder = "DESC"
+)
+
+func (s SortOrder) String() string {
+	return string(s)
+}
+
+type SearchOn string
+
+const (
+	// SearchOnHash indexes the hashes of the prefixes.
+	SearchOnHash SearchOn = "HASH"
+	// SearchOnJSON indexes the RedisJSON documents of the prefixes.
+	SearchOnJSON SearchOn = "JSON"
+)
+
+func (s SearchOn) String() string {
+	return string(s)
+}
+
+type SearchFieldType string
+
+const (
+	// SearchFieldText full-text field, queried by its terms.
+	SearchFieldText SearchFieldType = "TEXT"
+	// SearchFieldTag field with exact values separated by a separator, queried by @field:{value}.
+	SearchFieldTag SearchFieldType = "TAG"
+	// SearchFieldNumeric numeric field, queried by ranges @field:[min max].
+	SearchFieldNumeric SearchFieldType = "NUMERIC"
+	// SearchFieldGeo "longitude,latitude" field, queried by radius @field:[lon lat radius unit].
+	SearchFieldGeo SearchFieldType = "GEO"
+	// SearchFieldVector vector field, queried by KNN.
+	SearchFieldVector SearchFieldType = "VECTOR"
+)
+
+func (s SearchFieldType) String() string {
+	return string(s)
+}
+
+type VectorAlgorithm string
+
+const (
+	// VectorAlgorithmFlat exact search, by brute force.
+	VectorAlgorithmFlat VectorAlgorithm = "FLAT"
+	// VectorAlgorithmHNSW approximate search, faster with large indexes.
+	VectorAlgorithmHNSW VectorAlgorithm = "HNSW"
+)
+
+func (v VectorAlgorithm) String() string {
+	return string(v)
+}
+
+type VectorDistance string
+
+const (
+	// VectorDistanceL2 euclidean distance.
+	VectorDistanceL2 VectorDistance = "L2"
+	// VectorDistanceIP inner product.
+	VectorDistanceIP VectorDistance = "IP"
+	// VectorDistanceCosine cosine distance.
+	VectorDistanceCosine VectorDistance = "COSINE"
+)
+
+func (v VectorDistance) String() string {
+	return string(v)
+}
+
+type SearchReducer string
+
+const (
+	// SearchReducerCount number of rows of the group.
+	SearchReducerCount SearchReducer = "COUNT"
+	// SearchReducerCountDistinct number of distinct values of the field.
+	SearchReducerCountDistinct SearchReducer = "COUNT_DISTINCT"
+	// SearchReducerSum sum of the values of the field.
+	SearchReducerSum SearchReducer = "SUM"
+	// SearchReducerMin minimum value of the field.
+	SearchReducerMin SearchReducer = "MIN"
+	// SearchReducerMax maximum value of the field.
+	SearchReducerMax SearchReducer = "MAX"
+	// SearchReducerAvg average of the values of the field.
+	SearchReducerAvg SearchReducer = "AVG"
+	// SearchReducerToList distinct values of the field.
+	SearchReducerToList SearchReducer = "TOLIST"
+)
+
+func (s SearchReducer) String() string {
+	return string(s)
+}
+
+type TSDuplicatePolicy string
+
+const (
+	// TSDuplicatePolicyBlock rejects the sample with the timestamp of a sample of the time series.
+	TSDuplicatePolicyBlock TSDuplicatePolicy = "BLOCK"
+	// TSDuplicatePolicyFirst keeps the value of the sample of the time series.
+	TSDuplicatePolicyFirst TSDuplicatePolicy = "FIRST"
+	// TSDuplicatePolicyLast replaces the value of the sample of the time series.
+	TSDuplicatePolicyLast TSDuplicatePolicy = "LAST"
+	// TSDuplicatePolicyMin keeps the minimum of the values.
+	TSDuplicatePolicyMin TSDuplicatePolicy = "MIN"
+	// TSDuplicatePolicyMax keeps the maximum of the values.
+	TSDuplicatePolicyMax TSDuplicatePolicy = "MAX"
+	// TSDuplicatePolicySum keeps the sum of the values.
+	TSDuplicatePolicySum TSDuplicatePolicy = "SUM"
+)
+
+func (t TSDuplicatePolicy) String() string {
+	return string(t)
+}
+
+type TSAggregation string
+
+const (
+	// TSAggregationAvg average of the values of the bucket.
+	TSAggregationAvg TSAggregation = "avg"
+	// TSAggregationSum sum of the values of the bucket.
+	TSAggregationSum TSAggregation = "sum"
+	// TSAggregationMin minimum value of the bucket.
+	TSAggregationMin TSAggregation = "min"
+	// TSAggregationMax maximum value of the bucket.
+	TSAggregationMax TSAggregation = "max"
+	// TSAggregationRange difference between the maximum and the minimum values of the bucket.
+	TSAggregationRange TSAggregation = "range"
+	// TSAggregationCount number of samples of the bucket.
+	TSAggregationCount TSAggregation = "count"
+	// TSAggregationFirst value of the sample with the lowest timestamp of the bucket.
+	TSAggregationFirst TSAggregation = "first"
+	// TSAggregationLast value of the sample with the highest timestamp of the bucket.
+	TSAggregationLast TSAggregation = "last"
+	// TSAggregationStdP population standard deviation of the values of the bucket.
+	TSAggregationStdP TSAggregation = "std.p"
+	// TSAggregationStdS sample standard deviation of the values of the bucket.
+	TSAggregationStdS TSAggregation = "std.s"
+	// TSAggregationVarP population variance of the values of the bucket.
+	TSAggregationVarP TSAggregation = "var.p"
+	// TSAggregationVarS sample variance of the values of the bucket.
+	TSAggregationVarS TSAggregation = "var.s"
+	// TSAggregationTwa time-weighted average of the bucket.
+	TSAggregationTwa TSAggregation = "twa"
+)
+
+func (t TSAggregation) String() string {
+	return string(t)
+}
+
+type BloomFilterMode string
+
+const (
+	// BloomFilterModeAuto uses the RedisBloom module, falling back to BloomFilterModeBitmap when the server does not
+	// have the module.
+	BloomFilterModeAuto BloomFilterMode = "AUTO"
+	// BloomFilterModeModule uses the BF.INSERT and BF.MEXISTS commands of the RedisBloom module.
+	BloomFilterModeModule BloomFilterMode = "MODULE"
+	// BloomFilterModeBitmap uses a plain redis bitmap, changed with the BITFIELD command.
+	BloomFilterModeBitmap BloomFilterMode = "BITMAP"
+)
+
+func (b BloomFilterMode) String() string {
+	return string(b)
+}
+
+type ListDirection string
+
+const (
+	// ListDirectionLeft the head of the list.
+	ListDirectionLeft ListDirection = "LEFT"
+	// ListDirectionRight the tail of the list.
+	ListDirectionRight ListDirection = "RIGHT"
+)
+
+func (l ListDirection) String() string {
+	return string(l)
+}
@@ -0,0 +1,23 @@
+package option
+
+import (
+	"errors"
+)
+
+var MsgErrInvalidClient = "redis: invalid client option"
+var MsgErrInvalidTLS = "redis: invalid tls option"
+var MsgErrUnknownConfigFormat = "redis: unknown configuration format"
+var MsgErrInvalidSetMode = "redis: invalid set mode"
+var MsgErrConflictingMode = "redis: conflicting set modes, NX and XX cannot be used together"
+var MsgErrConflictingExpiry = "redis: conflicting expiry options, only one of TTL, ExpireAt and KeepTTL can be used"
+var MsgErrInvalidTTL = "redis: invalid ttl, it cannot be negative"
+var MsgErrInvalidGeoSearch = "redis: invalid geo search option"
+
+var ErrInvalidClient = errors.New(MsgErrInvalidClient)
+var ErrInvalidTLS = errors.New(MsgErrInvalidTLS)
+var ErrUnknownConfigFormat = errors.New(MsgErrUnknownConfigFormat)
+var ErrInvalidSetMode = errors.New(MsgErrInvalidSetMode)
+var ErrConflictingMode = errors.New(MsgErrConflictingMode)
+var ErrConflictingExpiry = errors.New(MsgErrConflictingExpiry)
+var ErrInvalidTTL = errors.New(MsgErrInvalidTTL)
+var ErrInvalidGeoSearch = errors.New(MsgErrInvalidGeoSearch)
@@ -0,0 +1,202 @@
+package option
+
+import (
+	"fmt"
+	"github.com/GabrielHCataldo/go-helper/helper"
+)
+
+// GeoSearch represents options that can be used to configure an 'GeoSearch' or 'GeoSearchStore' operation, the
+// center of the search is FromMember or FromLonLat, and the area is ByRadius or ByBox.
+type GeoSearch struct {
+	// FromMember member of the index used as the center of the search (FROMMEMBER), it is converted like the
+	// members of GeoAdd.
+	FromMember any
+	// FromLongitude and FromLatitude coordinates of the center of the search (FROMLONLAT).
+	FromLongitude *float64
+	FromLatitude  *float64
+	// Radius of the circular area of the search (BYRADIUS).
+	Radius *float64
+	// Width and Height of the rectangular area of the search (BYBOX).
+	Width  *float64
+	Height *float64
+	// Unit of the Radius, Width, Height and of the distances returned.
+	// Default is GeoUnitMeters.
+	Unit *GeoUnit
+	// Sort results by the distance to the center, SortOrderAsc or SortOrderDesc.
+	// Default is SortOrderDefault.
+	Sort *SortOrder
+	// Count maximum number of results, zero means all.
+	// Default is 0.
+	Count *int
+	// Any returns the first Count results found, faster, but they are not the closest ones (COUNT ANY).
+	// Default is false.
+	Any *bool
+	// WithCoord returns the coordinates of the results (only GeoSearch).
+	// Default is false.
+	WithCoord *bool
+	// WithDist returns the distance of the results to the center (only GeoSearch).
+	// Default is false.
+	WithDist *bool
+	// StoreDist stores the distances to the center as the scores, instead of the positions, (only GeoSearchStore).
+	// Default is false.
+	StoreDist *bool
+}
+
+// NewGeoSearch creates a new GeoSearch instance.
+func NewGeoSearch() *GeoSearch {
+	return &GeoSearch{}
+}
+
+// SetFromMember sets value for the FromMember field.
+func (g *GeoSearch) SetFromMember(member any) *GeoSearch {
+	g.FromMember = member
+	return g
+}
+
+// SetFromLonLat sets value for the FromLongitude and FromLatitude fields.
+func (g *GeoSearch) SetFromLonLat(longitude, latitude float64) *GeoSearch {
+	g.FromLongitude = &longitude
+	g.FromLatitude = &latitude
+	return g
+}
+
+// SetByRadius sets value for the Radius field.
+func (g *GeoSearch) SetByRadius(radius float64) *GeoSearch {
+	g.Radius = &radius
+	return g
+}
+
+// SetByBox sets value for the Width and Height fields.
+func (g *GeoSearch) SetByBox(width, height float64) *GeoSearch {
+	g.Width = &width
+	g.Height = &height
+	return g
+}
+
+// SetUnit sets value for the Unit field.
+func (g *GeoSearch) SetUnit(unit GeoUnit) *GeoSearch {
+	g.Unit = &unit
+	return g
+}
+
+// SetSort sets value for the Sort field.
+func (g *GeoSearch) SetSort(sort SortOrder) *GeoSearch {
+	g.Sort = &sort
+	return g
+}
+
+// SetCount sets value for the Count field.
+func (g *GeoSearch) SetCount(count int) *GeoSearch {
+	g.Count = &count
+	return g
+}
+
+// SetAny sets value for the Any field.
+func (g *GeoSearch) SetAny(countAny bool) *GeoSearch {
+	g.Any = &countAny
+	return g
+}
+
+// SetWithCoord sets value for the WithCoord field.
+func (g *GeoSearch) SetWithCoord(withCoord bool) *GeoSearch {
+	g.WithCoord = &withCoord
+	return g
+}
+
+// SetWithDist sets value for the WithDist field.
+func (g *GeoSearch) SetWithDist(withDist bool) *GeoSearch {
+	g.WithDist = &withDist
+	return g
+}
+
+// SetStoreDist sets value for the StoreDist field.
+func (g *GeoSearch) SetStoreDist(storeDist bool) *GeoSearch {
+	g.StoreDist = &storeDist
+	return g
+}
+
+// Validate returns ErrInvalidGeoSearch if the search does not have exactly one center and one area, or if Any is
+// informed without Count.
+func (g *GeoSearch) Validate() error {
+	fromLonLat := helper.IsNotNil(g.FromLongitude) && helper.IsNotNil(g.FromLatitude)
+	byBox := helper.IsNotNil(g.Width) && helper.IsNotNil(g.Height)
+	if helper.IsNotNil(g.FromMember) == fromLonLat {
+		return fmt.Errorf("%w: inform either the from member or the from longitude and latitude", ErrInvalidGeoSearch)
+	} else if helper.IsNotNil(g.Radius) == byBox {
+		return fmt.Errorf("%w: inform either the radius or the width and height", ErrInvalidGeoSearch)
+	} else if helper.IfNilReturns(g.Any, false) && helper.IfNilReturns(g.Count, 0) <= 0 {
+		return fmt.Errorf("%w: any requires a count greater than zero", ErrInvalidGeoSearch)
+	}
+	return nil
+}
+
+// GetOptionGeoSearchByParams assembles the GeoSearch object from optional parameters.
+func GetOptionGeoSearchByParams(opts []*GeoSearch) *GeoSearch {
+	result := &GeoSearch{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotNil(opt.FromMember) {
+			result.FromMember = opt.FromMember
+		}
+		if helper.IsNotNil(opt.FromLongitude) {
+			result.FromLongitude = opt.FromLongitude
+		}
+		if helper.IsNotNil(opt.FromLatitude) {
+			result.FromLatitude = opt.FromLatitude
+		}
+		if helper.IsNotNil(opt.Radius) {
+			result.Radius = opt.Radius
+		}
+		if helper.IsNotNil(opt.Width) {
+			result.Width = opt.Width
+		}
+		if helper.IsNotNil(opt.Height) {
+			result.Height = opt.Height
+		}
+		if helper.IsNotNil(opt.Unit) {
+			result.Unit = opt.Unit
+		}
+		if helper.IsNotNil(opt.Sort) {
+			result.Sort = opt.Sort
+		}
+		if helper.IsNotNil(opt.Count) {
+			result.Count = opt.Count
+		}
+		if helper.IsNotNil(opt.Any) {
+			result.Any = opt.Any
+		}
+		if helper.IsNotNil(opt.WithCoord) {
+			result.WithCoord = opt.WithCoord
+		}
+		if helper.IsNotNil(opt.WithDist) {
+			result.WithDist = opt.WithDist
+		}
+		if helper.IsNotNil(opt.StoreDist) {
+			result.StoreDist = opt.StoreDist
+		}
+	}
+	if helper.IsNil(result.Unit) {
+		result.Unit = helper.ConvertToPointer(GeoUnitMeters)
+	}
+	if helper.IsNil(result.Sort) {
+		result.Sort = helper.ConvertToPointer(SortOrderDefault)
+	}
+	if helper.IsNil(result.Count) {
+		result.Count = helper.ConvertToPointer(0)
+	}
+	if helper.IsNil(result.Any) {
+		result.Any = helper.ConvertToPointer(false)
+	}
+	if helper.IsNil(result.WithCoord) {
+		result.WithCoord = helper.ConvertToPointer(false)
+	}
+	if helper.IsNil(result.WithDist) {
+		result.WithDist = helper.ConvertToPointer(false)
+	}
+	if helper.IsNil(result.StoreDist) {
+		result.StoreDist = helper.ConvertToPointer(false)
+	}
+	return result
+}
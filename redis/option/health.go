@@ -0,0 +1,85 @@
+package option
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"time"
+)
+
+// Health represents options that can be used to configure the readiness handler (redis.Template.ReadinessHandler).
+type Health struct {
+	// Timeout of each health check.
+	// Default is 2 seconds.
+	Timeout *time.Duration
+	// MaxLatency is the round-trip latency of the PING command above which redis is reported as degraded.
+	// Zero means no threshold.
+	MaxLatency *time.Duration
+	// MaxMemoryUsage is the ratio between used_memory and maxmemory above which redis is reported as degraded,
+	// ex: 0.9. It is only evaluated when maxmemory is configured on the server. Zero means no threshold.
+	MaxMemoryUsage *float64
+	// MaxReplicationLag is the replication lag above which redis is reported as degraded. Zero means no threshold.
+	MaxReplicationLag *time.Duration
+}
+
+// NewHealth creates a new Health instance.
+func NewHealth() *Health {
+	return &Health{}
+}
+
+// SetTimeout sets value for the Timeout field.
+func (h *Health) SetTimeout(timeout time.Duration) *Health {
+	h.Timeout = &timeout
+	return h
+}
+
+// SetMaxLatency sets value for the MaxLatency field.
+func (h *Health) SetMaxLatency(maxLatency time.Duration) *Health {
+	h.MaxLatency = &maxLatency
+	return h
+}
+
+// SetMaxMemoryUsage sets value for the MaxMemoryUsage field.
+func (h *Health) SetMaxMemoryUsage(maxMemoryUsage float64) *Health {
+	h.MaxMemoryUsage = &maxMemoryUsage
+	return h
+}
+
+// SetMaxReplicationLag sets value for the MaxReplicationLag field.
+func (h *Health) SetMaxReplicationLag(maxReplicationLag time.Duration) *Health {
+	h.MaxReplicationLag = &maxReplicationLag
+	return h
+}
+
+// GetOptionHealthByParams assembles the Health object from optional parameters.
+func GetOptionHealthByParams(opts []*Health) *Health {
+	result := &Health{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotNil(opt.Timeout) {
+			result.Timeout = opt.Timeout
+		}
+		if helper.IsNotNil(opt.MaxLatency) {
+			result.MaxLatency = opt.MaxLatency
+		}
+		if helper.IsNotNil(opt.MaxMemoryUsage) {
+			result.MaxMemoryUsage = opt.MaxMemoryUsage
+		}
+		if helper.IsNotNil(opt.MaxReplicationLag) {
+			result.MaxReplicationLag = opt.MaxReplicationLag
+		}
+	}
+	if helper.IsNil(result.Timeout) || *result.Timeout <= 0 {
+		result.Timeout = helper.ConvertToPointer(2 * time.Second)
+	}
+	if helper.IsNil(result.MaxLatency) {
+		result.MaxLatency = helper.ConvertToPointer(time.Duration(0))
+	}
+	if helper.IsNil(result.MaxMemoryUsage) {
+		result.MaxMemoryUsage = helper.ConvertToPointer(float64(0))
+	}
+	if helper.IsNil(result.MaxReplicationLag) {
+		result.MaxReplicationLag = helper.ConvertToPointer(time.Duration(0))
+	}
+	return result
+}
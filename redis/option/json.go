@@ -0,0 +1,47 @@
+package option
+
+import "github.com/GabrielHCataldo/go-helper/helper"
+
+// JSONCodec encodes and decodes the documents of the RedisJSON operations of the template, ex: a codec with a
+// faster library or with custom handling of the types, it must produce and read standard JSON.
+type JSONCodec interface {
+	// Marshal returns the JSON encoding of v.
+	Marshal(v any) ([]byte, error)
+	// Unmarshal parses the JSON encoded data and stores the result in the value pointed to by v.
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONSet represents options that can be used to configure an 'JSONSet' operation.
+type JSONSet struct {
+	// Mode can be SetModeNx, SetModeXx or SetModeDefault.
+	// Default is SetModeDefault.
+	Mode *SetMode
+}
+
+// NewJSONSet creates a new JSONSet instance.
+func NewJSONSet() *JSONSet {
+	return &JSONSet{}
+}
+
+// SetMode sets value for the Mode field.
+func (j *JSONSet) SetMode(mode SetMode) *JSONSet {
+	j.Mode = &mode
+	return j
+}
+
+// GetOptionJSONSetByParams assembles the JSONSet object from optional parameters.
+func GetOptionJSONSetByParams(opts []*JSONSet) *JSONSet {
+	result := &JSONSet{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotNil(opt.Mode) {
+			result.Mode = opt.Mode
+		}
+	}
+	if helper.IsNil(result.Mode) {
+		result.Mode = helper.ConvertToPointer(SetModeDefault)
+	}
+	return result
+}
@@ -0,0 +1,185 @@
+package option
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+)
+
+// BFReserve represents options that can be used to configure an 'BFReserve' operation.
+type BFReserve struct {
+	// Expansion multiplies the capacity of each new sub-filter created when the filter is full.
+	// Default is 2.
+	Expansion *int64
+	// NonScaling prevents the creation of sub-filters, the items added when the filter is full return an error.
+	// Default is false.
+	NonScaling *bool
+}
+
+// TopKReserve represents options that can be used to configure an 'TopKReserve' operation.
+type TopKReserve struct {
+	// Width number of counters in each array of the sketch.
+	// Default is 8.
+	Width *int64
+	// Depth number of arrays of the sketch.
+	// Default is 7.
+	Depth *int64
+	// Decay probability of decreasing a counter of another item in a collision, between 0 and 1.
+	// Default is 0.9.
+	Decay *float64
+}
+
+// BloomFilter represents options that can be used to configure the Bloom filter (redis.NewBloomFilter).
+type BloomFilter struct {
+	// Capacity expected number of items, the false positive rate grows above the ErrorRate when more items are added.
+	// Default is 1000.
+	Capacity *int64
+	// ErrorRate desired false positive rate, between 0 and 1, ex: 0.01 is 1%.
+	// Default is 0.01.
+	ErrorRate *float64
+	// Mode of the filter, the keys created by a mode cannot be used by the other.
+	// Default is BloomFilterModeAuto.
+	Mode *BloomFilterMode
+}
+
+// NewBFReserve creates a new BFReserve instance.
+func NewBFReserve() *BFReserve {
+	return &BFReserve{}
+}
+
+// NewTopKReserve creates a new TopKReserve instance.
+func NewTopKReserve() *TopKReserve {
+	return &TopKReserve{}
+}
+
+// NewBloomFilter creates a new BloomFilter instance.
+func NewBloomFilter() *BloomFilter {
+	return &BloomFilter{}
+}
+
+// SetExpansion sets value for the Expansion field.
+func (b *BFReserve) SetExpansion(expansion int64) *BFReserve {
+	b.Expansion = &expansion
+	return b
+}
+
+// SetNonScaling sets value for the NonScaling field.
+func (b *BFReserve) SetNonScaling(nonScaling bool) *BFReserve {
+	b.NonScaling = &nonScaling
+	return b
+}
+
+// SetWidth sets value for the Width field.
+func (t *TopKReserve) SetWidth(width int64) *TopKReserve {
+	t.Width = &width
+	return t
+}
+
+// SetDepth sets value for the Depth field.
+func (t *TopKReserve) SetDepth(depth int64) *TopKReserve {
+	t.Depth = &depth
+	return t
+}
+
+// SetDecay sets value for the Decay field.
+func (t *TopKReserve) SetDecay(decay float64) *TopKReserve {
+	t.Decay = &decay
+	return t
+}
+
+// SetCapacity sets value for the Capacity field.
+func (b *BloomFilter) SetCapacity(capacity int64) *BloomFilter {
+	b.Capacity = &capacity
+	return b
+}
+
+// SetErrorRate sets value for the ErrorRate field.
+func (b *BloomFilter) SetErrorRate(errorRate float64) *BloomFilter {
+	b.ErrorRate = &errorRate
+	return b
+}
+
+// SetMode sets value for the Mode field.
+func (b *BloomFilter) SetMode(mode BloomFilterMode) *BloomFilter {
+	b.Mode = &mode
+	return b
+}
+
+// GetOptionBFReserveByParams assembles the BFReserve object from optional parameters.
+func GetOptionBFReserveByParams(opts []*BFReserve) *BFReserve {
+	result := &BFReserve{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotNil(opt.Expansion) {
+			result.Expansion = opt.Expansion
+		}
+		if helper.IsNotNil(opt.NonScaling) {
+			result.NonScaling = opt.NonScaling
+		}
+	}
+	if helper.IsNil(result.Expansion) || *result.Expansion < 1 {
+		result.Expansion = helper.ConvertToPointer(int64(2))
+	}
+	if helper.IsNil(result.NonScaling) {
+		result.NonScaling = helper.ConvertToPointer(false)
+	}
+	return result
+}
+
+// GetOptionTopKReserveByParams assembles the TopKReserve object from optional parameters.
+func GetOptionTopKReserveByParams(opts []*TopKReserve) *TopKReserve {
+	result := &TopKReserve{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotNil(opt.Width) {
+			result.Width = opt.Width
+		}
+		if helper.IsNotNil(opt.Depth) {
+			result.Depth = opt.Depth
+		}
+		if helper.IsNotNil(opt.Decay) {
+			result.Decay = opt.Decay
+		}
+	}
+	if helper.IsNil(result.Width) || *result.Width < 1 {
+		result.Width = helper.ConvertToPointer(int64(8))
+	}
+	if helper.IsNil(result.Depth) || *result.Depth < 1 {
+		result.Depth = helper.ConvertToPointer(int64(7))
+	}
+	if helper.IsNil(result.Decay) || *result.Decay <= 0 || *result.Decay > 1 {
+		result.Decay = helper.ConvertToPointer(0.9)
+	}
+	return result
+}
+
+// GetOptionBloomFilterByParams assembles the BloomFilter object from optional parameters.
+func GetOptionBloomFilterByParams(opts []*BloomFilter) *BloomFilter {
+	result := &BloomFilter{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotNil(opt.Capacity) {
+			result.Capacity = opt.Capacity
+		}
+		if helper.IsNotNil(opt.ErrorRate) {
+			result.ErrorRate = opt.ErrorRate
+		}
+		if helper.IsNotNil(opt.Mode) {
+			result.Mode = opt.Mode
+		}
+	}
+	if helper.IsNil(result.Capacity) || *result.Capacity < 1 {
+		result.Capacity = helper.ConvertToPointer(int64(1000))
+	}
+	if helper.IsNil(result.ErrorRate) || *result.ErrorRate <= 0 || *result.ErrorRate >= 1 {
+		result.ErrorRate = helper.ConvertToPointer(0.01)
+	}
+	if helper.IsNil(result.Mode) {
+		result.Mode = helper.ConvertToPointer(BloomFilterModeAuto)
+	}
+	return result
+}
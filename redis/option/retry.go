@@ -0,0 +1,121 @@
+package option
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"time"
+)
+
+// RetryPolicy represents options that can be used to configure the retries of the template operations, on the
+// template (Client.RetryPolicy) or per call (redis.WithRetryPolicy).
+//
+// The retries of the policy are applied to the whole template operation, on top of the retries of the commands
+// done by the underlying client (Client.MaxRetries).
+type RetryPolicy struct {
+	// MaxAttempts maximum number of attempts of the operation, including the first one, 1 disables the retries.
+	// Default is 3.
+	MaxAttempts *int
+	// MinBackoff backoff before the second attempt, doubled at each attempt.
+	// Default is 8 milliseconds.
+	MinBackoff *time.Duration
+	// MaxBackoff maximum backoff between the attempts.
+	// Default is 512 milliseconds.
+	MaxBackoff *time.Duration
+	// Jitter fraction of the backoff randomized, the backoff is chosen between backoff*(1-Jitter) and backoff, so
+	// the clients retrying at the same time are spread out.
+	// Default is 0.5.
+	Jitter *float64
+	// RetryOn decides if the operation is retried after the error (not required).
+	//
+	// By default, the errors retryable according to redis.IsRetryable are retried.
+	RetryOn func(err error) bool
+	// RetryNonIdempotent retries also the operations that can have a different effect when executed twice, like
+	// Set with SetModeNx, SetGet, GetDel, Rename or counter increments (redis.Operation.Idempotent).
+	// Default is false.
+	RetryNonIdempotent *bool
+}
+
+// NewRetryPolicy creates a new RetryPolicy instance.
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{}
+}
+
+// SetMaxAttempts sets value for the MaxAttempts field.
+func (r *RetryPolicy) SetMaxAttempts(maxAttempts int) *RetryPolicy {
+	r.MaxAttempts = &maxAttempts
+	return r
+}
+
+// SetMinBackoff sets value for the MinBackoff field.
+func (r *RetryPolicy) SetMinBackoff(backoff time.Duration) *RetryPolicy {
+	r.MinBackoff = &backoff
+	return r
+}
+
+// SetMaxBackoff sets value for the MaxBackoff field.
+func (r *RetryPolicy) SetMaxBackoff(backoff time.Duration) *RetryPolicy {
+	r.MaxBackoff = &backoff
+	return r
+}
+
+// SetJitter sets value for the Jitter field.
+func (r *RetryPolicy) SetJitter(jitter float64) *RetryPolicy {
+	r.Jitter = &jitter
+	return r
+}
+
+// SetRetryOn sets value for the RetryOn field.
+func (r *RetryPolicy) SetRetryOn(f func(err error) bool) *RetryPolicy {
+	r.RetryOn = f
+	return r
+}
+
+// SetRetryNonIdempotent sets value for the RetryNonIdempotent field.
+func (r *RetryPolicy) SetRetryNonIdempotent(retry bool) *RetryPolicy {
+	r.RetryNonIdempotent = &retry
+	return r
+}
+
+// GetOptionRetryPolicyByParams assembles the RetryPolicy object from optional parameters, the last ones have
+// priority, filling in the default values for the fields not informed.
+func GetOptionRetryPolicyByParams(opts []*RetryPolicy) *RetryPolicy {
+	result := &RetryPolicy{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotNil(opt.MaxAttempts) {
+			result.MaxAttempts = opt.MaxAttempts
+		}
+		if helper.IsNotNil(opt.MinBackoff) {
+			result.MinBackoff = opt.MinBackoff
+		}
+		if helper.IsNotNil(opt.MaxBackoff) {
+			result.MaxBackoff = opt.MaxBackoff
+		}
+		if helper.IsNotNil(opt.Jitter) {
+			result.Jitter = opt.Jitter
+		}
+		if opt.RetryOn != nil {
+			result.RetryOn = opt.RetryOn
+		}
+		if helper.IsNotNil(opt.RetryNonIdempotent) {
+			result.RetryNonIdempotent = opt.RetryNonIdempotent
+		}
+	}
+	if helper.IsNil(result.MaxAttempts) {
+		result.MaxAttempts = helper.ConvertToPointer(3)
+	}
+	if helper.IsNil(result.MinBackoff) {
+		result.MinBackoff = helper.ConvertToPointer(8 * time.Millisecond)
+	}
+	if helper.IsNil(result.MaxBackoff) {
+		result.MaxBackoff = helper.ConvertToPointer(512 * time.Millisecond)
+	}
+	if helper.IsNil(result.Jitter) {
+		result.Jitter = helper.ConvertToPointer(0.5)
+	}
+	if helper.IsNil(result.RetryNonIdempotent) {
+		result.RetryNonIdempotent = helper.ConvertToPointer(false)
+	}
+	return result
+}
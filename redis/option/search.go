@@ -0,0 +1,38 @@
+package option
+
+import "github.com/GabrielHCataldo/go-helper/helper"
+
+// FTDropIndex represents options that can be used to configure an 'FTDropIndex' operation.
+type FTDropIndex struct {
+	// DeleteDocs deletes the hashes or documents indexed with the index (DD).
+	// Default is false.
+	DeleteDocs *bool
+}
+
+// NewFTDropIndex creates a new FTDropIndex instance.
+func NewFTDropIndex() *FTDropIndex {
+	return &FTDropIndex{}
+}
+
+// SetDeleteDocs sets value for the DeleteDocs field.
+func (f *FTDropIndex) SetDeleteDocs(deleteDocs bool) *FTDropIndex {
+	f.DeleteDocs = &deleteDocs
+	return f
+}
+
+// GetOptionFTDropIndexByParams assembles the FTDropIndex object from optional parameters.
+func GetOptionFTDropIndexByParams(opts []*FTDropIndex) *FTDropIndex {
+	result := &FTDropIndex{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotNil(opt.DeleteDocs) {
+			result.DeleteDocs = opt.DeleteDocs
+		}
+	}
+	if helper.IsNil(result.DeleteDocs) {
+		result.DeleteDocs = helper.ConvertToPointer(false)
+	}
+	return result
+}
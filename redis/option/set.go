@@ -13,8 +13,16 @@ type Set struct {
 	TTL      *time.Duration
 	ExpireAt *time.Time
 	// KeepTTL is a Redis KEEPTTL option to keep existing TTL, it requires your redis-server version >= 6.0,
-	// otherwise you will receive an error: (error) ERR syntax error.
+	// otherwise the template returns the error ErrUnsupportedOption of the redis package.
 	KeepTTL *bool
+	// Compression algorithm of the value, overrides the Client.Compression of the template, use CompressionNone to
+	// store the value without compression.
+	Compression *Compression
+	// CompressionThreshold minimum size in bytes of the value to be compressed, overrides the
+	// Client.CompressionThreshold of the template.
+	CompressionThreshold *int
+	// Encrypt set false to store the value without encryption when the template has Client.Encryption.
+	Encrypt *bool
 }
 
 // NewSet creates a new Set instance.
@@ -46,6 +54,66 @@ func (s *Set) SetKeepTTL(keepTTL bool) *Set {
 	return s
 }
 
+// SetCompression sets value for the Compression field.
+func (s *Set) SetCompression(compression Compression) *Set {
+	s.Compression = &compression
+	return s
+}
+
+// SetCompressionThreshold sets value for the CompressionThreshold field.
+func (s *Set) SetCompressionThreshold(threshold int) *Set {
+	s.CompressionThreshold = &threshold
+	return s
+}
+
+// SetEncrypt sets value for the Encrypt field.
+func (s *Set) SetEncrypt(encrypt bool) *Set {
+	s.Encrypt = &encrypt
+	return s
+}
+
+// Validate returns the error of the options that conflict, which redis would reject with a syntax error:
+// ErrInvalidSetMode, ErrInvalidTTL or ErrConflictingExpiry.
+func (s *Set) Validate() error {
+	if helper.IsNil(s) {
+		return nil
+	} else if helper.IsNotNil(s.Mode) && *s.Mode != SetModeDefault && *s.Mode != SetModeNx && *s.Mode != SetModeXx {
+		return ErrInvalidSetMode
+	} else if helper.IsNotNil(s.TTL) && *s.TTL < 0 {
+		return ErrInvalidTTL
+	}
+	expiries := 0
+	if helper.IsNotNil(s.TTL) && *s.TTL > 0 {
+		expiries++
+	}
+	if helper.IsNotNil(s.ExpireAt) && !s.ExpireAt.IsZero() {
+		expiries++
+	}
+	if helper.IfNilReturns(s.KeepTTL, false) {
+		expiries++
+	}
+	if expiries > 1 {
+		return ErrConflictingExpiry
+	}
+	return nil
+}
+
+// ValidateSetByParams validates the optional parameters before they are assembled by GetOptionSetByParams,
+// returning ErrConflictingMode if they inform both SetModeNx and SetModeXx, and the error of Set.Validate of the
+// result.
+func ValidateSetByParams(opts []*Set) error {
+	var mode SetMode
+	for _, opt := range opts {
+		if helper.IsNil(opt) || helper.IsNil(opt.Mode) || *opt.Mode == SetModeDefault {
+			continue
+		} else if mode != SetModeDefault && mode != *opt.Mode {
+			return ErrConflictingMode
+		}
+		mode = *opt.Mode
+	}
+	return GetOptionSetByParams(opts).Validate()
+}
+
 // GetOptionSetByParams assembles the Set object from optional parameters.
 func GetOptionSetByParams(opts []*Set) *Set {
 	result := &Set{}
@@ -65,6 +133,15 @@ func GetOptionSetByParams(opts []*Set) *Set {
 		if helper.IsNotNil(opt.KeepTTL) {
 			result.KeepTTL = opt.KeepTTL
 		}
+		if helper.IsNotNil(opt.Compression) {
+			result.Compression = opt.Compression
+		}
+		if helper.IsNotNil(opt.CompressionThreshold) {
+			result.CompressionThreshold = opt.CompressionThreshold
+		}
+		if helper.IsNotNil(opt.Encrypt) {
+			result.Encrypt = opt.Encrypt
+		}
 	}
 	if helper.IsNil(result.Mode) {
 		result.Mode = helper.ConvertToPointer(SetModeDefault)
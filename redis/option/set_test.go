@@ -0,0 +1,39 @@
+package option
+
+import (
+	"errors"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"testing"
+	"time"
+)
+
+func TestSetValidate(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		opts []*Set
+		want error
+	}{
+		{name: "valid", opts: []*Set{NewSet().SetMode(SetModeNx).SetTTL(time.Minute)}},
+		{name: "nil", opts: []*Set{nil}},
+		{name: "zero ttl", opts: []*Set{NewSet().SetTTL(0).SetKeepTTL(true)}},
+		{name: "mode", opts: []*Set{NewSet().SetMode("NXX")}, want: ErrInvalidSetMode},
+		{name: "nx and xx", opts: []*Set{NewSet().SetMode(SetModeNx), NewSet().SetMode(SetModeXx)}, want: ErrConflictingMode},
+		{name: "negative ttl", opts: []*Set{NewSet().SetTTL(-time.Second)}, want: ErrInvalidTTL},
+		{
+			name: "ttl and expire at",
+			opts: []*Set{NewSet().SetTTL(time.Minute).SetExpireAt(time.Now().Add(time.Hour))},
+			want: ErrConflictingExpiry,
+		},
+		{
+			name: "keep ttl and ttl",
+			opts: []*Set{NewSet().SetKeepTTL(true), NewSet().SetTTL(time.Minute)},
+			want: ErrConflictingExpiry,
+		},
+	} {
+		err := ValidateSetByParams(tt.opts)
+		if !errors.Is(err, tt.want) {
+			logger.Errorf("ValidateSetByParams() %s err = %v, want = %v", tt.name, err, tt.want)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package option
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"time"
+)
+
+// TSCreate represents options that can be used to configure an 'TSCreate' operation.
+type TSCreate struct {
+	// Retention maximum age of the samples, compared to the highest timestamp of the time series, the older samples
+	// are deleted. Zero means that the samples are never deleted.
+	// Default is 0.
+	Retention *time.Duration
+	// DuplicatePolicy resolves the samples added with the timestamp of a sample of the time series, nil means the
+	// redis default (TSDuplicatePolicyBlock).
+	DuplicatePolicy *TSDuplicatePolicy
+	// Labels of the time series, used by the filters of TSMRange, ex: {"sensor": "temperature", "room": "kitchen"}.
+	Labels map[string]string
+}
+
+// TSAdd represents options that can be used to configure an 'TSAdd' operation.
+type TSAdd struct {
+	// Retention of the time series, used only if the key does not exist, see TSCreate.Retention.
+	// Default is 0.
+	Retention *time.Duration
+	// OnDuplicate overrides the duplicate policy of the time series for this sample, nil means the policy of the
+	// time series.
+	OnDuplicate *TSDuplicatePolicy
+	// Labels of the time series, used only if the key does not exist, see TSCreate.Labels.
+	Labels map[string]string
+}
+
+// TSRange represents options that can be used to configure an 'TSRange' or 'TSRevRange' operation.
+type TSRange struct {
+	// Aggregation aggregates the samples in buckets of Bucket duration, nil returns the samples.
+	Aggregation *TSAggregation
+	// Bucket duration of the aggregation, in milliseconds precision, the buckets are aligned to the Unix epoch.
+	// Default is 1 minute.
+	Bucket *time.Duration
+	// Empty returns the buckets without samples, with the value NaN, or zero for TSAggregationSum and
+	// TSAggregationCount.
+	// Default is false.
+	Empty *bool
+	// Count maximum number of samples, or buckets, returned. Zero means no limit.
+	// Default is 0.
+	Count *int64
+}
+
+// TSMRange represents options that can be used to configure an 'TSMRange' operation.
+type TSMRange struct {
+	// Aggregation aggregates the samples of each time series in buckets, see TSRange.Aggregation.
+	Aggregation *TSAggregation
+	// Bucket duration of the aggregation, see TSRange.Bucket.
+	// Default is 1 minute.
+	Bucket *time.Duration
+	// Empty returns the buckets without samples, see TSRange.Empty.
+	// Default is false.
+	Empty *bool
+	// Count maximum number of samples, or buckets, returned for each time series. Zero means no limit.
+	// Default is 0.
+	Count *int64
+	// WithLabels returns all the labels of the time series.
+	// Default is false.
+	WithLabels *bool
+	// SelectedLabels returns only these labels of the time series, ignored with WithLabels.
+	SelectedLabels []string
+	// GroupBy groups the time series by the value of the label, reducing the samples with the same timestamp with
+	// the Reducer, the series returned are named "label=value".
+	GroupBy *string
+	// Reducer of the samples of the groups, only TSAggregationAvg, TSAggregationSum, TSAggregationMin,
+	// TSAggregationMax, TSAggregationRange, TSAggregationCount and the deviations and variances are supported.
+	// Default is TSAggregationSum.
+	Reducer *TSAggregation
+}
+
+// NewTSCreate creates a new TSCreate instance.
+func NewTSCreate() *TSCreate {
+	return &TSCreate{}
+}
+
+// NewTSAdd creates a new TSAdd instance.
+func NewTSAdd() *TSAdd {
+	return &TSAdd{}
+}
+
+// NewTSRange creates a new TSRange instance.
+func NewTSRange() *TSRange {
+	return &TSRange{}
+}
+
+// NewTSMRange creates a new TSMRange instance.
+func NewTSMRange() *TSMRange {
+	return &TSMRange{}
+}
+
+// SetRetention sets value for the Retention field.
+func (t *TSCreate) SetRetention(retention time.Duration) *TSCreate {
+	t.Retention = &retention
+	return t
+}
+
+// SetDuplicatePolicy sets value for the DuplicatePolicy field.
+func (t *TSCreate) SetDuplicatePolicy(duplicatePolicy TSDuplicatePolicy) *TSCreate {
+	t.DuplicatePolicy = &duplicatePolicy
+	return t
+}
+
+// SetLabels sets value for the Labels field.
+func (t *TSCreate) SetLabels(labels map[string]string) *TSCreate {
+	t.Labels = labels
+	return t
+}
+
+// SetRetention sets value for the Retention field.
+func (t *TSAdd) SetRetention(retention time.Duration) *TSAdd {
+	t.Retention = &retention
+	return t
+}
+
+// SetOnDuplicate sets value for the OnDuplicate field.
+func (t *TSAdd) SetOnDuplicate(onDuplicate TSDuplicatePolicy) *TSAdd {
+	t.OnDuplicate = &onDuplicate
+	return t
+}
+
+// SetLabels sets value for the Labels field.
+func (t *TSAdd) SetLabels(labels map[string]string) *TSAdd {
+	t.Labels = labels
+	return t
+}
+
+// SetAggregation sets value for the Aggregation field.
+func (t *TSRange) SetAggregation(aggregation TSAggregation) *TSRange {
+	t.Aggregation = &aggregation
+	return t
+}
+
+// SetBucket sets value for the Bucket field.
+func (t *TSRange) SetBucket(bucket time.Duration) *TSRange {
+	t.Bucket = &bucket
+	return t
+}
+
+// SetEmpty sets value for the Empty field.
+func (t *TSRange) SetEmpty(empty bool) *TSRange {
+	t.Empty = &empty
+	return t
+}
+
+// SetCount sets value for the Count field.
+func (t *TSRange) SetCount(count int64) *TSRange {
+	t.Count = &count
+	return t
+}
+
+// SetAggregation sets value for the Aggregation field.
+func (t *TSMRange) SetAggregation(aggregation TSAggregation) *TSMRange {
+	t.Aggregation = &aggregation
+	return t
+}
+
+// SetBucket sets value for the Bucket field.
+func (t *TSMRange) SetBucket(bucket time.Duration) *TSMRange {
+	t.Bucket = &bucket
+	return t
+}
+
+// SetEmpty sets value for the Empty field.
+func (t *TSMRange) SetEmpty(empty bool) *TSMRange {
+	t.Empty = &empty
+	return t
+}
+
+// SetCount sets value for the Count field.
+func (t *TSMRange) SetCount(count int64) *TSMRange {
+	t.Count = &count
+	return t
+}
+
+// SetWithLabels sets value for the WithLabels field.
+func (t *TSMRange) SetWithLabels(withLabels bool) *TSMRange {
+	t.WithLabels = &withLabels
+	return t
+}
+
+// SetSelectedLabels sets value for the SelectedLabels field.
+func (t *TSMRange) SetSelectedLabels(selectedLabels ...string) *TSMRange {
+	t.SelectedLabels = selectedLabels
+	return t
+}
+
+// SetGroupBy sets value for the GroupBy field.
+func (t *TSMRange) SetGroupBy(groupBy string) *TSMRange {
+	t.GroupBy = &groupBy
+	return t
+}
+
+// SetReducer sets value for the Reducer field.
+func (t *TSMRange) SetReducer(reducer TSAggregation) *TSMRange {
+	t.Reducer = &reducer
+	return t
+}
+
+// GetOptionTSCreateByParams assembles the TSCreate object from optional parameters.
+func GetOptionTSCreateByParams(opts []*TSCreate) *TSCreate {
+	result := &TSCreate{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotNil(opt.Retention) {
+			result.Retention = opt.Retention
+		}
+		if helper.IsNotNil(opt.DuplicatePolicy) {
+			result.DuplicatePolicy = opt.DuplicatePolicy
+		}
+		if helper.IsNotEmpty(opt.Labels) {
+			result.Labels = opt.Labels
+		}
+	}
+	if helper.IsNil(result.Retention) || *result.Retention < 0 {
+		result.Retention = helper.ConvertToPointer(time.Duration(0))
+	}
+	return result
+}
+
+// GetOptionTSAddByParams assembles the TSAdd object from optional parameters.
+func GetOptionTSAddByParams(opts []*TSAdd) *TSAdd {
+	result := &TSAdd{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotNil(opt.Retention) {
+			result.Retention = opt.Retention
+		}
+		if helper.IsNotNil(opt.OnDuplicate) {
+			result.OnDuplicate = opt.OnDuplicate
+		}
+		if helper.IsNotEmpty(opt.Labels) {
+			result.Labels = opt.Labels
+		}
+	}
+	if helper.IsNil(result.Retention) || *result.Retention < 0 {
+		result.Retention = helper.ConvertToPointer(time.Duration(0))
+	}
+	return result
+}
+
+// GetOptionTSRangeByParams assembles the TSRange object from optional parameters.
+func GetOptionTSRangeByParams(opts []*TSRange) *TSRange {
+	result := &TSRange{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotNil(opt.Aggregation) {
+			result.Aggregation = opt.Aggregation
+		}
+		if helper.IsNotNil(opt.Bucket) {
+			result.Bucket = opt.Bucket
+		}
+		if helper.IsNotNil(opt.Empty) {
+			result.Empty = opt.Empty
+		}
+		if helper.IsNotNil(opt.Count) {
+			result.Count = opt.Count
+		}
+	}
+	if helper.IsNil(result.Bucket) || *result.Bucket < time.Millisecond {
+		result.Bucket = helper.ConvertToPointer(time.Minute)
+	}
+	if helper.IsNil(result.Empty) {
+		result.Empty = helper.ConvertToPointer(false)
+	}
+	if helper.IsNil(result.Count) || *result.Count < 0 {
+		result.Count = helper.ConvertToPointer(int64(0))
+	}
+	return result
+}
+
+// GetOptionTSMRangeByParams assembles the TSMRange object from optional parameters.
+func GetOptionTSMRangeByParams(opts []*TSMRange) *TSMRange {
+	result := &TSMRange{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotNil(opt.Aggregation) {
+			result.Aggregation = opt.Aggregation
+		}
+		if helper.IsNotNil(opt.Bucket) {
+			result.Bucket = opt.Bucket
+		}
+		if helper.IsNotNil(opt.Empty) {
+			result.Empty = opt.Empty
+		}
+		if helper.IsNotNil(opt.Count) {
+			result.Count = opt.Count
+		}
+		if helper.IsNotNil(opt.WithLabels) {
+			result.WithLabels = opt.WithLabels
+		}
+		if helper.IsNotEmpty(opt.SelectedLabels) {
+			result.SelectedLabels = opt.SelectedLabels
+		}
+		if helper.IsNotNil(opt.GroupBy) {
+			result.GroupBy = opt.GroupBy
+		}
+		if helper.IsNotNil(opt.Reducer) {
+			result.Reducer = opt.Reducer
+		}
+	}
+	if helper.IsNil(result.Bucket) || *result.Bucket < time.Millisecond {
+		result.Bucket = helper.ConvertToPointer(time.Minute)
+	}
+	if helper.IsNil(result.Empty) {
+		result.Empty = helper.ConvertToPointer(false)
+	}
+	if helper.IsNil(result.Count) || *result.Count < 0 {
+		result.Count = helper.ConvertToPointer(int64(0))
+	}
+	if helper.IsNil(result.WithLabels) {
+		result.WithLabels = helper.ConvertToPointer(false)
+	}
+	if helper.IsNil(result.Reducer) {
+		result.Reducer = helper.ConvertToPointer(TSAggregationSum)
+	}
+	return result
+}
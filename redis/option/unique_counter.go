@@ -0,0 +1,58 @@
+package option
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"time"
+)
+
+// UniqueCounter represents options that can be used to configure the unique counter (redis.NewUniqueCounter).
+type UniqueCounter struct {
+	// Bucket duration of each HyperLogLog key, the members added are counted in the bucket of the time they were
+	// added, ex: time.Hour counts the unique members per hour.
+	// Default is 1 hour.
+	Bucket *time.Duration
+	// TTL of the bucket keys, renewed on each addition, usually the bucket multiplied by the number of buckets
+	// counted. Zero means that the keys have no expiration time.
+	// Default is 0.
+	TTL *time.Duration
+}
+
+// NewUniqueCounter creates a new UniqueCounter instance.
+func NewUniqueCounter() *UniqueCounter {
+	return &UniqueCounter{}
+}
+
+// SetBucket sets value for the Bucket field.
+func (u *UniqueCounter) SetBucket(bucket time.Duration) *UniqueCounter {
+	u.Bucket = &bucket
+	return u
+}
+
+// SetTTL sets value for the TTL field.
+func (u *UniqueCounter) SetTTL(ttl time.Duration) *UniqueCounter {
+	u.TTL = &ttl
+	return u
+}
+
+// GetOptionUniqueCounterByParams assembles the UniqueCounter object from optional parameters.
+func GetOptionUniqueCounterByParams(opts []*UniqueCounter) *UniqueCounter {
+	result := &UniqueCounter{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotNil(opt.Bucket) {
+			result.Bucket = opt.Bucket
+		}
+		if helper.IsNotNil(opt.TTL) {
+			result.TTL = opt.TTL
+		}
+	}
+	if helper.IsNil(result.Bucket) || *result.Bucket <= 0 {
+		result.Bucket = helper.ConvertToPointer(time.Hour)
+	}
+	if helper.IsNil(result.TTL) || *result.TTL < 0 {
+		result.TTL = helper.ConvertToPointer(time.Duration(0))
+	}
+	return result
+}
@@ -0,0 +1,188 @@
+package option
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"time"
+)
+
+// VectorIndex represents options that can be used to configure the vector index (redis.NewVectorIndex).
+type VectorIndex struct {
+	// On type of the keys indexed, SearchOnHash stores the vector as a FLOAT32 blob in a hash field, and
+	// SearchOnJSON as an array in a RedisJSON document.
+	// Default is SearchOnHash.
+	On *SearchOn
+	// Prefix of the keys indexed, the keys passed to UpsertVector must start with it.
+	// Default is the name of the index followed by ":".
+	Prefix *string
+	// Field name of the vector in the hash or document.
+	// Default is "embedding".
+	Field *string
+	// Algorithm of the vector search.
+	// Default is VectorAlgorithmFlat.
+	Algorithm *VectorAlgorithm
+	// Distance metric between the vectors.
+	// Default is VectorDistanceCosine.
+	Distance *VectorDistance
+	// Tags fields of the metadata indexed as TAG, to be used in the filters of the KNN queries.
+	Tags []string
+	// Numerics fields of the metadata indexed as NUMERIC, to be used in the filters of the KNN queries.
+	Numerics []string
+}
+
+// SemanticCache represents options that can be used to configure the semantic cache (redis.NewSemanticCache).
+type SemanticCache struct {
+	// Threshold minimum cosine similarity, between 0 and 1, of the embedding of the entry cached with the embedding
+	// searched, for the answer cached to be returned.
+	// Default is 0.9.
+	Threshold *float64
+	// TTL of the entries cached, zero means that the entries have no expiration time.
+	// Default is 0.
+	TTL *time.Duration
+	// Algorithm of the vector search.
+	// Default is VectorAlgorithmFlat.
+	Algorithm *VectorAlgorithm
+}
+
+// NewVectorIndex creates a new VectorIndex instance.
+func NewVectorIndex() *VectorIndex {
+	return &VectorIndex{}
+}
+
+// SetOn sets value for the On field.
+func (v *VectorIndex) SetOn(on SearchOn) *VectorIndex {
+	v.On = &on
+	return v
+}
+
+// SetPrefix sets value for the Prefix field.
+func (v *VectorIndex) SetPrefix(prefix string) *VectorIndex {
+	v.Prefix = &prefix
+	return v
+}
+
+// SetField sets value for the Field field.
+func (v *VectorIndex) SetField(field string) *VectorIndex {
+	v.Field = &field
+	return v
+}
+
+// SetAlgorithm sets value for the Algorithm field.
+func (v *VectorIndex) SetAlgorithm(algorithm VectorAlgorithm) *VectorIndex {
+	v.Algorithm = &algorithm
+	return v
+}
+
+// SetDistance sets value for the Distance field.
+func (v *VectorIndex) SetDistance(distance VectorDistance) *VectorIndex {
+	v.Distance = &distance
+	return v
+}
+
+// SetTags sets value for the Tags field.
+func (v *VectorIndex) SetTags(tags ...string) *VectorIndex {
+	v.Tags = tags
+	return v
+}
+
+// SetNumerics sets value for the Numerics field.
+func (v *VectorIndex) SetNumerics(numerics ...string) *VectorIndex {
+	v.Numerics = numerics
+	return v
+}
+
+// NewSemanticCache creates a new SemanticCache instance.
+func NewSemanticCache() *SemanticCache {
+	return &SemanticCache{}
+}
+
+// SetThreshold sets value for the Threshold field.
+func (s *SemanticCache) SetThreshold(threshold float64) *SemanticCache {
+	s.Threshold = &threshold
+	return s
+}
+
+// SetTTL sets value for the TTL field.
+func (s *SemanticCache) SetTTL(ttl time.Duration) *SemanticCache {
+	s.TTL = &ttl
+	return s
+}
+
+// SetAlgorithm sets value for the Algorithm field.
+func (s *SemanticCache) SetAlgorithm(algorithm VectorAlgorithm) *SemanticCache {
+	s.Algorithm = &algorithm
+	return s
+}
+
+// GetOptionVectorIndexByParams assembles the VectorIndex object from optional parameters, the Prefix is kept nil
+// when not set, to be defaulted by redis.NewVectorIndex with the name of the index.
+func GetOptionVectorIndexByParams(opts []*VectorIndex) *VectorIndex {
+	result := &VectorIndex{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotNil(opt.On) {
+			result.On = opt.On
+		}
+		if helper.IsNotNil(opt.Prefix) {
+			result.Prefix = opt.Prefix
+		}
+		if helper.IsNotNil(opt.Field) {
+			result.Field = opt.Field
+		}
+		if helper.IsNotNil(opt.Algorithm) {
+			result.Algorithm = opt.Algorithm
+		}
+		if helper.IsNotNil(opt.Distance) {
+			result.Distance = opt.Distance
+		}
+		if helper.IsNotEmpty(opt.Tags) {
+			result.Tags = opt.Tags
+		}
+		if helper.IsNotEmpty(opt.Numerics) {
+			result.Numerics = opt.Numerics
+		}
+	}
+	if helper.IsNil(result.On) {
+		result.On = helper.ConvertToPointer(SearchOnHash)
+	}
+	if helper.IsNil(result.Field) || helper.IsEmpty(*result.Field) {
+		result.Field = helper.ConvertToPointer("embedding")
+	}
+	if helper.IsNil(result.Algorithm) {
+		result.Algorithm = helper.ConvertToPointer(VectorAlgorithmFlat)
+	}
+	if helper.IsNil(result.Distance) {
+		result.Distance = helper.ConvertToPointer(VectorDistanceCosine)
+	}
+	return result
+}
+
+// GetOptionSemanticCacheByParams assembles the SemanticCache object from optional parameters.
+func GetOptionSemanticCacheByParams(opts []*SemanticCache) *SemanticCache {
+	result := &SemanticCache{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotNil(opt.Threshold) {
+			result.Threshold = opt.Threshold
+		}
+		if helper.IsNotNil(opt.TTL) {
+			result.TTL = opt.TTL
+		}
+		if helper.IsNotNil(opt.Algorithm) {
+			result.Algorithm = opt.Algorithm
+		}
+	}
+	if helper.IsNil(result.Threshold) {
+		result.Threshold = helper.ConvertToPointer(0.9)
+	}
+	if helper.IsNil(result.TTL) || *result.TTL < 0 {
+		result.TTL = helper.ConvertToPointer(time.Duration(0))
+	}
+	if helper.IsNil(result.Algorithm) {
+		result.Algorithm = helper.ConvertToPointer(VectorAlgorithmFlat)
+	}
+	return result
+}
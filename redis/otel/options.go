@@ -0,0 +1,100 @@
+package otel
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	otelapi "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Options represents options that can be used to configure the template instrumentation (Instrument).
+type Options struct {
+	// TracerProvider used to create the tracer.
+	// Default is the global tracer provider (otel.GetTracerProvider).
+	TracerProvider trace.TracerProvider
+	// MeterProvider used to create the meter.
+	// Default is the global meter provider (otel.GetMeterProvider).
+	MeterProvider metric.MeterProvider
+	// Attributes added to every span and metric, ex: server.address or db.redis.database_index.
+	Attributes []attribute.KeyValue
+	// DisableStatement disables the db.statement span attribute.
+	// Default is false, the statement is recorded with the values replaced by "?".
+	DisableStatement *bool
+	// DisableMetrics disables the latency, error and pool metrics.
+	// Default is false.
+	DisableMetrics *bool
+}
+
+// NewOptions creates a new Options instance.
+func NewOptions() *Options {
+	return &Options{}
+}
+
+// SetTracerProvider sets value for the TracerProvider field.
+func (o *Options) SetTracerProvider(provider trace.TracerProvider) *Options {
+	o.TracerProvider = provider
+	return o
+}
+
+// SetMeterProvider sets value for the MeterProvider field.
+func (o *Options) SetMeterProvider(provider metric.MeterProvider) *Options {
+	o.MeterProvider = provider
+	return o
+}
+
+// SetAttributes sets value for the Attributes field.
+func (o *Options) SetAttributes(attributes ...attribute.KeyValue) *Options {
+	o.Attributes = attributes
+	return o
+}
+
+// SetDisableStatement sets value for the DisableStatement field.
+func (o *Options) SetDisableStatement(disable bool) *Options {
+	o.DisableStatement = &disable
+	return o
+}
+
+// SetDisableMetrics sets value for the DisableMetrics field.
+func (o *Options) SetDisableMetrics(disable bool) *Options {
+	o.DisableMetrics = &disable
+	return o
+}
+
+// GetOptionsByParams assembles the Options object from optional parameters.
+func GetOptionsByParams(opts []*Options) *Options {
+	result := &Options{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotNil(opt.TracerProvider) {
+			result.TracerProvider = opt.TracerProvider
+		}
+		if helper.IsNotNil(opt.MeterProvider) {
+			result.MeterProvider = opt.MeterProvider
+		}
+		if helper.IsNotEmpty(opt.Attributes) {
+			result.Attributes = opt.Attributes
+		}
+		if helper.IsNotNil(opt.DisableStatement) {
+			result.DisableStatement = opt.DisableStatement
+		}
+		if helper.IsNotNil(opt.DisableMetrics) {
+			result.DisableMetrics = opt.DisableMetrics
+		}
+	}
+	if helper.IsNil(result.TracerProvider) {
+		result.TracerProvider = otelapi.GetTracerProvider()
+	}
+	if helper.IsNil(result.MeterProvider) {
+		result.MeterProvider = otelapi.GetMeterProvider()
+	}
+	if helper.IsNil(result.DisableStatement) {
+		result.DisableStatement = helper.ConvertToPointer(false)
+	}
+	if helper.IsNil(result.DisableMetrics) {
+		result.DisableMetrics = helper.ConvertToPointer(false)
+	}
+	return result
+}
@@ -0,0 +1,181 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"strings"
+)
+
+const instrumentationName = "github.com/GabrielHCataldo/go-redis-template/redis/otel"
+
+type hook struct {
+	opt        *Options
+	tracer     trace.Tracer
+	duration   metric.Float64Histogram
+	errors     metric.Int64Counter
+	attributes []attribute.KeyValue
+}
+
+// Instrument registers a hook on the template that emits a span per template operation, with the db.system,
+// db.operation and db.statement attributes, and records the operation latency, error and connection pool metrics.
+//
+// To customize the instrumentation, use the opts parameter (Options).
+func Instrument(t *redis.Template, opts ...*Options) error {
+	opt := GetOptionsByParams(opts)
+	h := &hook{
+		opt:        opt,
+		tracer:     opt.TracerProvider.Tracer(instrumentationName),
+		attributes: append([]attribute.KeyValue{semconv.DBSystemRedis}, opt.Attributes...),
+	}
+	if !*opt.DisableMetrics {
+		err := h.registerMetrics(t, opt.MeterProvider.Meter(instrumentationName))
+		if helper.IsNotNil(err) {
+			return err
+		}
+	}
+	t.AddHook(h)
+	return nil
+}
+
+func (h *hook) Before(ctx context.Context, op redis.Operation) (context.Context, error) {
+	if helper.IsNotEqualTo(op.Type, redis.OperationTypeTemplate) {
+		return ctx, nil
+	}
+	attributes := append([]attribute.KeyValue{semconv.DBOperation(op.Name)}, h.attributes...)
+	if !*h.opt.DisableStatement {
+		attributes = append(attributes, semconv.DBStatement(statement(op)))
+	}
+	ctx, _ = h.tracer.Start(ctx, op.Name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attributes...))
+	return ctx, nil
+}
+
+func (h *hook) After(ctx context.Context, op redis.Operation) {
+	if helper.IsNotEqualTo(op.Type, redis.OperationTypeTemplate) {
+		return
+	}
+	span := trace.SpanFromContext(ctx)
+	failed := helper.IsNotNil(op.Err) && !errors.Is(op.Err, redis.ErrKeyNotFound)
+	if failed {
+		span.RecordError(op.Err)
+		span.SetStatus(codes.Error, op.Err.Error())
+	}
+	span.End()
+	if *h.opt.DisableMetrics {
+		return
+	}
+	attributes := append([]attribute.KeyValue{semconv.DBOperation(op.Name)}, h.attributes...)
+	h.duration.Record(ctx, op.Duration.Seconds(), metric.WithAttributes(attributes...))
+	if failed {
+		attributes = append(attributes, semconv.ErrorTypeKey.String(errorType(op.Err)))
+		h.errors.Add(ctx, 1, metric.WithAttributes(attributes...))
+	}
+}
+
+func (h *hook) registerMetrics(t *redis.Template, meter metric.Meter) error {
+	var err error
+	h.duration, err = meter.Float64Histogram(
+		"db.client.operation.duration",
+		metric.WithDescription("Duration of the redis template operations."),
+		metric.WithUnit("s"),
+	)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	h.errors, err = meter.Int64Counter(
+		"db.client.operation.errors",
+		metric.WithDescription("Number of redis template operations that failed."),
+	)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	hits, err := meter.Int64ObservableCounter(
+		"db.client.connections.hits",
+		metric.WithDescription("Number of times a free connection was found in the pool."),
+	)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	misses, err := meter.Int64ObservableCounter(
+		"db.client.connections.misses",
+		metric.WithDescription("Number of times a free connection was not found in the pool."),
+	)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	timeouts, err := meter.Int64ObservableCounter(
+		"db.client.connections.timeouts",
+		metric.WithDescription("Number of times a wait for a connection in the pool timed out."),
+	)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	idle, err := meter.Int64ObservableGauge(
+		"db.client.connections.idle",
+		metric.WithDescription("Number of idle connections in the pool."),
+	)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	total, err := meter.Int64ObservableGauge(
+		"db.client.connections.total",
+		metric.WithDescription("Number of total connections in the pool."),
+	)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	_, err = meter.RegisterCallback(func(ctx context.Context, observer metric.Observer) error {
+		stats := t.PoolStats()
+		attributes := metric.WithAttributes(h.attributes...)
+		observer.ObserveInt64(hits, int64(stats.Hits), attributes)
+		observer.ObserveInt64(misses, int64(stats.Misses), attributes)
+		observer.ObserveInt64(timeouts, int64(stats.Timeouts), attributes)
+		observer.ObserveInt64(idle, int64(stats.IdleConns), attributes)
+		observer.ObserveInt64(total, int64(stats.TotalConns), attributes)
+		return nil
+	}, hits, misses, timeouts, idle, total)
+	return err
+}
+
+func statement(op redis.Operation) string {
+	var builder strings.Builder
+	builder.WriteString(strings.ToUpper(op.Name))
+	for _, key := range op.Keys {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			sKey = "?"
+		}
+		builder.WriteString(" ")
+		builder.WriteString(sKey)
+	}
+	if helper.IsNotNil(op.Value) {
+		builder.WriteString(" ?")
+	}
+	return builder.String()
+}
+
+// errorTypes maps the classes of the errors (redis.ErrorKind) to the error.type attribute.
+var errorTypes = map[error]string{
+	redis.ErrTimeout:           "timeout",
+	redis.ErrPoolExhausted:     "pool_exhausted",
+	redis.ErrConnectionRefused: "connection_refused",
+	redis.ErrReadOnly:          "readonly",
+	redis.ErrMoved:             "moved",
+	redis.ErrAsk:               "ask",
+	redis.ErrWrongType:         "wrong_type",
+	redis.ErrNoScript:          "no_script",
+	redis.ErrOOM:               "oom",
+}
+
+func errorType(err error) string {
+	if errorType, ok := errorTypes[redis.ErrorKind(err)]; ok {
+		return errorType
+	}
+	return semconv.ErrorTypeOther.Value.AsString()
+}
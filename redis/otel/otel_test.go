@@ -0,0 +1,117 @@
+package otel
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"os"
+	"testing"
+	"time"
+)
+
+func initTemplate() *redis.Template {
+	return redis.NewTemplate(option.Client{
+		Addr:     os.Getenv("REDIS_URL"),
+		Password: os.Getenv("REDIS_PASSWORD"),
+		DB:       0,
+	})
+}
+
+func TestInstrument(t *testing.T) {
+	redisTemplate := initTemplate()
+	defer redisTemplate.SimpleDisconnect()
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	err := Instrument(redisTemplate, NewOptions().
+		SetTracerProvider(tracerProvider).
+		SetMeterProvider(meterProvider).
+		SetAttributes(semconv.DBRedisDBIndex(0)))
+	if helper.IsNotNil(err) {
+		logger.Error("Instrument() err =", err)
+		t.Fail()
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	_ = redisTemplate.Set(ctx, "test-otel", "value", option.NewSet().SetTTL(time.Minute))
+	spans := exporter.GetSpans()
+	if helper.IsNotEqualTo(len(spans), 1) || helper.IsNotEqualTo(spans[0].Name, "Set") {
+		logger.Errorf("Instrument() spans = %v, want 1 span named Set", spans)
+		t.Fail()
+		return
+	}
+	var statement string
+	for _, attr := range spans[0].Attributes {
+		if helper.Equals(attr.Key, semconv.DBStatementKey) {
+			statement = attr.Value.AsString()
+		}
+	}
+	if helper.IsNotEqualTo(statement, "SET test-otel ?") {
+		logger.Errorf("Instrument() db.statement = %v, want = %v", statement, "SET test-otel ?")
+		t.Fail()
+		return
+	}
+	var data metricdata.ResourceMetrics
+	err = reader.Collect(ctx, &data)
+	if helper.IsNotNil(err) {
+		logger.Error("Collect() err =", err)
+		t.Fail()
+		return
+	}
+	names := map[string]bool{}
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			names[m.Name] = true
+		}
+	}
+	for _, name := range []string{"db.client.operation.duration", "db.client.connections.idle", "db.client.connections.total"} {
+		if !names[name] {
+			logger.Errorf("Instrument() metric %s not recorded, metrics = %v", name, names)
+			t.Fail()
+			return
+		}
+	}
+	logger.Infof("Instrument() spans = %v metrics = %v", len(spans), names)
+}
+
+func TestInstrumentDisableMetrics(t *testing.T) {
+	redisTemplate := initTemplate()
+	defer redisTemplate.SimpleDisconnect()
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	err := Instrument(redisTemplate, NewOptions().
+		SetTracerProvider(tracerProvider).
+		SetDisableMetrics(true).
+		SetDisableStatement(true))
+	if helper.IsNotNil(err) {
+		logger.Error("Instrument() err =", err)
+		t.Fail()
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	_ = redisTemplate.Get(ctx, "test-otel", new(string))
+	spans := exporter.GetSpans()
+	if helper.IsNotEqualTo(len(spans), 1) {
+		logger.Errorf("Instrument() spans = %v, want 1 span", spans)
+		t.Fail()
+		return
+	}
+	for _, attr := range spans[0].Attributes {
+		if helper.Equals(attr.Key, semconv.DBStatementKey) {
+			logger.Error("Instrument() db.statement recorded with statement disabled")
+			t.Fail()
+			return
+		}
+	}
+	logger.Infof("Instrument() spans = %v", spans[0].Name)
+}
@@ -0,0 +1,393 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"github.com/redis/go-redis/v9"
+	"strings"
+)
+
+type CMSIncrByInput struct {
+	// Item can be of any type, but cannot be null, it is converted like the members of PFAdd.
+	Item any
+	// Increment added to the count of the item, cannot be negative.
+	Increment int64
+}
+
+// TopKItem is an item of the list of a Top-K, returned by Template.TopKList.
+type TopKItem struct {
+	// Item as converted when added, ex: the JSON of a struct.
+	Item string
+	// Count estimated number of times the item was added.
+	Count int64
+}
+
+// BFReserve redis `BF.RESERVE key error_rate capacity [EXPANSION expansion] [NONSCALING]` command, creates the Bloom
+// filter of the key, with the desired false positive rate, between 0 and 1, for the expected number of items.
+//
+// The key parameter can be of any type, but cannot be null, in case an error occurs when converting the key, the
+// error returned is ErrConvertKey. If the key already exists, the error returned is ErrKeyAlreadyExists.
+//
+// To customize the filter, use the opts parameter (option.BFReserve).
+func (t *Template) BFReserve(ctx context.Context, key any, errorRate float64, capacity int64,
+	opts ...*option.BFReserve) error {
+	op := &Operation{Name: "BFReserve", Keys: []any{key}}
+	return t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		opt := option.GetOptionBFReserveByParams(opts)
+		return probabilisticError(t.client.BFReserveWithArgs(ctx, sKey, &redis.BFReserveOptions{
+			Capacity:   capacity,
+			Error:      errorRate,
+			Expansion:  *opt.Expansion,
+			NonScaling: *opt.NonScaling,
+		}).Err())
+	})
+}
+
+// BFAdd redis `BF.ADD key item` command, adds the item to the Bloom filter of the key, creating it with the default
+// parameters of the module if it does not exist, and returns false if the item may already exist.
+//
+// The key parameter follows the BFReserve documentation. The item parameter can be of any type, but cannot be null,
+// in case an error occurs when converting, the error returned is ErrConvertValue. The items are converted like the
+// members of PFAdd, so the same item always has the same encoding.
+func (t *Template) BFAdd(ctx context.Context, key, item any) (bool, error) {
+	var added bool
+	op := &Operation{Name: "BFAdd", Keys: []any{key}, Value: item, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, sItems, err := convertKeyAndMembers(op, key, []any{item})
+		if helper.IsNotNil(err) {
+			return err
+		}
+		added, err = t.client.BFAdd(ctx, sKey, sItems[0]).Result()
+		return probabilisticError(err)
+	})
+	return added, err
+}
+
+// BFMAdd redis `BF.MADD key item [item ...]` command, adds the items to the Bloom filter of the key, returning for
+// each item, in the same order, false if the item may already exist.
+//
+// The key and items parameters follow the BFAdd documentation.
+func (t *Template) BFMAdd(ctx context.Context, key any, items ...any) ([]bool, error) {
+	var added []bool
+	op := &Operation{Name: "BFMAdd", Keys: []any{key}, Value: items, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, sItems, err := convertKeyAndMembers(op, key, items)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		added, err = t.client.BFMAdd(ctx, sKey, sItems...).Result()
+		return probabilisticError(err)
+	})
+	return added, err
+}
+
+// BFExists redis `BF.EXISTS key item` command, returns false if the item certainly was not added to the Bloom filter
+// of the key, and true if it may have been added. If the key does not exist, the return is false.
+//
+// The key and item parameters follow the BFAdd documentation.
+func (t *Template) BFExists(ctx context.Context, key, item any) (bool, error) {
+	var exists bool
+	op := &Operation{Name: "BFExists", Keys: []any{key}, Value: item, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, sItems, err := convertKeyAndMembers(op, key, []any{item})
+		if helper.IsNotNil(err) {
+			return err
+		}
+		exists, err = t.client.BFExists(ctx, sKey, sItems[0]).Result()
+		return probabilisticError(err)
+	})
+	return exists, err
+}
+
+// BFMExists redis `BF.MEXISTS key item [item ...]` command, returns for each item, in the same order, the result of
+// BFExists.
+//
+// The key and items parameters follow the BFAdd documentation.
+func (t *Template) BFMExists(ctx context.Context, key any, items ...any) ([]bool, error) {
+	var exists []bool
+	op := &Operation{Name: "BFMExists", Keys: []any{key}, Value: items, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, sItems, err := convertKeyAndMembers(op, key, items)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		exists, err = t.client.BFMExists(ctx, sKey, sItems...).Result()
+		return probabilisticError(err)
+	})
+	return exists, err
+}
+
+// CFReserve redis `CF.RESERVE key capacity` command, creates the cuckoo filter of the key for the expected number
+// of items. Unlike the Bloom filters, the items of a cuckoo filter can be deleted.
+//
+// The key parameter follows the BFReserve documentation.
+func (t *Template) CFReserve(ctx context.Context, key any, capacity int64) error {
+	op := &Operation{Name: "CFReserve", Keys: []any{key}}
+	return t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		return probabilisticError(t.client.CFReserve(ctx, sKey, capacity).Err())
+	})
+}
+
+// CFAdd redis `CF.ADD key item` command, adds the item to the cuckoo filter of the key, creating it with the default
+// parameters of the module if it does not exist. The same item can be added more than once, and must be deleted as
+// many times, use CFAddNX to add the item only once.
+//
+// The key and item parameters follow the BFAdd documentation.
+func (t *Template) CFAdd(ctx context.Context, key, item any) error {
+	op := &Operation{Name: "CFAdd", Keys: []any{key}, Value: item}
+	return t.process(ctx, op, func(ctx context.Context) error {
+		sKey, sItems, err := convertKeyAndMembers(op, key, []any{item})
+		if helper.IsNotNil(err) {
+			return err
+		}
+		return probabilisticError(t.client.CFAdd(ctx, sKey, sItems[0]).Err())
+	})
+}
+
+// CFAddNX redis `CF.ADDNX key item` command, adds the item to the cuckoo filter of the key if it may not exist,
+// returning false if the item may already exist.
+//
+// The key and item parameters follow the BFAdd documentation.
+func (t *Template) CFAddNX(ctx context.Context, key, item any) (bool, error) {
+	var added bool
+	op := &Operation{Name: "CFAddNX", Keys: []any{key}, Value: item, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, sItems, err := convertKeyAndMembers(op, key, []any{item})
+		if helper.IsNotNil(err) {
+			return err
+		}
+		added, err = t.client.CFAddNX(ctx, sKey, sItems[0]).Result()
+		return probabilisticError(err)
+	})
+	return added, err
+}
+
+// CFExists redis `CF.EXISTS key item` command, returns false if the item certainly is not in the cuckoo filter of
+// the key, and true if it may be. If the key does not exist, the return is false.
+//
+// The key and item parameters follow the BFAdd documentation.
+func (t *Template) CFExists(ctx context.Context, key, item any) (bool, error) {
+	var exists bool
+	op := &Operation{Name: "CFExists", Keys: []any{key}, Value: item, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, sItems, err := convertKeyAndMembers(op, key, []any{item})
+		if helper.IsNotNil(err) {
+			return err
+		}
+		exists, err = t.client.CFExists(ctx, sKey, sItems[0]).Result()
+		return probabilisticError(err)
+	})
+	return exists, err
+}
+
+// CFDel redis `CF.DEL key item` command, deletes one occurrence of the item from the cuckoo filter of the key,
+// returning false if the item was not found. Deleting an item that was never added may delete another item with the
+// same fingerprint.
+//
+// The key and item parameters follow the BFAdd documentation. If the key does not exist, the error returned is
+// ErrKeyNotFound.
+func (t *Template) CFDel(ctx context.Context, key, item any) (bool, error) {
+	var deleted bool
+	op := &Operation{Name: "CFDel", Keys: []any{key}, Value: item}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, sItems, err := convertKeyAndMembers(op, key, []any{item})
+		if helper.IsNotNil(err) {
+			return err
+		}
+		deleted, err = t.client.CFDel(ctx, sKey, sItems[0]).Result()
+		return probabilisticError(err)
+	})
+	return deleted, err
+}
+
+// CFCount redis `CF.COUNT key item` command, returns the estimated number of times the item is in the cuckoo filter
+// of the key, it can be greater than the real number when another item has the same fingerprint.
+//
+// The key and item parameters follow the BFAdd documentation.
+func (t *Template) CFCount(ctx context.Context, key, item any) (int64, error) {
+	var count int64
+	op := &Operation{Name: "CFCount", Keys: []any{key}, Value: item, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, sItems, err := convertKeyAndMembers(op, key, []any{item})
+		if helper.IsNotNil(err) {
+			return err
+		}
+		count, err = t.client.CFCount(ctx, sKey, sItems[0]).Result()
+		return probabilisticError(err)
+	})
+	return count, err
+}
+
+// CMSInitByDim redis `CMS.INITBYDIM key width depth` command, creates the Count-Min Sketch of the key with the
+// number of counters of each array (width) and the number of arrays (depth).
+//
+// The key parameter follows the BFReserve documentation.
+func (t *Template) CMSInitByDim(ctx context.Context, key any, width, depth int64) error {
+	op := &Operation{Name: "CMSInitByDim", Keys: []any{key}}
+	return t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		return probabilisticError(t.client.CMSInitByDim(ctx, sKey, width, depth).Err())
+	})
+}
+
+// CMSInitByProb redis `CMS.INITBYPROB key error probability` command, creates the Count-Min Sketch of the key with
+// the dimensions for the overestimation rate of the counts, ex: 0.001 of the total count, with the probability of
+// exceeding it, ex: 0.01.
+//
+// The key parameter follows the BFReserve documentation.
+func (t *Template) CMSInitByProb(ctx context.Context, key any, errorRate, probability float64) error {
+	op := &Operation{Name: "CMSInitByProb", Keys: []any{key}}
+	return t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		return probabilisticError(t.client.CMSInitByProb(ctx, sKey, errorRate, probability).Err())
+	})
+}
+
+// CMSIncrBy redis `CMS.INCRBY key item increment [item increment ...]` command, increments the counts of the items
+// in the Count-Min Sketch of the key, returning the new estimated count of each item, in the same order.
+//
+// The key parameter follows the BFReserve documentation, and the items follow the BFAdd documentation. If the key
+// does not exist, the error returned is ErrKeyNotFound.
+func (t *Template) CMSIncrBy(ctx context.Context, key any, increments ...CMSIncrByInput) ([]int64, error) {
+	var counts []int64
+	items := make([]any, len(increments))
+	for i, increment := range increments {
+		items[i] = increment.Item
+	}
+	op := &Operation{Name: "CMSIncrBy", Keys: []any{key}, Value: increments}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, sItems, err := convertKeyAndMembers(op, key, items)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		var args []any
+		for i, sItem := range sItems {
+			args = append(args, sItem, increments[i].Increment)
+		}
+		counts, err = t.client.CMSIncrBy(ctx, sKey, args...).Result()
+		return probabilisticError(err)
+	})
+	return counts, err
+}
+
+// CMSQuery redis `CMS.QUERY key item [item ...]` command, returns the estimated count of each item in the Count-Min
+// Sketch of the key, in the same order, the estimates are never lower than the real counts.
+//
+// The key and items parameters follow the CMSIncrBy documentation.
+func (t *Template) CMSQuery(ctx context.Context, key any, items ...any) ([]int64, error) {
+	var counts []int64
+	op := &Operation{Name: "CMSQuery", Keys: []any{key}, Value: items, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, sItems, err := convertKeyAndMembers(op, key, items)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		counts, err = t.client.CMSQuery(ctx, sKey, sItems...).Result()
+		return probabilisticError(err)
+	})
+	return counts, err
+}
+
+// TopKReserve redis `TOPK.RESERVE key topk [width depth decay]` command, creates the Top-K of the key, which keeps
+// the k items most added.
+//
+// The key parameter follows the BFReserve documentation.
+//
+// To customize the sketch, use the opts parameter (option.TopKReserve).
+func (t *Template) TopKReserve(ctx context.Context, key any, k int64, opts ...*option.TopKReserve) error {
+	op := &Operation{Name: "TopKReserve", Keys: []any{key}}
+	return t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		opt := option.GetOptionTopKReserveByParams(opts)
+		return probabilisticError(t.client.TopKReserveWithOptions(ctx, sKey, k, *opt.Width, *opt.Depth,
+			*opt.Decay).Err())
+	})
+}
+
+// TopKAdd redis `TOPK.ADD key item [item ...]` command, adds the items to the Top-K of the key, returning the items
+// expelled from the list by the items added, as converted when added.
+//
+// The key and items parameters follow the CMSIncrBy documentation.
+func (t *Template) TopKAdd(ctx context.Context, key any, items ...any) ([]string, error) {
+	var expelled []string
+	op := &Operation{Name: "TopKAdd", Keys: []any{key}, Value: items}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, sItems, err := convertKeyAndMembers(op, key, items)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		result, err := t.client.TopKAdd(ctx, sKey, sItems...).Result()
+		if helper.IsNotNil(err) {
+			return probabilisticError(err)
+		}
+		expelled = nil
+		for _, item := range result {
+			if helper.IsNotEmpty(item) {
+				expelled = append(expelled, item)
+			}
+		}
+		return nil
+	})
+	return expelled, err
+}
+
+// TopKList redis `TOPK.LIST key WITHCOUNT` command, returns the items of the Top-K of the key with their estimated
+// counts, ordered by the count descending.
+//
+// The key parameter follows the CMSIncrBy documentation.
+func (t *Template) TopKList(ctx context.Context, key any) ([]TopKItem, error) {
+	var items []TopKItem
+	op := &Operation{Name: "TopKList", Keys: []any{key}, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		result, err := t.client.Do(ctx, "TOPK.LIST", sKey, "WITHCOUNT").Slice()
+		if helper.IsNotNil(err) {
+			return probabilisticError(err)
+		}
+		items = nil
+		for i := 0; i+1 < len(result); i += 2 {
+			count, _ := result[i+1].(int64)
+			items = append(items, TopKItem{Item: replyString(result[i]), Count: count})
+		}
+		return nil
+	})
+	return items, err
+}
+
+// probabilisticError converts the RedisBloom errors of the missing and existing keys to ErrKeyNotFound and
+// ErrKeyAlreadyExists.
+func probabilisticError(err error) error {
+	var redisErr redis.Error
+	if helper.IsNil(err) || !errors.As(err, &redisErr) {
+		return err
+	}
+	msg := strings.ToLower(err.Error())
+	if strings.HasSuffix(msg, "item exists") || strings.Contains(msg, "key already exists") {
+		return ErrKeyAlreadyExists
+	} else if strings.HasSuffix(msg, "not found") || strings.Contains(msg, "key does not exist") {
+		return ErrKeyNotFound
+	}
+	return err
+}
@@ -0,0 +1,253 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"github.com/GabrielHCataldo/go-redis-template/redis/redistest"
+	redisdriver "github.com/redis/go-redis/v9"
+	"reflect"
+	"testing"
+)
+
+type probabilisticItem struct {
+	ID   int    `json:"id"`
+	Kind string `json:"kind"`
+}
+
+func TestTemplateBloomFilterCommands(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	err := redisTemplate.BFReserve(ctx, "emails", 0.01, 2, option.NewBFReserve().SetNonScaling(true))
+	if helper.IsNotNil(err) {
+		logger.Errorf("BFReserve() err = %v", err)
+		t.Fail()
+	}
+	err = redisTemplate.BFReserve(ctx, "emails", 0.01, 2)
+	if !errors.Is(err, redis.ErrKeyAlreadyExists) {
+		logger.Errorf("BFReserve() err = %v, want = %v", err, redis.ErrKeyAlreadyExists)
+		t.Fail()
+	}
+	added, err := redisTemplate.BFAdd(ctx, "emails", probabilisticItem{ID: 1, Kind: "welcome"})
+	if helper.IsNotNil(err) || !added {
+		logger.Errorf("BFAdd() result = %v err = %v", added, err)
+		t.Fail()
+	}
+	exists, err := redisTemplate.BFExists(ctx, "emails", &probabilisticItem{ID: 1, Kind: "welcome"})
+	if helper.IsNotNil(err) || !exists {
+		logger.Errorf("BFExists() result = %v err = %v", exists, err)
+		t.Fail()
+	}
+	addedItems, err := redisTemplate.BFMAdd(ctx, "users", 1, 2, 1)
+	if helper.IsNotNil(err) || !reflect.DeepEqual(addedItems, []bool{true, true, false}) {
+		logger.Errorf("BFMAdd() result = %v err = %v", addedItems, err)
+		t.Fail()
+	}
+	existsItems, err := redisTemplate.BFMExists(ctx, "users", "2", 3)
+	if helper.IsNotNil(err) || !reflect.DeepEqual(existsItems, []bool{true, false}) {
+		logger.Errorf("BFMExists() result = %v err = %v", existsItems, err)
+		t.Fail()
+	}
+	exists, err = redisTemplate.BFExists(ctx, "unknown", 1)
+	if helper.IsNotNil(err) || exists {
+		logger.Errorf("BFExists() unknown result = %v err = %v", exists, err)
+		t.Fail()
+	}
+	_, err = redisTemplate.BFAdd(ctx, "emails", nil)
+	if !errors.Is(err, redis.ErrConvertValue) {
+		logger.Errorf("BFAdd() err = %v, want = %v", err, redis.ErrConvertValue)
+		t.Fail()
+	}
+}
+
+func TestTemplateCuckooFilterCommands(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	if err := redisTemplate.CFReserve(ctx, "sessions", 1000); helper.IsNotNil(err) {
+		logger.Errorf("CFReserve() err = %v", err)
+		t.Fail()
+	}
+	if err := redisTemplate.CFReserve(ctx, "sessions", 1000); !errors.Is(err, redis.ErrKeyAlreadyExists) {
+		logger.Errorf("CFReserve() err = %v, want = %v", err, redis.ErrKeyAlreadyExists)
+		t.Fail()
+	}
+	_ = redisTemplate.CFAdd(ctx, "sessions", "a")
+	_ = redisTemplate.CFAdd(ctx, "sessions", "a")
+	added, err := redisTemplate.CFAddNX(ctx, "sessions", "a")
+	count, _ := redisTemplate.CFCount(ctx, "sessions", "a")
+	if helper.IsNotNil(err) || added || helper.IsNotEqualTo(count, int64(2)) {
+		logger.Errorf("CFAddNX() result = %v count = %v err = %v", added, count, err)
+		t.Fail()
+	}
+	deleted, err := redisTemplate.CFDel(ctx, "sessions", "a")
+	exists, _ := redisTemplate.CFExists(ctx, "sessions", "a")
+	if helper.IsNotNil(err) || !deleted || !exists {
+		logger.Errorf("CFDel() result = %v exists = %v err = %v", deleted, exists, err)
+		t.Fail()
+	}
+	_, _ = redisTemplate.CFDel(ctx, "sessions", "a")
+	deleted, err = redisTemplate.CFDel(ctx, "sessions", "a")
+	exists, _ = redisTemplate.CFExists(ctx, "sessions", "a")
+	if helper.IsNotNil(err) || deleted || exists {
+		logger.Errorf("CFDel() deleted result = %v exists = %v err = %v", deleted, exists, err)
+		t.Fail()
+	}
+	_, err = redisTemplate.CFDel(ctx, "unknown", "a")
+	if !errors.Is(err, redis.ErrKeyNotFound) {
+		logger.Errorf("CFDel() err = %v, want = %v", err, redis.ErrKeyNotFound)
+		t.Fail()
+	}
+}
+
+func TestTemplateCountMinSketchCommands(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	if err := redisTemplate.CMSInitByDim(ctx, "pages", 2000, 5); helper.IsNotNil(err) {
+		logger.Errorf("CMSInitByDim() err = %v", err)
+		t.Fail()
+	}
+	if err := redisTemplate.CMSInitByProb(ctx, "pages", 0.001, 0.01); !errors.Is(err, redis.ErrKeyAlreadyExists) {
+		logger.Errorf("CMSInitByProb() err = %v, want = %v", err, redis.ErrKeyAlreadyExists)
+		t.Fail()
+	}
+	counts, err := redisTemplate.CMSIncrBy(ctx, "pages", redis.CMSIncrByInput{Item: "/home", Increment: 3},
+		redis.CMSIncrByInput{Item: probabilisticItem{ID: 1}, Increment: 1},
+		redis.CMSIncrByInput{Item: "/home", Increment: 2})
+	if helper.IsNotNil(err) || !reflect.DeepEqual(counts, []int64{3, 1, 5}) {
+		logger.Errorf("CMSIncrBy() result = %v err = %v", counts, err)
+		t.Fail()
+	}
+	counts, err = redisTemplate.CMSQuery(ctx, "pages", "/home", "/about", probabilisticItem{ID: 1})
+	if helper.IsNotNil(err) || !reflect.DeepEqual(counts, []int64{5, 0, 1}) {
+		logger.Errorf("CMSQuery() result = %v err = %v", counts, err)
+		t.Fail()
+	}
+	_, err = redisTemplate.CMSQuery(ctx, "unknown", "/home")
+	if !errors.Is(err, redis.ErrKeyNotFound) {
+		logger.Errorf("CMSQuery() err = %v, want = %v", err, redis.ErrKeyNotFound)
+		t.Fail()
+	}
+}
+
+func TestTemplateTopKCommands(t *testing.T) {
+	for _, protocol := range []int{2, 3} {
+		server := redistest.NewServer(t)
+		opts := server.ClientOptions()
+		opts.Protocol = protocol
+		redisTemplate := redis.NewTemplate(opts)
+		ctx := context.TODO()
+		err := redisTemplate.TopKReserve(ctx, "searches", 2, option.NewTopKReserve().SetWidth(50).SetDepth(4))
+		if helper.IsNotNil(err) {
+			logger.Errorf("TopKReserve() protocol = %v err = %v", protocol, err)
+			t.Fail()
+		}
+		expelled, err := redisTemplate.TopKAdd(ctx, "searches", "go", "redis", "go")
+		if helper.IsNotNil(err) || helper.IsNotEmpty(expelled) {
+			logger.Errorf("TopKAdd() protocol = %v result = %v err = %v", protocol, expelled, err)
+			t.Fail()
+		}
+		expelled, err = redisTemplate.TopKAdd(ctx, "searches", "lua", "lua", "lua")
+		if helper.IsNotNil(err) || !reflect.DeepEqual(expelled, []string{"redis"}) {
+			logger.Errorf("TopKAdd() protocol = %v expelled result = %v err = %v", protocol, expelled, err)
+			t.Fail()
+		}
+		items, err := redisTemplate.TopKList(ctx, "searches")
+		if helper.IsNotNil(err) || !reflect.DeepEqual(items, []redis.TopKItem{{"lua", 3}, {"go", 2}}) {
+			logger.Errorf("TopKList() protocol = %v result = %v err = %v", protocol, items, err)
+			t.Fail()
+		}
+		_, err = redisTemplate.TopKAdd(ctx, "unknown", "go")
+		if !errors.Is(err, redis.ErrKeyNotFound) {
+			logger.Errorf("TopKAdd() protocol = %v err = %v, want = %v", protocol, err, redis.ErrKeyNotFound)
+			t.Fail()
+		}
+		redisTemplate.SimpleDisconnect()
+	}
+}
+
+func TestBloomFilter(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	client := redisdriver.NewClient(server.ClientOptions().ParseToRedisOptions())
+	defer client.Close()
+	ctx := context.TODO()
+	filter := redis.NewBloomFilter(redisTemplate, "filter:bitmap", option.NewBloomFilter().SetCapacity(1000).
+		SetErrorRate(0.01).SetMode(option.BloomFilterModeBitmap))
+	if helper.IsNotEqualTo(filter.Bits(), uint64(9586)) || helper.IsNotEqualTo(filter.Hashes(), 7) {
+		logger.Errorf("NewBloomFilter() bits = %v hashes = %v", filter.Bits(), filter.Hashes())
+		t.Fail()
+	}
+	var items []any
+	for i := 0; i < 1000; i++ {
+		items = append(items, fmt.Sprint("item:", i))
+	}
+	added, err := filter.Add(ctx, items...)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(len(added), 1000) || !added[0] {
+		logger.Errorf("Add() result = %v err = %v", added, err)
+		t.Fail()
+	}
+	exists, err := filter.Exists(ctx, items...)
+	for i := range exists {
+		if !exists[i] {
+			logger.Errorf("Exists() item = %v result = false", items[i])
+			t.Fail()
+		}
+	}
+	var unknown []any
+	for i := 0; i < 1000; i++ {
+		unknown = append(unknown, probabilisticItem{ID: i, Kind: "unknown"})
+	}
+	exists, err = filter.Exists(ctx, unknown...)
+	falsePositives := 0
+	for _, e := range exists {
+		if e {
+			falsePositives++
+		}
+	}
+	if helper.IsNotNil(err) || falsePositives > 30 {
+		logger.Errorf("Exists() false positives = %v err = %v", falsePositives, err)
+		t.Fail()
+	}
+	added, _ = filter.Add(ctx, "item:0")
+	keyType := client.Type(ctx, "filter:bitmap").Val()
+	if added[0] || helper.IsNotEqualTo(keyType, "string") {
+		logger.Errorf("Add() repeated result = %v type = %v", added, keyType)
+		t.Fail()
+	}
+	moduleFilter := redis.NewBloomFilter(redisTemplate, "filter:module")
+	added, err = moduleFilter.Add(ctx, 1, 2, 1)
+	keyType = client.Type(ctx, "filter:module").Val()
+	if helper.IsNotNil(err) || !reflect.DeepEqual(added, []bool{true, true, false}) ||
+		helper.IsNotEqualTo(keyType, "MBbloom--") {
+		logger.Errorf("Add() module result = %v type = %v err = %v", added, keyType, err)
+		t.Fail()
+	}
+	server.SetError("bf.insert", "ERR unknown command 'BF.INSERT', with args beginning with: ")
+	server.SetError("bf.mexists", "ERR unknown command 'BF.MEXISTS', with args beginning with: ")
+	autoFilter := redis.NewBloomFilter(redisTemplate, "filter:auto")
+	added, err = autoFilter.Add(ctx, 1, 2)
+	exists, _ = autoFilter.Exists(ctx, 1, 3)
+	keyType = client.Type(ctx, "filter:auto").Val()
+	if helper.IsNotNil(err) || !reflect.DeepEqual(added, []bool{true, true}) ||
+		!reflect.DeepEqual(exists, []bool{true, false}) || helper.IsNotEqualTo(keyType, "string") {
+		logger.Errorf("Add() auto result = %v exists = %v type = %v err = %v", added, exists, keyType, err)
+		t.Fail()
+	}
+	strictFilter := redis.NewBloomFilter(redisTemplate, "filter:strict", option.NewBloomFilter().
+		SetMode(option.BloomFilterModeModule))
+	if _, err = strictFilter.Add(ctx, 1); helper.IsNil(err) {
+		logger.Errorf("Add() module err = %v, want unknown command", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,210 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// ErrorClassNotFound the key was not found (redis.ErrKeyNotFound).
+	ErrorClassNotFound = "not_found"
+	// ErrorClassTimeout the operation timed out, either by the context or by the connection.
+	ErrorClassTimeout = "timeout"
+	// ErrorClassConversion the key, value or dest could not be converted.
+	ErrorClassConversion = "conversion"
+	// ErrorClassPoolExhausted no connection was available in the pool (redis.ErrPoolExhausted).
+	ErrorClassPoolExhausted = "pool_exhausted"
+	// ErrorClassConnectionRefused the connection to redis was refused (redis.ErrConnectionRefused).
+	ErrorClassConnectionRefused = "connection_refused"
+	// ErrorClassReadOnly the command was sent to a replica (redis.ErrReadOnly).
+	ErrorClassReadOnly = "readonly"
+	// ErrorClassMoved the key was moved to another cluster node (redis.ErrMoved).
+	ErrorClassMoved = "moved"
+	// ErrorClassAsk the key is migrating to another cluster node (redis.ErrAsk).
+	ErrorClassAsk = "ask"
+	// ErrorClassWrongType the key holds another type of value (redis.ErrWrongType).
+	ErrorClassWrongType = "wrong_type"
+	// ErrorClassNoScript the script was not loaded (redis.ErrNoScript).
+	ErrorClassNoScript = "no_script"
+	// ErrorClassOOM redis reached the maxmemory limit (redis.ErrOOM).
+	ErrorClassOOM = "oom"
+	// ErrorClassOther any other error.
+	ErrorClassOther = "other"
+)
+
+// Collector is a prometheus.Collector that exports the latency, errors and cache hits of the template operations,
+// and the connection pool stats of the underlying client.
+type Collector struct {
+	template *redis.Template
+
+	duration    *prom.HistogramVec
+	errors      *prom.CounterVec
+	cacheHits   prom.Counter
+	cacheMisses prom.Counter
+
+	poolHits       *prom.Desc
+	poolMisses     *prom.Desc
+	poolTimeouts   *prom.Desc
+	poolTotalConns *prom.Desc
+	poolIdleConns  *prom.Desc
+	poolStaleConns *prom.Desc
+}
+
+var _ prom.Collector = (*Collector)(nil)
+
+// NewCollector creates a new collector and registers it as a hook on the template, to export the metrics,
+// register the collector, ex: prometheus.MustRegister(collector).
+//
+// To customize the collector, use the opts parameter (Options).
+func NewCollector(t *redis.Template, opts ...*Options) *Collector {
+	opt := GetOptionsByParams(opts)
+	namespace := *opt.Namespace
+	c := &Collector{
+		template: t,
+		duration: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace:   namespace,
+			Name:        "operation_duration_seconds",
+			Help:        "Duration of the redis template operations.",
+			ConstLabels: opt.ConstLabels,
+			Buckets:     opt.Buckets,
+		}, []string{"operation"}),
+		errors: prom.NewCounterVec(prom.CounterOpts{
+			Namespace:   namespace,
+			Name:        "operation_errors_total",
+			Help:        "Number of redis template operations that failed, by error class.",
+			ConstLabels: opt.ConstLabels,
+		}, []string{"operation", "class"}),
+		cacheHits: prom.NewCounter(prom.CounterOpts{
+			Namespace:   namespace,
+			Name:        "cache_hits_total",
+			Help:        "Number of Get operations that found the key.",
+			ConstLabels: opt.ConstLabels,
+		}),
+		cacheMisses: prom.NewCounter(prom.CounterOpts{
+			Namespace:   namespace,
+			Name:        "cache_misses_total",
+			Help:        "Number of Get operations that did not find the key.",
+			ConstLabels: opt.ConstLabels,
+		}),
+		poolHits: prom.NewDesc(
+			prom.BuildFQName(namespace, "pool", "hits_total"),
+			"Number of times a free connection was found in the pool.",
+			nil,
+			opt.ConstLabels,
+		),
+		poolMisses: prom.NewDesc(
+			prom.BuildFQName(namespace, "pool", "misses_total"),
+			"Number of times a free connection was not found in the pool.",
+			nil,
+			opt.ConstLabels,
+		),
+		poolTimeouts: prom.NewDesc(
+			prom.BuildFQName(namespace, "pool", "timeouts_total"),
+			"Number of times a wait for a connection in the pool timed out.",
+			nil,
+			opt.ConstLabels,
+		),
+		poolTotalConns: prom.NewDesc(
+			prom.BuildFQName(namespace, "pool", "total_connections"),
+			"Number of total connections in the pool.",
+			nil,
+			opt.ConstLabels,
+		),
+		poolIdleConns: prom.NewDesc(
+			prom.BuildFQName(namespace, "pool", "idle_connections"),
+			"Number of idle connections in the pool.",
+			nil,
+			opt.ConstLabels,
+		),
+		poolStaleConns: prom.NewDesc(
+			prom.BuildFQName(namespace, "pool", "stale_connections_total"),
+			"Number of stale connections removed from the pool.",
+			nil,
+			opt.ConstLabels,
+		),
+	}
+	t.AddHook(c)
+	return c
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prom.Desc) {
+	c.duration.Describe(ch)
+	c.errors.Describe(ch)
+	c.cacheHits.Describe(ch)
+	c.cacheMisses.Describe(ch)
+	ch <- c.poolHits
+	ch <- c.poolMisses
+	ch <- c.poolTimeouts
+	ch <- c.poolTotalConns
+	ch <- c.poolIdleConns
+	ch <- c.poolStaleConns
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prom.Metric) {
+	c.duration.Collect(ch)
+	c.errors.Collect(ch)
+	c.cacheHits.Collect(ch)
+	c.cacheMisses.Collect(ch)
+	stats := c.template.PoolStats()
+	ch <- prom.MustNewConstMetric(c.poolHits, prom.CounterValue, float64(stats.Hits))
+	ch <- prom.MustNewConstMetric(c.poolMisses, prom.CounterValue, float64(stats.Misses))
+	ch <- prom.MustNewConstMetric(c.poolTimeouts, prom.CounterValue, float64(stats.Timeouts))
+	ch <- prom.MustNewConstMetric(c.poolTotalConns, prom.GaugeValue, float64(stats.TotalConns))
+	ch <- prom.MustNewConstMetric(c.poolIdleConns, prom.GaugeValue, float64(stats.IdleConns))
+	ch <- prom.MustNewConstMetric(c.poolStaleConns, prom.CounterValue, float64(stats.StaleConns))
+}
+
+// Before implements redis.Hook.
+func (c *Collector) Before(ctx context.Context, _ redis.Operation) (context.Context, error) {
+	return ctx, nil
+}
+
+// After implements redis.Hook.
+func (c *Collector) After(_ context.Context, op redis.Operation) {
+	if helper.IsNotEqualTo(op.Type, redis.OperationTypeTemplate) {
+		return
+	}
+	c.duration.WithLabelValues(op.Name).Observe(op.Duration.Seconds())
+	if helper.Equals(op.Name, "Get") {
+		if helper.IsNil(op.Err) {
+			c.cacheHits.Inc()
+		} else if errors.Is(op.Err, redis.ErrKeyNotFound) {
+			c.cacheMisses.Inc()
+		}
+	}
+	if helper.IsNotNil(op.Err) {
+		c.errors.WithLabelValues(op.Name, ErrorClass(op.Err)).Inc()
+	}
+}
+
+// errorClasses maps the classes of the errors (redis.ErrorKind) to the ErrorClass constants.
+var errorClasses = map[error]string{
+	redis.ErrTimeout:           ErrorClassTimeout,
+	redis.ErrPoolExhausted:     ErrorClassPoolExhausted,
+	redis.ErrConnectionRefused: ErrorClassConnectionRefused,
+	redis.ErrReadOnly:          ErrorClassReadOnly,
+	redis.ErrMoved:             ErrorClassMoved,
+	redis.ErrAsk:               ErrorClassAsk,
+	redis.ErrWrongType:         ErrorClassWrongType,
+	redis.ErrNoScript:          ErrorClassNoScript,
+	redis.ErrOOM:               ErrorClassOOM,
+}
+
+// ErrorClass returns the class of the error used in the errors metric label, one of the ErrorClass constants,
+// ErrorClassOther if the error has no class. The classes of the redis errors are the ones of redis.ErrorKind.
+func ErrorClass(err error) string {
+	if errors.Is(err, redis.ErrKeyNotFound) {
+		return ErrorClassNotFound
+	} else if errors.Is(err, redis.ErrConvertKey) || errors.Is(err, redis.ErrConvertNewKey) ||
+		errors.Is(err, redis.ErrConvertValue) || errors.Is(err, redis.ErrDestIsNotPointer) {
+		return ErrorClassConversion
+	} else if class, ok := errorClasses[redis.ErrorKind(err)]; ok {
+		return class
+	}
+	return ErrorClassOther
+}
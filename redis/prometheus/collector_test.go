@@ -0,0 +1,80 @@
+package prometheus
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func initTemplate() *redis.Template {
+	return redis.NewTemplate(option.Client{
+		Addr:     os.Getenv("REDIS_URL"),
+		Password: os.Getenv("REDIS_PASSWORD"),
+		DB:       0,
+	})
+}
+
+func TestNewCollector(t *testing.T) {
+	redisTemplate := initTemplate()
+	defer redisTemplate.SimpleDisconnect()
+	collector := NewCollector(redisTemplate, NewOptions().
+		SetNamespace("test").
+		SetConstLabels(prom.Labels{"template": "cache", "db": "0"}))
+	registry := prom.NewPedanticRegistry()
+	err := registry.Register(collector)
+	if helper.IsNotNil(err) {
+		logger.Error("Register() err =", err)
+		t.Fail()
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	_ = redisTemplate.Get(ctx, nil, new(string))
+	_ = redisTemplate.Get(ctx, "test-prometheus", new(string))
+	count := testutil.ToFloat64(collector.errors.WithLabelValues("Get", ErrorClassConversion))
+	if helper.IsNotEqualTo(count, float64(1)) {
+		logger.Errorf("Collect() conversion errors = %v, want = %v", count, 1)
+		t.Fail()
+		return
+	}
+	expected := `
+# HELP test_pool_timeouts_total Number of times a wait for a connection in the pool timed out.
+# TYPE test_pool_timeouts_total counter
+test_pool_timeouts_total{db="0",template="cache"} 0
+`
+	err = testutil.GatherAndCompare(registry, strings.NewReader(expected), "test_pool_timeouts_total")
+	if helper.IsNotNil(err) {
+		logger.Error("GatherAndCompare() err =", err)
+		t.Fail()
+		return
+	}
+	logger.Infof("Collect() metrics = %v", testutil.CollectAndCount(collector))
+}
+
+func TestErrorClass(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		want string
+	}{
+		{err: redis.ErrKeyNotFound, want: ErrorClassNotFound},
+		{err: context.DeadlineExceeded, want: ErrorClassTimeout},
+		{err: redis.ErrConvertValue, want: ErrorClassConversion},
+		{err: redis.ErrCircuitOpen, want: ErrorClassOther},
+		{err: &redis.OpError{Op: "Get", Key: "key", Err: redis.ErrKeyNotFound}, want: ErrorClassNotFound},
+		{err: redis.NewOpError("Set", "key", redis.ErrOOM), want: ErrorClassOOM},
+	} {
+		result := ErrorClass(tt.err)
+		if helper.IsNotEqualTo(result, tt.want) {
+			logger.Errorf("ErrorClass() = %v, want = %v", result, tt.want)
+			t.Fail()
+		}
+	}
+}
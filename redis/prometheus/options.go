@@ -0,0 +1,67 @@
+package prometheus
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+// Options represents options that can be used to configure the template collector (NewCollector).
+type Options struct {
+	// Namespace prefix of the metric names.
+	// Default is "redis_template".
+	Namespace *string
+	// ConstLabels added to every metric, ex: the template name or the DB number.
+	ConstLabels prom.Labels
+	// Buckets of the operation latency histogram, in seconds.
+	// Default is prometheus.DefBuckets.
+	Buckets []float64
+}
+
+// NewOptions creates a new Options instance.
+func NewOptions() *Options {
+	return &Options{}
+}
+
+// SetNamespace sets value for the Namespace field.
+func (o *Options) SetNamespace(namespace string) *Options {
+	o.Namespace = &namespace
+	return o
+}
+
+// SetConstLabels sets value for the ConstLabels field.
+func (o *Options) SetConstLabels(labels prom.Labels) *Options {
+	o.ConstLabels = labels
+	return o
+}
+
+// SetBuckets sets value for the Buckets field.
+func (o *Options) SetBuckets(buckets ...float64) *Options {
+	o.Buckets = buckets
+	return o
+}
+
+// GetOptionsByParams assembles the Options object from optional parameters.
+func GetOptionsByParams(opts []*Options) *Options {
+	result := &Options{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotNil(opt.Namespace) {
+			result.Namespace = opt.Namespace
+		}
+		if helper.IsNotEmpty(opt.ConstLabels) {
+			result.ConstLabels = opt.ConstLabels
+		}
+		if helper.IsNotEmpty(opt.Buckets) {
+			result.Buckets = opt.Buckets
+		}
+	}
+	if helper.IsNil(result.Namespace) {
+		result.Namespace = helper.ConvertToPointer("redis_template")
+	}
+	if helper.IsEmpty(result.Buckets) {
+		result.Buckets = prom.DefBuckets
+	}
+	return result
+}
@@ -0,0 +1,148 @@
+package queue
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"time"
+)
+
+// Options represents options that can be used to configure the queue (New) and its workers.
+type Options struct {
+	// Concurrency number of jobs processed at the same time by each worker (Queue.Work).
+	// Default is 1.
+	Concurrency *int
+	// VisibilityTimeout lease of each job, if the handler does not return within it, the job is considered stuck and
+	// is requeued by the reaper, and the jobs of a consumer without heartbeats for it, ex: the process crashed, are
+	// requeued too.
+	// Default is 30 seconds.
+	VisibilityTimeout *time.Duration
+	// HeartbeatInterval interval of the heartbeats of the consumers of a worker, must be lower than the
+	// VisibilityTimeout.
+	// Default is a third of the VisibilityTimeout.
+	HeartbeatInterval *time.Duration
+	// PollInterval maximum time a consumer blocks waiting for a job, and interval of the reaper, which requeues the
+	// stuck jobs and the retries whose backoff has expired.
+	// Default is 1 second.
+	PollInterval *time.Duration
+	// MaxAttempts number of attempts of a job, including the first, before it is moved to the dead-letter queue.
+	// Default is 5.
+	MaxAttempts *int
+	// BaseBackoff delay of the first retry, doubled on each following retry.
+	// Default is 1 second.
+	BaseBackoff *time.Duration
+	// MaxBackoff maximum delay of the retries.
+	// Default is 10 minutes.
+	MaxBackoff *time.Duration
+	// OnError is called with the errors of the background operations of the workers, ex: a fetch failed because the
+	// connection was lost (not required).
+	OnError func(err error)
+}
+
+// NewOptions creates a new Options instance.
+func NewOptions() *Options {
+	return &Options{}
+}
+
+// SetConcurrency sets value for the Concurrency field.
+func (o *Options) SetConcurrency(concurrency int) *Options {
+	o.Concurrency = &concurrency
+	return o
+}
+
+// SetVisibilityTimeout sets value for the VisibilityTimeout field.
+func (o *Options) SetVisibilityTimeout(visibilityTimeout time.Duration) *Options {
+	o.VisibilityTimeout = &visibilityTimeout
+	return o
+}
+
+// SetHeartbeatInterval sets value for the HeartbeatInterval field.
+func (o *Options) SetHeartbeatInterval(heartbeatInterval time.Duration) *Options {
+	o.HeartbeatInterval = &heartbeatInterval
+	return o
+}
+
+// SetPollInterval sets value for the PollInterval field.
+func (o *Options) SetPollInterval(pollInterval time.Duration) *Options {
+	o.PollInterval = &pollInterval
+	return o
+}
+
+// SetMaxAttempts sets value for the MaxAttempts field.
+func (o *Options) SetMaxAttempts(maxAttempts int) *Options {
+	o.MaxAttempts = &maxAttempts
+	return o
+}
+
+// SetBaseBackoff sets value for the BaseBackoff field.
+func (o *Options) SetBaseBackoff(baseBackoff time.Duration) *Options {
+	o.BaseBackoff = &baseBackoff
+	return o
+}
+
+// SetMaxBackoff sets value for the MaxBackoff field.
+func (o *Options) SetMaxBackoff(maxBackoff time.Duration) *Options {
+	o.MaxBackoff = &maxBackoff
+	return o
+}
+
+// SetOnError sets value for the OnError field.
+func (o *Options) SetOnError(f func(err error)) *Options {
+	o.OnError = f
+	return o
+}
+
+// GetOptionsByParams assembles the Options object from optional parameters.
+func GetOptionsByParams(opts []*Options) *Options {
+	result := &Options{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotNil(opt.Concurrency) {
+			result.Concurrency = opt.Concurrency
+		}
+		if helper.IsNotNil(opt.VisibilityTimeout) {
+			result.VisibilityTimeout = opt.VisibilityTimeout
+		}
+		if helper.IsNotNil(opt.HeartbeatInterval) {
+			result.HeartbeatInterval = opt.HeartbeatInterval
+		}
+		if helper.IsNotNil(opt.PollInterval) {
+			result.PollInterval = opt.PollInterval
+		}
+		if helper.IsNotNil(opt.MaxAttempts) {
+			result.MaxAttempts = opt.MaxAttempts
+		}
+		if helper.IsNotNil(opt.BaseBackoff) {
+			result.BaseBackoff = opt.BaseBackoff
+		}
+		if helper.IsNotNil(opt.MaxBackoff) {
+			result.MaxBackoff = opt.MaxBackoff
+		}
+		if opt.OnError != nil {
+			result.OnError = opt.OnError
+		}
+	}
+	if helper.IsNil(result.Concurrency) || *result.Concurrency < 1 {
+		result.Concurrency = helper.ConvertToPointer(1)
+	}
+	if helper.IsNil(result.VisibilityTimeout) || *result.VisibilityTimeout <= 0 {
+		result.VisibilityTimeout = helper.ConvertToPointer(30 * time.Second)
+	}
+	if helper.IsNil(result.HeartbeatInterval) || *result.HeartbeatInterval <= 0 ||
+		*result.HeartbeatInterval >= *result.VisibilityTimeout {
+		result.HeartbeatInterval = helper.ConvertToPointer(*result.VisibilityTimeout / 3)
+	}
+	if helper.IsNil(result.PollInterval) || *result.PollInterval <= 0 {
+		result.PollInterval = helper.ConvertToPointer(time.Second)
+	}
+	if helper.IsNil(result.MaxAttempts) || *result.MaxAttempts < 1 {
+		result.MaxAttempts = helper.ConvertToPointer(5)
+	}
+	if helper.IsNil(result.BaseBackoff) || *result.BaseBackoff < 0 {
+		result.BaseBackoff = helper.ConvertToPointer(time.Second)
+	}
+	if helper.IsNil(result.MaxBackoff) || *result.MaxBackoff < *result.BaseBackoff {
+		result.MaxBackoff = helper.ConvertToPointer(max(10*time.Minute, *result.BaseBackoff))
+	}
+	return result
+}
@@ -0,0 +1,312 @@
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"math"
+	"strings"
+	"time"
+)
+
+// reapBatch is the maximum number of delayed jobs and expired leases handled by each pass of the reaper.
+const reapBatch = 100
+
+// Job is a job of the queue with its typed payload, stored as JSON.
+type Job[T any] struct {
+	// ID unique identifier of the job, generated by Enqueue.
+	ID string `json:"id"`
+	// Payload of the job, must be compatible with the encoding/json package.
+	Payload T `json:"payload"`
+	// Attempt number of the current attempt of the job, starting at 1.
+	Attempt int `json:"attempt"`
+	// EnqueuedAt time the job was enqueued.
+	EnqueuedAt time.Time `json:"enqueuedAt"`
+	// LastError error returned by the handler on the previous attempt, empty on the first attempt.
+	LastError string `json:"lastError,omitempty"`
+}
+
+// Stats is the number of jobs in each state of the queue, returned by Queue.Stats.
+type Stats struct {
+	// Pending jobs waiting for a consumer.
+	Pending int64
+	// Processing jobs taken by a consumer, including the stuck jobs not yet requeued.
+	Processing int64
+	// Delayed jobs waiting for the backoff of a retry, or enqueued with EnqueueIn.
+	Delayed int64
+	// Dead jobs that failed all the attempts.
+	Dead int64
+}
+
+// Queue is a reliable job queue on the lists and sorted sets of a template, with at-least-once delivery. The
+// pending jobs are in a list, each consumer of a worker moves a job atomically (BLMOVE) to its own processing list
+// and leases the job for the visibility timeout in a sorted set of leases, so the jobs whose handler did not finish
+// in time are moved back to the pending list by the reaper, even if their consumer is still alive. The consumers
+// also register heartbeats in a sorted set of consumers, so the jobs of the consumers without heartbeats for the
+// visibility timeout, ex: a job fetched right before the process crashed, are requeued too. The failed jobs are
+// retried with exponential backoff from a sorted set of delayed jobs, and moved to the dead-letter list after the
+// maximum attempts.
+//
+// A job can be processed more than once, ex: when a consumer is considered stuck but finishes the job afterward,
+// so the handlers must be idempotent.
+//
+// The jobs are stored as JSON in plain text, even if the template has encryption (option.Client.Encryption), since
+// they are acknowledged by their exact value, so encrypt the sensitive fields of the payload before enqueueing it.
+type Queue[T any] struct {
+	template *redis.Template
+	name     string
+	opt      *Options
+}
+
+// New creates a new queue of the template, with the name as prefix of its keys, ex: "emails:pending",
+// "emails:delayed", "emails:dead", "emails:consumers", "emails:leases" and "emails:processing:<consumer>".
+//
+// On redis cluster, use a hash tag in the name, ex: "{emails}", so that the jobs can be moved between the keys.
+//
+// To customize the queue and its workers, use the opts parameter (Options).
+func New[T any](t *redis.Template, name string, opts ...*Options) *Queue[T] {
+	return &Queue[T]{
+		template: t,
+		name:     name,
+		opt:      GetOptionsByParams(opts),
+	}
+}
+
+// Name returns the name of the queue.
+func (q *Queue[T]) Name() string {
+	return q.name
+}
+
+// Enqueue adds a job with the payload to the end of the queue, returning the ID of the job.
+func (q *Queue[T]) Enqueue(ctx context.Context, payload T) (string, error) {
+	job, raw, err := q.newJob(payload)
+	if helper.IsNil(err) {
+		_, err = q.template.LPush(ctx, q.pendingKey(), raw)
+	}
+	return job.ID, err
+}
+
+// EnqueueIn adds a job with the payload to the queue after the delay, returning the ID of the job. The job is
+// moved to the end of the queue by the reaper of a worker, so it is delayed by up to Options.PollInterval more.
+func (q *Queue[T]) EnqueueIn(ctx context.Context, payload T, delay time.Duration) (string, error) {
+	job, raw, err := q.newJob(payload)
+	if helper.IsNil(err) {
+		err = q.delay(ctx, raw, time.Now().Add(delay))
+	}
+	return job.ID, err
+}
+
+// Stats returns the number of jobs in each state of the queue.
+func (q *Queue[T]) Stats(ctx context.Context) (Stats, error) {
+	var stats Stats
+	var err error
+	if stats.Pending, err = q.template.LLen(ctx, q.pendingKey()); helper.IsNotNil(err) {
+		return stats, err
+	} else if stats.Delayed, err = q.template.ZCard(ctx, q.delayedKey()); helper.IsNotNil(err) {
+		return stats, err
+	} else if stats.Dead, err = q.template.LLen(ctx, q.deadKey()); helper.IsNotNil(err) {
+		return stats, err
+	}
+	consumers, err := q.template.ZRangeByScore(ctx, q.consumersKey(), math.Inf(-1), math.Inf(1), 0, 0)
+	if helper.IsNotNil(err) {
+		return stats, err
+	}
+	for _, consumer := range consumers {
+		processing, err := q.template.LLen(ctx, q.processingKey(consumer))
+		if helper.IsNotNil(err) {
+			return stats, err
+		}
+		stats.Processing += processing
+	}
+	return stats, nil
+}
+
+// DeadJobs returns the jobs of the dead-letter queue, the most recent first, with the error of the last attempt.
+// The jobs that cannot be decoded are returned with only the LastError field.
+func (q *Queue[T]) DeadJobs(ctx context.Context) ([]*Job[T], error) {
+	raws, err := q.template.LRange(ctx, q.deadKey(), 0, -1)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	jobs := make([]*Job[T], len(raws))
+	for i, raw := range raws {
+		jobs[i] = &Job[T]{}
+		if err = json.Unmarshal([]byte(raw), jobs[i]); helper.IsNotNil(err) {
+			jobs[i].LastError = err.Error()
+		}
+	}
+	return jobs, nil
+}
+
+// Reap moves back to the queue the jobs whose lease has expired, the jobs of the consumers without heartbeats for
+// the visibility timeout, and the delayed jobs whose time has come, returning the number of jobs moved. It is called
+// by the workers every Options.PollInterval, and can be called by any process, ex: a cron job when there are no
+// workers running.
+func (q *Queue[T]) Reap(ctx context.Context) (int, error) {
+	moved, err := q.reapLeases(ctx)
+	if helper.IsNotNil(err) {
+		return moved, err
+	}
+	deadline := time.Now().Add(-*q.opt.VisibilityTimeout)
+	consumers, err := q.template.ZRangeByScore(ctx, q.consumersKey(), math.Inf(-1), score(deadline), 0, 0)
+	if helper.IsNotNil(err) {
+		return moved, err
+	}
+	for _, consumer := range consumers {
+		requeued, err := q.requeue(ctx, consumer)
+		moved += requeued
+		if helper.IsNotNil(err) {
+			return moved, err
+		} else if _, err = q.template.ZRem(ctx, q.consumersKey(), consumer); helper.IsNotNil(err) {
+			return moved, err
+		}
+	}
+	raws, err := q.template.ZRangeByScore(ctx, q.delayedKey(), math.Inf(-1), score(time.Now()), 0, reapBatch)
+	if helper.IsNotNil(err) {
+		return moved, err
+	}
+	for _, raw := range raws {
+		// the job is pushed before it is removed from the delayed jobs, so it is not lost if the process stops in
+		// between, and the push is undone if another reaper removed it first.
+		if _, err = q.template.LPush(ctx, q.pendingKey(), raw); helper.IsNotNil(err) {
+			return moved, err
+		}
+		removed, err := q.template.ZRem(ctx, q.delayedKey(), raw)
+		if helper.IsNotNil(err) {
+			return moved, err
+		} else if helper.IsEmpty(removed) {
+			if _, err = q.template.LRem(ctx, q.pendingKey(), 1, raw); helper.IsNotNil(err) {
+				return moved, err
+			}
+			continue
+		}
+		moved++
+	}
+	return moved, nil
+}
+
+// reapLeases moves back to the queue the jobs whose lease has expired, returning the number of jobs moved. The
+// leases of the jobs no longer in the processing list of their consumer, ex: requeued with the consumer, are only
+// removed.
+func (q *Queue[T]) reapLeases(ctx context.Context) (int, error) {
+	moved := 0
+	leases, err := q.template.ZRangeByScore(ctx, q.leasesKey(), math.Inf(-1), score(time.Now()), 0, reapBatch)
+	if helper.IsNotNil(err) {
+		return moved, err
+	}
+	for _, lease := range leases {
+		consumer, raw, _ := strings.Cut(lease, ":")
+		// like the delayed jobs, the job is pushed before it is removed from the processing list, and the push is
+		// undone if the job was no longer there.
+		if _, err = q.template.LPush(ctx, q.pendingKey(), raw); helper.IsNotNil(err) {
+			return moved, err
+		}
+		removed, err := q.template.LRem(ctx, q.processingKey(consumer), 1, raw)
+		if helper.IsNotNil(err) {
+			return moved, err
+		} else if helper.IsEmpty(removed) {
+			if _, err = q.template.LRem(ctx, q.pendingKey(), 1, raw); helper.IsNotNil(err) {
+				return moved, err
+			}
+		} else {
+			moved++
+		}
+		if _, err = q.template.ZRem(ctx, q.leasesKey(), lease); helper.IsNotNil(err) {
+			return moved, err
+		}
+	}
+	return moved, nil
+}
+
+// requeue moves the jobs of the processing list of the consumer back to the queue, removing their leases, returning
+// the number of jobs moved.
+func (q *Queue[T]) requeue(ctx context.Context, consumer string) (int, error) {
+	moved := 0
+	for {
+		raw, err := q.template.LMove(ctx, q.processingKey(consumer), q.pendingKey(), option.ListDirectionRight,
+			option.ListDirectionRight)
+		if errors.Is(err, redis.ErrKeyNotFound) {
+			return moved, nil
+		} else if helper.IsNotNil(err) {
+			return moved, err
+		}
+		moved++
+		if _, err = q.template.ZRem(ctx, q.leasesKey(), leaseMember(consumer, raw)); helper.IsNotNil(err) {
+			return moved, err
+		}
+	}
+}
+
+// newJob creates a job with the payload, returning it encoded.
+func (q *Queue[T]) newJob(payload T) (*Job[T], string, error) {
+	id, err := newID()
+	if helper.IsNotNil(err) {
+		return &Job[T]{}, "", err
+	}
+	job := &Job[T]{ID: id, Payload: payload, Attempt: 1, EnqueuedAt: time.Now().UTC()}
+	raw, err := json.Marshal(job)
+	if helper.IsNotNil(err) {
+		return job, "", errors.Join(redis.ErrConvertValue, err)
+	}
+	return job, string(raw), nil
+}
+
+// delay adds the encoded job to the delayed jobs, to be moved to the queue at the time.
+func (q *Queue[T]) delay(ctx context.Context, raw string, at time.Time) error {
+	_, err := q.template.ZAdd(ctx, q.delayedKey(), redis.ZMember{Member: raw, Score: score(at)})
+	return err
+}
+
+// backoff returns the delay of the retry after the failed attempt.
+func (q *Queue[T]) backoff(attempt int) time.Duration {
+	delay := float64(*q.opt.BaseBackoff) * math.Pow(2, float64(attempt-1))
+	return time.Duration(min(delay, float64(*q.opt.MaxBackoff)))
+}
+
+func (q *Queue[T]) pendingKey() string {
+	return redis.SprintKey(q.name, "pending")
+}
+
+func (q *Queue[T]) delayedKey() string {
+	return redis.SprintKey(q.name, "delayed")
+}
+
+func (q *Queue[T]) deadKey() string {
+	return redis.SprintKey(q.name, "dead")
+}
+
+func (q *Queue[T]) consumersKey() string {
+	return redis.SprintKey(q.name, "consumers")
+}
+
+func (q *Queue[T]) leasesKey() string {
+	return redis.SprintKey(q.name, "leases")
+}
+
+func (q *Queue[T]) processingKey(consumer string) string {
+	return redis.SprintKey(q.name, "processing", consumer)
+}
+
+// leaseMember returns the member of the job of the consumer in the sorted set of leases.
+func leaseMember(consumer, raw string) string {
+	return consumer + ":" + raw
+}
+
+// newID returns a random identifier for the jobs and the consumers.
+func newID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); helper.IsNotNil(err) {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// score returns the time as the score of the sorted sets, in Unix milliseconds.
+func score(t time.Time) float64 {
+	return float64(t.UnixMilli())
+}
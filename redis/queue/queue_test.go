@@ -0,0 +1,315 @@
+package queue_test
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"github.com/GabrielHCataldo/go-redis-template/redis/queue"
+	"github.com/GabrielHCataldo/go-redis-template/redis/redistest"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+type email struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+}
+
+// waitFor polls the condition until it is true or the timeout expires.
+func waitFor(timeout time.Duration, condition func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return condition()
+}
+
+func TestQueueWork(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	emails := queue.New[email](redisTemplate, "emails", queue.NewOptions().SetConcurrency(2))
+	for _, to := range []string{"a@go.dev", "b@go.dev", "c@go.dev"} {
+		if _, err := emails.Enqueue(ctx, email{To: to, Subject: "welcome"}); helper.IsNotNil(err) {
+			logger.Errorf("Enqueue() err = %v", err)
+			t.Fail()
+		}
+	}
+	var mutex sync.Mutex
+	received := map[string]int{}
+	worker := emails.Work(func(ctx context.Context, job *queue.Job[email]) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if helper.IsEmpty(job.ID) || helper.IsNotEqualTo(job.Attempt, 1) || job.EnqueuedAt.IsZero() {
+			logger.Errorf("Work() job = %v", job)
+			t.Fail()
+		}
+		received[job.Payload.To]++
+		return nil
+	})
+	processed := waitFor(5*time.Second, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return helper.Equals(len(received), 3)
+	})
+	if err := worker.Shutdown(ctx); helper.IsNotNil(err) || !processed {
+		logger.Errorf("Shutdown() received = %v err = %v", received, err)
+		t.Fail()
+	}
+	stats, err := emails.Stats(ctx)
+	consumers, _ := redisTemplate.ZCard(ctx, "emails:consumers")
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(stats, queue.Stats{}) || helper.IsNotEqualTo(consumers, int64(0)) {
+		logger.Errorf("Stats() result = %v consumers = %v err = %v", stats, consumers, err)
+		t.Fail()
+	}
+}
+
+func TestQueueRetry(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	emails := queue.New[email](redisTemplate, "emails", queue.NewOptions().SetMaxAttempts(3).
+		SetBaseBackoff(10*time.Millisecond).SetPollInterval(20*time.Millisecond))
+	_, _ = emails.Enqueue(ctx, email{To: "flaky@go.dev"})
+	_, _ = emails.Enqueue(ctx, email{To: "broken@go.dev"})
+	_, _ = redisTemplate.LPush(ctx, "emails:pending", "not a job")
+	var mutex sync.Mutex
+	attempts := map[string][]int{}
+	worker := emails.Work(func(ctx context.Context, job *queue.Job[email]) error {
+		mutex.Lock()
+		attempts[job.Payload.To] = append(attempts[job.Payload.To], job.Attempt)
+		mutex.Unlock()
+		if helper.Equals(job.Payload.To, "broken@go.dev") {
+			panic("smtp unavailable")
+		} else if helper.Equals(job.Attempt, 1) {
+			return errors.New("timeout")
+		}
+		return nil
+	})
+	finished := waitFor(5*time.Second, func() bool {
+		stats, _ := emails.Stats(ctx)
+		return helper.Equals(stats.Dead, int64(2)) && helper.IsEmpty(stats.Pending) && helper.IsEmpty(stats.Delayed) &&
+			helper.IsEmpty(stats.Processing)
+	})
+	_ = worker.Shutdown(ctx)
+	mutex.Lock()
+	defer mutex.Unlock()
+	if !finished || helper.IsNotEqualTo(attempts["flaky@go.dev"], []int{1, 2}) ||
+		helper.IsNotEqualTo(attempts["broken@go.dev"], []int{1, 2, 3}) {
+		logger.Errorf("Work() attempts = %v finished = %v", attempts, finished)
+		t.Fail()
+	}
+	dead, err := emails.DeadJobs(ctx)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(len(dead), 2) {
+		logger.Errorf("DeadJobs() result = %v err = %v", dead, err)
+		t.FailNow()
+	}
+	for _, job := range dead {
+		if helper.Equals(job.Payload.To, "broken@go.dev") && (helper.IsNotEqualTo(job.Attempt, 3) ||
+			helper.IsNotEqualTo(job.LastError, "queue: handler panic: smtp unavailable")) {
+			logger.Errorf("DeadJobs() job = %v", job)
+			t.Fail()
+		} else if helper.IsEmpty(job.Payload.To) && helper.IsEmpty(job.LastError) {
+			logger.Errorf("DeadJobs() undecoded job = %v", job)
+			t.Fail()
+		}
+	}
+}
+
+func TestQueueReap(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	emails := queue.New[email](redisTemplate, "emails", queue.NewOptions().SetVisibilityTimeout(time.Minute))
+	_, _ = emails.Enqueue(ctx, email{To: "crashed@go.dev"})
+	_, _ = emails.Enqueue(ctx, email{To: "alive@go.dev"})
+	_, _ = redisTemplate.LMove(ctx, "emails:pending", "emails:processing:crashed", option.ListDirectionRight,
+		option.ListDirectionLeft)
+	_, _ = redisTemplate.LMove(ctx, "emails:pending", "emails:processing:alive", option.ListDirectionRight,
+		option.ListDirectionLeft)
+	_, _ = redisTemplate.ZAdd(ctx, "emails:consumers",
+		redis.ZMember{Member: "crashed", Score: float64(time.Now().Add(-2 * time.Minute).UnixMilli())},
+		redis.ZMember{Member: "alive", Score: float64(time.Now().UnixMilli())})
+	_, _ = emails.EnqueueIn(ctx, email{To: "due@go.dev"}, -time.Second)
+	_, _ = emails.EnqueueIn(ctx, email{To: "later@go.dev"}, time.Hour)
+	moved, err := emails.Reap(ctx)
+	stats, _ := emails.Stats(ctx)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(moved, 2) ||
+		helper.IsNotEqualTo(stats, queue.Stats{Pending: 2, Processing: 1, Delayed: 1}) {
+		logger.Errorf("Reap() result = %v stats = %v err = %v", moved, stats, err)
+		t.Fail()
+	}
+	consumers, _ := redisTemplate.ZRangeByScore(ctx, "emails:consumers", math.Inf(-1), math.Inf(1), 0, 0)
+	if helper.IsNotEqualTo(consumers, []string{"alive"}) {
+		logger.Errorf("Reap() consumers = %v", consumers)
+		t.Fail()
+	}
+}
+
+func TestQueueShutdown(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	emails := queue.New[email](redisTemplate, "emails", queue.NewOptions().
+		SetVisibilityTimeout(300*time.Millisecond).SetPollInterval(time.Hour))
+	_, _ = emails.Enqueue(ctx, email{To: "slow@go.dev"})
+	started := make(chan struct{})
+	release := make(chan struct{})
+	worker := emails.Work(func(ctx context.Context, job *queue.Job[email]) error {
+		close(started)
+		<-release
+		return nil
+	})
+	<-started
+	shutdownCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	err := worker.Shutdown(shutdownCtx)
+	stats, _ := emails.Stats(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || helper.IsNotEqualTo(stats.Processing, int64(1)) {
+		logger.Errorf("Shutdown() stats = %v err = %v", stats, err)
+		t.Fail()
+	}
+	time.Sleep(400 * time.Millisecond)
+	moved, err := emails.Reap(ctx)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(moved, 1) {
+		logger.Errorf("Reap() result = %v err = %v", moved, err)
+		t.Fail()
+	}
+	close(release)
+	done := make(chan struct{})
+	worker = emails.Work(func(ctx context.Context, job *queue.Job[email]) error {
+		time.Sleep(100 * time.Millisecond)
+		close(done)
+		return nil
+	})
+	waitFor(5*time.Second, func() bool {
+		stats, _ = emails.Stats(ctx)
+		return helper.Equals(stats.Processing, int64(1))
+	})
+	err = worker.Shutdown(ctx)
+	stats, _ = emails.Stats(ctx)
+	select {
+	case <-done:
+	default:
+		err = errors.New("job in progress not finished")
+	}
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(stats, queue.Stats{}) {
+		logger.Errorf("Shutdown() graceful stats = %v err = %v", stats, err)
+		t.Fail()
+	}
+}
+
+func TestQueueShutdownRequeue(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	var mutex sync.Mutex
+	var errs []error
+	emails := queue.New[email](redisTemplate, "emails", queue.NewOptions().SetConcurrency(1).SetMaxAttempts(1).
+		SetPollInterval(10*time.Millisecond).SetOnError(func(err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		errs = append(errs, err)
+	}))
+	worker := emails.Work(func(ctx context.Context, job *queue.Job[email]) error {
+		return errors.New("smtp unavailable")
+	})
+	consumers, _ := redisTemplate.ZRangeByScore(ctx, "emails:consumers", math.Inf(-1), math.Inf(1), 0, 0)
+	if helper.IsNotEqualTo(len(consumers), 1) {
+		logger.Errorf("Work() consumers = %v", consumers)
+		t.FailNow()
+	}
+	// a job moved to the processing list while the worker stopped fetching.
+	_, _ = redisTemplate.LPush(ctx, "emails:processing:"+consumers[0], `{"id":"lost","payload":{"to":"lost@go.dev"}}`)
+	// the dead letter and the requeue of a failed job fail, so the job stays in the processing list.
+	_, _ = redisTemplate.LPush(ctx, "emails:staging", `{"id":"failed","attempt":1}`)
+	server.SetError("lpush", "ERR boom")
+	_, _ = redisTemplate.LMove(ctx, "emails:staging", "emails:pending", option.ListDirectionRight,
+		option.ListDirectionLeft)
+	failed := waitFor(5*time.Second, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(errs) >= 2
+	})
+	server.SetError("lpush", "")
+	err := worker.Shutdown(ctx)
+	stats, _ := emails.Stats(ctx)
+	consumers, _ = redisTemplate.ZRangeByScore(ctx, "emails:consumers", math.Inf(-1), math.Inf(1), 0, 0)
+	if !failed || helper.IsNotNil(err) || helper.IsNotEqualTo(stats, queue.Stats{Pending: 2}) ||
+		helper.IsNotEmpty(consumers) {
+		logger.Errorf("Shutdown() stats = %v consumers = %v err = %v", stats, consumers, err)
+		t.Fail()
+	}
+}
+
+func TestQueueLease(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	emails := queue.New[email](redisTemplate, "emails", queue.NewOptions().SetConcurrency(2).
+		SetVisibilityTimeout(200*time.Millisecond).SetPollInterval(20*time.Millisecond))
+	_, _ = emails.Enqueue(ctx, email{To: "hung@go.dev"})
+	release := make(chan struct{})
+	canceled := make(chan error, 1)
+	var mutex sync.Mutex
+	deliveries := 0
+	worker := emails.Work(func(ctx context.Context, job *queue.Job[email]) error {
+		mutex.Lock()
+		deliveries++
+		first := helper.Equals(deliveries, 1)
+		mutex.Unlock()
+		if first {
+			<-ctx.Done()
+			canceled <- ctx.Err()
+			<-release
+			return errors.New("too late")
+		}
+		return nil
+	})
+	requeued := waitFor(5*time.Second, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return helper.Equals(deliveries, 2)
+	})
+	var err error
+	select {
+	case err = <-canceled:
+	case <-time.After(time.Second):
+	}
+	close(release)
+	if !requeued || !errors.Is(err, context.DeadlineExceeded) {
+		logger.Errorf("Work() requeued = %v err = %v", requeued, err)
+		t.Fail()
+	}
+	finished := waitFor(5*time.Second, func() bool {
+		stats, _ := emails.Stats(ctx)
+		leases, _ := redisTemplate.ZCard(ctx, "emails:leases")
+		return helper.Equals(stats, queue.Stats{}) && helper.IsEmpty(leases)
+	})
+	shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err = worker.Shutdown(shutdownCtx); helper.IsNotNil(err) || !finished {
+		logger.Errorf("Shutdown() finished = %v err = %v", finished, err)
+		t.Fail()
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	if helper.IsNotEqualTo(deliveries, 2) {
+		logger.Errorf("Work() deliveries = %d, want = 2", deliveries)
+		t.Fail()
+	}
+}
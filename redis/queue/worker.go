@@ -0,0 +1,289 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"math"
+	"sync"
+	"time"
+)
+
+// Handler processes a job of the queue, the errors and the panics retry the job with backoff. The context is
+// canceled when the lease of the job expires, after Options.VisibilityTimeout, or if the Worker.Shutdown times out.
+type Handler[T any] func(ctx context.Context, job *Job[T]) error
+
+// Worker is a pool of consumers processing the jobs of a queue, started by Queue.Work.
+type Worker[T any] struct {
+	queue           *Queue[T]
+	handler         Handler[T]
+	consumers       []string
+	stopFetch       context.CancelFunc
+	fetchCtx        context.Context
+	cancelJobs      context.CancelFunc
+	jobCtx          context.Context
+	stopBackground  context.CancelFunc
+	backgroundCtx   context.Context
+	consumersGroup  sync.WaitGroup
+	backgroundGroup sync.WaitGroup
+	shutdownOnce    sync.Once
+	shutdownErr     error
+}
+
+// Work starts a worker with Options.Concurrency consumers processing the jobs of the queue with the handler, and
+// the heartbeats and the reaper of the queue in background. Stop the worker with Worker.Shutdown.
+func (q *Queue[T]) Work(handler Handler[T]) *Worker[T] {
+	w := &Worker[T]{queue: q, handler: handler}
+	w.fetchCtx, w.stopFetch = context.WithCancel(context.Background())
+	w.jobCtx, w.cancelJobs = context.WithCancel(context.Background())
+	w.backgroundCtx, w.stopBackground = context.WithCancel(context.Background())
+	for i := 0; i < *q.opt.Concurrency; i++ {
+		consumer, err := newID()
+		if helper.IsNotNil(err) {
+			consumer = fmt.Sprint(time.Now().UnixNano(), "-", i)
+		}
+		w.consumers = append(w.consumers, consumer)
+	}
+	w.heartbeat()
+	w.backgroundGroup.Add(2)
+	go w.loop(*q.opt.HeartbeatInterval, w.heartbeat)
+	go w.loop(*q.opt.PollInterval, func() {
+		if _, err := q.Reap(w.backgroundCtx); helper.IsNotNil(err) {
+			w.report(err)
+		}
+	})
+	w.consumersGroup.Add(len(w.consumers))
+	for _, consumer := range w.consumers {
+		go w.consume(consumer)
+	}
+	return w
+}
+
+// finishAttempts is the number of attempts to move a processed job before giving up.
+const finishAttempts = 3
+
+// Shutdown stops fetching jobs, waits for the jobs in progress to finish, and returns the jobs left in the
+// processing lists of the consumers to the queue. If the context is done first, the contexts of the handlers are
+// canceled, the error of the context is returned, and the unfinished jobs are requeued by a reaper after the
+// visibility timeout.
+func (w *Worker[T]) Shutdown(ctx context.Context) error {
+	w.shutdownOnce.Do(func() {
+		w.stopFetch()
+		done := make(chan struct{})
+		go func() {
+			w.consumersGroup.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			w.cancelJobs()
+			w.shutdownErr = ctx.Err()
+		}
+		w.stopBackground()
+		w.backgroundGroup.Wait()
+		for _, consumer := range w.consumers {
+			if helper.IsNotNil(w.shutdownErr) {
+				break
+			}
+			_, w.shutdownErr = w.queue.requeue(ctx, consumer)
+		}
+		if helper.IsNil(w.shutdownErr) {
+			members := make([]any, len(w.consumers))
+			for i, consumer := range w.consumers {
+				members[i] = consumer
+			}
+			_, w.shutdownErr = w.queue.template.ZRem(ctx, w.queue.consumersKey(), members...)
+		}
+		w.cancelJobs()
+	})
+	return w.shutdownErr
+}
+
+// consume fetches and processes the jobs of the queue until the worker stops fetching.
+func (w *Worker[T]) consume(consumer string) {
+	defer w.consumersGroup.Done()
+	q := w.queue
+	timeout := time.Duration(math.Ceil(q.opt.PollInterval.Seconds())) * time.Second
+	for helper.IsNil(w.fetchCtx.Err()) {
+		raw, err := q.template.BLMove(w.fetchCtx, q.pendingKey(), q.processingKey(consumer), option.ListDirectionRight,
+			option.ListDirectionLeft, timeout)
+		if errors.Is(err, redis.ErrKeyNotFound) {
+			continue
+		} else if helper.IsNotNil(err) {
+			if helper.IsNotNil(w.fetchCtx.Err()) {
+				return
+			}
+			// the job may have been moved before the connection failed, so it is returned to the queue.
+			w.report(err)
+			if _, err = q.requeue(w.fetchCtx, consumer); helper.IsNotNil(err) {
+				w.report(err)
+			}
+			w.sleep(w.fetchCtx, *q.opt.PollInterval)
+			continue
+		}
+		w.process(consumer, raw)
+	}
+}
+
+// process leases the job, runs the handler with it and acknowledges, retries or kills the job with the result. If
+// the lease expires before the handler returns, the result is discarded and the job is requeued by the reaper.
+func (w *Worker[T]) process(consumer, raw string) {
+	q := w.queue
+	ctx := context.Background()
+	deadline := time.Now().Add(*q.opt.VisibilityTimeout)
+	if err := w.retry(func() error {
+		_, err := q.template.ZAdd(ctx, q.leasesKey(), redis.ZMember{
+			Member: leaseMember(consumer, raw),
+			Score:  score(deadline),
+		})
+		return err
+	}); helper.IsNotNil(err) {
+		// without the lease, the job is requeued only if the consumer stops its heartbeats.
+		w.report(err)
+	}
+	job := &Job[T]{}
+	if err := json.Unmarshal([]byte(raw), job); helper.IsNotNil(err) {
+		w.finish(consumer, raw, func() error {
+			_, err = q.template.LPush(ctx, q.deadKey(), raw)
+			return err
+		})
+		return
+	}
+	err := w.run(job, deadline)
+	if !time.Now().Before(deadline) {
+		return
+	} else if helper.IsNil(err) {
+		w.finish(consumer, raw, nil)
+		return
+	}
+	job.LastError = err.Error()
+	if job.Attempt >= *q.opt.MaxAttempts {
+		w.finish(consumer, raw, func() error {
+			encoded, err := json.Marshal(job)
+			if helper.IsNil(err) {
+				_, err = q.template.LPush(ctx, q.deadKey(), string(encoded))
+			}
+			return err
+		})
+		return
+	}
+	retryAt := time.Now().Add(q.backoff(job.Attempt))
+	job.Attempt++
+	w.finish(consumer, raw, func() error {
+		encoded, err := json.Marshal(job)
+		if helper.IsNil(err) {
+			err = q.delay(ctx, string(encoded), retryAt)
+		}
+		return err
+	})
+}
+
+// run calls the handler with a context canceled at the deadline of the lease, converting the panics to errors.
+func (w *Worker[T]) run(job *Job[T], deadline time.Time) (err error) {
+	ctx, cancel := context.WithDeadline(w.jobCtx, deadline)
+	defer cancel()
+	defer func() {
+		if r := recover(); helper.IsNotNil(r) {
+			err = fmt.Errorf("queue: handler panic: %v", r)
+		}
+	}()
+	return w.handler(ctx, job)
+}
+
+// finish runs the move of the job, if any, and then removes it from the processing list of the consumer and its
+// lease, each with finishAttempts attempts. If the move fails, the job is returned to the queue to be processed
+// again, and if that fails too, it stays in the processing list until the Shutdown of the worker or a reaper
+// requeues it.
+func (w *Worker[T]) finish(consumer, raw string, move func() error) {
+	q := w.queue
+	ctx := context.Background()
+	if move != nil {
+		if err := w.retry(move); helper.IsNotNil(err) {
+			w.report(err)
+			if err = w.retry(func() error {
+				_, err := q.template.LPush(ctx, q.pendingKey(), raw)
+				return err
+			}); helper.IsNotNil(err) {
+				w.report(err)
+				return
+			}
+		}
+	}
+	if err := w.retry(func() error {
+		_, err := q.template.LRem(ctx, q.processingKey(consumer), 1, raw)
+		return err
+	}); helper.IsNotNil(err) {
+		w.report(err)
+		return
+	}
+	if err := w.retry(func() error {
+		_, err := q.template.ZRem(ctx, q.leasesKey(), leaseMember(consumer, raw))
+		return err
+	}); helper.IsNotNil(err) {
+		w.report(err)
+	}
+}
+
+// retry calls f up to finishAttempts times, waiting Options.PollInterval between the attempts, returning the last
+// error.
+func (w *Worker[T]) retry(f func() error) error {
+	var err error
+	for attempt := 1; attempt <= finishAttempts; attempt++ {
+		if err = f(); helper.IsNil(err) {
+			return nil
+		} else if attempt < finishAttempts {
+			w.sleep(w.jobCtx, *w.queue.opt.PollInterval)
+		}
+	}
+	return err
+}
+
+// heartbeat registers the current time as the heartbeat of the consumers of the worker.
+func (w *Worker[T]) heartbeat() {
+	now := score(time.Now())
+	members := make([]redis.ZMember, len(w.consumers))
+	for i, consumer := range w.consumers {
+		members[i] = redis.ZMember{Member: consumer, Score: now}
+	}
+	if _, err := w.queue.template.ZAdd(w.backgroundCtx, w.queue.consumersKey(), members...); helper.IsNotNil(err) &&
+		helper.IsNil(w.backgroundCtx.Err()) {
+		w.report(err)
+	}
+}
+
+// loop calls f every interval until the background of the worker stops.
+func (w *Worker[T]) loop(interval time.Duration, f func()) {
+	defer w.backgroundGroup.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.backgroundCtx.Done():
+			return
+		case <-ticker.C:
+			f()
+		}
+	}
+}
+
+// sleep waits for the duration or until the context is done.
+func (w *Worker[T]) sleep(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// report passes the error to Options.OnError.
+func (w *Worker[T]) report(err error) {
+	if w.queue.opt.OnError != nil {
+		w.queue.opt.OnError(err)
+	}
+}
@@ -0,0 +1,49 @@
+package redistest
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock provides the current time used to evaluate the key expirations.
+type Clock interface {
+	Now() time.Time
+}
+
+// ManualClock is a Clock that only moves when Advance or Set is called, useful to test expirations without sleeping.
+type ManualClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+type systemClock struct{}
+
+// NewManualClock creates a new ManualClock starting at the informed time.
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+// Now returns the current time of the clock.
+func (c *ManualClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by the duration.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Set moves the clock to the informed time.
+func (c *ManualClock) Set(now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = now
+}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,157 @@
+package redistest
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errNotInteger = errors.New("ERR value is not an integer or out of range")
+var errNotFloat = errors.New("ERR value is not a valid float")
+var errTimeout = errors.New("ERR timeout is not a float or out of range")
+
+type commandFlag int
+
+const (
+	// flagWrite commands that modify the keys, they are rejected by the read only replica.
+	flagWrite commandFlag = 1 << iota
+	// flagNoQueue commands executed immediately inside a transaction.
+	flagNoQueue
+	// flagPubSub commands allowed in the RESP2 subscribed context.
+	flagPubSub
+	// flagHandshake commands sent by go-redis when the connection is established.
+	flagHandshake
+)
+
+// command describes a command as redis does, the arity is the number of arguments including the command name,
+// negative means at least -arity arguments, and the keys are between firstKey and lastKey (negative counts from
+// the end) with step.
+type command struct {
+	arity    int
+	flags    commandFlag
+	firstKey int
+	lastKey  int
+	step     int
+	handler  func(c *conn, args []string)
+	// block is used instead of handler by the blocking commands, it returns false when there is nothing to serve,
+	// then the command waits until a key is written or the timeout expires, when timeout writes the reply.
+	block   func(c *conn, args []string) bool
+	timeout func(w *respWriter)
+}
+
+var commands = map[string]*command{}
+
+func init() {
+	registerConnectionCommands()
+	registerStringCommands()
+	registerBitmapCommands()
+	registerHyperLogLogCommands()
+	registerKeyCommands()
+	registerHashCommands()
+	registerListCommands()
+	registerSetCommands()
+	registerSortedSetCommands()
+	registerGeoCommands()
+	registerJSONCommands()
+	registerSearchCommands()
+	registerTimeSeriesCommands()
+	registerProbabilisticCommands()
+	registerScriptingCommands()
+	registerPubSubCommands()
+	registerTransactionCommands()
+}
+
+func register(name string, cmd *command) {
+	commands[name] = cmd
+}
+
+func (c *command) has(flag commandFlag) bool {
+	return c.flags&flag != 0
+}
+
+func (c *command) checkArity(n int) bool {
+	if c.arity >= 0 {
+		return n == c.arity
+	}
+	return n >= -c.arity
+}
+
+func (c *command) keys(args []string) []string {
+	if c.firstKey == 0 {
+		return nil
+	}
+	last := c.lastKey
+	if last < 0 {
+		last = len(args) + last
+	}
+	step := c.step
+	if step == 0 {
+		step = 1
+	}
+	var keys []string
+	for i := c.firstKey; i <= last && i < len(args); i += step {
+		keys = append(keys, args[i])
+	}
+	return keys
+}
+
+func parseInt(s string) (int64, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, errNotInteger
+	}
+	return n, nil
+}
+
+func parseFloat(s string) (float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) {
+		return 0, errNotFloat
+	}
+	return f, nil
+}
+
+// parseTimeout parses the timeout in seconds of the blocking commands, zero blocks indefinitely.
+func parseTimeout(s string) (time.Duration, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 || math.IsInf(f, 0) {
+		return 0, errTimeout
+	}
+	return time.Duration(f * float64(time.Second)), nil
+}
+
+// parseRange converts the start and stop indexes of LRANGE, GETRANGE and ZRANGE, negative counts from the end,
+// returning false when the range is empty.
+func parseRange(startArg, stopArg string, size int) (int, int, bool, error) {
+	start, err := parseInt(startArg)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	stop, err := parseInt(stopArg)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	n := int64(size)
+	if start < 0 {
+		start += n
+	}
+	if stop < 0 {
+		stop += n
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= n {
+		stop = n - 1
+	}
+	if start > stop || start >= n {
+		return 0, 0, false, nil
+	}
+	return int(start), int(stop), true, nil
+}
+
+func equalFold(arg, option string) bool {
+	return strings.EqualFold(arg, option)
+}
@@ -0,0 +1,404 @@
+package redistest
+
+import (
+	"math/big"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+var errBitOffset = replyError("ERR bit offset is not an integer or out of range")
+var errBitValue = replyError("ERR bit is not an integer or out of range")
+var errBitFieldType = replyError("ERR Invalid bitfield type. Use something like i16 u8. Note that u64 is not " +
+	"supported but i64 is.")
+
+const maxBitOffset = 1<<32 - 1
+
+func registerBitmapCommands() {
+	register("setbit", &command{arity: 4, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdSetBit})
+	register("getbit", &command{arity: 3, firstKey: 1, lastKey: 1, handler: cmdGetBit})
+	register("bitcount", &command{arity: -2, firstKey: 1, lastKey: 1, handler: cmdBitCount})
+	register("bitpos", &command{arity: -3, firstKey: 1, lastKey: 1, handler: cmdBitPos})
+	register("bitop", &command{arity: -4, flags: flagWrite, firstKey: 2, lastKey: -1, handler: cmdBitOp})
+	register("bitfield", &command{arity: -2, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdBitField})
+}
+
+func cmdSetBit(c *conn, args []string) {
+	offset, err := parseBitOffset(args[2], 0)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	} else if args[3] != "0" && args[3] != "1" {
+		c.out.writeError(errBitValue.Error())
+		return
+	}
+	ks := c.db()
+	value, _, err := ks.getString(args[1])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	bitmap := growBitmap(value, offset+1)
+	previous := getBit(bitmap, offset)
+	setBit(bitmap, offset, args[3] == "1")
+	_, _, _, _ = ks.set(args[1], string(bitmap), setArgs{keepTTL: true})
+	c.out.writeInt(int64(previous))
+}
+
+func cmdGetBit(c *conn, args []string) {
+	offset, err := parseBitOffset(args[2], 0)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	value, _, err := c.db().getString(args[1])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	c.out.writeInt(int64(getBit([]byte(value), offset)))
+}
+
+func cmdBitCount(c *conn, args []string) {
+	value, _, err := c.db().getString(args[1])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	start, end, unit := int64(0), int64(len(value))*8-1, "BIT"
+	switch len(args) {
+	case 2:
+	case 4, 5:
+		if start, end, unit, err = parseBitRange(args[2:], value); err != nil {
+			c.out.writeError(err.Error())
+			return
+		}
+	default:
+		c.out.writeError(errSyntax.Error())
+		return
+	}
+	count := 0
+	for offset := start; offset <= end; offset++ {
+		if unit == "BYTE" {
+			count += bits.OnesCount8(value[offset])
+		} else {
+			count += getBit([]byte(value), offset)
+		}
+	}
+	c.out.writeInt(int64(count))
+}
+
+func cmdBitPos(c *conn, args []string) {
+	if args[2] != "0" && args[2] != "1" {
+		c.out.writeError("ERR The bit argument must be 1 or 0.")
+		return
+	} else if len(args) > 6 {
+		c.out.writeError(errSyntax.Error())
+		return
+	}
+	value, ok, err := c.db().getString(args[1])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	bit := int(args[2][0] - '0')
+	if !ok {
+		c.out.writeInt(int64(bit - 1))
+		return
+	}
+	rangeArgs := []string{"0", "-1"}
+	copy(rangeArgs, args[3:])
+	if len(args) > 5 {
+		rangeArgs = append(rangeArgs, args[5])
+	}
+	start, end, unit, err := parseBitRange(rangeArgs, value)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	if unit == "BYTE" {
+		start, end = start*8, end*8+7
+	}
+	for offset := start; offset <= end; offset++ {
+		if getBit([]byte(value), offset) == bit {
+			c.out.writeInt(offset)
+			return
+		}
+	}
+	if bit == 0 && len(args) < 5 && end >= start {
+		c.out.writeInt(end + 1)
+	} else {
+		c.out.writeInt(-1)
+	}
+}
+
+func cmdBitOp(c *conn, args []string) {
+	operation := strings.ToUpper(args[1])
+	if operation != "AND" && operation != "OR" && operation != "XOR" && operation != "NOT" {
+		c.out.writeError(errSyntax.Error())
+		return
+	} else if operation == "NOT" && len(args) != 4 {
+		c.out.writeError("ERR BITOP NOT must be called with a single source key.")
+		return
+	}
+	ks := c.db()
+	var sources []string
+	length := 0
+	for _, key := range args[3:] {
+		value, _, err := ks.getString(key)
+		if err != nil {
+			c.out.writeError(err.Error())
+			return
+		}
+		sources = append(sources, value)
+		length = max(length, len(value))
+	}
+	result := make([]byte, length)
+	for i := range result {
+		for j, source := range sources {
+			var b byte
+			if i < len(source) {
+				b = source[i]
+			}
+			switch {
+			case operation == "NOT":
+				result[i] = ^b
+			case j == 0:
+				result[i] = b
+			case operation == "AND":
+				result[i] &= b
+			case operation == "OR":
+				result[i] |= b
+			default:
+				result[i] ^= b
+			}
+		}
+	}
+	if length == 0 {
+		ks.del(args[2])
+	} else {
+		_, _, _, _ = ks.set(args[2], string(result), setArgs{})
+	}
+	c.out.writeInt(int64(length))
+}
+
+// bitField is a GET, SET or INCRBY sub-command of the BITFIELD command.
+type bitField struct {
+	name     string
+	signed   bool
+	bits     int
+	offset   int64
+	value    int64
+	overflow string
+}
+
+func cmdBitField(c *conn, args []string) {
+	var fields []bitField
+	overflow := "WRAP"
+	for i := 2; i < len(args); {
+		name := strings.ToUpper(args[i])
+		if name == "OVERFLOW" && i+1 < len(args) {
+			overflow = strings.ToUpper(args[i+1])
+			if overflow != "WRAP" && overflow != "SAT" && overflow != "FAIL" {
+				c.out.writeError("ERR Invalid OVERFLOW type specified")
+				return
+			}
+			i += 2
+			continue
+		}
+		n := 3
+		if name == "SET" || name == "INCRBY" {
+			n = 4
+		} else if name != "GET" {
+			c.out.writeError(errSyntax.Error())
+			return
+		}
+		if i+n > len(args) {
+			c.out.writeError(errSyntax.Error())
+			return
+		}
+		field, err := parseBitField(name, args[i+1:i+n], overflow)
+		if err != nil {
+			c.out.writeError(err.Error())
+			return
+		}
+		fields = append(fields, field)
+		i += n
+	}
+	ks := c.db()
+	value, _, err := ks.getString(args[1])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	bitmap := []byte(value)
+	written := false
+	c.out.writeArrayLen(len(fields))
+	for _, field := range fields {
+		current := getBitField(bitmap, field)
+		if field.name == "GET" {
+			c.out.writeInt(current)
+			continue
+		}
+		next := new(big.Int).SetInt64(field.value)
+		if field.name == "INCRBY" {
+			next.Add(next, big.NewInt(current))
+		}
+		result, ok := overflowBitField(next, field)
+		if !ok {
+			c.out.writeNull()
+			continue
+		}
+		bitmap = growBitmap(string(bitmap), field.offset+int64(field.bits))
+		setBitField(bitmap, field, result)
+		written = true
+		if field.name == "SET" {
+			c.out.writeInt(current)
+		} else {
+			c.out.writeInt(result)
+		}
+	}
+	if written {
+		_, _, _, _ = ks.set(args[1], string(bitmap), setArgs{keepTTL: true})
+	}
+}
+
+func parseBitField(name string, args []string, overflow string) (bitField, error) {
+	field := bitField{name: name, overflow: overflow}
+	typ := strings.ToLower(args[0])
+	n, err := strconv.Atoi(strings.TrimLeft(typ, "iu"))
+	if len(typ) < 2 || (typ[0] != 'i' && typ[0] != 'u') || err != nil || n < 1 || n > 64 || (typ[0] == 'u' && n > 63) {
+		return field, errBitFieldType
+	}
+	field.signed, field.bits = typ[0] == 'i', n
+	if field.offset, err = parseBitOffset(args[1], n); err != nil {
+		return field, err
+	}
+	if len(args) > 2 {
+		if field.value, err = parseInt(args[2]); err != nil {
+			return field, err
+		}
+	}
+	return field, nil
+}
+
+// parseBitOffset parses the offset of SETBIT, GETBIT and BITFIELD, the offsets prefixed with "#" are multiplied by
+// the width of the bitfield type.
+func parseBitOffset(s string, width int) (int64, error) {
+	multiplier := int64(1)
+	if width > 0 && strings.HasPrefix(s, "#") {
+		s, multiplier = s[1:], int64(width)
+	}
+	offset, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || offset < 0 || offset*multiplier > maxBitOffset {
+		return 0, errBitOffset
+	}
+	return offset * multiplier, nil
+}
+
+// parseBitRange parses the start, end and unit of BITCOUNT and BITPOS, returning the range limited to the value,
+// the end is lower than the start when the range is empty.
+func parseBitRange(args []string, value string) (int64, int64, string, error) {
+	start, err := parseInt(args[0])
+	if err != nil {
+		return 0, 0, "", err
+	}
+	end, err := parseInt(args[1])
+	if err != nil {
+		return 0, 0, "", err
+	}
+	unit := "BYTE"
+	if len(args) > 2 {
+		unit = strings.ToUpper(args[2])
+		if unit != "BYTE" && unit != "BIT" {
+			return 0, 0, "", errSyntax
+		}
+	}
+	size := int64(len(value))
+	if unit == "BIT" {
+		size *= 8
+	}
+	if start < 0 {
+		start = max(start+size, 0)
+	}
+	if end < 0 {
+		end = max(end+size, 0)
+	}
+	end = min(end, size-1)
+	if start > end {
+		return 0, -1, unit, nil
+	}
+	return start, end, unit, nil
+}
+
+// growBitmap returns the bytes of the bitmap with at least the number of bits.
+func growBitmap(value string, bits int64) []byte {
+	bitmap := []byte(value)
+	if size := int((bits + 7) / 8); size > len(bitmap) {
+		bitmap = append(bitmap, make([]byte, size-len(bitmap))...)
+	}
+	return bitmap
+}
+
+// getBit returns the bit at the offset, the first bit of the bitmap is the most significant bit of the first byte.
+func getBit(bitmap []byte, offset int64) int {
+	if offset/8 >= int64(len(bitmap)) {
+		return 0
+	}
+	return int(bitmap[offset/8]>>(7-offset%8)) & 1
+}
+
+func setBit(bitmap []byte, offset int64, value bool) {
+	if value {
+		bitmap[offset/8] |= 0x80 >> (offset % 8)
+	} else {
+		bitmap[offset/8] &^= 0x80 >> (offset % 8)
+	}
+}
+
+func getBitField(bitmap []byte, field bitField) int64 {
+	var value uint64
+	for i := 0; i < field.bits; i++ {
+		value = value<<1 | uint64(getBit(bitmap, field.offset+int64(i)))
+	}
+	if field.signed && field.bits < 64 && value&(1<<(field.bits-1)) != 0 {
+		value |= ^uint64(0) << field.bits
+	}
+	return int64(value)
+}
+
+func setBitField(bitmap []byte, field bitField, value int64) {
+	for i := 0; i < field.bits; i++ {
+		setBit(bitmap, field.offset+int64(i), uint64(value)>>(field.bits-1-i)&1 == 1)
+	}
+}
+
+// overflowBitField applies the overflow mode to the value, returning false if it is FAIL and the value is out of
+// the range of the type.
+func overflowBitField(value *big.Int, field bitField) (int64, bool) {
+	minValue, maxValue := big.NewInt(0), new(big.Int).Lsh(big.NewInt(1), uint(field.bits))
+	if field.signed {
+		minValue.Neg(new(big.Int).Lsh(big.NewInt(1), uint(field.bits-1)))
+		maxValue.Lsh(big.NewInt(1), uint(field.bits-1))
+	}
+	maxValue.Sub(maxValue, big.NewInt(1))
+	if value.Cmp(minValue) >= 0 && value.Cmp(maxValue) <= 0 {
+		return value.Int64(), true
+	}
+	switch field.overflow {
+	case "FAIL":
+		return 0, false
+	case "SAT":
+		if value.Sign() < 0 {
+			return minValue.Int64(), true
+		}
+		return maxValue.Int64(), true
+	default:
+		wrapped := new(big.Int).And(value, new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(field.bits)),
+			big.NewInt(1)))
+		if field.signed && wrapped.Bit(field.bits-1) == 1 {
+			wrapped.Sub(wrapped, new(big.Int).Lsh(big.NewInt(1), uint(field.bits)))
+		}
+		return wrapped.Int64(), true
+	}
+}
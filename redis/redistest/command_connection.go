@@ -0,0 +1,288 @@
+package redistest
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultVersion reported by the HELLO and INFO commands, see Server.SetVersion.
+const defaultVersion = "7.2.0"
+
+func registerConnectionCommands() {
+	register("hello", &command{arity: -1, flags: flagHandshake | flagNoQueue, handler: cmdHello})
+	register("auth", &command{arity: -2, flags: flagHandshake | flagNoQueue, handler: cmdAuth})
+	register("client", &command{arity: -2, flags: flagHandshake, handler: cmdClient})
+	register("select", &command{arity: 2, flags: flagHandshake, handler: cmdSelect})
+	register("ping", &command{arity: -1, flags: flagPubSub, handler: cmdPing})
+	register("echo", &command{arity: 2, handler: cmdEcho})
+	register("quit", &command{arity: -1, flags: flagPubSub | flagNoQueue, handler: cmdQuit})
+	register("reset", &command{arity: 1, flags: flagPubSub | flagNoQueue, handler: cmdReset})
+	register("info", &command{arity: -1, handler: cmdInfo})
+	register("time", &command{arity: 1, handler: cmdTime})
+	register("dbsize", &command{arity: 1, handler: cmdDBSize})
+	register("flushdb", &command{arity: -1, flags: flagWrite, handler: cmdFlushDB})
+	register("flushall", &command{arity: -1, flags: flagWrite, handler: cmdFlushAll})
+}
+
+func cmdHello(c *conn, args []string) {
+	protocol := c.out.protocol
+	if len(args) > 1 {
+		n, err := parseInt(args[1])
+		if err != nil {
+			c.out.writeError("ERR Protocol version is not an integer or out of range")
+			return
+		} else if n != 2 && n != 3 {
+			c.out.writeError("NOPROTO unsupported protocol version")
+			return
+		}
+		protocol = int(n)
+	}
+	for i := 2; i < len(args); i++ {
+		if equalFold(args[i], "AUTH") && i+2 < len(args) {
+			i += 2
+		} else if equalFold(args[i], "SETNAME") && i+1 < len(args) {
+			c.name = args[i+1]
+			i++
+		} else {
+			c.out.writeError(errSyntax.Error())
+			return
+		}
+	}
+	c.out.protocol = protocol
+	role := "master"
+	if c.server.isReadOnly() {
+		role = "replica"
+	}
+	c.out.writeMapLen(7)
+	c.out.writeBulk("server")
+	c.out.writeBulk("redis")
+	c.out.writeBulk("version")
+	c.out.writeBulk(c.server.redisVersion())
+	c.out.writeBulk("proto")
+	c.out.writeInt(int64(protocol))
+	c.out.writeBulk("id")
+	c.out.writeInt(c.id)
+	c.out.writeBulk("mode")
+	c.out.writeBulk("standalone")
+	c.out.writeBulk("role")
+	c.out.writeBulk(role)
+	c.out.writeBulk("modules")
+	c.out.writeArrayLen(0)
+}
+
+// cmdAuth accepts any credentials, the server has no ACL.
+func cmdAuth(c *conn, _ []string) {
+	c.out.writeOK()
+}
+
+func cmdClient(c *conn, args []string) {
+	switch strings.ToLower(args[1]) {
+	case "setname":
+		if len(args) != 3 {
+			c.out.writeError(errWrongArgs("client|setname").Error())
+			return
+		}
+		c.name = args[2]
+		c.out.writeOK()
+	case "getname":
+		if c.name == "" {
+			c.out.writeNull()
+		} else {
+			c.out.writeBulk(c.name)
+		}
+	case "id":
+		c.out.writeInt(c.id)
+	case "setinfo", "no-evict", "no-touch":
+		c.out.writeOK()
+	default:
+		c.out.writeError("ERR unknown subcommand '" + args[1] + "'. Try CLIENT HELP.")
+	}
+}
+
+func cmdSelect(c *conn, args []string) {
+	index, err := parseInt(args[1])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	} else if index < 0 || index > 15 {
+		c.out.writeError("ERR DB index is out of range")
+		return
+	}
+	c.index = int(index)
+	c.out.writeOK()
+}
+
+func cmdPing(c *conn, args []string) {
+	if len(args) > 2 {
+		c.out.writeError(errWrongArgs("ping").Error())
+		return
+	}
+	if c.subscribed() && !c.out.resp3() {
+		c.out.writeArrayLen(2)
+		c.out.writeBulk("pong")
+		if len(args) == 2 {
+			c.out.writeBulk(args[1])
+		} else {
+			c.out.writeBulk("")
+		}
+	} else if len(args) == 2 {
+		c.out.writeBulk(args[1])
+	} else {
+		c.out.writeSimple("PONG")
+	}
+}
+
+func cmdEcho(c *conn, args []string) {
+	c.out.writeBulk(args[1])
+}
+
+func cmdQuit(c *conn, _ []string) {
+	c.out.writeOK()
+	c.quit = true
+}
+
+func cmdReset(c *conn, _ []string) {
+	c.resetMulti()
+	c.server.unsubscribeAll(c, c.server.channels)
+	c.server.unsubscribeAll(c, c.server.patterns)
+	c.channels = map[string]struct{}{}
+	c.patterns = map[string]struct{}{}
+	c.index = 0
+	c.name = ""
+	c.out.protocol = 2
+	c.out.writeSimple("RESET")
+}
+
+func cmdInfo(c *conn, args []string) {
+	sections := map[string]bool{}
+	for _, arg := range args[1:] {
+		sections[strings.ToLower(arg)] = true
+	}
+	all := len(sections) == 0 || sections["all"] || sections["everything"] || sections["default"]
+	var b strings.Builder
+	section := func(name string, lines ...string) {
+		if !all && !sections[strings.ToLower(name)] {
+			return
+		}
+		if b.Len() > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString("# " + name + "\r\n")
+		for _, line := range lines {
+			b.WriteString(line + "\r\n")
+		}
+	}
+	s := c.server
+	_, port, _ := strings.Cut(s.Addr(), ":")
+	section("Server",
+		"redis_version:"+s.redisVersion(),
+		"redis_mode:standalone",
+		"tcp_port:"+port,
+		"uptime_in_seconds:"+strconv.FormatInt(int64(time.Since(s.startedAt).Seconds()), 10),
+	)
+	section("Clients",
+		"connected_clients:"+strconv.Itoa(s.Connections()),
+		"blocked_clients:0",
+	)
+	usedMemory := s.usedMemory()
+	section("Memory",
+		"used_memory:"+strconv.FormatInt(usedMemory, 10),
+		"used_memory_human:"+strconv.FormatFloat(float64(usedMemory)/1024/1024, 'f', 2, 64)+"M",
+		"maxmemory:0",
+		"maxmemory_policy:noeviction",
+	)
+	if s.isReadOnly() {
+		section("Replication",
+			"role:slave",
+			"master_host:127.0.0.1",
+			"master_port:0",
+			"master_link_status:up",
+			"master_last_io_seconds_ago:0",
+			"connected_slaves:0",
+		)
+	} else {
+		section("Replication",
+			"role:master",
+			"connected_slaves:0",
+		)
+	}
+	var keyspace []string
+	var indexes []int
+	for index := range s.store.databases {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	for _, index := range indexes {
+		ks := s.store.databases[index]
+		keys, expires := 0, 0
+		for _, key := range ks.sortedKeys() {
+			keys++
+			if !ks.entries[key].expireAt.IsZero() {
+				expires++
+			}
+		}
+		if keys > 0 {
+			keyspace = append(keyspace, fmt.Sprintf("db%d:keys=%d,expires=%d,avg_ttl=0", index, keys, expires))
+		}
+	}
+	section("Keyspace", keyspace...)
+	c.out.writeBulk(b.String())
+}
+
+func cmdTime(c *conn, _ []string) {
+	now := c.server.store.now()
+	c.out.writeStrings([]string{
+		strconv.FormatInt(now.Unix(), 10),
+		strconv.Itoa(now.Nanosecond() / 1000),
+	})
+}
+
+func cmdDBSize(c *conn, _ []string) {
+	c.out.writeInt(int64(c.db().size()))
+}
+
+func cmdFlushDB(c *conn, _ []string) {
+	c.db().flush()
+	c.out.writeOK()
+}
+
+func cmdFlushAll(c *conn, _ []string) {
+	for _, ks := range c.server.store.databases {
+		ks.flush()
+	}
+	c.out.writeOK()
+}
+
+// usedMemory estimates the memory used by the keys and values, it is reported by the INFO command.
+func (s *Server) usedMemory() int64 {
+	size := int64(1 << 20)
+	for _, ks := range s.store.databases {
+		for key, e := range ks.entries {
+			size += int64(len(key))
+			switch value := e.value.(type) {
+			case string:
+				size += int64(len(value))
+			case hashValue:
+				for field, v := range value {
+					size += int64(len(field) + len(v))
+				}
+			case *listValue:
+				for _, item := range value.items {
+					size += int64(len(item))
+				}
+			case setValue:
+				for member := range value {
+					size += int64(len(member))
+				}
+			case zsetValue:
+				for member := range value {
+					size += int64(len(member) + 8)
+				}
+			}
+		}
+	}
+	return size
+}
@@ -0,0 +1,410 @@
+package redistest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The positions are stored in sorted sets with the 52 bits geohash as the score, as redis does, so the coordinates
+// read back have the same precision.
+const (
+	geoStep         = 26
+	geoLongitudeMin = -180.0
+	geoLongitudeMax = 180.0
+	geoLatitudeMin  = -85.05112878
+	geoLatitudeMax  = 85.05112878
+	// geoEarthRadius in meters, the same used by redis.
+	geoEarthRadius = 6372797.560856
+)
+
+var errGeoMember = replyError("ERR could not decode requested zset member")
+var errGeoUnit = replyError("ERR unsupported unit provided. please use M, KM, FT, MI")
+
+// geoSearchArgs are the arguments of GEOSEARCH and GEOSEARCHSTORE.
+type geoSearchArgs struct {
+	longitude float64
+	latitude  float64
+	radius    float64
+	width     float64
+	height    float64
+	byBox     bool
+	unit      float64
+	sort      string
+	count     int64
+	withCoord bool
+	withDist  bool
+	withHash  bool
+	storeDist bool
+	hasCenter bool
+	hasArea   bool
+}
+
+// geoResult is a member found by GEOSEARCH.
+type geoResult struct {
+	member    string
+	score     float64
+	distance  float64
+	longitude float64
+	latitude  float64
+}
+
+func registerGeoCommands() {
+	register("geoadd", &command{arity: -5, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdGeoAdd})
+	register("geopos", &command{arity: -2, firstKey: 1, lastKey: 1, handler: cmdGeoPos})
+	register("geodist", &command{arity: -4, firstKey: 1, lastKey: 1, handler: cmdGeoDist})
+	register("geosearch", &command{arity: -7, firstKey: 1, lastKey: 1, handler: cmdGeoSearch})
+	register("geosearchstore", &command{arity: -8, flags: flagWrite, firstKey: 1, lastKey: 2,
+		handler: cmdGeoSearch})
+}
+
+func cmdGeoAdd(c *conn, args []string) {
+	var nx, xx, ch bool
+	i := 2
+	for ; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "NX":
+			nx = true
+			continue
+		case "XX":
+			xx = true
+			continue
+		case "CH":
+			ch = true
+			continue
+		}
+		break
+	}
+	triples := args[i:]
+	if len(triples) == 0 || len(triples)%3 != 0 || (nx && xx) {
+		c.out.writeError(errSyntax.Error())
+		return
+	}
+	scores := make([]float64, 0, len(triples)/3)
+	for j := 0; j < len(triples); j += 3 {
+		longitude, err1 := strconv.ParseFloat(triples[j], 64)
+		latitude, err2 := strconv.ParseFloat(triples[j+1], 64)
+		if err1 != nil || err2 != nil {
+			c.out.writeError(errNotFloat.Error())
+			return
+		} else if longitude < geoLongitudeMin || longitude > geoLongitudeMax || latitude < geoLatitudeMin ||
+			latitude > geoLatitudeMax {
+			c.out.writeError(fmt.Sprintf("ERR invalid longitude,latitude pair %f,%f", longitude, latitude))
+			return
+		}
+		scores = append(scores, geoEncode(longitude, latitude))
+	}
+	ks := c.db()
+	zset, err := ks.getZSet(args[1], !xx)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	var added, changed int64
+	for j, score := range scores {
+		member := triples[3*j+2]
+		current, exists := zset[member]
+		if (nx && exists) || (xx && !exists) {
+			continue
+		} else if !exists {
+			added++
+		} else if current != score {
+			changed++
+		}
+		zset[member] = score
+	}
+	ks.removeIfEmpty(args[1])
+	if ch {
+		c.out.writeInt(added + changed)
+	} else {
+		c.out.writeInt(added)
+	}
+}
+
+func cmdGeoPos(c *conn, args []string) {
+	zset, err := c.db().getZSet(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	c.out.writeArrayLen(len(args) - 2)
+	for _, member := range args[2:] {
+		score, ok := zset[member]
+		if !ok {
+			c.out.writeNullArray()
+			continue
+		}
+		longitude, latitude := geoDecode(score)
+		writeGeoCoord(c.out, longitude, latitude)
+	}
+}
+
+func cmdGeoDist(c *conn, args []string) {
+	if len(args) > 5 {
+		c.out.writeError(errSyntax.Error())
+		return
+	}
+	unit := 1.0
+	if len(args) == 5 {
+		var err error
+		if unit, err = parseGeoUnit(args[4]); err != nil {
+			c.out.writeError(err.Error())
+			return
+		}
+	}
+	zset, err := c.db().getZSet(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	score1, ok1 := zset[args[2]]
+	score2, ok2 := zset[args[3]]
+	if !ok1 || !ok2 {
+		c.out.writeNull()
+		return
+	}
+	longitude1, latitude1 := geoDecode(score1)
+	longitude2, latitude2 := geoDecode(score2)
+	c.out.writeBulk(formatGeoDistance(geoDistance(longitude1, latitude1, longitude2, latitude2) / unit))
+}
+
+func cmdGeoSearch(c *conn, args []string) {
+	store := equalFold(args[0], "geosearchstore")
+	source, first := args[1], 2
+	if store {
+		source, first = args[2], 3
+	}
+	ks := c.db()
+	zset, err := ks.getZSet(source, false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	sArgs, err := parseGeoSearch(args[first:], zset, store)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	results := geoSearch(zset, sArgs)
+	if store {
+		dest := zsetValue{}
+		for _, result := range results {
+			dest[result.member] = result.score
+			if sArgs.storeDist {
+				dest[result.member] = result.distance / sArgs.unit
+			}
+		}
+		ks.del(args[1])
+		if len(dest) > 0 {
+			ks.entries[args[1]] = &entry{value: dest}
+		}
+		c.out.writeInt(int64(len(dest)))
+		return
+	}
+	c.out.writeArrayLen(len(results))
+	for _, result := range results {
+		if !sArgs.withCoord && !sArgs.withDist && !sArgs.withHash {
+			c.out.writeBulk(result.member)
+			continue
+		}
+		n := 1
+		for _, with := range []bool{sArgs.withDist, sArgs.withHash, sArgs.withCoord} {
+			if with {
+				n++
+			}
+		}
+		c.out.writeArrayLen(n)
+		c.out.writeBulk(result.member)
+		if sArgs.withDist {
+			c.out.writeBulk(formatGeoDistance(result.distance / sArgs.unit))
+		}
+		if sArgs.withHash {
+			c.out.writeInt(int64(result.score))
+		}
+		if sArgs.withCoord {
+			writeGeoCoord(c.out, result.longitude, result.latitude)
+		}
+	}
+}
+
+func parseGeoSearch(args []string, zset zsetValue, store bool) (geoSearchArgs, error) {
+	var sArgs geoSearchArgs
+	var countAny bool
+	for i := 0; i < len(args); i++ {
+		remaining := len(args) - i - 1
+		switch arg := strings.ToUpper(args[i]); {
+		case arg == "FROMMEMBER" && remaining >= 1 && !sArgs.hasCenter:
+			score, ok := zset[args[i+1]]
+			if !ok {
+				return sArgs, errGeoMember
+			}
+			sArgs.longitude, sArgs.latitude = geoDecode(score)
+			sArgs.hasCenter = true
+			i++
+		case arg == "FROMLONLAT" && remaining >= 2 && !sArgs.hasCenter:
+			longitude, err1 := strconv.ParseFloat(args[i+1], 64)
+			latitude, err2 := strconv.ParseFloat(args[i+2], 64)
+			if err1 != nil || err2 != nil {
+				return sArgs, errNotFloat
+			}
+			sArgs.longitude, sArgs.latitude, sArgs.hasCenter = longitude, latitude, true
+			i += 2
+		case arg == "BYRADIUS" && remaining >= 2 && !sArgs.hasArea:
+			radius, err := parseFloat(args[i+1])
+			if err != nil || radius < 0 {
+				return sArgs, errNotFloat
+			} else if sArgs.unit, err = parseGeoUnit(args[i+2]); err != nil {
+				return sArgs, err
+			}
+			sArgs.radius, sArgs.hasArea = radius*sArgs.unit, true
+			i += 2
+		case arg == "BYBOX" && remaining >= 3 && !sArgs.hasArea:
+			width, err1 := parseFloat(args[i+1])
+			height, err2 := parseFloat(args[i+2])
+			if err1 != nil || err2 != nil || width < 0 || height < 0 {
+				return sArgs, errNotFloat
+			}
+			unit, err := parseGeoUnit(args[i+3])
+			if err != nil {
+				return sArgs, err
+			}
+			sArgs.width, sArgs.height, sArgs.unit, sArgs.byBox, sArgs.hasArea = width*unit, height*unit, unit, true, true
+			i += 3
+		case arg == "ASC" || arg == "DESC":
+			sArgs.sort = arg
+		case arg == "COUNT" && remaining >= 1:
+			count, err := parseInt(args[i+1])
+			if err != nil || count <= 0 {
+				return sArgs, replyError("ERR COUNT must be > 0")
+			}
+			sArgs.count = count
+			i++
+			if i+1 < len(args) && equalFold(args[i+1], "ANY") {
+				countAny = true
+				i++
+			}
+		case arg == "WITHCOORD" && !store:
+			sArgs.withCoord = true
+		case arg == "WITHDIST" && !store:
+			sArgs.withDist = true
+		case arg == "WITHHASH" && !store:
+			sArgs.withHash = true
+		case arg == "STOREDIST" && store:
+			sArgs.storeDist = true
+		default:
+			return sArgs, errSyntax
+		}
+	}
+	if !sArgs.hasCenter || !sArgs.hasArea {
+		return sArgs, errSyntax
+	} else if countAny && sArgs.count == 0 {
+		return sArgs, replyError("ERR the ANY argument requires COUNT argument")
+	} else if !countAny && sArgs.count > 0 && sArgs.sort == "" {
+		sArgs.sort = "ASC"
+	}
+	return sArgs, nil
+}
+
+// geoSearch returns the members in the area of the search, sorted by the members and then by the sort of the
+// search, limited to the count.
+func geoSearch(zset zsetValue, sArgs geoSearchArgs) []geoResult {
+	var results []geoResult
+	for member, score := range zset {
+		longitude, latitude := geoDecode(score)
+		distance := geoDistance(sArgs.longitude, sArgs.latitude, longitude, latitude)
+		if sArgs.byBox {
+			latDistance := geoDistance(sArgs.longitude, sArgs.latitude, sArgs.longitude, latitude)
+			lonDistance := geoDistance(sArgs.longitude, latitude, longitude, latitude)
+			if latDistance > sArgs.height/2 || lonDistance > sArgs.width/2 {
+				continue
+			}
+		} else if distance > sArgs.radius {
+			continue
+		}
+		results = append(results, geoResult{
+			member:    member,
+			score:     score,
+			distance:  distance,
+			longitude: longitude,
+			latitude:  latitude,
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].member < results[j].member
+	})
+	if sArgs.sort != "" {
+		sort.SliceStable(results, func(i, j int) bool {
+			if sArgs.sort == "DESC" {
+				return results[i].distance > results[j].distance
+			}
+			return results[i].distance < results[j].distance
+		})
+	}
+	if sArgs.count > 0 && int64(len(results)) > sArgs.count {
+		results = results[:sArgs.count]
+	}
+	return results
+}
+
+func parseGeoUnit(unit string) (float64, error) {
+	switch strings.ToLower(unit) {
+	case "m":
+		return 1, nil
+	case "km":
+		return 1000, nil
+	case "ft":
+		return 0.3048, nil
+	case "mi":
+		return 1609.34, nil
+	}
+	return 0, errGeoUnit
+}
+
+// geoEncode returns the 52 bits geohash of the position, with the latitude bits in the even positions and the
+// longitude bits in the odd positions.
+func geoEncode(longitude, latitude float64) float64 {
+	latOffset := uint64((latitude - geoLatitudeMin) / (geoLatitudeMax - geoLatitudeMin) * (1 << geoStep))
+	lonOffset := uint64((longitude - geoLongitudeMin) / (geoLongitudeMax - geoLongitudeMin) * (1 << geoStep))
+	latOffset, lonOffset = min(latOffset, 1<<geoStep-1), min(lonOffset, 1<<geoStep-1)
+	var hash uint64
+	for i := 0; i < geoStep; i++ {
+		hash |= (latOffset >> i & 1) << (2 * i)
+		hash |= (lonOffset >> i & 1) << (2*i + 1)
+	}
+	return float64(hash)
+}
+
+// geoDecode returns the center of the area of the geohash.
+func geoDecode(score float64) (float64, float64) {
+	hash := uint64(score)
+	var latOffset, lonOffset uint64
+	for i := 0; i < geoStep; i++ {
+		latOffset |= (hash >> (2 * i) & 1) << i
+		lonOffset |= (hash >> (2*i + 1) & 1) << i
+	}
+	latScale := (geoLatitudeMax - geoLatitudeMin) / (1 << geoStep)
+	lonScale := (geoLongitudeMax - geoLongitudeMin) / (1 << geoStep)
+	latitude := geoLatitudeMin + (float64(latOffset)+0.5)*latScale
+	longitude := geoLongitudeMin + (float64(lonOffset)+0.5)*lonScale
+	return max(min(longitude, geoLongitudeMax), geoLongitudeMin), max(min(latitude, geoLatitudeMax), geoLatitudeMin)
+}
+
+// geoDistance returns the distance in meters between the positions with the haversine formula.
+func geoDistance(longitude1, latitude1, longitude2, latitude2 float64) float64 {
+	lat1, lat2 := latitude1*math.Pi/180, latitude2*math.Pi/180
+	u := math.Sin((lat2 - lat1) / 2)
+	v := math.Sin((longitude2 - longitude1) * math.Pi / 180 / 2)
+	return 2 * geoEarthRadius * math.Asin(math.Sqrt(u*u+math.Cos(lat1)*math.Cos(lat2)*v*v))
+}
+
+func formatGeoDistance(distance float64) string {
+	return strconv.FormatFloat(distance, 'f', 4, 64)
+}
+
+func writeGeoCoord(w *respWriter, longitude, latitude float64) {
+	w.writeArrayLen(2)
+	w.writeBulk(strconv.FormatFloat(longitude, 'f', 17, 64))
+	w.writeBulk(strconv.FormatFloat(latitude, 'f', 17, 64))
+}
@@ -0,0 +1,246 @@
+package redistest
+
+import (
+	"sort"
+	"strconv"
+)
+
+func registerHashCommands() {
+	register("hset", &command{arity: -4, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdHSet})
+	register("hmset", &command{arity: -4, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdHSet})
+	register("hsetnx", &command{arity: 4, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdHSetNX})
+	register("hget", &command{arity: 3, firstKey: 1, lastKey: 1, handler: cmdHGet})
+	register("hmget", &command{arity: -3, firstKey: 1, lastKey: 1, handler: cmdHMGet})
+	register("hgetall", &command{arity: 2, firstKey: 1, lastKey: 1, handler: cmdHGetAll})
+	register("hdel", &command{arity: -3, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdHDel})
+	register("hexists", &command{arity: 3, firstKey: 1, lastKey: 1, handler: cmdHExists})
+	register("hlen", &command{arity: 2, firstKey: 1, lastKey: 1, handler: cmdHLen})
+	register("hstrlen", &command{arity: 3, firstKey: 1, lastKey: 1, handler: cmdHStrLen})
+	register("hkeys", &command{arity: 2, firstKey: 1, lastKey: 1, handler: cmdHKeys})
+	register("hvals", &command{arity: 2, firstKey: 1, lastKey: 1, handler: cmdHVals})
+	register("hincrby", &command{arity: 4, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdHIncrBy})
+	register("hincrbyfloat", &command{arity: 4, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdHIncrByFloat})
+	register("hscan", &command{arity: -3, firstKey: 1, lastKey: 1, handler: cmdHScan})
+}
+
+func cmdHSet(c *conn, args []string) {
+	if len(args)%2 != 0 {
+		c.out.writeError(errWrongArgs(args[0]).Error())
+		return
+	}
+	hash, err := c.db().getHash(args[1], true)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	var n int64
+	for i := 2; i < len(args); i += 2 {
+		if _, ok := hash[args[i]]; !ok {
+			n++
+		}
+		hash[args[i]] = args[i+1]
+	}
+	if equalFold(args[0], "hmset") {
+		c.out.writeOK()
+	} else {
+		c.out.writeInt(n)
+	}
+}
+
+func cmdHSetNX(c *conn, args []string) {
+	hash, err := c.db().getHash(args[1], true)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	} else if _, ok := hash[args[2]]; ok {
+		c.out.writeInt(0)
+		return
+	}
+	hash[args[2]] = args[3]
+	c.out.writeInt(1)
+}
+
+func cmdHGet(c *conn, args []string) {
+	hash, err := c.db().getHash(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+	} else if value, ok := hash[args[2]]; !ok {
+		c.out.writeNull()
+	} else {
+		c.out.writeBulk(value)
+	}
+}
+
+func cmdHMGet(c *conn, args []string) {
+	hash, err := c.db().getHash(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	c.out.writeArrayLen(len(args) - 2)
+	for _, field := range args[2:] {
+		if value, ok := hash[field]; ok {
+			c.out.writeBulk(value)
+		} else {
+			c.out.writeNull()
+		}
+	}
+}
+
+func cmdHGetAll(c *conn, args []string) {
+	hash, err := c.db().getHash(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	fields := hash.fields()
+	c.out.writeMapLen(len(fields))
+	for _, field := range fields {
+		c.out.writeBulk(field)
+		c.out.writeBulk(hash[field])
+	}
+}
+
+func cmdHDel(c *conn, args []string) {
+	ks := c.db()
+	hash, err := ks.getHash(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	var n int64
+	for _, field := range args[2:] {
+		if _, ok := hash[field]; ok {
+			delete(hash, field)
+			n++
+		}
+	}
+	ks.removeIfEmpty(args[1])
+	c.out.writeInt(n)
+}
+
+func cmdHExists(c *conn, args []string) {
+	hash, err := c.db().getHash(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	_, ok := hash[args[2]]
+	c.out.writeBool(ok)
+}
+
+func cmdHLen(c *conn, args []string) {
+	hash, err := c.db().getHash(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	c.out.writeInt(int64(len(hash)))
+}
+
+func cmdHStrLen(c *conn, args []string) {
+	hash, err := c.db().getHash(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	c.out.writeInt(int64(len(hash[args[2]])))
+}
+
+func cmdHKeys(c *conn, args []string) {
+	hash, err := c.db().getHash(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	c.out.writeStrings(hash.fields())
+}
+
+func cmdHVals(c *conn, args []string) {
+	hash, err := c.db().getHash(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	var values []string
+	for _, field := range hash.fields() {
+		values = append(values, hash[field])
+	}
+	c.out.writeStrings(values)
+}
+
+func cmdHIncrBy(c *conn, args []string) {
+	delta, err := parseInt(args[3])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	hash, err := c.db().getHash(args[1], true)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	var n int64
+	if value, ok := hash[args[2]]; ok {
+		if n, err = parseInt(value); err != nil {
+			c.out.writeError("ERR hash value is not an integer")
+			return
+		}
+	}
+	n += delta
+	hash[args[2]] = strconv.FormatInt(n, 10)
+	c.out.writeInt(n)
+}
+
+func cmdHIncrByFloat(c *conn, args []string) {
+	delta, err := parseFloat(args[3])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	hash, err := c.db().getHash(args[1], true)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	var f float64
+	if value, ok := hash[args[2]]; ok {
+		if f, err = parseFloat(value); err != nil {
+			c.out.writeError("ERR hash value is not a float")
+			return
+		}
+	}
+	result := formatFloat(f + delta)
+	hash[args[2]] = result
+	c.out.writeBulk(result)
+}
+
+// cmdHScan returns all fields in a single page, the cursor returned is always 0.
+func cmdHScan(c *conn, args []string) {
+	match, _, _, err := parseScanArgs(args[3:], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	hash, err := c.db().getHash(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	var page []string
+	for _, field := range hash.fields() {
+		if match == "" || matchGlob(match, field) {
+			page = append(page, field, hash[field])
+		}
+	}
+	writeScan(c.out, 0, page)
+}
+
+func (h hashValue) fields() []string {
+	fields := make([]string, 0, len(h))
+	for field := range h {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
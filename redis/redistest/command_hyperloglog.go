@@ -0,0 +1,111 @@
+package redistest
+
+import (
+	"encoding/binary"
+	"sort"
+	"strings"
+)
+
+// headerHyperLogLog prefixes the HyperLogLog strings, as redis does. The in-memory HyperLogLog keeps the members
+// instead of the registers, so the counts are exact.
+const headerHyperLogLog = "HYLL"
+
+var errNotHyperLogLog = replyError("WRONGTYPE Key is not a valid HyperLogLog string value.")
+
+func registerHyperLogLogCommands() {
+	register("pfadd", &command{arity: -2, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdPFAdd})
+	register("pfcount", &command{arity: -2, firstKey: 1, lastKey: -1, handler: cmdPFCount})
+	register("pfmerge", &command{arity: -2, flags: flagWrite, firstKey: 1, lastKey: -1, handler: cmdPFMerge})
+}
+
+func cmdPFAdd(c *conn, args []string) {
+	ks := c.db()
+	members, ok, err := getHyperLogLog(ks, args[1])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	changed := !ok
+	for _, member := range args[2:] {
+		if _, exists := members[member]; !exists {
+			members[member] = struct{}{}
+			changed = true
+		}
+	}
+	if changed {
+		_, _, _, _ = ks.set(args[1], encodeHyperLogLog(members), setArgs{keepTTL: true})
+	}
+	c.out.writeBool(changed)
+}
+
+func cmdPFCount(c *conn, args []string) {
+	union, err := unionHyperLogLog(c.db(), args[1:])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	c.out.writeInt(int64(len(union)))
+}
+
+func cmdPFMerge(c *conn, args []string) {
+	ks := c.db()
+	union, err := unionHyperLogLog(ks, args[1:])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	_, _, _, _ = ks.set(args[1], encodeHyperLogLog(union), setArgs{keepTTL: true})
+	c.out.writeOK()
+}
+
+// getHyperLogLog returns the members of the HyperLogLog of the key, empty if the key does not exist.
+func getHyperLogLog(ks *keyspace, key string) (setValue, bool, error) {
+	value, ok, err := ks.getString(key)
+	if err != nil {
+		return nil, false, err
+	} else if !ok {
+		return setValue{}, false, nil
+	} else if !strings.HasPrefix(value, headerHyperLogLog) {
+		return nil, false, errNotHyperLogLog
+	}
+	members := setValue{}
+	data := []byte(value[len(headerHyperLogLog):])
+	for len(data) > 0 {
+		size, n := binary.Uvarint(data)
+		if n <= 0 || uint64(len(data)-n) < size {
+			return nil, false, errNotHyperLogLog
+		}
+		members[string(data[n:n+int(size)])] = struct{}{}
+		data = data[n+int(size):]
+	}
+	return members, true, nil
+}
+
+func unionHyperLogLog(ks *keyspace, keys []string) (setValue, error) {
+	union := setValue{}
+	for _, key := range keys {
+		members, _, err := getHyperLogLog(ks, key)
+		if err != nil {
+			return nil, err
+		}
+		for member := range members {
+			union[member] = struct{}{}
+		}
+	}
+	return union, nil
+}
+
+// encodeHyperLogLog encodes the members sorted and prefixed by their length.
+func encodeHyperLogLog(members setValue) string {
+	sorted := make([]string, 0, len(members))
+	for member := range members {
+		sorted = append(sorted, member)
+	}
+	sort.Strings(sorted)
+	data := []byte(headerHyperLogLog)
+	for _, member := range sorted {
+		data = binary.AppendUvarint(data, uint64(len(member)))
+		data = append(data, member...)
+	}
+	return string(data)
+}
@@ -0,0 +1,598 @@
+package redistest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonValue is the document of a RedisJSON key, the numbers are kept as json.Number, so the integers are not
+// converted to floats.
+type jsonValue struct {
+	root any
+}
+
+// jsonPath is a JSONPath ($) or a legacy path (.), the subset supported is: the children by name (.name or
+// ['name']), the array indexes ([0] or [-1]), the wildcard (.* or [*]) and the recursive descent (..name).
+type jsonPath struct {
+	segments []jsonSegment
+	legacy   bool
+}
+
+type jsonSegment struct {
+	name      string
+	index     int
+	hasIndex  bool
+	wildcard  bool
+	recursive bool
+}
+
+// jsonRef is a value found by a path, with the functions to replace and to delete it in the document.
+type jsonRef struct {
+	get func() any
+	set func(value any)
+	del func()
+}
+
+var errJSONPath = replyError("ERR invalid JSONPath")
+var errJSONValue = replyError("ERR expected value")
+var errJSONNumber = replyError("ERR expected number")
+var errJSONRoot = replyError("ERR new objects must be created at the root")
+var errJSONNoKey = replyError("ERR could not perform this operation on a key that doesn't exist")
+
+func registerJSONCommands() {
+	register("json.set", &command{arity: -4, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdJSONSet})
+	register("json.get", &command{arity: -2, firstKey: 1, lastKey: 1, handler: cmdJSONGet})
+	register("json.mget", &command{arity: -3, firstKey: 1, lastKey: -2, handler: cmdJSONMGet})
+	register("json.del", &command{arity: -2, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdJSONDel})
+	register("json.arrappend", &command{arity: -4, flags: flagWrite, firstKey: 1, lastKey: 1,
+		handler: cmdJSONArrAppend})
+	register("json.numincrby", &command{arity: 4, flags: flagWrite, firstKey: 1, lastKey: 1,
+		handler: cmdJSONNumIncrBy})
+	register("json.merge", &command{arity: 4, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdJSONMerge})
+}
+
+func cmdJSONSet(c *conn, args []string) {
+	ks := c.db()
+	path, err := parseJSONPath(args[2])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	value, err := parseJSON(args[3])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	var nx, xx bool
+	for _, arg := range args[4:] {
+		switch strings.ToUpper(arg) {
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		default:
+			c.out.writeError(errSyntax.Error())
+			return
+		}
+	}
+	doc, err := getJSON(ks, args[1])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	} else if doc == nil {
+		if len(path.segments) > 0 {
+			c.out.writeError(errJSONRoot.Error())
+		} else if xx {
+			c.out.writeNull()
+		} else {
+			ks.lookup(args[1], func() any { return &jsonValue{root: value} })
+			c.out.writeOK()
+		}
+		return
+	}
+	refs := path.eval(ks, args[1], doc)
+	if len(refs) > 0 && !nx {
+		for _, ref := range refs {
+			ref.set(cloneJSON(value))
+		}
+		c.out.writeOK()
+	} else if len(refs) == 0 && !xx && path.create(ks, args[1], doc, value) {
+		c.out.writeOK()
+	} else {
+		c.out.writeNull()
+	}
+}
+
+func cmdJSONGet(c *conn, args []string) {
+	ks := c.db()
+	doc, err := getJSON(ks, args[1])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	} else if doc == nil {
+		c.out.writeNull()
+		return
+	}
+	var paths []string
+	for i := 2; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "INDENT", "NEWLINE", "SPACE":
+			i++
+		default:
+			paths = append(paths, args[i])
+		}
+	}
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	results := map[string]any{}
+	for _, path := range paths {
+		result, err := getJSONPath(ks, args[1], doc, path)
+		if err != nil {
+			c.out.writeError(err.Error())
+			return
+		}
+		results[path] = result
+	}
+	if len(paths) == 1 {
+		c.out.writeBulk(marshalJSON(results[paths[0]]))
+	} else {
+		c.out.writeBulk(marshalJSON(results))
+	}
+}
+
+func cmdJSONMGet(c *conn, args []string) {
+	ks := c.db()
+	path := args[len(args)-1]
+	if _, err := parseJSONPath(path); err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	keys := args[1 : len(args)-1]
+	c.out.writeArrayLen(len(keys))
+	for _, key := range keys {
+		doc, err := getJSON(ks, key)
+		if err != nil || doc == nil {
+			c.out.writeNull()
+			continue
+		}
+		result, err := getJSONPath(ks, key, doc, path)
+		if err != nil {
+			c.out.writeNull()
+			continue
+		}
+		c.out.writeBulk(marshalJSON(result))
+	}
+}
+
+func cmdJSONDel(c *conn, args []string) {
+	ks := c.db()
+	path := "."
+	if len(args) > 2 {
+		path = args[2]
+	}
+	parsed, err := parseJSONPath(path)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	doc, err := getJSON(ks, args[1])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	} else if doc == nil {
+		c.out.writeInt(0)
+		return
+	} else if len(parsed.segments) == 0 {
+		ks.del(args[1])
+		c.out.writeInt(1)
+		return
+	}
+	// the refs are deleted from the last, so the indexes of the arrays are still valid.
+	refs := parsed.eval(ks, args[1], doc)
+	for i := len(refs) - 1; i >= 0; i-- {
+		refs[i].del()
+	}
+	c.out.writeInt(int64(len(refs)))
+}
+
+func cmdJSONArrAppend(c *conn, args []string) {
+	ks := c.db()
+	path, doc, ok := jsonCommandArgs(c, args)
+	if !ok {
+		return
+	}
+	var values []any
+	for _, arg := range args[3:] {
+		value, err := parseJSON(arg)
+		if err != nil {
+			c.out.writeError(err.Error())
+			return
+		}
+		values = append(values, value)
+	}
+	var lengths []any
+	var last any
+	for _, ref := range path.eval(ks, args[1], doc) {
+		array, isArray := ref.get().([]any)
+		if !isArray {
+			lengths = append(lengths, nil)
+			continue
+		}
+		for _, value := range values {
+			array = append(array, cloneJSON(value))
+		}
+		ref.set(array)
+		last = int64(len(array))
+		lengths = append(lengths, last)
+	}
+	if !path.legacy {
+		c.out.writeArrayLen(len(lengths))
+		for _, length := range lengths {
+			writeJSONInt(c, length)
+		}
+	} else if last == nil {
+		c.out.writeError(fmt.Sprintf("ERR Path '%s' does not exist or not an array", args[2]))
+	} else {
+		writeJSONInt(c, last)
+	}
+}
+
+func cmdJSONNumIncrBy(c *conn, args []string) {
+	ks := c.db()
+	path, doc, ok := jsonCommandArgs(c, args)
+	if !ok {
+		return
+	}
+	value, err := parseJSON(args[3])
+	increment, isNumber := value.(json.Number)
+	if err != nil || !isNumber {
+		c.out.writeError(errJSONNumber.Error())
+		return
+	}
+	var results []any
+	var last any
+	for _, ref := range path.eval(ks, args[1], doc) {
+		number, isNumber := ref.get().(json.Number)
+		if !isNumber {
+			results = append(results, nil)
+			continue
+		}
+		last = addJSONNumbers(number, increment)
+		ref.set(last)
+		results = append(results, last)
+	}
+	if !path.legacy {
+		c.out.writeBulk(marshalJSON(append([]any{}, results...)))
+	} else if last == nil {
+		c.out.writeError(fmt.Sprintf("ERR Path '%s' does not exist or not a number", args[2]))
+	} else {
+		c.out.writeBulk(marshalJSON(last))
+	}
+}
+
+func cmdJSONMerge(c *conn, args []string) {
+	ks := c.db()
+	path, err := parseJSONPath(args[2])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	patch, err := parseJSON(args[3])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	doc, err := getJSON(ks, args[1])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	} else if doc == nil {
+		if len(path.segments) > 0 {
+			c.out.writeError(errJSONRoot.Error())
+			return
+		}
+		ks.lookup(args[1], func() any { return &jsonValue{root: mergeJSON(nil, patch)} })
+		c.out.writeOK()
+		return
+	}
+	refs := path.eval(ks, args[1], doc)
+	for _, ref := range refs {
+		ref.set(mergeJSON(ref.get(), cloneJSON(patch)))
+	}
+	if len(refs) == 0 && patch != nil {
+		path.create(ks, args[1], doc, mergeJSON(nil, patch))
+	}
+	c.out.writeOK()
+}
+
+// jsonCommandArgs parses the path of the commands that require the key to exist, writing the error otherwise.
+func jsonCommandArgs(c *conn, args []string) (jsonPath, *jsonValue, bool) {
+	path, err := parseJSONPath(args[2])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return path, nil, false
+	}
+	doc, err := getJSON(c.db(), args[1])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return path, nil, false
+	} else if doc == nil {
+		c.out.writeError(errJSONNoKey.Error())
+		return path, nil, false
+	}
+	return path, doc, true
+}
+
+func writeJSONInt(c *conn, value any) {
+	if n, ok := value.(int64); ok {
+		c.out.writeInt(n)
+	} else {
+		c.out.writeNull()
+	}
+}
+
+func getJSON(ks *keyspace, key string) (*jsonValue, error) {
+	value := ks.lookup(key, nil)
+	if value == nil {
+		return nil, nil
+	}
+	doc, ok := value.(*jsonValue)
+	if !ok {
+		return nil, errWrongType
+	}
+	return doc, nil
+}
+
+// getJSONPath returns the values found by a JSONPath as an array, or the first value found by a legacy path.
+func getJSONPath(ks *keyspace, key string, doc *jsonValue, path string) (any, error) {
+	parsed, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	refs := parsed.eval(ks, key, doc)
+	if parsed.legacy {
+		if len(refs) == 0 {
+			return nil, replyError(fmt.Sprintf("ERR Path '%s' does not exist", path))
+		}
+		return refs[0].get(), nil
+	}
+	values := []any{}
+	for _, ref := range refs {
+		values = append(values, ref.get())
+	}
+	return values, nil
+}
+
+func parseJSONPath(path string) (jsonPath, error) {
+	parsed := jsonPath{legacy: !strings.HasPrefix(path, "$")}
+	rest := strings.TrimPrefix(path, "$")
+	if parsed.legacy && rest != "." && !strings.HasPrefix(rest, ".") && !strings.HasPrefix(rest, "[") && rest != "" {
+		rest = "." + rest
+	} else if parsed.legacy && rest == "." {
+		rest = ""
+	}
+	for rest != "" {
+		var segment jsonSegment
+		if strings.HasPrefix(rest, "..") {
+			segment.recursive = true
+			rest = rest[2:]
+		} else if strings.HasPrefix(rest, ".") {
+			rest = rest[1:]
+		} else if !strings.HasPrefix(rest, "[") {
+			return parsed, errJSONPath
+		}
+		if strings.HasPrefix(rest, "[") {
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return parsed, errJSONPath
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				segment.name = inner[1 : len(inner)-1]
+			} else if inner == "*" {
+				segment.wildcard = true
+			} else if index, err := strconv.Atoi(inner); err == nil {
+				segment.index, segment.hasIndex = index, true
+			} else {
+				return parsed, errJSONPath
+			}
+		} else {
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			segment.name, rest = rest[:end], rest[end:]
+			if segment.name == "" {
+				return parsed, errJSONPath
+			} else if segment.name == "*" {
+				segment.name, segment.wildcard = "", true
+			}
+		}
+		parsed.segments = append(parsed.segments, segment)
+	}
+	return parsed, nil
+}
+
+// eval returns the values found by the path in the document of the key.
+func (p jsonPath) eval(ks *keyspace, key string, doc *jsonValue) []jsonRef {
+	refs := []jsonRef{{
+		get: func() any { return doc.root },
+		set: func(value any) { doc.root = value },
+		del: func() { ks.del(key) },
+	}}
+	for _, segment := range p.segments {
+		var next []jsonRef
+		for _, ref := range refs {
+			if segment.recursive {
+				for _, descendant := range jsonDescendants(ref) {
+					next = append(next, segment.children(descendant)...)
+				}
+			} else {
+				next = append(next, segment.children(ref)...)
+			}
+		}
+		refs = next
+	}
+	return refs
+}
+
+// create sets the value as a new field of the objects found by the parent of the path, returning false if the last
+// segment of the path is not a name or there is no object.
+func (p jsonPath) create(ks *keyspace, key string, doc *jsonValue, value any) bool {
+	if len(p.segments) == 0 {
+		return false
+	}
+	last := p.segments[len(p.segments)-1]
+	if last.recursive || last.wildcard || last.hasIndex {
+		return false
+	}
+	parent := jsonPath{segments: p.segments[:len(p.segments)-1], legacy: p.legacy}
+	created := false
+	for _, ref := range parent.eval(ks, key, doc) {
+		if object, ok := ref.get().(map[string]any); ok {
+			object[last.name] = cloneJSON(value)
+			created = true
+		}
+	}
+	return created
+}
+
+func (s jsonSegment) children(ref jsonRef) []jsonRef {
+	var children []jsonRef
+	switch value := ref.get().(type) {
+	case map[string]any:
+		if !s.wildcard {
+			if _, ok := value[s.name]; !ok || s.hasIndex {
+				return nil
+			}
+			return []jsonRef{jsonField(value, s.name)}
+		}
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			children = append(children, jsonField(value, name))
+		}
+	case []any:
+		if s.wildcard {
+			for i := range value {
+				children = append(children, jsonElement(ref, i))
+			}
+		} else if index := s.index; s.hasIndex {
+			if index < 0 {
+				index += len(value)
+			}
+			if index >= 0 && index < len(value) {
+				children = append(children, jsonElement(ref, index))
+			}
+		}
+	}
+	return children
+}
+
+func jsonField(object map[string]any, name string) jsonRef {
+	return jsonRef{
+		get: func() any { return object[name] },
+		set: func(value any) { object[name] = value },
+		del: func() { delete(object, name) },
+	}
+}
+
+func jsonElement(parent jsonRef, index int) jsonRef {
+	return jsonRef{
+		get: func() any { return parent.get().([]any)[index] },
+		set: func(value any) { parent.get().([]any)[index] = value },
+		del: func() {
+			array := parent.get().([]any)
+			parent.set(append(array[:index:index], array[index+1:]...))
+		},
+	}
+}
+
+// jsonDescendants returns the ref and all the values inside it, depth first.
+func jsonDescendants(ref jsonRef) []jsonRef {
+	refs := []jsonRef{ref}
+	for _, child := range (jsonSegment{wildcard: true}).children(ref) {
+		refs = append(refs, jsonDescendants(child)...)
+	}
+	return refs
+}
+
+func parseJSON(s string) (any, error) {
+	decoder := json.NewDecoder(strings.NewReader(s))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, errJSONValue
+	} else if _, err = decoder.Token(); err != io.EOF {
+		return nil, errJSONValue
+	}
+	return value, nil
+}
+
+func marshalJSON(value any) string {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(value)
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
+
+func cloneJSON(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		object := make(map[string]any, len(value))
+		for name, field := range value {
+			object[name] = cloneJSON(field)
+		}
+		return object
+	case []any:
+		array := make([]any, len(value))
+		for i, element := range value {
+			array[i] = cloneJSON(element)
+		}
+		return array
+	}
+	return value
+}
+
+// mergeJSON applies the patch to the target as the RFC 7396 (JSON Merge Patch).
+func mergeJSON(target, patch any) any {
+	fields, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	object, ok := target.(map[string]any)
+	if !ok {
+		object = map[string]any{}
+	}
+	for name, value := range fields {
+		if value == nil {
+			delete(object, name)
+		} else {
+			object[name] = mergeJSON(object[name], value)
+		}
+	}
+	return object
+}
+
+// addJSONNumbers adds the numbers keeping an integer when both are integers.
+func addJSONNumbers(a, b json.Number) json.Number {
+	intA, errA := a.Int64()
+	intB, errB := b.Int64()
+	if errA == nil && errB == nil {
+		return json.Number(strconv.FormatInt(intA+intB, 10))
+	}
+	floatA, _ := a.Float64()
+	floatB, _ := b.Float64()
+	return json.Number(strconv.FormatFloat(floatA+floatB, 'f', -1, 64))
+}
@@ -0,0 +1,208 @@
+package redistest
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+func registerKeyCommands() {
+	register("del", &command{arity: -2, flags: flagWrite, firstKey: 1, lastKey: -1, handler: cmdDel})
+	register("unlink", &command{arity: -2, flags: flagWrite, firstKey: 1, lastKey: -1, handler: cmdDel})
+	register("exists", &command{arity: -2, firstKey: 1, lastKey: -1, handler: cmdExists})
+	register("keys", &command{arity: 2, handler: cmdKeys})
+	register("scan", &command{arity: -2, handler: cmdScan})
+	register("rename", &command{arity: 3, flags: flagWrite, firstKey: 1, lastKey: 2, handler: cmdRename})
+	register("renamenx", &command{arity: 3, flags: flagWrite, firstKey: 1, lastKey: 2, handler: cmdRename})
+	register("expire", &command{arity: -3, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdExpire})
+	register("pexpire", &command{arity: -3, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdExpire})
+	register("expireat", &command{arity: -3, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdExpire})
+	register("pexpireat", &command{arity: -3, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdExpire})
+	register("ttl", &command{arity: 2, firstKey: 1, lastKey: 1, handler: cmdTTL})
+	register("pttl", &command{arity: 2, firstKey: 1, lastKey: 1, handler: cmdTTL})
+	register("expiretime", &command{arity: 2, firstKey: 1, lastKey: 1, handler: cmdExpireTime})
+	register("pexpiretime", &command{arity: 2, firstKey: 1, lastKey: 1, handler: cmdExpireTime})
+	register("persist", &command{arity: 2, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdPersist})
+	register("type", &command{arity: 2, firstKey: 1, lastKey: 1, handler: cmdType})
+}
+
+func cmdDel(c *conn, args []string) {
+	ks := c.db()
+	var n int64
+	for _, key := range args[1:] {
+		if ks.del(key) {
+			n++
+		}
+	}
+	c.out.writeInt(n)
+}
+
+func cmdExists(c *conn, args []string) {
+	ks := c.db()
+	var n int64
+	for _, key := range args[1:] {
+		if ks.exists(key) {
+			n++
+		}
+	}
+	c.out.writeInt(n)
+}
+
+func cmdKeys(c *conn, args []string) {
+	c.out.writeStrings(c.db().keys(args[1]))
+}
+
+func cmdScan(c *conn, args []string) {
+	cursor, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		c.out.writeError("ERR invalid cursor")
+		return
+	}
+	match, count, typ, err := parseScanArgs(args[2:], true)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	ks := c.db()
+	page, next := ks.scan(cursor, match, count)
+	if typ != "" {
+		var filtered []string
+		for _, key := range page {
+			if equalFold(ks.typeOf(key), typ) {
+				filtered = append(filtered, key)
+			}
+		}
+		page = filtered
+	}
+	writeScan(c.out, next, page)
+}
+
+func cmdRename(c *conn, args []string) {
+	ks := c.db()
+	nx := equalFold(args[0], "renamenx")
+	if !ks.exists(args[1]) {
+		c.out.writeError(errNoSuchKey.Error())
+		return
+	} else if nx && ks.exists(args[2]) {
+		c.out.writeInt(0)
+		return
+	}
+	_ = ks.rename(args[1], args[2])
+	if nx {
+		c.out.writeInt(1)
+	} else {
+		c.out.writeOK()
+	}
+}
+
+func cmdExpire(c *conn, args []string) {
+	n, err := parseInt(args[2])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	ks := c.db()
+	e := ks.get(args[1])
+	if e == nil {
+		c.out.writeInt(0)
+		return
+	}
+	name := strings.ToUpper(args[0])
+	unit := map[string]string{"EXPIRE": "EX", "PEXPIRE": "PX", "EXPIREAT": "EXAT", "PEXPIREAT": "PXAT"}[name]
+	at := expireAt(unit, n, c.server.store.now())
+	for _, arg := range args[3:] {
+		var ok bool
+		switch strings.ToUpper(arg) {
+		case "NX":
+			ok = e.expireAt.IsZero()
+		case "XX":
+			ok = !e.expireAt.IsZero()
+		case "GT":
+			ok = !e.expireAt.IsZero() && at.After(e.expireAt)
+		case "LT":
+			ok = e.expireAt.IsZero() || at.Before(e.expireAt)
+		default:
+			c.out.writeError("ERR Unsupported option " + arg)
+			return
+		}
+		if !ok {
+			c.out.writeInt(0)
+			return
+		}
+	}
+	ks.expire(args[1], at)
+	c.out.writeInt(1)
+}
+
+func cmdTTL(c *conn, args []string) {
+	ttl, ok := c.db().ttl(args[1])
+	if !ok {
+		c.out.writeInt(-2)
+	} else if ttl < 0 {
+		c.out.writeInt(-1)
+	} else if equalFold(args[0], "pttl") {
+		c.out.writeInt(ttl.Milliseconds())
+	} else {
+		c.out.writeInt(int64((ttl + 500*time.Millisecond) / time.Second))
+	}
+}
+
+func cmdExpireTime(c *conn, args []string) {
+	e := c.db().get(args[1])
+	if e == nil {
+		c.out.writeInt(-2)
+	} else if e.expireAt.IsZero() {
+		c.out.writeInt(-1)
+	} else if equalFold(args[0], "pexpiretime") {
+		c.out.writeInt(e.expireAt.UnixMilli())
+	} else {
+		c.out.writeInt(e.expireAt.Unix())
+	}
+}
+
+func cmdPersist(c *conn, args []string) {
+	e := c.db().get(args[1])
+	if e == nil || e.expireAt.IsZero() {
+		c.out.writeInt(0)
+		return
+	}
+	e.expireAt = time.Time{}
+	c.out.writeInt(1)
+}
+
+func cmdType(c *conn, args []string) {
+	c.out.writeSimple(c.db().typeOf(args[1]))
+}
+
+// parseScanArgs parses the MATCH, COUNT and, when allowed, TYPE arguments of the SCAN family.
+func parseScanArgs(args []string, allowType bool) (match string, count int64, typ string, err error) {
+	for i := 0; i < len(args); i += 2 {
+		if i+1 >= len(args) {
+			return "", 0, "", errSyntax
+		}
+		switch strings.ToUpper(args[i]) {
+		case "MATCH":
+			match = args[i+1]
+		case "COUNT":
+			if count, err = parseInt(args[i+1]); err != nil {
+				return "", 0, "", err
+			} else if count < 1 {
+				return "", 0, "", errSyntax
+			}
+		case "TYPE":
+			if !allowType {
+				return "", 0, "", errSyntax
+			}
+			typ = args[i+1]
+		default:
+			return "", 0, "", errSyntax
+		}
+	}
+	return match, count, typ, nil
+}
+
+func writeScan(w *respWriter, cursor uint64, page []string) {
+	w.writeArrayLen(2)
+	w.writeBulk(strconv.FormatUint(cursor, 10))
+	w.writeStrings(page)
+}
@@ -0,0 +1,362 @@
+package redistest
+
+import (
+	"strings"
+)
+
+func registerListCommands() {
+	register("lpush", &command{arity: -3, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdPush})
+	register("rpush", &command{arity: -3, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdPush})
+	register("lpushx", &command{arity: -3, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdPush})
+	register("rpushx", &command{arity: -3, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdPush})
+	register("lpop", &command{arity: -2, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdPop})
+	register("rpop", &command{arity: -2, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdPop})
+	register("llen", &command{arity: 2, firstKey: 1, lastKey: 1, handler: cmdLLen})
+	register("lrange", &command{arity: 4, firstKey: 1, lastKey: 1, handler: cmdLRange})
+	register("lindex", &command{arity: 3, firstKey: 1, lastKey: 1, handler: cmdLIndex})
+	register("lset", &command{arity: 4, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdLSet})
+	register("lrem", &command{arity: 4, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdLRem})
+	register("ltrim", &command{arity: 4, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdLTrim})
+	register("linsert", &command{arity: 5, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdLInsert})
+	register("lmove", &command{arity: 5, flags: flagWrite, firstKey: 1, lastKey: 2, handler: cmdLMove})
+	register("rpoplpush", &command{arity: 3, flags: flagWrite, firstKey: 1, lastKey: 2, handler: cmdLMove})
+	register("blmove", &command{arity: 6, flags: flagWrite, firstKey: 1, lastKey: 2, block: blockLMove,
+		timeout: (*respWriter).writeNull})
+	register("brpoplpush", &command{arity: 4, flags: flagWrite, firstKey: 1, lastKey: 2, block: blockLMove,
+		timeout: (*respWriter).writeNull})
+	register("blpop", &command{arity: -3, flags: flagWrite, firstKey: 1, lastKey: -2, block: blockPop,
+		timeout: (*respWriter).writeNullArray})
+	register("brpop", &command{arity: -3, flags: flagWrite, firstKey: 1, lastKey: -2, block: blockPop,
+		timeout: (*respWriter).writeNullArray})
+}
+
+func cmdPush(c *conn, args []string) {
+	name := strings.ToLower(args[0])
+	ks := c.db()
+	list, err := ks.getList(args[1], !strings.HasSuffix(name, "x"))
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	} else if list == nil {
+		c.out.writeInt(0)
+		return
+	}
+	for _, item := range args[2:] {
+		list.push(name[0] == 'l', item)
+	}
+	c.out.writeInt(int64(len(list.items)))
+}
+
+func cmdPop(c *conn, args []string) {
+	if len(args) > 3 {
+		c.out.writeError(errSyntax.Error())
+		return
+	}
+	count := int64(-1)
+	if len(args) == 3 {
+		var err error
+		if count, err = parseInt(args[2]); err != nil || count < 0 {
+			c.out.writeError("ERR value is out of range, must be positive")
+			return
+		}
+	}
+	ks := c.db()
+	list, err := ks.getList(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	} else if list == nil {
+		if count < 0 {
+			c.out.writeNull()
+		} else {
+			c.out.writeNullArray()
+		}
+		return
+	}
+	left := equalFold(args[0], "lpop")
+	if count < 0 {
+		item, _ := list.pop(left)
+		c.out.writeBulk(item)
+	} else {
+		var items []string
+		for i := int64(0); i < count && len(list.items) > 0; i++ {
+			item, _ := list.pop(left)
+			items = append(items, item)
+		}
+		c.out.writeStrings(items)
+	}
+	ks.removeIfEmpty(args[1])
+}
+
+func cmdLLen(c *conn, args []string) {
+	list, err := c.db().getList(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+	} else if list == nil {
+		c.out.writeInt(0)
+	} else {
+		c.out.writeInt(int64(len(list.items)))
+	}
+}
+
+func cmdLRange(c *conn, args []string) {
+	list, err := c.db().getList(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	} else if list == nil {
+		list = &listValue{}
+	}
+	start, stop, ok, err := parseRange(args[2], args[3], len(list.items))
+	if err != nil {
+		c.out.writeError(err.Error())
+	} else if !ok {
+		c.out.writeStrings(nil)
+	} else {
+		c.out.writeStrings(list.items[start : stop+1])
+	}
+}
+
+func cmdLIndex(c *conn, args []string) {
+	index, err := parseInt(args[2])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	list, err := c.db().getList(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+	} else if i, ok := list.index(index); !ok {
+		c.out.writeNull()
+	} else {
+		c.out.writeBulk(list.items[i])
+	}
+}
+
+func cmdLSet(c *conn, args []string) {
+	index, err := parseInt(args[2])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	list, err := c.db().getList(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+	} else if list == nil {
+		c.out.writeError(errNoSuchKey.Error())
+	} else if i, ok := list.index(index); !ok {
+		c.out.writeError("ERR index out of range")
+	} else {
+		list.items[i] = args[3]
+		c.out.writeOK()
+	}
+}
+
+func cmdLRem(c *conn, args []string) {
+	count, err := parseInt(args[2])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	ks := c.db()
+	list, err := ks.getList(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	} else if list == nil {
+		c.out.writeInt(0)
+		return
+	}
+	var removed int64
+	limit := count
+	if limit < 0 {
+		limit = -limit
+	}
+	remove := func(i int) bool {
+		if list.items[i] == args[3] && (limit == 0 || removed < limit) {
+			removed++
+			return true
+		}
+		return false
+	}
+	var kept []string
+	if count >= 0 {
+		for i := range list.items {
+			if !remove(i) {
+				kept = append(kept, list.items[i])
+			}
+		}
+	} else {
+		for i := len(list.items) - 1; i >= 0; i-- {
+			if !remove(i) {
+				kept = append([]string{list.items[i]}, kept...)
+			}
+		}
+	}
+	list.items = kept
+	ks.removeIfEmpty(args[1])
+	c.out.writeInt(removed)
+}
+
+func cmdLTrim(c *conn, args []string) {
+	ks := c.db()
+	list, err := ks.getList(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	} else if list == nil {
+		c.out.writeOK()
+		return
+	}
+	start, stop, ok, err := parseRange(args[2], args[3], len(list.items))
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	} else if !ok {
+		list.items = nil
+	} else {
+		list.items = append([]string(nil), list.items[start:stop+1]...)
+	}
+	ks.removeIfEmpty(args[1])
+	c.out.writeOK()
+}
+
+func cmdLInsert(c *conn, args []string) {
+	before := equalFold(args[2], "before")
+	if !before && !equalFold(args[2], "after") {
+		c.out.writeError(errSyntax.Error())
+		return
+	}
+	list, err := c.db().getList(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	} else if list == nil {
+		c.out.writeInt(0)
+		return
+	}
+	for i, item := range list.items {
+		if item != args[3] {
+			continue
+		}
+		if !before {
+			i++
+		}
+		list.items = append(list.items[:i], append([]string{args[4]}, list.items[i:]...)...)
+		c.out.writeInt(int64(len(list.items)))
+		return
+	}
+	c.out.writeInt(-1)
+}
+
+func cmdLMove(c *conn, args []string) {
+	if _, err := lMove(c, args); err != nil {
+		c.out.writeError(err.Error())
+	}
+}
+
+func blockLMove(c *conn, args []string) bool {
+	moved, err := lMove(c, args)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return true
+	}
+	return moved
+}
+
+// lMove executes the LMOVE, RPOPLPUSH, BLMOVE and BRPOPLPUSH commands, the reply is written only when an element
+// is moved or an error occurs, returns false if the source list is empty.
+func lMove(c *conn, args []string) (bool, error) {
+	fromLeft, toLeft := false, true
+	name := strings.ToLower(args[0])
+	if name == "lmove" || name == "blmove" {
+		var err error
+		if fromLeft, err = parseDirection(args[3]); err != nil {
+			return false, err
+		} else if toLeft, err = parseDirection(args[4]); err != nil {
+			return false, err
+		}
+	}
+	ks := c.db()
+	source, err := ks.getList(args[1], false)
+	if err != nil {
+		return false, err
+	} else if _, err = ks.getList(args[2], false); err != nil {
+		return false, err
+	} else if source == nil {
+		if !strings.HasPrefix(name, "b") {
+			c.out.writeNull()
+			return true, nil
+		}
+		return false, nil
+	}
+	item, _ := source.pop(fromLeft)
+	ks.removeIfEmpty(args[1])
+	destination, _ := ks.getList(args[2], true)
+	destination.push(toLeft, item)
+	c.out.writeBulk(item)
+	return true, nil
+}
+
+func blockPop(c *conn, args []string) bool {
+	ks := c.db()
+	left := equalFold(args[0], "blpop")
+	for _, key := range args[1 : len(args)-1] {
+		list, err := ks.getList(key, false)
+		if err != nil {
+			c.out.writeError(err.Error())
+			return true
+		} else if list == nil {
+			continue
+		}
+		item, _ := list.pop(left)
+		ks.removeIfEmpty(key)
+		c.out.writeStrings([]string{key, item})
+		return true
+	}
+	return false
+}
+
+func parseDirection(arg string) (bool, error) {
+	if equalFold(arg, "left") {
+		return true, nil
+	} else if equalFold(arg, "right") {
+		return false, nil
+	}
+	return false, errSyntax
+}
+
+func (l *listValue) push(left bool, item string) {
+	if left {
+		l.items = append([]string{item}, l.items...)
+	} else {
+		l.items = append(l.items, item)
+	}
+}
+
+func (l *listValue) pop(left bool) (string, bool) {
+	if len(l.items) == 0 {
+		return "", false
+	}
+	var item string
+	if left {
+		item, l.items = l.items[0], l.items[1:]
+	} else {
+		item, l.items = l.items[len(l.items)-1], l.items[:len(l.items)-1]
+	}
+	return item, true
+}
+
+// index converts the index of LINDEX and LSET, negative counts from the end.
+func (l *listValue) index(index int64) (int, bool) {
+	if l == nil {
+		return 0, false
+	}
+	if index < 0 {
+		index += int64(len(l.items))
+	}
+	if index < 0 || index >= int64(len(l.items)) {
+		return 0, false
+	}
+	return int(index), true
+}
@@ -0,0 +1,584 @@
+package redistest
+
+import (
+	"math"
+	"sort"
+)
+
+// bloomFilter is a RedisBloom Bloom filter key. The in-memory filter keeps the items instead of the bits, so there
+// are no false positives, as the in-memory HyperLogLog.
+type bloomFilter struct {
+	capacity   int64
+	errorRate  float64
+	nonScaling bool
+	items      setValue
+}
+
+// cuckooFilter is a RedisBloom cuckoo filter key, the items are counted since a cuckoo filter accepts the same item
+// more than once.
+type cuckooFilter struct {
+	capacity int64
+	items    map[string]int64
+}
+
+// countMinSketch is a RedisBloom Count-Min Sketch key, with the exact counts of the items.
+type countMinSketch struct {
+	width  int64
+	depth  int64
+	counts map[string]int64
+}
+
+// topK is a RedisBloom Top-K key, with the exact counts of the items, the ties are ranked by the order the items
+// were first added.
+type topK struct {
+	k      int
+	counts map[string]int64
+	order  map[string]int
+}
+
+var errItemExists = replyError("ERR item exists")
+var errNotFound = replyError("ERR not found")
+var errBloomFull = replyError("ERR non scaling filter is full")
+var errBloomBadRate = replyError("ERR (0 < error rate range < 1)")
+var errBloomBadCapacity = replyError("ERR (capacity should be larger than 0)")
+var errCMSKeyExists = replyError("CMS: key already exists")
+var errCMSKeyNotExists = replyError("CMS: key does not exist")
+var errTopKKeyExists = replyError("TopK: key already exists")
+var errTopKKeyNotExists = replyError("TopK: key does not exist")
+
+// defaultBloomCapacity and defaultBloomErrorRate are the parameters of the filters created by BF.ADD, as the
+// RedisBloom defaults, and defaultCuckooCapacity the capacity of the filters created by CF.ADD.
+const (
+	defaultBloomCapacity  = 100
+	defaultBloomErrorRate = 0.01
+	defaultCuckooCapacity = 1024
+)
+
+func registerProbabilisticCommands() {
+	register("bf.reserve", &command{arity: -4, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdBFReserve})
+	register("bf.add", &command{arity: 3, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdBFAdd})
+	register("bf.madd", &command{arity: -3, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdBFAdd})
+	register("bf.insert", &command{arity: -4, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdBFInsert})
+	register("bf.exists", &command{arity: 3, firstKey: 1, lastKey: 1, handler: cmdBFExists})
+	register("bf.mexists", &command{arity: -3, firstKey: 1, lastKey: 1, handler: cmdBFExists})
+	register("cf.reserve", &command{arity: -3, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdCFReserve})
+	register("cf.add", &command{arity: 3, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdCFAdd})
+	register("cf.addnx", &command{arity: 3, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdCFAdd})
+	register("cf.del", &command{arity: 3, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdCFDel})
+	register("cf.exists", &command{arity: 3, firstKey: 1, lastKey: 1, handler: cmdCFExists})
+	register("cf.mexists", &command{arity: -3, firstKey: 1, lastKey: 1, handler: cmdCFExists})
+	register("cf.count", &command{arity: 3, firstKey: 1, lastKey: 1, handler: cmdCFCount})
+	register("cms.initbydim", &command{arity: 4, flags: flagWrite, firstKey: 1, lastKey: 1,
+		handler: cmdCMSInitByDim})
+	register("cms.initbyprob", &command{arity: 4, flags: flagWrite, firstKey: 1, lastKey: 1,
+		handler: cmdCMSInitByProb})
+	register("cms.incrby", &command{arity: -4, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdCMSIncrBy})
+	register("cms.query", &command{arity: -3, firstKey: 1, lastKey: 1, handler: cmdCMSQuery})
+	register("topk.reserve", &command{arity: -3, flags: flagWrite, firstKey: 1, lastKey: 1,
+		handler: cmdTopKReserve})
+	register("topk.add", &command{arity: -3, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdTopKAdd})
+	register("topk.incrby", &command{arity: -4, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdTopKAdd})
+	register("topk.query", &command{arity: -3, firstKey: 1, lastKey: 1, handler: cmdTopKQuery})
+	register("topk.list", &command{arity: -2, firstKey: 1, lastKey: 1, handler: cmdTopKList})
+}
+
+func cmdBFReserve(c *conn, args []string) {
+	ks := c.db()
+	errorRate, err := parseFloat(args[2])
+	if err != nil || errorRate <= 0 || errorRate >= 1 {
+		c.out.writeError(errBloomBadRate.Error())
+		return
+	}
+	capacity, err := parseInt(args[3])
+	if err != nil || capacity <= 0 {
+		c.out.writeError(errBloomBadCapacity.Error())
+		return
+	}
+	filter := &bloomFilter{capacity: capacity, errorRate: errorRate, items: setValue{}}
+	for i := 4; i < len(args); i++ {
+		switch {
+		case equalFold(args[i], "NONSCALING"):
+			filter.nonScaling = true
+		case equalFold(args[i], "EXPANSION") && i+1 < len(args):
+			if _, err = parseInt(args[i+1]); err != nil {
+				c.out.writeError(err.Error())
+				return
+			}
+			i++
+		default:
+			c.out.writeError(errSyntax.Error())
+			return
+		}
+	}
+	if ks.exists(args[1]) {
+		c.out.writeError(errItemExists.Error())
+		return
+	}
+	ks.lookup(args[1], func() any { return filter })
+	c.out.writeOK()
+}
+
+func cmdBFAdd(c *conn, args []string) {
+	ks := c.db()
+	filter, err := getBloomFilter(ks, args[1])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	} else if filter == nil {
+		filter = ks.lookup(args[1], func() any {
+			return &bloomFilter{capacity: defaultBloomCapacity, errorRate: defaultBloomErrorRate, items: setValue{}}
+		}).(*bloomFilter)
+	}
+	writeBloomAdd(c, filter, args[2:], equalFold(args[0], "bf.madd"))
+}
+
+func cmdBFInsert(c *conn, args []string) {
+	ks := c.db()
+	filter := &bloomFilter{capacity: defaultBloomCapacity, errorRate: defaultBloomErrorRate, items: setValue{}}
+	noCreate := false
+	i := 2
+	for ; i < len(args) && !equalFold(args[i], "ITEMS"); i++ {
+		var err error
+		switch {
+		case equalFold(args[i], "NOCREATE"):
+			noCreate = true
+		case equalFold(args[i], "NONSCALING"):
+			filter.nonScaling = true
+		case equalFold(args[i], "CAPACITY") && i+1 < len(args):
+			filter.capacity, err = parseInt(args[i+1])
+			i++
+		case equalFold(args[i], "ERROR") && i+1 < len(args):
+			filter.errorRate, err = parseFloat(args[i+1])
+			i++
+		case equalFold(args[i], "EXPANSION") && i+1 < len(args):
+			_, err = parseInt(args[i+1])
+			i++
+		default:
+			err = errSyntax
+		}
+		if err != nil {
+			c.out.writeError(err.Error())
+			return
+		}
+	}
+	if i+1 >= len(args) {
+		c.out.writeError(errSyntax.Error())
+		return
+	}
+	current, err := getBloomFilter(ks, args[1])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	} else if current == nil && noCreate {
+		c.out.writeError(errNotFound.Error())
+		return
+	} else if current == nil {
+		current = ks.lookup(args[1], func() any { return filter }).(*bloomFilter)
+	}
+	writeBloomAdd(c, current, args[i+1:], true)
+}
+
+func cmdBFExists(c *conn, args []string) {
+	filter, err := getBloomFilter(c.db(), args[1])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	multi := equalFold(args[0], "bf.mexists")
+	if multi {
+		c.out.writeArrayLen(len(args) - 2)
+	}
+	for _, item := range args[2:] {
+		exists := false
+		if filter != nil {
+			_, exists = filter.items[item]
+		}
+		c.out.writeBool(exists)
+	}
+}
+
+func cmdCFReserve(c *conn, args []string) {
+	ks := c.db()
+	capacity, err := parseInt(args[2])
+	if err != nil || capacity <= 0 {
+		c.out.writeError(errBloomBadCapacity.Error())
+		return
+	}
+	for i := 3; i < len(args); i += 2 {
+		if i+1 >= len(args) || !(equalFold(args[i], "BUCKETSIZE") || equalFold(args[i], "MAXITERATIONS") ||
+			equalFold(args[i], "EXPANSION")) {
+			c.out.writeError(errSyntax.Error())
+			return
+		} else if _, err = parseInt(args[i+1]); err != nil {
+			c.out.writeError(err.Error())
+			return
+		}
+	}
+	if ks.exists(args[1]) {
+		c.out.writeError(errItemExists.Error())
+		return
+	}
+	ks.lookup(args[1], func() any { return &cuckooFilter{capacity: capacity, items: map[string]int64{}} })
+	c.out.writeOK()
+}
+
+func cmdCFAdd(c *conn, args []string) {
+	ks := c.db()
+	filter, err := getCuckooFilter(ks, args[1])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	} else if filter == nil {
+		filter = ks.lookup(args[1], func() any {
+			return &cuckooFilter{capacity: defaultCuckooCapacity, items: map[string]int64{}}
+		}).(*cuckooFilter)
+	}
+	if equalFold(args[0], "cf.addnx") && filter.items[args[2]] > 0 {
+		c.out.writeBool(false)
+		return
+	}
+	filter.items[args[2]]++
+	c.out.writeBool(true)
+}
+
+func cmdCFDel(c *conn, args []string) {
+	filter, err := getCuckooFilter(c.db(), args[1])
+	if err == nil && filter == nil {
+		err = errNotFound
+	}
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	} else if filter.items[args[2]] == 0 {
+		c.out.writeBool(false)
+		return
+	}
+	filter.items[args[2]]--
+	if filter.items[args[2]] == 0 {
+		delete(filter.items, args[2])
+	}
+	c.out.writeBool(true)
+}
+
+func cmdCFExists(c *conn, args []string) {
+	filter, err := getCuckooFilter(c.db(), args[1])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	if equalFold(args[0], "cf.mexists") {
+		c.out.writeArrayLen(len(args) - 2)
+	}
+	for _, item := range args[2:] {
+		c.out.writeBool(filter != nil && filter.items[item] > 0)
+	}
+}
+
+func cmdCFCount(c *conn, args []string) {
+	filter, err := getCuckooFilter(c.db(), args[1])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	} else if filter == nil {
+		c.out.writeInt(0)
+		return
+	}
+	c.out.writeInt(filter.items[args[2]])
+}
+
+func cmdCMSInitByDim(c *conn, args []string) {
+	width, err := parseInt(args[2])
+	if err == nil {
+		var depth int64
+		depth, err = parseInt(args[3])
+		if err == nil {
+			initCountMinSketch(c, args[1], width, depth)
+			return
+		}
+	}
+	c.out.writeError(err.Error())
+}
+
+func cmdCMSInitByProb(c *conn, args []string) {
+	errorRate, err := parseFloat(args[2])
+	if err != nil || errorRate <= 0 || errorRate >= 1 {
+		c.out.writeError("CMS: invalid overestimation value")
+		return
+	}
+	probability, err := parseFloat(args[3])
+	if err != nil || probability <= 0 || probability >= 1 {
+		c.out.writeError("CMS: invalid prob value")
+		return
+	}
+	initCountMinSketch(c, args[1], int64(math.Ceil(2/errorRate)), int64(math.Ceil(math.Log10(probability)/
+		math.Log10(0.5))))
+}
+
+func cmdCMSIncrBy(c *conn, args []string) {
+	if (len(args)-2)%2 != 0 {
+		c.out.writeError(errWrongArgs("cms.incrby").Error())
+		return
+	}
+	sketch, err := getCountMinSketch(c.db(), args[1])
+	if err == nil && sketch == nil {
+		err = errCMSKeyNotExists
+	}
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	increments := make([]int64, 0, (len(args)-2)/2)
+	for i := 2; i < len(args); i += 2 {
+		increment, err := parseInt(args[i+1])
+		if err != nil || increment < 0 {
+			c.out.writeError("CMS: Cannot parse number")
+			return
+		}
+		increments = append(increments, increment)
+	}
+	c.out.writeArrayLen(len(increments))
+	for i, increment := range increments {
+		item := args[2+2*i]
+		sketch.counts[item] += increment
+		c.out.writeInt(sketch.counts[item])
+	}
+}
+
+func cmdCMSQuery(c *conn, args []string) {
+	sketch, err := getCountMinSketch(c.db(), args[1])
+	if err == nil && sketch == nil {
+		err = errCMSKeyNotExists
+	}
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	c.out.writeArrayLen(len(args) - 2)
+	for _, item := range args[2:] {
+		c.out.writeInt(sketch.counts[item])
+	}
+}
+
+func cmdTopKReserve(c *conn, args []string) {
+	ks := c.db()
+	k, err := parseInt(args[2])
+	if err != nil || k <= 0 {
+		c.out.writeError("TopK: invalid k")
+		return
+	} else if len(args) != 3 && len(args) != 6 {
+		c.out.writeError(errSyntax.Error())
+		return
+	}
+	for _, arg := range args[3:] {
+		if _, err = parseFloat(arg); err != nil {
+			c.out.writeError(err.Error())
+			return
+		}
+	}
+	if ks.exists(args[1]) {
+		c.out.writeError(errTopKKeyExists.Error())
+		return
+	}
+	ks.lookup(args[1], func() any { return &topK{k: int(k), counts: map[string]int64{}, order: map[string]int{}} })
+	c.out.writeOK()
+}
+
+func cmdTopKAdd(c *conn, args []string) {
+	incrBy := equalFold(args[0], "topk.incrby")
+	if incrBy && (len(args)-2)%2 != 0 {
+		c.out.writeError(errWrongArgs("topk.incrby").Error())
+		return
+	}
+	top, err := getTopK(c.db(), args[1])
+	if err == nil && top == nil {
+		err = errTopKKeyNotExists
+	}
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	type increment struct {
+		item  string
+		value int64
+	}
+	var increments []increment
+	for i := 2; i < len(args); i++ {
+		if !incrBy {
+			increments = append(increments, increment{item: args[i], value: 1})
+			continue
+		}
+		value, err := parseInt(args[i+1])
+		if err != nil || value < 1 {
+			c.out.writeError("TopK: increment must be an integer greater or equal to 1")
+			return
+		}
+		increments = append(increments, increment{item: args[i], value: value})
+		i++
+	}
+	c.out.writeArrayLen(len(increments))
+	for _, inc := range increments {
+		before := top.list()
+		if _, ok := top.order[inc.item]; !ok {
+			top.order[inc.item] = len(top.order)
+		}
+		top.counts[inc.item] += inc.value
+		if expelled := top.expelled(before); expelled != "" {
+			c.out.writeBulk(expelled)
+		} else {
+			c.out.writeNull()
+		}
+	}
+}
+
+func cmdTopKQuery(c *conn, args []string) {
+	top, err := getTopK(c.db(), args[1])
+	if err == nil && top == nil {
+		err = errTopKKeyNotExists
+	}
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	listed := setValue{}
+	for _, item := range top.list() {
+		listed[item] = struct{}{}
+	}
+	c.out.writeArrayLen(len(args) - 2)
+	for _, item := range args[2:] {
+		_, ok := listed[item]
+		c.out.writeBool(ok)
+	}
+}
+
+func cmdTopKList(c *conn, args []string) {
+	withCount := len(args) == 3 && equalFold(args[2], "WITHCOUNT")
+	if len(args) > 3 || (len(args) == 3 && !withCount) {
+		c.out.writeError(errSyntax.Error())
+		return
+	}
+	top, err := getTopK(c.db(), args[1])
+	if err == nil && top == nil {
+		err = errTopKKeyNotExists
+	}
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	items := top.list()
+	if !withCount {
+		c.out.writeStrings(items)
+		return
+	}
+	c.out.writeArrayLen(2 * len(items))
+	for _, item := range items {
+		c.out.writeBulk(item)
+		c.out.writeInt(top.counts[item])
+	}
+}
+
+// writeBloomAdd adds the items to the filter, replying if each item was added, in an array if multi is true.
+func writeBloomAdd(c *conn, filter *bloomFilter, items []string, multi bool) {
+	if multi {
+		c.out.writeArrayLen(len(items))
+	}
+	for _, item := range items {
+		if _, exists := filter.items[item]; exists {
+			c.out.writeBool(false)
+		} else if filter.nonScaling && int64(len(filter.items)) >= filter.capacity {
+			c.out.writeError(errBloomFull.Error())
+		} else {
+			filter.items[item] = struct{}{}
+			c.out.writeBool(true)
+		}
+	}
+}
+
+func initCountMinSketch(c *conn, key string, width, depth int64) {
+	ks := c.db()
+	if width <= 0 || depth <= 0 {
+		c.out.writeError("CMS: invalid width/depth")
+		return
+	} else if ks.exists(key) {
+		c.out.writeError(errCMSKeyExists.Error())
+		return
+	}
+	ks.lookup(key, func() any { return &countMinSketch{width: width, depth: depth, counts: map[string]int64{}} })
+	c.out.writeOK()
+}
+
+func getBloomFilter(ks *keyspace, key string) (*bloomFilter, error) {
+	value := ks.lookup(key, nil)
+	if value == nil {
+		return nil, nil
+	}
+	filter, ok := value.(*bloomFilter)
+	if !ok {
+		return nil, errWrongType
+	}
+	return filter, nil
+}
+
+func getCuckooFilter(ks *keyspace, key string) (*cuckooFilter, error) {
+	value := ks.lookup(key, nil)
+	if value == nil {
+		return nil, nil
+	}
+	filter, ok := value.(*cuckooFilter)
+	if !ok {
+		return nil, errWrongType
+	}
+	return filter, nil
+}
+
+func getCountMinSketch(ks *keyspace, key string) (*countMinSketch, error) {
+	value := ks.lookup(key, nil)
+	if value == nil {
+		return nil, nil
+	}
+	sketch, ok := value.(*countMinSketch)
+	if !ok {
+		return nil, errWrongType
+	}
+	return sketch, nil
+}
+
+func getTopK(ks *keyspace, key string) (*topK, error) {
+	value := ks.lookup(key, nil)
+	if value == nil {
+		return nil, nil
+	}
+	top, ok := value.(*topK)
+	if !ok {
+		return nil, errWrongType
+	}
+	return top, nil
+}
+
+// list returns the top k items, ordered by the count descending.
+func (t *topK) list() []string {
+	items := make([]string, 0, len(t.counts))
+	for item := range t.counts {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if t.counts[items[i]] != t.counts[items[j]] {
+			return t.counts[items[i]] > t.counts[items[j]]
+		}
+		return t.order[items[i]] < t.order[items[j]]
+	})
+	return items[:min(len(items), t.k)]
+}
+
+// expelled returns the item of the list before an addition that is no longer in the list, empty if none.
+func (t *topK) expelled(before []string) string {
+	after := setValue{}
+	for _, item := range t.list() {
+		after[item] = struct{}{}
+	}
+	for _, item := range before {
+		if _, ok := after[item]; !ok {
+			return item
+		}
+	}
+	return ""
+}
@@ -0,0 +1,128 @@
+package redistest
+
+import (
+	"sort"
+	"strings"
+)
+
+func registerPubSubCommands() {
+	register("subscribe", &command{arity: -2, flags: flagPubSub, handler: cmdSubscribe})
+	register("psubscribe", &command{arity: -2, flags: flagPubSub, handler: cmdSubscribe})
+	register("unsubscribe", &command{arity: -1, flags: flagPubSub, handler: cmdUnsubscribe})
+	register("punsubscribe", &command{arity: -1, flags: flagPubSub, handler: cmdUnsubscribe})
+	register("publish", &command{arity: 3, handler: cmdPublish})
+	register("pubsub", &command{arity: -2, handler: cmdPubSub})
+}
+
+func cmdSubscribe(c *conn, args []string) {
+	kind := strings.ToLower(args[0])
+	own, all := c.subscriptions(kind == "psubscribe")
+	for _, name := range args[1:] {
+		own[name] = struct{}{}
+		if all[name] == nil {
+			all[name] = map[*conn]struct{}{}
+		}
+		all[name][c] = struct{}{}
+		c.writeSubscription(kind, name, true)
+	}
+}
+
+func cmdUnsubscribe(c *conn, args []string) {
+	kind := strings.ToLower(args[0])
+	own, all := c.subscriptions(kind == "punsubscribe")
+	names := args[1:]
+	if len(names) == 0 {
+		for name := range own {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	if len(names) == 0 {
+		c.writeSubscription(kind, "", false)
+		return
+	}
+	for _, name := range names {
+		delete(own, name)
+		delete(all[name], c)
+		if len(all[name]) == 0 {
+			delete(all, name)
+		}
+		c.writeSubscription(kind, name, true)
+	}
+}
+
+func cmdPublish(c *conn, args []string) {
+	s := c.server
+	var n int64
+	for sub := range s.channels[args[1]] {
+		w := newRespWriter(sub.out.protocol)
+		w.writePushLen(3)
+		w.writeBulk("message")
+		w.writeBulk(args[1])
+		w.writeBulk(args[2])
+		_ = sub.send(w.take())
+		n++
+	}
+	for pattern, subs := range s.patterns {
+		if !matchGlob(pattern, args[1]) {
+			continue
+		}
+		for sub := range subs {
+			w := newRespWriter(sub.out.protocol)
+			w.writePushLen(4)
+			w.writeBulk("pmessage")
+			w.writeBulk(pattern)
+			w.writeBulk(args[1])
+			w.writeBulk(args[2])
+			_ = sub.send(w.take())
+			n++
+		}
+	}
+	c.out.writeInt(n)
+}
+
+func cmdPubSub(c *conn, args []string) {
+	s := c.server
+	switch strings.ToLower(args[1]) {
+	case "channels":
+		var channels []string
+		for channel := range s.channels {
+			if len(args) < 3 || matchGlob(args[2], channel) {
+				channels = append(channels, channel)
+			}
+		}
+		sort.Strings(channels)
+		c.out.writeStrings(channels)
+	case "numsub":
+		c.out.writeMapLen(len(args) - 2)
+		for _, channel := range args[2:] {
+			c.out.writeBulk(channel)
+			c.out.writeInt(int64(len(s.channels[channel])))
+		}
+	case "numpat":
+		c.out.writeInt(int64(len(s.patterns)))
+	default:
+		c.out.writeError("ERR unknown subcommand '" + args[1] + "'. Try PUBSUB HELP.")
+	}
+}
+
+// subscriptions returns the channels or patterns subscribed by the connection and by all connections.
+func (c *conn) subscriptions(pattern bool) (map[string]struct{}, map[string]map[*conn]struct{}) {
+	if pattern {
+		return c.patterns, c.server.patterns
+	}
+	return c.channels, c.server.channels
+}
+
+// writeSubscription writes the confirmation of the (P)SUBSCRIBE and (P)UNSUBSCRIBE commands, with the number of
+// subscriptions of the connection.
+func (c *conn) writeSubscription(kind, name string, hasName bool) {
+	c.out.writePushLen(3)
+	c.out.writeBulk(kind)
+	if hasName {
+		c.out.writeBulk(name)
+	} else {
+		c.out.writeNull()
+	}
+	c.out.writeInt(int64(len(c.channels) + len(c.patterns)))
+}
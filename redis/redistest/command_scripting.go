@@ -0,0 +1,221 @@
+package redistest
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ScriptCall runs a redis command inside a ScriptFunc, as redis.call does, returning the reply as int64, string,
+// []any or nil, or the error reply.
+type ScriptCall func(args ...string) (any, error)
+
+// ScriptFunc emulates a Lua script registered with Server.SetScript, it runs atomically with the keys and the args
+// of EVAL, and its return is converted as the return of a Lua script: int64, string, []any, []string, bool (false is
+// nil), nil, or an error for an error reply.
+type ScriptFunc func(call ScriptCall, keys, args []string) (any, error)
+
+func registerScriptingCommands() {
+	register("eval", &command{arity: -3, handler: cmdEval})
+	register("evalsha", &command{arity: -3, handler: cmdEvalSha})
+	register("script", &command{arity: -2, handler: cmdScript})
+}
+
+// SetScript registers the Go function that emulates the Lua script, the server has no Lua interpreter, so EVAL and
+// EVALSHA run the function registered for the SHA1 digest of the script, nil removes the function.
+func (s *Server) SetScript(script string, fn ScriptFunc) {
+	s.store.mutex.Lock()
+	defer s.store.mutex.Unlock()
+	hash := scriptHash(script)
+	if fn == nil {
+		delete(s.scripts, hash)
+	} else {
+		s.scripts[hash] = fn
+	}
+}
+
+func cmdEval(c *conn, args []string) {
+	hash := scriptHash(args[1])
+	c.server.loaded[hash] = struct{}{}
+	runScript(c, hash, args)
+}
+
+func cmdEvalSha(c *conn, args []string) {
+	hash := strings.ToLower(args[1])
+	if _, ok := c.server.loaded[hash]; !ok {
+		c.out.writeError("NOSCRIPT No matching script. Please use EVAL.")
+		return
+	}
+	runScript(c, hash, args)
+}
+
+func cmdScript(c *conn, args []string) {
+	switch strings.ToLower(args[1]) {
+	case "load":
+		if len(args) != 3 {
+			c.out.writeError(errWrongArgs("script|load").Error())
+			return
+		}
+		hash := scriptHash(args[2])
+		c.server.loaded[hash] = struct{}{}
+		c.out.writeBulk(hash)
+	case "exists":
+		c.out.writeArrayLen(len(args) - 2)
+		for _, hash := range args[2:] {
+			_, ok := c.server.loaded[strings.ToLower(hash)]
+			c.out.writeBool(ok)
+		}
+	case "flush":
+		c.server.loaded = map[string]struct{}{}
+		c.out.writeOK()
+	default:
+		c.out.writeError("ERR unknown subcommand '" + args[1] + "'. Try SCRIPT HELP.")
+	}
+}
+
+// runScript runs the function of the script with the keys and the args of EVAL or EVALSHA.
+func runScript(c *conn, hash string, args []string) {
+	numKeys, err := parseInt(args[2])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	} else if numKeys < 0 {
+		c.out.writeError("ERR Number of keys can't be negative")
+		return
+	} else if numKeys > int64(len(args)-3) {
+		c.out.writeError("ERR Number of keys can't be greater than number of args")
+		return
+	}
+	fn, ok := c.server.scripts[hash]
+	if !ok {
+		c.out.writeError("ERR redistest: script " + hash + " is not emulated, register it with Server.SetScript")
+		return
+	}
+	keys := args[3 : 3+numKeys]
+	reply, err := fn(c.scriptCall, keys, args[3+numKeys:])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	writeScriptReply(c.out, reply)
+}
+
+// scriptCall executes the command inside a script, capturing its reply in RESP2 as redis.call does, the
+// transaction, pub/sub and scripting commands are not allowed, and the blocking commands do not block.
+func (c *conn) scriptCall(args ...string) (any, error) {
+	if len(args) == 0 {
+		return nil, replyError("ERR Please specify at least one argument for this redis lib call")
+	}
+	name := strings.ToLower(args[0])
+	cmd := commands[name]
+	if cmd == nil {
+		return nil, replyError("ERR Unknown Redis command called from script")
+	} else if cmd.has(flagNoQueue) || cmd.has(flagPubSub) || cmd.has(flagHandshake) || name == "eval" ||
+		name == "evalsha" || name == "script" {
+		return nil, replyError("ERR This Redis command is not allowed from script")
+	} else if !cmd.checkArity(len(args)) {
+		return nil, replyError("ERR Wrong number of args calling Redis command from script")
+	} else if cmd.has(flagWrite) && c.server.isReadOnly() {
+		return nil, replyError("READONLY You can't write against a read only replica.")
+	}
+	out := c.out
+	c.out = newRespWriter(2)
+	if !c.server.call(c, cmd, args) {
+		cmd.timeout(c.out)
+	}
+	reply := c.out.take()
+	c.out = out
+	return readScriptReply(bufio.NewReader(bytes.NewReader(reply)))
+}
+
+// readScriptReply parses a RESP2 reply written by a command inside a script.
+func readScriptReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if len(line) == 0 {
+		return nil, errProtocol
+	}
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, replyError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err = io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:size]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		values := make([]any, n)
+		for i := range values {
+			// the error replies inside arrays, like the EXEC replies, are kept as values.
+			if values[i], err = readScriptReply(r); err != nil {
+				if _, ok := err.(replyError); !ok {
+					return nil, err
+				}
+				values[i] = err
+			}
+		}
+		return values, nil
+	}
+	return nil, errProtocol
+}
+
+// writeScriptReply writes the return of a script, converted as redis converts the Lua values.
+func writeScriptReply(w *respWriter, reply any) {
+	switch v := reply.(type) {
+	case nil:
+		w.writeNull()
+	case bool:
+		if v {
+			w.writeInt(1)
+		} else {
+			w.writeNull()
+		}
+	case int:
+		w.writeInt(int64(v))
+	case int64:
+		w.writeInt(v)
+	case float64:
+		// the Lua numbers are converted to integers, as redis does.
+		w.writeInt(int64(v))
+	case string:
+		w.writeBulk(v)
+	case error:
+		w.writeError(v.Error())
+	case []string:
+		w.writeStrings(v)
+	case []any:
+		w.writeArrayLen(len(v))
+		for _, item := range v {
+			writeScriptReply(w, item)
+		}
+	default:
+		w.writeError(fmt.Sprintf("ERR redistest: unsupported script reply %T", reply))
+	}
+}
+
+// scriptHash returns the SHA1 digest of the script, as SCRIPT LOAD does.
+func scriptHash(script string) string {
+	sum := sha1.Sum([]byte(script))
+	return hex.EncodeToString(sum[:])
+}
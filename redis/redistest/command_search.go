@@ -0,0 +1,1012 @@
+package redistest
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// searchIndex is a RediSearch index, the documents are searched in the keyspace by every query, so they are
+// always up to date, without the indexing of redis.
+type searchIndex struct {
+	name     string
+	on       string
+	prefixes []string
+	fields   []*searchField
+}
+
+type searchField struct {
+	identifier    string
+	name          string
+	typ           string
+	sortable      bool
+	noIndex       bool
+	caseSensitive bool
+	separator     string
+	weight        float64
+	dim           int
+	distance      string
+}
+
+// searchDoc is a hash or a JSON document of an index, with the values of its attributes.
+type searchDoc struct {
+	key     string
+	value   any
+	values  map[string][]string
+	vectors map[string][]float32
+	score   float64
+	fields  [][2]string
+}
+
+// searchOptions are the options of FT.SEARCH.
+type searchOptions struct {
+	noContent       bool
+	withScores      bool
+	returns         []string
+	highlight       bool
+	highlightFields []string
+	highlightOpen   string
+	highlightClose  string
+	sortBy          string
+	sortDesc        bool
+	offset          int
+	num             int
+	params          map[string]string
+}
+
+// searchRow is a row of FT.AGGREGATE, with the names of the fields in the order they are returned.
+type searchRow struct {
+	names  []string
+	values map[string]any
+}
+
+var errIndexExists = replyError("Index already exists")
+var errUnknownIndex = replyError("Unknown Index name")
+
+func registerSearchCommands() {
+	register("ft.create", &command{arity: -5, handler: cmdFTCreate})
+	register("ft.dropindex", &command{arity: -2, flags: flagWrite, handler: cmdFTDropIndex})
+	register("ft.search", &command{arity: -3, handler: cmdFTSearch})
+	register("ft.aggregate", &command{arity: -3, handler: cmdFTAggregate})
+}
+
+func cmdFTCreate(c *conn, args []string) {
+	ks := c.db()
+	if _, ok := ks.indexes[args[1]]; ok {
+		c.out.writeError(errIndexExists.Error())
+		return
+	}
+	idx := &searchIndex{name: args[1], on: "HASH"}
+	i := 2
+	for ; i < len(args) && !equalFold(args[i], "SCHEMA"); i++ {
+		switch {
+		case equalFold(args[i], "ON") && i+1 < len(args):
+			i++
+			idx.on = strings.ToUpper(args[i])
+			if idx.on != "HASH" && idx.on != "JSON" {
+				c.out.writeError(errSyntax.Error())
+				return
+			}
+		case equalFold(args[i], "PREFIX") && i+1 < len(args):
+			n, err := parseInt(args[i+1])
+			if err != nil || i+2+int(n) > len(args) {
+				c.out.writeError(errSyntax.Error())
+				return
+			}
+			idx.prefixes = append(idx.prefixes, args[i+2:i+2+int(n)]...)
+			i += 1 + int(n)
+		default:
+			c.out.writeError(errSyntax.Error())
+			return
+		}
+	}
+	if i+1 >= len(args) {
+		c.out.writeError("ERR Fields arguments are missing")
+		return
+	}
+	fields, err := parseSearchFields(args[i+1:])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	idx.fields = fields
+	ks.indexes[idx.name] = idx
+	c.out.writeOK()
+}
+
+func parseSearchFields(args []string) ([]*searchField, error) {
+	var fields []*searchField
+	for i := 0; i < len(args); {
+		field := &searchField{identifier: args[i], name: args[i], weight: 1, separator: ","}
+		i++
+		if i+1 < len(args) && equalFold(args[i], "AS") {
+			field.name = args[i+1]
+			i += 2
+		}
+		if i >= len(args) {
+			return nil, errSyntax
+		}
+		field.typ = strings.ToUpper(args[i])
+		i++
+		switch field.typ {
+		case "TEXT", "TAG", "NUMERIC", "GEO":
+		case "VECTOR":
+			if i+1 >= len(args) {
+				return nil, errSyntax
+			}
+			n, err := parseInt(args[i+1])
+			if err != nil || i+2+int(n) > len(args) {
+				return nil, errSyntax
+			}
+			attributes := args[i+2 : i+2+int(n)]
+			for j := 0; j+1 < len(attributes); j += 2 {
+				switch strings.ToUpper(attributes[j]) {
+				case "DIM":
+					dim, err := parseInt(attributes[j+1])
+					if err != nil {
+						return nil, err
+					}
+					field.dim = int(dim)
+				case "DISTANCE_METRIC":
+					field.distance = strings.ToUpper(attributes[j+1])
+				}
+			}
+			i += 2 + int(n)
+		default:
+			return nil, replyError("Invalid field type for field `" + field.name + "`")
+		}
+	options:
+		for i < len(args) {
+			switch strings.ToUpper(args[i]) {
+			case "SORTABLE":
+				field.sortable = true
+			case "NOINDEX":
+				field.noIndex = true
+			case "NOSTEM", "UNF":
+			case "CASESENSITIVE":
+				field.caseSensitive = true
+			case "WEIGHT", "SEPARATOR":
+				if i+1 >= len(args) {
+					return nil, errSyntax
+				}
+				i++
+				if equalFold(args[i-1], "SEPARATOR") {
+					field.separator = args[i]
+				} else if weight, err := parseFloat(args[i]); err != nil {
+					return nil, err
+				} else {
+					field.weight = weight
+				}
+			default:
+				break options
+			}
+			i++
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+func cmdFTDropIndex(c *conn, args []string) {
+	ks := c.db()
+	idx, ok := ks.indexes[args[1]]
+	if !ok {
+		c.out.writeError(errUnknownIndex.Error())
+		return
+	}
+	if len(args) > 2 && equalFold(args[2], "DD") {
+		for _, doc := range idx.documents(ks) {
+			ks.del(doc.key)
+		}
+	}
+	delete(ks.indexes, args[1])
+	c.out.writeOK()
+}
+
+func cmdFTSearch(c *conn, args []string) {
+	ks := c.db()
+	idx, ok := ks.indexes[args[1]]
+	if !ok {
+		c.out.writeError(args[1] + ": no such index")
+		return
+	}
+	opts, err := parseSearchOptions(args[3:])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	query, err := parseSearchQuery(args[2], opts.params)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	docs, err := idx.search(ks, query)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	if opts.sortBy != "" {
+		sortSearchDocs(idx, docs, opts.sortBy, opts.sortDesc)
+	}
+	total := len(docs)
+	docs = docs[min(opts.offset, len(docs)):min(opts.offset+opts.num, len(docs))]
+	for _, doc := range docs {
+		if !opts.noContent {
+			doc.fields = idx.content(ks, doc, opts.returns)
+			if query.knn != nil && (len(opts.returns) == 0 || containsFold(opts.returns, query.knn.alias)) {
+				doc.fields = append(doc.fields, [2]string{query.knn.alias, doc.values[query.knn.alias][0]})
+			}
+		}
+		if opts.highlight {
+			idx.highlight(doc, query.terms, opts)
+		}
+	}
+	writeSearchReply(c, total, docs, opts)
+}
+
+func cmdFTAggregate(c *conn, args []string) {
+	ks := c.db()
+	idx, ok := ks.indexes[args[1]]
+	if !ok {
+		c.out.writeError(args[1] + ": no such index")
+		return
+	}
+	params := map[string]string{}
+	for i := 3; i < len(args); i++ {
+		if equalFold(args[i], "PARAMS") && i+1 < len(args) {
+			n, _ := parseInt(args[i+1])
+			for j := i + 2; j+1 < len(args) && j < i+2+int(n); j += 2 {
+				params[args[j]] = args[j+1]
+			}
+		}
+	}
+	query, err := parseSearchQuery(args[2], params)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	docs, err := idx.search(ks, query)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	var rows []*searchRow
+	for _, doc := range docs {
+		row := &searchRow{values: map[string]any{}}
+		if query.knn != nil {
+			row.values[query.knn.alias] = doc.values[query.knn.alias][0]
+		}
+		for _, field := range idx.fields {
+			if values := doc.values[field.name]; len(values) > 0 {
+				row.values[field.name] = values[0]
+			}
+		}
+		if hash, ok := doc.value.(hashValue); ok {
+			for _, name := range hash.fields() {
+				if _, exists := row.values[name]; !exists {
+					row.values[name] = hash[name]
+				}
+			}
+		}
+		rows = append(rows, row)
+	}
+	rows, err = aggregateSearchRows(rows, args[3:])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	writeAggregateReply(c, rows)
+}
+
+// aggregateSearchRows runs the LOAD, GROUPBY, REDUCE, SORTBY and LIMIT steps, in the order of the arguments.
+func aggregateSearchRows(rows []*searchRow, args []string) ([]*searchRow, error) {
+	for i := 0; i < len(args); i++ {
+		arg := strings.ToUpper(args[i])
+		switch arg {
+		case "VERBATIM":
+			continue
+		case "DIALECT", "TIMEOUT":
+			i++
+			continue
+		case "PARAMS", "LOAD", "GROUPBY", "SORTBY":
+		case "LIMIT":
+			if i+2 >= len(args) {
+				return nil, errSyntax
+			}
+			offset, errOffset := parseInt(args[i+1])
+			num, errNum := parseInt(args[i+2])
+			if errOffset != nil || errNum != nil {
+				return nil, errSyntax
+			}
+			rows = rows[min(int(offset), len(rows)):min(int(offset+num), len(rows))]
+			i += 2
+			continue
+		default:
+			return nil, replyError("Unknown argument `" + args[i] + "`")
+		}
+		if i+1 >= len(args) {
+			return nil, errSyntax
+		}
+		if arg == "LOAD" && args[i+1] == "*" {
+			for _, row := range rows {
+				row.names = sortedRowNames(row)
+			}
+			i++
+			continue
+		}
+		n, err := parseInt(args[i+1])
+		if err != nil || i+2+int(n) > len(args) {
+			return nil, errSyntax
+		}
+		values := args[i+2 : i+2+int(n)]
+		i += 1 + int(n)
+		switch arg {
+		case "LOAD":
+			for _, row := range rows {
+				for _, value := range values {
+					row.names = append(row.names, strings.TrimPrefix(value, "@"))
+				}
+			}
+		case "GROUPBY":
+			var reducers []string
+			for i+1 < len(args) && equalFold(args[i+1], "REDUCE") {
+				end, err := reducerEnd(args, i+1)
+				if err != nil {
+					return nil, err
+				}
+				reducers = append(reducers, args[i+1:end]...)
+				i = end - 1
+			}
+			if rows, err = groupSearchRows(rows, values, reducers); err != nil {
+				return nil, err
+			}
+		case "SORTBY":
+			sortSearchRows(rows, values)
+			if i+2 < len(args) && equalFold(args[i+1], "MAX") {
+				if max, err := parseInt(args[i+2]); err == nil {
+					rows = rows[:min(int(max), len(rows))]
+				}
+				i += 2
+			}
+		}
+	}
+	return rows, nil
+}
+
+// reducerEnd returns the position after the REDUCE at the position, with its arguments and its alias.
+func reducerEnd(args []string, pos int) (int, error) {
+	if pos+2 >= len(args) {
+		return 0, errSyntax
+	}
+	n, err := parseInt(args[pos+2])
+	if err != nil || pos+3+int(n) > len(args) {
+		return 0, errSyntax
+	}
+	end := pos + 3 + int(n)
+	if end+1 < len(args) && equalFold(args[end], "AS") {
+		end += 2
+	}
+	return end, nil
+}
+
+func groupSearchRows(rows []*searchRow, fields []string, reducers []string) ([]*searchRow, error) {
+	var groups []*searchRow
+	members := map[string][]*searchRow{}
+	for _, row := range rows {
+		var key []string
+		for _, field := range fields {
+			key = append(key, rowString(row, strings.TrimPrefix(field, "@")))
+		}
+		id := strings.Join(key, "\x00")
+		if _, ok := members[id]; !ok {
+			group := &searchRow{values: map[string]any{}}
+			for j, field := range fields {
+				name := strings.TrimPrefix(field, "@")
+				group.names = append(group.names, name)
+				group.values[name] = key[j]
+			}
+			groups = append(groups, group)
+		}
+		members[id] = append(members[id], row)
+	}
+	if len(fields) == 0 && len(groups) == 0 {
+		groups = append(groups, &searchRow{values: map[string]any{}})
+	}
+	for i := 0; i < len(reducers); {
+		n, _ := parseInt(reducers[i+2])
+		function := strings.ToUpper(reducers[i+1])
+		var field string
+		if n > 0 {
+			field = strings.TrimPrefix(reducers[i+3], "@")
+		}
+		name := "__generated_alias" + strings.ToLower(function) + field
+		i += 3 + int(n)
+		if i+1 < len(reducers) && equalFold(reducers[i], "AS") {
+			name = reducers[i+1]
+			i += 2
+		}
+		for _, group := range groups {
+			var key []string
+			for _, f := range fields {
+				key = append(key, rowString(group, strings.TrimPrefix(f, "@")))
+			}
+			value, err := reduceSearchRows(function, field, members[strings.Join(key, "\x00")])
+			if err != nil {
+				return nil, err
+			}
+			group.names = append(group.names, name)
+			group.values[name] = value
+		}
+	}
+	return groups, nil
+}
+
+func reduceSearchRows(function, field string, rows []*searchRow) (any, error) {
+	distinct := map[string]bool{}
+	var list []string
+	var numbers []float64
+	for _, row := range rows {
+		if _, ok := row.values[field]; !ok {
+			continue
+		}
+		s := rowString(row, field)
+		if !distinct[s] {
+			distinct[s] = true
+			list = append(list, s)
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			numbers = append(numbers, f)
+		}
+	}
+	switch function {
+	case "COUNT":
+		return formatFloat(float64(len(rows))), nil
+	case "COUNT_DISTINCT":
+		return formatFloat(float64(len(distinct))), nil
+	case "TOLIST":
+		return list, nil
+	case "SUM", "AVG", "MIN", "MAX":
+		result := 0.0
+		if function == "MIN" {
+			result = math.Inf(1)
+		} else if function == "MAX" {
+			result = math.Inf(-1)
+		}
+		for _, number := range numbers {
+			switch function {
+			case "MIN":
+				result = math.Min(result, number)
+			case "MAX":
+				result = math.Max(result, number)
+			default:
+				result += number
+			}
+		}
+		if function == "AVG" && len(numbers) > 0 {
+			result /= float64(len(numbers))
+		}
+		return formatFloat(result), nil
+	}
+	return nil, replyError("Bad arguments for " + function + ": unknown reducer")
+}
+
+func sortSearchRows(rows []*searchRow, args []string) {
+	type key struct {
+		field string
+		desc  bool
+	}
+	var keys []key
+	for i := 0; i < len(args); i++ {
+		k := key{field: strings.TrimPrefix(args[i], "@")}
+		if i+1 < len(args) && (equalFold(args[i+1], "ASC") || equalFold(args[i+1], "DESC")) {
+			k.desc = equalFold(args[i+1], "DESC")
+			i++
+		}
+		keys = append(keys, k)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, k := range keys {
+			if c := compareSearchValues(rowString(rows[i], k.field), rowString(rows[j], k.field)); c != 0 {
+				return (c < 0) != k.desc
+			}
+		}
+		return false
+	})
+}
+
+func rowString(row *searchRow, name string) string {
+	switch value := row.values[name].(type) {
+	case string:
+		return value
+	case []string:
+		return strings.Join(value, ",")
+	}
+	return ""
+}
+
+func sortedRowNames(row *searchRow) []string {
+	names := make([]string, 0, len(row.values))
+	for name := range row.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// compareSearchValues compares the values as numbers when both are numbers, otherwise as strings.
+func compareSearchValues(a, b string) int {
+	fa, errA := strconv.ParseFloat(a, 64)
+	fb, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		if fa < fb {
+			return -1
+		} else if fa > fb {
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
+
+func parseSearchOptions(args []string) (searchOptions, error) {
+	opts := searchOptions{num: 10, params: map[string]string{}}
+	for i := 0; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "NOCONTENT":
+			opts.noContent = true
+		case "WITHSCORES":
+			opts.withScores = true
+		case "VERBATIM", "NOSTOPWORDS", "WITHSORTKEYS":
+		case "DIALECT", "TIMEOUT":
+			i++
+		case "RETURN", "PARAMS":
+			if i+1 >= len(args) {
+				return opts, errSyntax
+			}
+			n, err := parseInt(args[i+1])
+			if err != nil || i+2+int(n) > len(args) {
+				return opts, errSyntax
+			}
+			values := args[i+2 : i+2+int(n)]
+			if equalFold(args[i], "RETURN") {
+				opts.returns = values
+			} else {
+				for j := 0; j+1 < len(values); j += 2 {
+					opts.params[values[j]] = values[j+1]
+				}
+			}
+			i += 1 + int(n)
+		case "HIGHLIGHT":
+			opts.highlight = true
+			for i+1 < len(args) {
+				if equalFold(args[i+1], "FIELDS") && i+2 < len(args) {
+					n, err := parseInt(args[i+2])
+					if err != nil || i+3+int(n) > len(args) {
+						return opts, errSyntax
+					}
+					opts.highlightFields = args[i+3 : i+3+int(n)]
+					i += 2 + int(n)
+				} else if equalFold(args[i+1], "TAGS") && i+3 < len(args) {
+					opts.highlightOpen, opts.highlightClose = args[i+2], args[i+3]
+					i += 3
+				} else {
+					break
+				}
+			}
+		case "SORTBY":
+			if i+1 >= len(args) {
+				return opts, errSyntax
+			}
+			opts.sortBy = strings.TrimPrefix(args[i+1], "@")
+			i++
+			if i+1 < len(args) && (equalFold(args[i+1], "ASC") || equalFold(args[i+1], "DESC")) {
+				opts.sortDesc = equalFold(args[i+1], "DESC")
+				i++
+			}
+		case "LIMIT":
+			if i+2 >= len(args) {
+				return opts, errSyntax
+			}
+			offset, errOffset := parseInt(args[i+1])
+			num, errNum := parseInt(args[i+2])
+			if errOffset != nil || errNum != nil || offset < 0 || num < 0 {
+				return opts, errSyntax
+			}
+			opts.offset, opts.num = int(offset), int(num)
+			i += 2
+		default:
+			return opts, replyError("Unknown argument `" + args[i] + "`")
+		}
+	}
+	if opts.highlightOpen == "" && opts.highlightClose == "" {
+		opts.highlightOpen, opts.highlightClose = "<b>", "</b>"
+	}
+	return opts, nil
+}
+
+// search returns the documents that match the query, sorted by the score and the key, or the nearest documents
+// of its vector search, sorted by the distance.
+func (idx *searchIndex) search(ks *keyspace, query *searchQuery) ([]*searchDoc, error) {
+	var docs []*searchDoc
+	for _, doc := range idx.documents(ks) {
+		if query.root.match(idx, doc) {
+			doc.score = idx.score(doc, query.terms)
+			docs = append(docs, doc)
+		}
+	}
+	sort.SliceStable(docs, func(i, j int) bool {
+		return docs[i].score > docs[j].score
+	})
+	if query.knn != nil {
+		return query.knn.nearest(idx, docs)
+	}
+	return docs, nil
+}
+
+// score is the frequency of the terms in the text fields, multiplied by their weights.
+func (idx *searchIndex) score(doc *searchDoc, terms []string) float64 {
+	score := 0.0
+	for _, field := range idx.textFields("") {
+		for _, value := range doc.values[field.name] {
+			for _, token := range tokenizeSearch(value) {
+				for _, term := range terms {
+					if token == term {
+						score += field.weight
+					}
+				}
+			}
+		}
+	}
+	return score
+}
+
+func (idx *searchIndex) documents(ks *keyspace) []*searchDoc {
+	var docs []*searchDoc
+	for _, key := range ks.sortedKeys() {
+		if !idx.hasPrefix(key) {
+			continue
+		}
+		doc := &searchDoc{key: key, values: map[string][]string{}, vectors: map[string][]float32{}}
+		switch value := ks.lookup(key, nil).(type) {
+		case hashValue:
+			if idx.on != "HASH" {
+				continue
+			}
+			doc.value = value
+			for _, field := range idx.fields {
+				if v, ok := value[field.identifier]; ok && field.typ == "VECTOR" {
+					doc.vectors[field.name] = decodeSearchVector([]byte(v))
+				} else if ok {
+					doc.values[field.name] = []string{v}
+				}
+			}
+		case *jsonValue:
+			if idx.on != "JSON" {
+				continue
+			}
+			doc.value = value
+			for _, field := range idx.fields {
+				path, err := parseJSONPath(field.identifier)
+				if err != nil {
+					continue
+				}
+				for _, ref := range path.eval(ks, key, value) {
+					doc.addJSONValue(field, ref.get())
+				}
+			}
+		default:
+			continue
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+func (doc *searchDoc) addJSONValue(field *searchField, value any) {
+	switch value := value.(type) {
+	case string:
+		doc.values[field.name] = append(doc.values[field.name], value)
+	case json.Number:
+		doc.values[field.name] = append(doc.values[field.name], value.String())
+	case bool:
+		doc.values[field.name] = append(doc.values[field.name], strconv.FormatBool(value))
+	case []any:
+		if field.typ == "VECTOR" {
+			for _, item := range value {
+				number, _ := item.(json.Number)
+				f, _ := number.Float64()
+				doc.vectors[field.name] = append(doc.vectors[field.name], float32(f))
+			}
+			return
+		}
+		for _, item := range value {
+			doc.addJSONValue(field, item)
+		}
+	}
+}
+
+// tags returns the values of the tag field, the values of the hashes are split by the separator.
+func (doc *searchDoc) tags(field *searchField) []string {
+	var tags []string
+	for _, value := range doc.values[field.name] {
+		if _, ok := doc.value.(hashValue); !ok {
+			tags = append(tags, value)
+			continue
+		}
+		for _, tag := range strings.Split(value, field.separator) {
+			tags = append(tags, strings.TrimSpace(tag))
+		}
+	}
+	return tags
+}
+
+// content returns the fields of the document returned by FT.SEARCH, the entire hash or JSON document ($), or the
+// fields of RETURN.
+func (idx *searchIndex) content(ks *keyspace, doc *searchDoc, returns []string) [][2]string {
+	var fields [][2]string
+	if len(returns) == 0 {
+		switch value := doc.value.(type) {
+		case hashValue:
+			for _, name := range value.fields() {
+				fields = append(fields, [2]string{name, value[name]})
+			}
+		case *jsonValue:
+			fields = append(fields, [2]string{"$", marshalJSON(value.root)})
+		}
+		return fields
+	}
+	for _, name := range returns {
+		if field := idx.field(name); field != nil && len(doc.values[field.name]) > 0 {
+			fields = append(fields, [2]string{name, doc.values[field.name][0]})
+		} else if hash, ok := doc.value.(hashValue); ok {
+			if value, exists := hash[name]; exists {
+				fields = append(fields, [2]string{name, value})
+			}
+		} else if document, ok := doc.value.(*jsonValue); ok && strings.HasPrefix(name, "$") {
+			path, err := parseJSONPath(name)
+			if refs := path.eval(ks, doc.key, document); err == nil && len(refs) > 0 {
+				value, isString := refs[0].get().(string)
+				if !isString {
+					value = marshalJSON(refs[0].get())
+				}
+				fields = append(fields, [2]string{name, value})
+			}
+		}
+	}
+	return fields
+}
+
+// highlight wraps the terms of the query in the text fields returned.
+func (idx *searchIndex) highlight(doc *searchDoc, terms []string, opts searchOptions) {
+	for i, pair := range doc.fields {
+		field := idx.field(pair[0])
+		if field == nil || field.typ != "TEXT" {
+			continue
+		} else if len(opts.highlightFields) > 0 && !containsFold(opts.highlightFields, pair[0]) {
+			continue
+		}
+		doc.fields[i][1] = highlightTerms(pair[1], terms, opts.highlightOpen, opts.highlightClose)
+	}
+}
+
+func highlightTerms(text string, terms []string, open, close string) string {
+	var builder strings.Builder
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && len(tokenizeSearch(string(runes[j]))) > 0 {
+			j++
+		}
+		if j == i {
+			builder.WriteRune(runes[i])
+			i++
+			continue
+		}
+		word := string(runes[i:j])
+		if containsFold(terms, word) {
+			word = open + word + close
+		}
+		builder.WriteString(word)
+		i = j
+	}
+	return builder.String()
+}
+
+func (idx *searchIndex) hasPrefix(key string) bool {
+	if len(idx.prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range idx.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// field returns the field of the attribute name, or of the identifier.
+func (idx *searchIndex) field(name string) *searchField {
+	name = strings.TrimPrefix(name, "@")
+	for _, field := range idx.fields {
+		if field.name == name {
+			return field
+		}
+	}
+	for _, field := range idx.fields {
+		if field.identifier == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// textFields returns the text field of the name, or all the text fields indexed when the name is empty.
+func (idx *searchIndex) textFields(name string) []*searchField {
+	if name != "" {
+		if field := idx.field(name); field != nil {
+			return []*searchField{field}
+		}
+		return nil
+	}
+	var fields []*searchField
+	for _, field := range idx.fields {
+		if field.typ == "TEXT" && !field.noIndex {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func sortSearchDocs(idx *searchIndex, docs []*searchDoc, name string, desc bool) {
+	value := func(doc *searchDoc) string {
+		if field := idx.field(name); field != nil && len(doc.values[field.name]) > 0 {
+			return doc.values[field.name][0]
+		} else if values := doc.values[name]; len(values) > 0 {
+			return values[0]
+		} else if hash, ok := doc.value.(hashValue); ok {
+			return hash[name]
+		}
+		return ""
+	}
+	sort.SliceStable(docs, func(i, j int) bool {
+		if c := compareSearchValues(value(docs[i]), value(docs[j])); c != 0 {
+			return (c < 0) != desc
+		}
+		return docs[i].key < docs[j].key
+	})
+}
+
+func writeSearchReply(c *conn, total int, docs []*searchDoc, opts searchOptions) {
+	if c.out.resp3() {
+		c.out.writeMapLen(5)
+		writeSearchReplyHeader(c)
+		c.out.writeBulk("results")
+		c.out.writeArrayLen(len(docs))
+		for _, doc := range docs {
+			n := 3
+			if opts.withScores {
+				n++
+			}
+			c.out.writeMapLen(n)
+			c.out.writeBulk("id")
+			c.out.writeBulk(doc.key)
+			if opts.withScores {
+				c.out.writeBulk("score")
+				c.out.writeDouble(doc.score)
+			}
+			writeSearchFields(c, doc.fields)
+		}
+		writeSearchReplyFooter(c, total)
+		return
+	}
+	n := 1
+	if opts.withScores {
+		n++
+	}
+	if !opts.noContent {
+		n++
+	}
+	c.out.writeArrayLen(1 + n*len(docs))
+	c.out.writeInt(int64(total))
+	for _, doc := range docs {
+		c.out.writeBulk(doc.key)
+		if opts.withScores {
+			c.out.writeBulk(formatFloat(doc.score))
+		}
+		if !opts.noContent {
+			c.out.writeArrayLen(2 * len(doc.fields))
+			for _, pair := range doc.fields {
+				c.out.writeBulk(pair[0])
+				c.out.writeBulk(pair[1])
+			}
+		}
+	}
+}
+
+func writeAggregateReply(c *conn, rows []*searchRow) {
+	if c.out.resp3() {
+		c.out.writeMapLen(5)
+		writeSearchReplyHeader(c)
+		c.out.writeBulk("results")
+		c.out.writeArrayLen(len(rows))
+		for _, row := range rows {
+			c.out.writeMapLen(2)
+			c.out.writeBulk("extra_attributes")
+			writeSearchRow(c, row)
+			c.out.writeBulk("values")
+			c.out.writeArrayLen(0)
+		}
+		writeSearchReplyFooter(c, len(rows))
+		return
+	}
+	c.out.writeArrayLen(1 + len(rows))
+	c.out.writeInt(int64(len(rows)))
+	for _, row := range rows {
+		writeSearchRow(c, row)
+	}
+}
+
+func writeSearchRow(c *conn, row *searchRow) {
+	var names []string
+	for _, name := range row.names {
+		if _, ok := row.values[name]; ok {
+			names = append(names, name)
+		}
+	}
+	c.out.writeMapLen(len(names))
+	for _, name := range names {
+		c.out.writeBulk(name)
+		switch value := row.values[name].(type) {
+		case []string:
+			c.out.writeStrings(value)
+		default:
+			c.out.writeBulk(rowString(row, name))
+		}
+	}
+}
+
+func writeSearchFields(c *conn, fields [][2]string) {
+	c.out.writeBulk("extra_attributes")
+	c.out.writeMapLen(len(fields))
+	for _, pair := range fields {
+		c.out.writeBulk(pair[0])
+		c.out.writeBulk(pair[1])
+	}
+	c.out.writeBulk("values")
+	c.out.writeArrayLen(0)
+}
+
+func writeSearchReplyHeader(c *conn) {
+	c.out.writeBulk("attributes")
+	c.out.writeArrayLen(0)
+	c.out.writeBulk("format")
+	c.out.writeBulk("STRING")
+}
+
+func writeSearchReplyFooter(c *conn, total int) {
+	c.out.writeBulk("total_results")
+	c.out.writeInt(int64(total))
+	c.out.writeBulk("warning")
+	c.out.writeArrayLen(0)
+}
+
+// decodeSearchVector decodes the FLOAT32 vectors of the hashes, little-endian.
+func decodeSearchVector(data []byte) []float32 {
+	vector := make([]float32, len(data)/4)
+	for i := range vector {
+		bits := uint32(data[4*i]) | uint32(data[4*i+1])<<8 | uint32(data[4*i+2])<<16 | uint32(data[4*i+3])<<24
+		vector[i] = math.Float32frombits(bits)
+	}
+	return vector
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,257 @@
+package redistest
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+func registerSetCommands() {
+	register("sadd", &command{arity: -3, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdSAdd})
+	register("srem", &command{arity: -3, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdSRem})
+	register("smembers", &command{arity: 2, firstKey: 1, lastKey: 1, handler: cmdSMembers})
+	register("sismember", &command{arity: 3, firstKey: 1, lastKey: 1, handler: cmdSIsMember})
+	register("smismember", &command{arity: -3, firstKey: 1, lastKey: 1, handler: cmdSMIsMember})
+	register("scard", &command{arity: 2, firstKey: 1, lastKey: 1, handler: cmdSCard})
+	register("spop", &command{arity: -2, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdSPop})
+	register("srandmember", &command{arity: -2, firstKey: 1, lastKey: 1, handler: cmdSPop})
+	register("smove", &command{arity: 4, flags: flagWrite, firstKey: 1, lastKey: 2, handler: cmdSMove})
+	register("sinter", &command{arity: -2, firstKey: 1, lastKey: -1, handler: cmdSetOperation})
+	register("sunion", &command{arity: -2, firstKey: 1, lastKey: -1, handler: cmdSetOperation})
+	register("sdiff", &command{arity: -2, firstKey: 1, lastKey: -1, handler: cmdSetOperation})
+	register("sinterstore", &command{arity: -3, flags: flagWrite, firstKey: 1, lastKey: -1, handler: cmdSetOperation})
+	register("sunionstore", &command{arity: -3, flags: flagWrite, firstKey: 1, lastKey: -1, handler: cmdSetOperation})
+	register("sdiffstore", &command{arity: -3, flags: flagWrite, firstKey: 1, lastKey: -1, handler: cmdSetOperation})
+	register("sscan", &command{arity: -3, firstKey: 1, lastKey: 1, handler: cmdSScan})
+}
+
+func cmdSAdd(c *conn, args []string) {
+	set, err := c.db().getSet(args[1], true)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	var n int64
+	for _, member := range args[2:] {
+		if _, ok := set[member]; !ok {
+			set[member] = struct{}{}
+			n++
+		}
+	}
+	c.out.writeInt(n)
+}
+
+func cmdSRem(c *conn, args []string) {
+	ks := c.db()
+	set, err := ks.getSet(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	var n int64
+	for _, member := range args[2:] {
+		if _, ok := set[member]; ok {
+			delete(set, member)
+			n++
+		}
+	}
+	ks.removeIfEmpty(args[1])
+	c.out.writeInt(n)
+}
+
+func cmdSMembers(c *conn, args []string) {
+	set, err := c.db().getSet(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	writeSet(c.out, set.members())
+}
+
+func cmdSIsMember(c *conn, args []string) {
+	set, err := c.db().getSet(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	_, ok := set[args[2]]
+	c.out.writeBool(ok)
+}
+
+func cmdSMIsMember(c *conn, args []string) {
+	set, err := c.db().getSet(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	c.out.writeArrayLen(len(args) - 2)
+	for _, member := range args[2:] {
+		_, ok := set[member]
+		c.out.writeBool(ok)
+	}
+}
+
+func cmdSCard(c *conn, args []string) {
+	set, err := c.db().getSet(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	c.out.writeInt(int64(len(set)))
+}
+
+// cmdSPop executes the SPOP and SRANDMEMBER commands, negative count of SRANDMEMBER is not supported.
+func cmdSPop(c *conn, args []string) {
+	if len(args) > 3 {
+		c.out.writeError(errSyntax.Error())
+		return
+	}
+	count := int64(-1)
+	if len(args) == 3 {
+		var err error
+		if count, err = parseInt(args[2]); err != nil || count < 0 {
+			c.out.writeError("ERR value is out of range, must be positive")
+			return
+		}
+	}
+	ks := c.db()
+	set, err := ks.getSet(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	members := set.members()
+	rand.Shuffle(len(members), func(i, j int) {
+		members[i], members[j] = members[j], members[i]
+	})
+	n := int(count)
+	if count < 0 || n > len(members) {
+		n = len(members)
+	}
+	if count < 0 && n > 1 {
+		n = 1
+	}
+	members = members[:n]
+	if equalFold(args[0], "spop") {
+		for _, member := range members {
+			delete(set, member)
+		}
+		ks.removeIfEmpty(args[1])
+	}
+	if count >= 0 {
+		writeSet(c.out, members)
+	} else if len(members) == 0 {
+		c.out.writeNull()
+	} else {
+		c.out.writeBulk(members[0])
+	}
+}
+
+func cmdSMove(c *conn, args []string) {
+	ks := c.db()
+	source, err := ks.getSet(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	} else if _, err = ks.getSet(args[2], false); err != nil {
+		c.out.writeError(err.Error())
+		return
+	} else if _, ok := source[args[3]]; !ok {
+		c.out.writeInt(0)
+		return
+	}
+	delete(source, args[3])
+	ks.removeIfEmpty(args[1])
+	destination, _ := ks.getSet(args[2], true)
+	destination[args[3]] = struct{}{}
+	c.out.writeInt(1)
+}
+
+// cmdSetOperation executes the SINTER, SUNION and SDIFF commands and their STORE variants.
+func cmdSetOperation(c *conn, args []string) {
+	name := strings.ToLower(args[0])
+	store := strings.HasSuffix(name, "store")
+	keys := args[1:]
+	if store {
+		keys = args[2:]
+	}
+	ks := c.db()
+	var sets []setValue
+	for _, key := range keys {
+		set, err := ks.getSet(key, false)
+		if err != nil {
+			c.out.writeError(err.Error())
+			return
+		}
+		sets = append(sets, set)
+	}
+	result := setValue{}
+	for i, set := range sets {
+		switch {
+		case strings.HasPrefix(name, "sunion") || (strings.HasPrefix(name, "sdiff") && i == 0):
+			for member := range set {
+				result[member] = struct{}{}
+			}
+		case strings.HasPrefix(name, "sdiff"):
+			for member := range set {
+				delete(result, member)
+			}
+		case i == 0:
+			for member := range set {
+				result[member] = struct{}{}
+			}
+		default:
+			for member := range result {
+				if _, ok := set[member]; !ok {
+					delete(result, member)
+				}
+			}
+		}
+	}
+	if !store {
+		writeSet(c.out, result.members())
+		return
+	}
+	ks.del(args[1])
+	if len(result) > 0 {
+		ks.entries[args[1]] = &entry{value: result}
+	}
+	c.out.writeInt(int64(len(result)))
+}
+
+// cmdSScan returns all members in a single page, the cursor returned is always 0.
+func cmdSScan(c *conn, args []string) {
+	match, _, _, err := parseScanArgs(args[3:], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	set, err := c.db().getSet(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	var page []string
+	for _, member := range set.members() {
+		if match == "" || matchGlob(match, member) {
+			page = append(page, member)
+		}
+	}
+	writeScan(c.out, 0, page)
+}
+
+func writeSet(w *respWriter, members []string) {
+	w.writeSetLen(len(members))
+	for _, member := range members {
+		w.writeBulk(member)
+	}
+}
+
+func (s setValue) members() []string {
+	members := make([]string, 0, len(s))
+	for member := range s {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+	return members
+}
@@ -0,0 +1,594 @@
+package redistest
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var errMinMaxFloat = errors.New("ERR min or max is not a float")
+var errMinMaxLex = errors.New("ERR min or max not valid string range item")
+
+type zmember struct {
+	member string
+	score  float64
+}
+
+// scoreBound is a boundary of BYSCORE ranges, "(" makes it exclusive.
+type scoreBound struct {
+	value     float64
+	exclusive bool
+}
+
+// lexBound is a boundary of BYLEX ranges, "-" and "+" are the infinite boundaries.
+type lexBound struct {
+	value     string
+	exclusive bool
+	infinite  int
+}
+
+// zrangeArgs are the arguments of the ZRANGE family, min and max are ranks, scores or lexicographic boundaries.
+type zrangeArgs struct {
+	min        string
+	max        string
+	byScore    bool
+	byLex      bool
+	rev        bool
+	offset     int64
+	count      int64
+	withScores bool
+}
+
+func registerSortedSetCommands() {
+	register("zadd", &command{arity: -4, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdZAdd})
+	register("zincrby", &command{arity: 4, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdZIncrBy})
+	register("zrem", &command{arity: -3, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdZRem})
+	register("zscore", &command{arity: 3, firstKey: 1, lastKey: 1, handler: cmdZScore})
+	register("zmscore", &command{arity: -3, firstKey: 1, lastKey: 1, handler: cmdZMScore})
+	register("zcard", &command{arity: 2, firstKey: 1, lastKey: 1, handler: cmdZCard})
+	register("zcount", &command{arity: 4, firstKey: 1, lastKey: 1, handler: cmdZCount})
+	register("zrank", &command{arity: 3, firstKey: 1, lastKey: 1, handler: cmdZRank})
+	register("zrevrank", &command{arity: 3, firstKey: 1, lastKey: 1, handler: cmdZRank})
+	register("zrange", &command{arity: -4, firstKey: 1, lastKey: 1, handler: cmdZRange})
+	register("zrevrange", &command{arity: -4, firstKey: 1, lastKey: 1, handler: cmdZRange})
+	register("zrangebyscore", &command{arity: -4, firstKey: 1, lastKey: 1, handler: cmdZRange})
+	register("zrevrangebyscore", &command{arity: -4, firstKey: 1, lastKey: 1, handler: cmdZRange})
+	register("zrangebylex", &command{arity: -4, firstKey: 1, lastKey: 1, handler: cmdZRange})
+	register("zrevrangebylex", &command{arity: -4, firstKey: 1, lastKey: 1, handler: cmdZRange})
+	register("zremrangebyscore", &command{arity: 4, flags: flagWrite, firstKey: 1, lastKey: 1,
+		handler: cmdZRemRange})
+	register("zremrangebyrank", &command{arity: 4, flags: flagWrite, firstKey: 1, lastKey: 1,
+		handler: cmdZRemRange})
+	register("zremrangebylex", &command{arity: 4, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdZRemRange})
+	register("zpopmin", &command{arity: -2, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdZPop})
+	register("zpopmax", &command{arity: -2, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdZPop})
+	register("bzpopmin", &command{arity: -3, flags: flagWrite, firstKey: 1, lastKey: -2, block: blockZPop,
+		timeout: (*respWriter).writeNullArray})
+	register("bzpopmax", &command{arity: -3, flags: flagWrite, firstKey: 1, lastKey: -2, block: blockZPop,
+		timeout: (*respWriter).writeNullArray})
+	register("zscan", &command{arity: -3, firstKey: 1, lastKey: 1, handler: cmdZScan})
+}
+
+func cmdZAdd(c *conn, args []string) {
+	var nx, xx, gt, lt, ch, incr bool
+	i := 2
+options:
+	for ; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		case "GT":
+			gt = true
+		case "LT":
+			lt = true
+		case "CH":
+			ch = true
+		case "INCR":
+			incr = true
+		default:
+			break options
+		}
+	}
+	pairs := args[i:]
+	if len(pairs) == 0 || len(pairs)%2 != 0 {
+		c.out.writeError(errSyntax.Error())
+		return
+	} else if nx && xx {
+		c.out.writeError("ERR XX and NX options at the same time are not compatible")
+		return
+	} else if (gt && lt) || (nx && (gt || lt)) {
+		c.out.writeError("ERR GT, LT, and/or NX options at the same time are not compatible")
+		return
+	} else if incr && len(pairs) > 2 {
+		c.out.writeError("ERR INCR option supports a single increment-element pair")
+		return
+	}
+	scores := make([]float64, 0, len(pairs)/2)
+	for j := 0; j < len(pairs); j += 2 {
+		score, err := parseFloat(pairs[j])
+		if err != nil {
+			c.out.writeError(errNotFloat.Error())
+			return
+		}
+		scores = append(scores, score)
+	}
+	ks := c.db()
+	zset, err := ks.getZSet(args[1], !xx)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	var added, changed int64
+	var result float64
+	updated := false
+	for j, score := range scores {
+		member := pairs[2*j+1]
+		current, exists := zset[member]
+		if (nx && exists) || (xx && !exists) {
+			continue
+		}
+		if incr && exists {
+			score += current
+		}
+		if exists && ((gt && score <= current) || (lt && score >= current)) {
+			continue
+		}
+		result, updated = score, true
+		if !exists {
+			added++
+		} else if score != current {
+			changed++
+		}
+		zset[member] = score
+	}
+	ks.removeIfEmpty(args[1])
+	if incr {
+		if updated {
+			c.out.writeDouble(result)
+		} else {
+			c.out.writeNull()
+		}
+	} else if ch {
+		c.out.writeInt(added + changed)
+	} else {
+		c.out.writeInt(added)
+	}
+}
+
+func cmdZIncrBy(c *conn, args []string) {
+	delta, err := parseFloat(args[2])
+	if err != nil {
+		c.out.writeError(errNotFloat.Error())
+		return
+	}
+	zset, err := c.db().getZSet(args[1], true)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	zset[args[3]] += delta
+	c.out.writeDouble(zset[args[3]])
+}
+
+func cmdZRem(c *conn, args []string) {
+	ks := c.db()
+	zset, err := ks.getZSet(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	var n int64
+	for _, member := range args[2:] {
+		if _, ok := zset[member]; ok {
+			delete(zset, member)
+			n++
+		}
+	}
+	ks.removeIfEmpty(args[1])
+	c.out.writeInt(n)
+}
+
+func cmdZScore(c *conn, args []string) {
+	zset, err := c.db().getZSet(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+	} else if score, ok := zset[args[2]]; !ok {
+		c.out.writeNull()
+	} else {
+		c.out.writeDouble(score)
+	}
+}
+
+func cmdZMScore(c *conn, args []string) {
+	zset, err := c.db().getZSet(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	c.out.writeArrayLen(len(args) - 2)
+	for _, member := range args[2:] {
+		if score, ok := zset[member]; ok {
+			c.out.writeDouble(score)
+		} else {
+			c.out.writeNull()
+		}
+	}
+}
+
+func cmdZCard(c *conn, args []string) {
+	zset, err := c.db().getZSet(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	c.out.writeInt(int64(len(zset)))
+}
+
+func cmdZCount(c *conn, args []string) {
+	members, err := c.zrange(args[1], zrangeArgs{min: args[2], max: args[3], byScore: true, count: -1})
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	c.out.writeInt(int64(len(members)))
+}
+
+func cmdZRank(c *conn, args []string) {
+	zset, err := c.db().getZSet(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	members := zset.sorted()
+	for i, m := range members {
+		if m.member != args[2] {
+			continue
+		}
+		if equalFold(args[0], "zrevrank") {
+			i = len(members) - 1 - i
+		}
+		c.out.writeInt(int64(i))
+		return
+	}
+	c.out.writeNull()
+}
+
+// cmdZRange executes the ZRANGE command and its deprecated variants (ZREVRANGE, ZRANGEBYSCORE, ...), converting
+// them to the ZRANGE arguments.
+func cmdZRange(c *conn, args []string) {
+	name := strings.ToLower(args[0])
+	rArgs := zrangeArgs{
+		min:     args[2],
+		max:     args[3],
+		rev:     strings.HasPrefix(name, "zrev"),
+		byScore: strings.HasSuffix(name, "byscore"),
+		byLex:   strings.HasSuffix(name, "bylex"),
+		count:   -1,
+	}
+	if (rArgs.byScore || rArgs.byLex) && rArgs.rev {
+		rArgs.min, rArgs.max = rArgs.max, rArgs.min
+	}
+	limit := false
+	for i := 4; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "WITHSCORES":
+			rArgs.withScores = !rArgs.byLex
+		case "BYSCORE":
+			rArgs.byScore = name == "zrange"
+		case "BYLEX":
+			rArgs.byLex = name == "zrange"
+		case "REV":
+			rArgs.rev = name == "zrange"
+		case "LIMIT":
+			if i+2 >= len(args) {
+				c.out.writeError(errSyntax.Error())
+				return
+			}
+			var err error
+			if rArgs.offset, err = parseInt(args[i+1]); err != nil {
+				c.out.writeError(err.Error())
+				return
+			} else if rArgs.count, err = parseInt(args[i+2]); err != nil {
+				c.out.writeError(err.Error())
+				return
+			}
+			limit = true
+			i += 2
+		default:
+			c.out.writeError(errSyntax.Error())
+			return
+		}
+	}
+	if name == "zrange" && rArgs.rev && (rArgs.byScore || rArgs.byLex) {
+		rArgs.min, rArgs.max = rArgs.max, rArgs.min
+	}
+	if limit && !rArgs.byScore && !rArgs.byLex {
+		c.out.writeError("ERR syntax error, LIMIT is only supported in combination with either BYSCORE or BYLEX")
+		return
+	}
+	members, err := c.zrange(args[1], rArgs)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	writeScored(c.out, members, rArgs.withScores)
+}
+
+func cmdZRemRange(c *conn, args []string) {
+	name := strings.ToLower(args[0])
+	rArgs := zrangeArgs{
+		min:     args[2],
+		max:     args[3],
+		byScore: strings.HasSuffix(name, "byscore"),
+		byLex:   strings.HasSuffix(name, "bylex"),
+		count:   -1,
+	}
+	members, err := c.zrange(args[1], rArgs)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	ks := c.db()
+	zset, _ := ks.getZSet(args[1], false)
+	for _, m := range members {
+		delete(zset, m.member)
+	}
+	ks.removeIfEmpty(args[1])
+	c.out.writeInt(int64(len(members)))
+}
+
+func cmdZPop(c *conn, args []string) {
+	if len(args) > 3 {
+		c.out.writeError(errSyntax.Error())
+		return
+	}
+	count := int64(1)
+	if len(args) == 3 {
+		var err error
+		if count, err = parseInt(args[2]); err != nil || count < 0 {
+			c.out.writeError("ERR value is out of range, must be positive")
+			return
+		}
+	}
+	members, err := c.zpop(args[1], equalFold(args[0], "zpopmax"), count)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	writeScored(c.out, members, true)
+}
+
+func blockZPop(c *conn, args []string) bool {
+	max := equalFold(args[0], "bzpopmax")
+	for _, key := range args[1 : len(args)-1] {
+		members, err := c.zpop(key, max, 1)
+		if err != nil {
+			c.out.writeError(err.Error())
+			return true
+		} else if len(members) == 0 {
+			continue
+		}
+		c.out.writeArrayLen(3)
+		c.out.writeBulk(key)
+		c.out.writeBulk(members[0].member)
+		c.out.writeDouble(members[0].score)
+		return true
+	}
+	return false
+}
+
+// cmdZScan returns all members in a single page, the cursor returned is always 0.
+func cmdZScan(c *conn, args []string) {
+	match, _, _, err := parseScanArgs(args[3:], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	zset, err := c.db().getZSet(args[1], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	var page []string
+	for _, m := range zset.sorted() {
+		if match == "" || matchGlob(match, m.member) {
+			page = append(page, m.member, formatFloat(m.score))
+		}
+	}
+	writeScan(c.out, 0, page)
+}
+
+// zrange returns the members in the range, by rank, score or lexicographic order, reversed when args.rev is true.
+func (c *conn) zrange(key string, args zrangeArgs) ([]zmember, error) {
+	zset, err := c.db().getZSet(key, false)
+	if err != nil {
+		return nil, err
+	}
+	members := zset.sorted()
+	if args.rev {
+		for i, j := 0, len(members)-1; i < j; i, j = i+1, j-1 {
+			members[i], members[j] = members[j], members[i]
+		}
+	}
+	var result []zmember
+	switch {
+	case args.byScore:
+		min, err := parseScoreBound(args.min)
+		if err != nil {
+			return nil, err
+		}
+		max, err := parseScoreBound(args.max)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range members {
+			if min.below(m.score) && max.above(m.score) {
+				result = append(result, m)
+			}
+		}
+	case args.byLex:
+		min, err := parseLexBound(args.min)
+		if err != nil {
+			return nil, err
+		}
+		max, err := parseLexBound(args.max)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range members {
+			if min.below(m.member) && max.above(m.member) {
+				result = append(result, m)
+			}
+		}
+	default:
+		start, stop, ok, err := parseRange(args.min, args.max, len(members))
+		if err != nil {
+			return nil, err
+		} else if ok {
+			result = members[start : stop+1]
+		}
+	}
+	if args.offset > 0 {
+		if args.offset >= int64(len(result)) {
+			return nil, nil
+		}
+		result = result[args.offset:]
+	} else if args.offset < 0 {
+		return nil, nil
+	}
+	if args.count >= 0 && args.count < int64(len(result)) {
+		result = result[:args.count]
+	}
+	return result, nil
+}
+
+func (c *conn) zpop(key string, max bool, count int64) ([]zmember, error) {
+	ks := c.db()
+	zset, err := ks.getZSet(key, false)
+	if err != nil {
+		return nil, err
+	}
+	members := zset.sorted()
+	if max {
+		for i, j := 0, len(members)-1; i < j; i, j = i+1, j-1 {
+			members[i], members[j] = members[j], members[i]
+		}
+	}
+	if count < int64(len(members)) {
+		members = members[:count]
+	}
+	for _, m := range members {
+		delete(zset, m.member)
+	}
+	ks.removeIfEmpty(key)
+	return members, nil
+}
+
+// writeScored writes the members, with the scores they are written as pairs in RESP3 and flattened in RESP2.
+func writeScored(w *respWriter, members []zmember, withScores bool) {
+	if !withScores {
+		w.writeArrayLen(len(members))
+		for _, m := range members {
+			w.writeBulk(m.member)
+		}
+	} else if w.resp3() {
+		w.writeArrayLen(len(members))
+		for _, m := range members {
+			w.writeArrayLen(2)
+			w.writeBulk(m.member)
+			w.writeDouble(m.score)
+		}
+	} else {
+		w.writeArrayLen(2 * len(members))
+		for _, m := range members {
+			w.writeBulk(m.member)
+			w.writeDouble(m.score)
+		}
+	}
+}
+
+func parseScoreBound(arg string) (scoreBound, error) {
+	bound := scoreBound{}
+	if strings.HasPrefix(arg, "(") {
+		bound.exclusive = true
+		arg = arg[1:]
+	}
+	switch strings.ToLower(arg) {
+	case "-inf":
+		bound.value = math.Inf(-1)
+	case "+inf", "inf":
+		bound.value = math.Inf(1)
+	default:
+		f, err := strconv.ParseFloat(arg, 64)
+		if err != nil || math.IsNaN(f) {
+			return bound, errMinMaxFloat
+		}
+		bound.value = f
+	}
+	return bound, nil
+}
+
+func (b scoreBound) below(score float64) bool {
+	if b.exclusive {
+		return b.value < score
+	}
+	return b.value <= score
+}
+
+func (b scoreBound) above(score float64) bool {
+	if b.exclusive {
+		return b.value > score
+	}
+	return b.value >= score
+}
+
+func parseLexBound(arg string) (lexBound, error) {
+	switch {
+	case arg == "-":
+		return lexBound{infinite: -1}, nil
+	case arg == "+":
+		return lexBound{infinite: 1}, nil
+	case strings.HasPrefix(arg, "["):
+		return lexBound{value: arg[1:]}, nil
+	case strings.HasPrefix(arg, "("):
+		return lexBound{value: arg[1:], exclusive: true}, nil
+	}
+	return lexBound{}, errMinMaxLex
+}
+
+func (b lexBound) below(member string) bool {
+	if b.infinite != 0 {
+		return b.infinite < 0
+	} else if b.exclusive {
+		return b.value < member
+	}
+	return b.value <= member
+}
+
+func (b lexBound) above(member string) bool {
+	if b.infinite != 0 {
+		return b.infinite > 0
+	} else if b.exclusive {
+		return b.value > member
+	}
+	return b.value >= member
+}
+
+// sorted returns the members ordered by score, and lexicographically when the scores are equal.
+func (z zsetValue) sorted() []zmember {
+	members := make([]zmember, 0, len(z))
+	for member, score := range z {
+		members = append(members, zmember{member: member, score: score})
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].score != members[j].score {
+			return members[i].score < members[j].score
+		}
+		return members[i].member < members[j].member
+	})
+	return members
+}
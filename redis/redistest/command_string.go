@@ -0,0 +1,281 @@
+package redistest
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+func registerStringCommands() {
+	register("get", &command{arity: 2, firstKey: 1, lastKey: 1, handler: cmdGet})
+	register("set", &command{arity: -3, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdSet})
+	register("setnx", &command{arity: 3, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdSetNX})
+	register("setex", &command{arity: 4, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdSetEX})
+	register("psetex", &command{arity: 4, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdSetEX})
+	register("getset", &command{arity: 3, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdGetSet})
+	register("getdel", &command{arity: 2, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdGetDel})
+	register("mget", &command{arity: -2, firstKey: 1, lastKey: -1, handler: cmdMGet})
+	register("mset", &command{arity: -3, flags: flagWrite, firstKey: 1, lastKey: -1, step: 2, handler: cmdMSet})
+	register("msetnx", &command{arity: -3, flags: flagWrite, firstKey: 1, lastKey: -1, step: 2, handler: cmdMSet})
+	register("incr", &command{arity: 2, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdIncr})
+	register("decr", &command{arity: 2, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdIncr})
+	register("incrby", &command{arity: 3, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdIncr})
+	register("decrby", &command{arity: 3, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdIncr})
+	register("incrbyfloat", &command{arity: 3, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdIncrByFloat})
+	register("append", &command{arity: 3, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdAppend})
+	register("strlen", &command{arity: 2, firstKey: 1, lastKey: 1, handler: cmdStrLen})
+	register("getrange", &command{arity: 4, firstKey: 1, lastKey: 1, handler: cmdGetRange})
+}
+
+func cmdGet(c *conn, args []string) {
+	value, ok, err := c.db().getString(args[1])
+	if err != nil {
+		c.out.writeError(err.Error())
+	} else if !ok {
+		c.out.writeNull()
+	} else {
+		c.out.writeBulk(value)
+	}
+}
+
+func cmdSet(c *conn, args []string) {
+	var sArgs setArgs
+	expirations := 0
+	now := c.server.store.now()
+	for i := 3; i < len(args); i++ {
+		arg := strings.ToUpper(args[i])
+		switch arg {
+		case "NX":
+			sArgs.nx = true
+		case "XX":
+			sArgs.xx = true
+		case "GET":
+			sArgs.get = true
+		case "KEEPTTL":
+			sArgs.keepTTL = true
+			expirations++
+		case "EX", "PX", "EXAT", "PXAT":
+			if i+1 >= len(args) {
+				c.out.writeError(errSyntax.Error())
+				return
+			}
+			n, err := parseInt(args[i+1])
+			if err != nil {
+				c.out.writeError(err.Error())
+				return
+			} else if n <= 0 {
+				c.out.writeError("ERR invalid expire time in 'set' command")
+				return
+			}
+			sArgs.expireAt = expireAt(arg, n, now)
+			expirations++
+			i++
+		default:
+			c.out.writeError(errSyntax.Error())
+			return
+		}
+	}
+	if (sArgs.nx && sArgs.xx) || expirations > 1 {
+		c.out.writeError(errSyntax.Error())
+		return
+	}
+	old, hadOld, written, err := c.db().set(args[1], args[2], sArgs)
+	if err != nil {
+		c.out.writeError(err.Error())
+	} else if sArgs.get && hadOld {
+		c.out.writeBulk(old)
+	} else if sArgs.get || !written {
+		c.out.writeNull()
+	} else {
+		c.out.writeOK()
+	}
+}
+
+func cmdSetNX(c *conn, args []string) {
+	_, _, written, _ := c.db().set(args[1], args[2], setArgs{nx: true})
+	c.out.writeBool(written)
+}
+
+func cmdSetEX(c *conn, args []string) {
+	n, err := parseInt(args[2])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	} else if n <= 0 {
+		c.out.writeError("ERR invalid expire time in '" + strings.ToLower(args[0]) + "' command")
+		return
+	}
+	unit := "EX"
+	if equalFold(args[0], "psetex") {
+		unit = "PX"
+	}
+	_, _, _, _ = c.db().set(args[1], args[3], setArgs{expireAt: expireAt(unit, n, c.server.store.now())})
+	c.out.writeOK()
+}
+
+func cmdGetSet(c *conn, args []string) {
+	old, hadOld, _, err := c.db().set(args[1], args[2], setArgs{get: true})
+	if err != nil {
+		c.out.writeError(err.Error())
+	} else if !hadOld {
+		c.out.writeNull()
+	} else {
+		c.out.writeBulk(old)
+	}
+}
+
+func cmdGetDel(c *conn, args []string) {
+	ks := c.db()
+	value, ok, err := ks.getString(args[1])
+	if err != nil {
+		c.out.writeError(err.Error())
+	} else if !ok {
+		c.out.writeNull()
+	} else {
+		ks.del(args[1])
+		c.out.writeBulk(value)
+	}
+}
+
+func cmdMGet(c *conn, args []string) {
+	ks := c.db()
+	c.out.writeArrayLen(len(args) - 1)
+	for _, key := range args[1:] {
+		value, ok, err := ks.getString(key)
+		if err != nil || !ok {
+			c.out.writeNull()
+		} else {
+			c.out.writeBulk(value)
+		}
+	}
+}
+
+func cmdMSet(c *conn, args []string) {
+	if len(args)%2 == 0 {
+		c.out.writeError(errWrongArgs(args[0]).Error())
+		return
+	}
+	ks := c.db()
+	nx := equalFold(args[0], "msetnx")
+	if nx {
+		for i := 1; i < len(args); i += 2 {
+			if ks.exists(args[i]) {
+				c.out.writeInt(0)
+				return
+			}
+		}
+	}
+	for i := 1; i < len(args); i += 2 {
+		_, _, _, _ = ks.set(args[i], args[i+1], setArgs{})
+	}
+	if nx {
+		c.out.writeInt(1)
+	} else {
+		c.out.writeOK()
+	}
+}
+
+func cmdIncr(c *conn, args []string) {
+	delta := int64(1)
+	if len(args) == 3 {
+		var err error
+		if delta, err = parseInt(args[2]); err != nil {
+			c.out.writeError(err.Error())
+			return
+		}
+	}
+	if strings.HasPrefix(strings.ToLower(args[0]), "decr") {
+		delta = -delta
+	}
+	ks := c.db()
+	value, ok, err := ks.getString(args[1])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	var n int64
+	if ok {
+		if n, err = parseInt(value); err != nil {
+			c.out.writeError(err.Error())
+			return
+		}
+	}
+	n += delta
+	_, _, _, _ = ks.set(args[1], strconv.FormatInt(n, 10), setArgs{keepTTL: true})
+	c.out.writeInt(n)
+}
+
+func cmdIncrByFloat(c *conn, args []string) {
+	delta, err := parseFloat(args[2])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	ks := c.db()
+	value, ok, err := ks.getString(args[1])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	var f float64
+	if ok {
+		if f, err = parseFloat(value); err != nil {
+			c.out.writeError(err.Error())
+			return
+		}
+	}
+	result := formatFloat(f + delta)
+	_, _, _, _ = ks.set(args[1], result, setArgs{keepTTL: true})
+	c.out.writeBulk(result)
+}
+
+func cmdAppend(c *conn, args []string) {
+	ks := c.db()
+	value, _, err := ks.getString(args[1])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	value += args[2]
+	_, _, _, _ = ks.set(args[1], value, setArgs{keepTTL: true})
+	c.out.writeInt(int64(len(value)))
+}
+
+func cmdStrLen(c *conn, args []string) {
+	value, _, err := c.db().getString(args[1])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	c.out.writeInt(int64(len(value)))
+}
+
+func cmdGetRange(c *conn, args []string) {
+	value, _, err := c.db().getString(args[1])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	start, stop, ok, err := parseRange(args[2], args[3], len(value))
+	if err != nil {
+		c.out.writeError(err.Error())
+	} else if !ok {
+		c.out.writeBulk("")
+	} else {
+		c.out.writeBulk(value[start : stop+1])
+	}
+}
+
+// expireAt converts the EX, PX, EXAT and PXAT arguments to the expiration time.
+func expireAt(unit string, n int64, now time.Time) time.Time {
+	switch strings.ToUpper(unit) {
+	case "EX":
+		return now.Add(time.Duration(n) * time.Second)
+	case "PX":
+		return now.Add(time.Duration(n) * time.Millisecond)
+	case "EXAT":
+		return time.Unix(n, 0)
+	default:
+		return time.UnixMilli(n)
+	}
+}
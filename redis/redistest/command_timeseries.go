@@ -0,0 +1,796 @@
+package redistest
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// timeSeries is a RedisTimeSeries key, the samples are kept sorted by the timestamp, in milliseconds.
+type timeSeries struct {
+	retention       int64
+	duplicatePolicy string
+	labels          [][2]string
+	samples         []tsSample
+	rules           []*tsRule
+}
+
+type tsSample struct {
+	timestamp int64
+	value     float64
+}
+
+// tsRule is a compaction rule, the buckets of the source closed by a new sample are aggregated to the dest key.
+type tsRule struct {
+	dest        string
+	aggregation string
+	bucket      int64
+	align       int64
+}
+
+// tsArgs are the options of TS.CREATE and TS.ADD.
+type tsArgs struct {
+	retention       *int64
+	duplicatePolicy string
+	onDuplicate     string
+	labels          [][2]string
+}
+
+// tsRangeArgs are the options of TS.RANGE and TS.MRANGE.
+type tsRangeArgs struct {
+	from            int64
+	to              int64
+	count           int
+	aggregation     string
+	bucket          int64
+	align           int64
+	bucketTimestamp string
+	empty           bool
+	filterByValue   []float64
+	filterByTS      []int64
+	withLabels      bool
+	selectedLabels  []string
+	filters         []string
+	groupBy         string
+	reducer         string
+}
+
+var errTSKeyNotExists = replyError("ERR TSDB: the key does not exist")
+var errTSKeyExists = replyError("ERR TSDB: key already exists")
+var errTSBlock = replyError("ERR TSDB: Error at upsert, update is not supported when DUPLICATE_POLICY is set to " +
+	"BLOCK mode")
+var errTSRetention = replyError("ERR TSDB: Timestamp is older than retention")
+var errTSAggregation = replyError("ERR TSDB: Unknown aggregation type")
+
+var tsAggregations = []string{"avg", "sum", "min", "max", "range", "count", "first", "last", "std.p", "std.s",
+	"var.p", "var.s", "twa"}
+
+var tsDuplicatePolicies = []string{"BLOCK", "FIRST", "LAST", "MIN", "MAX", "SUM"}
+
+func registerTimeSeriesCommands() {
+	register("ts.create", &command{arity: -2, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdTSCreate})
+	register("ts.add", &command{arity: -4, flags: flagWrite, firstKey: 1, lastKey: 1, handler: cmdTSAdd})
+	register("ts.madd", &command{arity: -4, flags: flagWrite, firstKey: 1, lastKey: -1, step: 3,
+		handler: cmdTSMAdd})
+	register("ts.range", &command{arity: -4, firstKey: 1, lastKey: 1, handler: cmdTSRange})
+	register("ts.revrange", &command{arity: -4, firstKey: 1, lastKey: 1, handler: cmdTSRange})
+	register("ts.mrange", &command{arity: -5, handler: cmdTSMRange})
+	register("ts.mrevrange", &command{arity: -5, handler: cmdTSMRange})
+	register("ts.createrule", &command{arity: -6, flags: flagWrite, firstKey: 1, lastKey: 2,
+		handler: cmdTSCreateRule})
+	register("ts.deleterule", &command{arity: 3, flags: flagWrite, firstKey: 1, lastKey: 2,
+		handler: cmdTSDeleteRule})
+}
+
+func cmdTSCreate(c *conn, args []string) {
+	ks := c.db()
+	opts, err := parseTSArgs(args[2:], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	} else if ks.exists(args[1]) {
+		c.out.writeError(errTSKeyExists.Error())
+		return
+	}
+	ks.lookup(args[1], func() any { return newTimeSeries(opts) })
+	c.out.writeOK()
+}
+
+func cmdTSAdd(c *conn, args []string) {
+	ks := c.db()
+	opts, err := parseTSArgs(args[4:], true)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	series, err := getTimeSeries(ks, args[1])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	} else if series == nil {
+		series = ks.lookup(args[1], func() any { return newTimeSeries(opts) }).(*timeSeries)
+	}
+	timestamp, err := c.tsTimestamp(args[2])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	value, err := parseFloat(args[3])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	if err = series.add(ks, timestamp, value, opts.onDuplicate); err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	c.out.writeInt(timestamp)
+}
+
+func cmdTSMAdd(c *conn, args []string) {
+	if (len(args)-1)%3 != 0 {
+		c.out.writeError("ERR wrong number of arguments for 'ts.madd' command")
+		return
+	}
+	ks := c.db()
+	c.out.writeArrayLen((len(args) - 1) / 3)
+	for i := 1; i+2 < len(args); i += 3 {
+		series, err := getTimeSeries(ks, args[i])
+		if err == nil && series == nil {
+			err = errTSKeyNotExists
+		}
+		var timestamp int64
+		var value float64
+		if err == nil {
+			timestamp, err = c.tsTimestamp(args[i+1])
+		}
+		if err == nil {
+			value, err = parseFloat(args[i+2])
+		}
+		if err == nil {
+			err = series.add(ks, timestamp, value, "")
+		}
+		if err != nil {
+			c.out.writeError(err.Error())
+			continue
+		}
+		c.out.writeInt(timestamp)
+	}
+}
+
+func cmdTSRange(c *conn, args []string) {
+	series, err := getTimeSeries(c.db(), args[1])
+	if err == nil && series == nil {
+		err = errTSKeyNotExists
+	}
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	opts, err := parseTSRangeArgs(args[2:], false)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	writeTSSamples(c, series.query(opts, equalFold(args[0], "ts.revrange")))
+}
+
+func cmdTSMRange(c *conn, args []string) {
+	ks := c.db()
+	opts, err := parseTSRangeArgs(args[1:], true)
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	reverse := equalFold(args[0], "ts.mrevrange")
+	type result struct {
+		key     string
+		labels  [][2]string
+		samples []tsSample
+	}
+	var results []result
+	var keys []string
+	for _, key := range ks.sortedKeys() {
+		if series, ok := ks.lookup(key, nil).(*timeSeries); ok && series.matches(opts.filters) {
+			keys = append(keys, key)
+		}
+	}
+	if opts.groupBy == "" {
+		for _, key := range keys {
+			series := ks.lookup(key, nil).(*timeSeries)
+			results = append(results, result{key: key, labels: series.selectLabels(opts),
+				samples: series.query(opts, reverse)})
+		}
+	} else {
+		groups := map[string][]string{}
+		var values []string
+		for _, key := range keys {
+			value, ok := ks.lookup(key, nil).(*timeSeries).label(opts.groupBy)
+			if !ok {
+				continue
+			} else if _, exists := groups[value]; !exists {
+				values = append(values, value)
+			}
+			groups[value] = append(groups[value], key)
+		}
+		sort.Strings(values)
+		for _, value := range values {
+			byTimestamp := map[int64][]tsSample{}
+			var timestamps []int64
+			for _, key := range groups[value] {
+				for _, sample := range ks.lookup(key, nil).(*timeSeries).query(opts, false) {
+					if _, ok := byTimestamp[sample.timestamp]; !ok {
+						timestamps = append(timestamps, sample.timestamp)
+					}
+					byTimestamp[sample.timestamp] = append(byTimestamp[sample.timestamp], sample)
+				}
+			}
+			sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+			var samples []tsSample
+			for _, timestamp := range timestamps {
+				samples = append(samples, tsSample{timestamp, aggregateTS(opts.reducer, byTimestamp[timestamp])})
+			}
+			if reverse {
+				reverseTS(samples)
+			}
+			labels := [][2]string{{opts.groupBy, value}, {"__reducer__", opts.reducer},
+				{"__source__", strings.Join(groups[value], ",")}}
+			results = append(results, result{key: opts.groupBy + "=" + value, labels: labels, samples: samples})
+		}
+	}
+	if c.out.resp3() {
+		c.out.writeMapLen(len(results))
+	} else {
+		c.out.writeArrayLen(len(results))
+	}
+	for _, r := range results {
+		if c.out.resp3() {
+			c.out.writeBulk(r.key)
+			c.out.writeArrayLen(3)
+			c.out.writeMapLen(len(r.labels))
+			for _, label := range r.labels {
+				c.out.writeBulk(label[0])
+				c.out.writeBulk(label[1])
+			}
+			c.out.writeMapLen(0)
+		} else {
+			c.out.writeArrayLen(3)
+			c.out.writeBulk(r.key)
+			c.out.writeArrayLen(len(r.labels))
+			for _, label := range r.labels {
+				c.out.writeArrayLen(2)
+				c.out.writeBulk(label[0])
+				c.out.writeBulk(label[1])
+			}
+		}
+		writeTSSamples(c, r.samples)
+	}
+}
+
+func cmdTSCreateRule(c *conn, args []string) {
+	ks := c.db()
+	source, err := getTimeSeries(ks, args[1])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	dest, err := getTimeSeries(ks, args[2])
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	} else if source == nil || dest == nil {
+		c.out.writeError(errTSKeyNotExists.Error())
+		return
+	} else if args[1] == args[2] {
+		c.out.writeError("ERR TSDB: the source key and destination key should be different")
+		return
+	} else if !equalFold(args[3], "AGGREGATION") {
+		c.out.writeError(errSyntax.Error())
+		return
+	}
+	rule := &tsRule{dest: args[2], aggregation: strings.ToLower(args[4])}
+	if !containsFold(tsAggregations, rule.aggregation) {
+		c.out.writeError(errTSAggregation.Error())
+		return
+	}
+	if rule.bucket, err = parseInt(args[5]); err != nil || rule.bucket <= 0 {
+		c.out.writeError("ERR TSDB: bucketDuration must be greater than zero")
+		return
+	}
+	if len(args) > 6 {
+		if rule.align, err = parseInt(args[6]); err != nil {
+			c.out.writeError(err.Error())
+			return
+		}
+	}
+	for _, r := range source.rules {
+		if r.dest == rule.dest {
+			c.out.writeError("ERR TSDB: the destination key already has a src rule")
+			return
+		}
+	}
+	source.rules = append(source.rules, rule)
+	c.out.writeOK()
+}
+
+func cmdTSDeleteRule(c *conn, args []string) {
+	source, err := getTimeSeries(c.db(), args[1])
+	if err == nil && source == nil {
+		err = errTSKeyNotExists
+	}
+	if err != nil {
+		c.out.writeError(err.Error())
+		return
+	}
+	for i, rule := range source.rules {
+		if rule.dest == args[2] {
+			source.rules = append(source.rules[:i], source.rules[i+1:]...)
+			c.out.writeOK()
+			return
+		}
+	}
+	c.out.writeError("ERR TSDB: compaction rule does not exist")
+}
+
+func getTimeSeries(ks *keyspace, key string) (*timeSeries, error) {
+	value := ks.lookup(key, nil)
+	if value == nil {
+		return nil, nil
+	}
+	series, ok := value.(*timeSeries)
+	if !ok {
+		return nil, errWrongType
+	}
+	return series, nil
+}
+
+func newTimeSeries(opts tsArgs) *timeSeries {
+	series := &timeSeries{duplicatePolicy: opts.duplicatePolicy, labels: opts.labels}
+	if opts.retention != nil {
+		series.retention = *opts.retention
+	}
+	return series
+}
+
+// tsTimestamp parses the timestamp of a sample, * is the current time of the server.
+func (c *conn) tsTimestamp(arg string) (int64, error) {
+	if arg == "*" {
+		return c.server.store.now().UnixMilli(), nil
+	}
+	timestamp, err := parseInt(arg)
+	if err != nil || timestamp < 0 {
+		return 0, replyError("ERR TSDB: invalid timestamp")
+	}
+	return timestamp, nil
+}
+
+func parseTSArgs(args []string, add bool) (tsArgs, error) {
+	var opts tsArgs
+	for i := 0; i < len(args); i++ {
+		name := strings.ToUpper(args[i])
+		if i+1 >= len(args) {
+			return opts, errSyntax
+		}
+		switch {
+		case name == "RETENTION":
+			retention, err := parseInt(args[i+1])
+			if err != nil || retention < 0 {
+				return opts, replyError("ERR TSDB: invalid retention")
+			}
+			opts.retention = &retention
+		case name == "ENCODING", name == "CHUNK_SIZE":
+		case name == "DUPLICATE_POLICY", name == "ON_DUPLICATE" && add:
+			policy := strings.ToUpper(args[i+1])
+			if !containsFold(tsDuplicatePolicies, policy) {
+				return opts, replyError("ERR TSDB: Unknown DUPLICATE_POLICY")
+			} else if name == "ON_DUPLICATE" {
+				opts.onDuplicate = policy
+			} else {
+				opts.duplicatePolicy = policy
+			}
+		case name == "LABELS":
+			labels := args[i+1:]
+			if len(labels)%2 != 0 {
+				return opts, errSyntax
+			}
+			for j := 0; j+1 < len(labels); j += 2 {
+				opts.labels = append(opts.labels, [2]string{labels[j], labels[j+1]})
+			}
+			return opts, nil
+		default:
+			return opts, errSyntax
+		}
+		i++
+	}
+	return opts, nil
+}
+
+func parseTSRangeArgs(args []string, multi bool) (tsRangeArgs, error) {
+	opts := tsRangeArgs{count: -1}
+	var err error
+	if opts.from, err = parseTSRangeTimestamp(args[0], 0); err != nil {
+		return opts, err
+	} else if opts.to, err = parseTSRangeTimestamp(args[1], math.MaxInt64); err != nil {
+		return opts, err
+	}
+	for i := 2; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "LATEST":
+		case "WITHLABELS":
+			opts.withLabels = true
+		case "EMPTY":
+			opts.empty = true
+		case "COUNT", "ALIGN", "BUCKETTIMESTAMP", "SELECTED_LABELS", "FILTER_BY_VALUE":
+			if i+1 >= len(args) {
+				return opts, errSyntax
+			}
+			switch strings.ToUpper(args[i]) {
+			case "COUNT":
+				count, err := parseInt(args[i+1])
+				if err != nil || count < 0 {
+					return opts, replyError("ERR TSDB: Couldn't parse COUNT")
+				}
+				opts.count = int(count)
+			case "ALIGN":
+				switch args[i+1] {
+				case "-", "start":
+					opts.align = opts.from
+				case "+", "end":
+					opts.align = opts.to
+				default:
+					if opts.align, err = parseInt(args[i+1]); err != nil {
+						return opts, err
+					}
+				}
+			case "BUCKETTIMESTAMP":
+				opts.bucketTimestamp = args[i+1]
+			case "SELECTED_LABELS":
+				for i+1 < len(args) && !isTSRangeKeyword(args[i+1]) {
+					opts.selectedLabels = append(opts.selectedLabels, args[i+1])
+					i++
+				}
+				continue
+			case "FILTER_BY_VALUE":
+				if i+2 >= len(args) {
+					return opts, errSyntax
+				}
+				low, errLow := parseFloat(args[i+1])
+				high, errHigh := parseFloat(args[i+2])
+				if errLow != nil || errHigh != nil {
+					return opts, errSyntax
+				}
+				opts.filterByValue = []float64{low, high}
+				i++
+			}
+			i++
+		case "AGGREGATION":
+			if i+2 >= len(args) {
+				return opts, errSyntax
+			}
+			opts.aggregation = strings.ToLower(args[i+1])
+			if !containsFold(tsAggregations, opts.aggregation) {
+				return opts, errTSAggregation
+			}
+			if opts.bucket, err = parseInt(args[i+2]); err != nil || opts.bucket <= 0 {
+				return opts, replyError("ERR TSDB: bucketDuration must be greater than zero")
+			}
+			i += 2
+		case "FILTER_BY_TS":
+			for i+1 < len(args) && !isTSRangeKeyword(args[i+1]) {
+				timestamp, err := parseInt(args[i+1])
+				if err != nil {
+					return opts, replyError("ERR TSDB: invalid timestamp")
+				}
+				opts.filterByTS = append(opts.filterByTS, timestamp)
+				i++
+			}
+		case "FILTER":
+			if !multi {
+				return opts, errSyntax
+			}
+			for i+1 < len(args) && !isTSRangeKeyword(args[i+1]) {
+				opts.filters = append(opts.filters, args[i+1])
+				i++
+			}
+		case "GROUPBY":
+			if !multi || i+3 >= len(args) || !equalFold(args[i+2], "REDUCE") {
+				return opts, errSyntax
+			}
+			opts.groupBy = args[i+1]
+			opts.reducer = strings.ToLower(args[i+3])
+			if !containsFold(tsAggregations, opts.reducer) {
+				return opts, replyError("ERR TSDB: Invalid reducer")
+			}
+			i += 3
+		default:
+			return opts, errSyntax
+		}
+	}
+	if multi && len(opts.filters) == 0 {
+		return opts, replyError("ERR TSDB: missing FILTER argument")
+	}
+	return opts, nil
+}
+
+func parseTSRangeTimestamp(arg string, def int64) (int64, error) {
+	if arg == "-" || arg == "+" {
+		return def, nil
+	}
+	timestamp, err := parseInt(arg)
+	if err != nil {
+		return 0, replyError("ERR TSDB: invalid timestamp")
+	}
+	return timestamp, nil
+}
+
+func isTSRangeKeyword(arg string) bool {
+	switch strings.ToUpper(arg) {
+	case "LATEST", "WITHLABELS", "SELECTED_LABELS", "COUNT", "ALIGN", "AGGREGATION", "FILTER", "GROUPBY",
+		"FILTER_BY_VALUE", "FILTER_BY_TS", "BUCKETTIMESTAMP", "EMPTY":
+		return true
+	}
+	return false
+}
+
+// add adds the sample, resolving the duplicates with the policy, trimming the samples older than the retention
+// and compacting the buckets closed by the sample.
+func (s *timeSeries) add(ks *keyspace, timestamp int64, value float64, onDuplicate string) error {
+	last := int64(-1)
+	if len(s.samples) > 0 {
+		last = s.samples[len(s.samples)-1].timestamp
+	}
+	if s.retention > 0 && last >= 0 && timestamp < last-s.retention {
+		return errTSRetention
+	}
+	i := sort.Search(len(s.samples), func(i int) bool { return s.samples[i].timestamp >= timestamp })
+	if i < len(s.samples) && s.samples[i].timestamp == timestamp {
+		policy := onDuplicate
+		if policy == "" {
+			policy = s.duplicatePolicy
+		}
+		current := &s.samples[i].value
+		switch policy {
+		case "", "BLOCK":
+			return errTSBlock
+		case "LAST":
+			*current = value
+		case "MIN":
+			*current = math.Min(*current, value)
+		case "MAX":
+			*current = math.Max(*current, value)
+		case "SUM":
+			*current += value
+		}
+		return nil
+	}
+	s.samples = append(s.samples, tsSample{})
+	copy(s.samples[i+1:], s.samples[i:])
+	s.samples[i] = tsSample{timestamp, value}
+	if timestamp > last && last >= 0 {
+		for _, rule := range s.rules {
+			s.compact(ks, rule, last, timestamp)
+		}
+	}
+	if s.retention > 0 {
+		newest := s.samples[len(s.samples)-1].timestamp
+		j := sort.Search(len(s.samples), func(i int) bool { return s.samples[i].timestamp >= newest-s.retention })
+		s.samples = s.samples[j:]
+	}
+	return nil
+}
+
+// compact aggregates the bucket of the last sample to the dest of the rule, when the new sample starts another
+// bucket.
+func (s *timeSeries) compact(ks *keyspace, rule *tsRule, last, timestamp int64) {
+	start := tsBucketStart(last, rule.bucket, rule.align)
+	if tsBucketStart(timestamp, rule.bucket, rule.align) == start {
+		return
+	}
+	var samples []tsSample
+	for _, sample := range s.samples {
+		if sample.timestamp >= start && sample.timestamp < start+rule.bucket {
+			samples = append(samples, sample)
+		}
+	}
+	if dest, err := getTimeSeries(ks, rule.dest); err == nil && dest != nil && len(samples) > 0 {
+		_ = dest.add(ks, start, aggregateTS(rule.aggregation, samples), "LAST")
+		ks.touch(rule.dest)
+	}
+}
+
+// query returns the samples of the range, aggregated in buckets and limited by the count.
+func (s *timeSeries) query(opts tsRangeArgs, reverse bool) []tsSample {
+	var samples []tsSample
+	for _, sample := range s.samples {
+		if sample.timestamp < opts.from || sample.timestamp > opts.to {
+			continue
+		} else if opts.filterByValue != nil && (sample.value < opts.filterByValue[0] ||
+			sample.value > opts.filterByValue[1]) {
+			continue
+		} else if opts.filterByTS != nil && !containsTS(opts.filterByTS, sample.timestamp) {
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	if opts.aggregation != "" {
+		samples = aggregateTSBuckets(samples, opts)
+	}
+	if reverse {
+		reverseTS(samples)
+	}
+	if opts.count >= 0 && opts.count < len(samples) {
+		samples = samples[:opts.count]
+	}
+	return samples
+}
+
+// matches returns true if the labels match all the filters: label=value, label!=value, label=(a,b),
+// label!=(a,b), label= (without the label) and label!= (with the label).
+func (s *timeSeries) matches(filters []string) bool {
+	for _, filter := range filters {
+		name, value, negate := filter, "", false
+		if i := strings.Index(filter, "!="); i >= 0 {
+			name, value, negate = filter[:i], filter[i+2:], true
+		} else if i = strings.Index(filter, "="); i >= 0 {
+			name, value = filter[:i], filter[i+1:]
+		} else {
+			return false
+		}
+		values := []string{value}
+		if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+			values = strings.Split(value[1:len(value)-1], ",")
+		}
+		label, ok := s.label(name)
+		found := false
+		for _, v := range values {
+			if (v == "" && !ok) || (ok && v == label) {
+				found = true
+			}
+		}
+		if found == negate {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *timeSeries) label(name string) (string, bool) {
+	for _, label := range s.labels {
+		if label[0] == name {
+			return label[1], true
+		}
+	}
+	return "", false
+}
+
+// selectLabels returns the labels of WITHLABELS or SELECTED_LABELS.
+func (s *timeSeries) selectLabels(opts tsRangeArgs) [][2]string {
+	if opts.withLabels {
+		return s.labels
+	}
+	var labels [][2]string
+	for _, name := range opts.selectedLabels {
+		value, _ := s.label(name)
+		labels = append(labels, [2]string{name, value})
+	}
+	return labels
+}
+
+func tsBucketStart(timestamp, bucket, align int64) int64 {
+	offset := (timestamp - align) % bucket
+	if offset < 0 {
+		offset += bucket
+	}
+	return timestamp - offset
+}
+
+// aggregateTSBuckets aggregates the samples in buckets, the empty buckets between them are returned with EMPTY.
+func aggregateTSBuckets(samples []tsSample, opts tsRangeArgs) []tsSample {
+	var result []tsSample
+	for i := 0; i < len(samples); {
+		start := tsBucketStart(samples[i].timestamp, opts.bucket, opts.align)
+		j := i
+		for j < len(samples) && samples[j].timestamp < start+opts.bucket {
+			j++
+		}
+		if opts.empty && len(result) > 0 {
+			for empty := result[len(result)-1].timestamp + opts.bucket; empty < start; empty += opts.bucket {
+				value := math.NaN()
+				if opts.aggregation == "sum" || opts.aggregation == "count" {
+					value = 0
+				}
+				result = append(result, tsSample{empty, value})
+			}
+		}
+		result = append(result, tsSample{start, aggregateTS(opts.aggregation, samples[i:j])})
+		i = j
+	}
+	switch opts.bucketTimestamp {
+	case "+", "end":
+		for i := range result {
+			result[i].timestamp += opts.bucket
+		}
+	case "~", "mid":
+		for i := range result {
+			result[i].timestamp += opts.bucket / 2
+		}
+	}
+	return result
+}
+
+// aggregateTS aggregates the values of the samples, twa is computed as the average.
+func aggregateTS(aggregation string, samples []tsSample) float64 {
+	if len(samples) == 0 {
+		return math.NaN()
+	}
+	sum, low, high := 0.0, math.Inf(1), math.Inf(-1)
+	for _, sample := range samples {
+		sum += sample.value
+		low = math.Min(low, sample.value)
+		high = math.Max(high, sample.value)
+	}
+	n := float64(len(samples))
+	mean := sum / n
+	variance := 0.0
+	for _, sample := range samples {
+		variance += (sample.value - mean) * (sample.value - mean)
+	}
+	switch aggregation {
+	case "sum":
+		return sum
+	case "min":
+		return low
+	case "max":
+		return high
+	case "range":
+		return high - low
+	case "count":
+		return n
+	case "first":
+		return samples[0].value
+	case "last":
+		return samples[len(samples)-1].value
+	case "var.p", "std.p":
+		variance /= n
+	case "var.s", "std.s":
+		if n > 1 {
+			variance /= n - 1
+		}
+	default:
+		return mean
+	}
+	if strings.HasPrefix(aggregation, "std") {
+		return math.Sqrt(variance)
+	}
+	return variance
+}
+
+func containsTS(timestamps []int64, timestamp int64) bool {
+	for _, t := range timestamps {
+		if t == timestamp {
+			return true
+		}
+	}
+	return false
+}
+
+func reverseTS(samples []tsSample) {
+	for i, j := 0, len(samples)-1; i < j; i, j = i+1, j-1 {
+		samples[i], samples[j] = samples[j], samples[i]
+	}
+}
+
+// writeTSSamples writes the samples, the values are doubles in RESP3 and strings in RESP2.
+func writeTSSamples(c *conn, samples []tsSample) {
+	c.out.writeArrayLen(len(samples))
+	for _, sample := range samples {
+		c.out.writeArrayLen(2)
+		c.out.writeInt(sample.timestamp)
+		if c.out.resp3() {
+			c.out.writeDouble(sample.value)
+		} else {
+			c.out.writeBulk(strconv.FormatFloat(sample.value, 'f', -1, 64))
+		}
+	}
+}
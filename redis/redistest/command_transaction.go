@@ -0,0 +1,83 @@
+package redistest
+
+import (
+	"strings"
+)
+
+func registerTransactionCommands() {
+	register("multi", &command{arity: 1, flags: flagNoQueue, handler: cmdMulti})
+	register("exec", &command{arity: 1, flags: flagNoQueue, handler: cmdExec})
+	register("discard", &command{arity: 1, flags: flagNoQueue, handler: cmdDiscard})
+	register("watch", &command{arity: -2, flags: flagNoQueue, firstKey: 1, lastKey: -1, handler: cmdWatch})
+	register("unwatch", &command{arity: 1, flags: flagNoQueue, handler: cmdUnwatch})
+}
+
+func cmdMulti(c *conn, _ []string) {
+	if c.multi {
+		c.out.writeError("ERR MULTI calls can not be nested")
+		return
+	}
+	c.multi = true
+	c.out.writeOK()
+}
+
+// cmdExec executes the queued commands, the transaction is aborted when a command failed to be queued, or when a
+// watched key was written, the blocking commands are executed without blocking.
+func cmdExec(c *conn, _ []string) {
+	if !c.multi {
+		c.out.writeError("ERR EXEC without MULTI")
+		return
+	}
+	queued, aborted, dirty := c.queued, c.multiErr, c.watchDirty()
+	c.resetMulti()
+	if aborted {
+		c.out.writeError("EXECABORT Transaction discarded because of previous errors.")
+		return
+	} else if dirty {
+		c.out.writeNullArray()
+		return
+	}
+	c.out.writeArrayLen(len(queued))
+	for _, args := range queued {
+		cmd := commands[strings.ToLower(args[0])]
+		if !c.server.call(c, cmd, args) {
+			cmd.timeout(c.out)
+		}
+	}
+}
+
+func cmdDiscard(c *conn, _ []string) {
+	if !c.multi {
+		c.out.writeError("ERR DISCARD without MULTI")
+		return
+	}
+	c.resetMulti()
+	c.out.writeOK()
+}
+
+func cmdWatch(c *conn, args []string) {
+	if c.multi {
+		c.out.writeError("ERR WATCH inside MULTI is not allowed")
+		return
+	}
+	ks := c.db()
+	for _, key := range args[1:] {
+		c.watched[watchKey{index: c.index, key: key}] = ks.version(key)
+	}
+	c.out.writeOK()
+}
+
+func cmdUnwatch(c *conn, _ []string) {
+	c.watched = map[watchKey]uint64{}
+	c.out.writeOK()
+}
+
+// watchDirty returns true when a watched key was written after the WATCH command.
+func (c *conn) watchDirty() bool {
+	for w, version := range c.watched {
+		if c.server.store.db(w.index).version(w.key) != version {
+			return true
+		}
+	}
+	return false
+}
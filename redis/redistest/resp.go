@@ -0,0 +1,199 @@
+package redistest
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var errProtocol = errors.New("ERR Protocol error")
+
+type respReader struct {
+	reader *bufio.Reader
+}
+
+// respWriter buffers replies in RESP2 or RESP3 according to the protocol negotiated by the HELLO command, the
+// buffer is sent to the connection after the command is executed.
+type respWriter struct {
+	buffer   bytes.Buffer
+	protocol int
+}
+
+func newRespReader(r io.Reader) *respReader {
+	return &respReader{reader: bufio.NewReader(r)}
+}
+
+func newRespWriter(protocol int) *respWriter {
+	return &respWriter{protocol: protocol}
+}
+
+// readCommand reads a command sent as an array of bulk strings, or an inline command.
+func (r *respReader) readCommand() ([]string, error) {
+	line, err := r.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, nil
+	} else if line[0] != '*' {
+		return strings.Fields(line), nil
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < 0 {
+		return nil, errProtocol
+	}
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		line, err = r.readLine()
+		if err != nil {
+			return nil, err
+		} else if len(line) == 0 || line[0] != '$' {
+			return nil, errProtocol
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, errProtocol
+		}
+		buf := make([]byte, size+2)
+		if _, err = io.ReadFull(r.reader, buf); err != nil {
+			return nil, err
+		}
+		args = append(args, string(buf[:size]))
+	}
+	return args, nil
+}
+
+func (r *respReader) buffered() int {
+	return r.reader.Buffered()
+}
+
+func (r *respReader) readLine() (string, error) {
+	line, err := r.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// take returns the buffered replies and resets the buffer.
+func (w *respWriter) take() []byte {
+	b := append([]byte(nil), w.buffer.Bytes()...)
+	w.buffer.Reset()
+	return b
+}
+
+func (w *respWriter) resp3() bool {
+	return w.protocol == 3
+}
+
+func (w *respWriter) writeSimple(s string) {
+	w.writeLine("+" + s)
+}
+
+func (w *respWriter) writeOK() {
+	w.writeSimple("OK")
+}
+
+func (w *respWriter) writeError(msg string) {
+	w.writeLine("-" + msg)
+}
+
+func (w *respWriter) writeInt(n int64) {
+	w.writeLine(":" + strconv.FormatInt(n, 10))
+}
+
+func (w *respWriter) writeBool(b bool) {
+	if b {
+		w.writeInt(1)
+	} else {
+		w.writeInt(0)
+	}
+}
+
+func (w *respWriter) writeBulk(s string) {
+	w.writeLine("$" + strconv.Itoa(len(s)))
+	w.writeLine(s)
+}
+
+func (w *respWriter) writeNull() {
+	if w.resp3() {
+		w.writeLine("_")
+	} else {
+		w.writeLine("$-1")
+	}
+}
+
+func (w *respWriter) writeNullArray() {
+	if w.resp3() {
+		w.writeLine("_")
+	} else {
+		w.writeLine("*-1")
+	}
+}
+
+func (w *respWriter) writeDouble(f float64) {
+	if w.resp3() {
+		w.writeLine("," + formatFloat(f))
+	} else {
+		w.writeBulk(formatFloat(f))
+	}
+}
+
+func (w *respWriter) writeArrayLen(n int) {
+	w.writeLine("*" + strconv.Itoa(n))
+}
+
+// writeMapLen writes the header of a map with n pairs, in RESP2 it is flattened into an array of 2*n elements.
+func (w *respWriter) writeMapLen(n int) {
+	if w.resp3() {
+		w.writeLine("%" + strconv.Itoa(n))
+	} else {
+		w.writeArrayLen(2 * n)
+	}
+}
+
+func (w *respWriter) writeSetLen(n int) {
+	if w.resp3() {
+		w.writeLine("~" + strconv.Itoa(n))
+	} else {
+		w.writeArrayLen(n)
+	}
+}
+
+func (w *respWriter) writePushLen(n int) {
+	if w.resp3() {
+		w.writeLine(">" + strconv.Itoa(n))
+	} else {
+		w.writeArrayLen(n)
+	}
+}
+
+func (w *respWriter) writeStrings(values []string) {
+	w.writeArrayLen(len(values))
+	for _, value := range values {
+		w.writeBulk(value)
+	}
+}
+
+func (w *respWriter) writeLine(s string) {
+	w.buffer.WriteString(s)
+	w.buffer.WriteString("\r\n")
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "inf"
+	} else if math.IsInf(f, -1) {
+		return "-inf"
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func errWrongArgs(name string) error {
+	return fmt.Errorf("ERR wrong number of arguments for '%s' command", strings.ToLower(name))
+}
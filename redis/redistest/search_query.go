@@ -0,0 +1,576 @@
+package redistest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// searchNode is a node of the query of FT.SEARCH and FT.AGGREGATE, the subset supported is: the terms and the
+// "phrases" of the text fields, @field:(...), @field:{tag | tag}, @field:[min max] with ( exclusive and inf,
+// @field:[lon lat radius unit], the negation -, the union |, the parentheses and *. The query can be followed by
+// the vector search =>[KNN k @field $param AS alias] of FT.SEARCH.
+type searchNode interface {
+	match(idx *searchIndex, doc *searchDoc) bool
+}
+
+type searchAll struct{}
+
+// searchTerms matches the terms in sequence, the last one as a prefix when prefix is true.
+type searchTerms struct {
+	field  string
+	terms  []string
+	prefix bool
+}
+
+type searchTags struct {
+	field  string
+	values []string
+}
+
+type searchRange struct {
+	field        string
+	min          float64
+	max          float64
+	minExclusive bool
+	maxExclusive bool
+}
+
+type searchGeoRadius struct {
+	field     string
+	longitude float64
+	latitude  float64
+	radius    float64
+}
+
+type searchNot struct {
+	node searchNode
+}
+
+type searchAnd []searchNode
+
+type searchOr []searchNode
+
+// searchKNN is the vector search of a query, the k documents nearest to the vector, with the distance returned as
+// the alias field.
+type searchKNN struct {
+	k      int
+	field  string
+	vector []float32
+	alias  string
+}
+
+// searchQuery is a parsed query, with its terms, used by the scores and the highlight.
+type searchQuery struct {
+	root  searchNode
+	terms []string
+	knn   *searchKNN
+}
+
+type searchParser struct {
+	input  []rune
+	pos    int
+	params map[string]string
+	field  string
+	terms  []string
+}
+
+func parseSearchQuery(query string, params map[string]string) (*searchQuery, error) {
+	query, knn, err := parseSearchKNN(query, params)
+	if err != nil {
+		return nil, err
+	}
+	p := &searchParser{input: []rune(strings.TrimSpace(query)), params: params}
+	root, err := p.parseUnion()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if !p.eof() {
+		return nil, p.syntaxError()
+	}
+	return &searchQuery{root: root, terms: p.terms, knn: knn}, nil
+}
+
+// parseSearchKNN splits the query and its vector search =>[KNN k @field $param [AS alias]], the default alias is
+// __field_score.
+func parseSearchKNN(query string, params map[string]string) (string, *searchKNN, error) {
+	i := strings.LastIndex(query, "=>")
+	if i < 0 {
+		return query, nil, nil
+	}
+	clause := strings.TrimSpace(query[i+2:])
+	if !strings.HasPrefix(clause, "[") || !strings.HasSuffix(clause, "]") {
+		return query, nil, replyError("Syntax error at offset " + strconv.Itoa(i) + " near =>")
+	}
+	parts := strings.Fields(clause[1 : len(clause)-1])
+	for j, part := range parts {
+		if value, ok := params[strings.TrimPrefix(part, "$")]; ok && strings.HasPrefix(part, "$") && j != 3 {
+			parts[j] = value
+		}
+	}
+	if (len(parts) != 4 && len(parts) != 6) || !equalFold(parts[0], "KNN") || !strings.HasPrefix(parts[2], "@") ||
+		!strings.HasPrefix(parts[3], "$") || (len(parts) == 6 && !equalFold(parts[4], "AS")) {
+		return query, nil, replyError("Syntax error at offset " + strconv.Itoa(i) + " near " + clause)
+	}
+	k, err := strconv.Atoi(parts[1])
+	if err != nil || k < 0 {
+		return query, nil, replyError("Invalid K value: " + parts[1])
+	}
+	blob, ok := params[parts[3][1:]]
+	if !ok {
+		return query, nil, replyError("No such parameter `" + parts[3][1:] + "`")
+	}
+	knn := &searchKNN{k: k, field: parts[2][1:], vector: decodeSearchVector([]byte(blob))}
+	knn.alias = "__" + knn.field + "_score"
+	if len(parts) == 6 {
+		knn.alias = parts[5]
+	}
+	return query[:i], knn, nil
+}
+
+func (p *searchParser) parseUnion() (searchNode, error) {
+	var nodes searchOr
+	for {
+		node, err := p.parseIntersect()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+		p.skipSpaces()
+		if p.peek() != '|' {
+			break
+		}
+		p.pos++
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+func (p *searchParser) parseIntersect() (searchNode, error) {
+	var nodes searchAnd
+	for {
+		p.skipSpaces()
+		if p.eof() || p.peek() == ')' || p.peek() == '|' {
+			break
+		}
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	if len(nodes) == 0 {
+		return nil, p.syntaxError()
+	} else if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+func (p *searchParser) parseUnary() (searchNode, error) {
+	switch p.peek() {
+	case '-':
+		p.pos++
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return searchNot{node: node}, nil
+	case '~':
+		// the optional terms only change the score, they are required by the in-memory search.
+		p.pos++
+	}
+	return p.parseAtom()
+}
+
+func (p *searchParser) parseAtom() (searchNode, error) {
+	switch p.peek() {
+	case '(':
+		return p.parseGroup()
+	case '*':
+		p.pos++
+		return searchAll{}, nil
+	case '"':
+		return p.parsePhrase(p.field)
+	case '@':
+		p.pos++
+		field := p.readName()
+		if field == "" || p.peek() != ':' {
+			return nil, p.syntaxError()
+		}
+		p.pos++
+		p.skipSpaces()
+		switch p.peek() {
+		case '{':
+			return p.parseTags(field)
+		case '[':
+			return p.parseBrackets(field)
+		case '(':
+			saved := p.field
+			p.field = field
+			node, err := p.parseGroup()
+			p.field = saved
+			return node, err
+		case '"':
+			return p.parsePhrase(field)
+		}
+		return p.parseTerm(field)
+	}
+	return p.parseTerm(p.field)
+}
+
+func (p *searchParser) parseGroup() (searchNode, error) {
+	p.pos++
+	node, err := p.parseUnion()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.peek() != ')' {
+		return nil, p.syntaxError()
+	}
+	p.pos++
+	return node, nil
+}
+
+func (p *searchParser) parseTerm(field string) (searchNode, error) {
+	var word strings.Builder
+	prefix := false
+	for !p.eof() {
+		r := p.peek()
+		if r == '\\' && p.pos+1 < len(p.input) {
+			word.WriteRune(p.input[p.pos+1])
+			p.pos += 2
+			continue
+		} else if r == '*' {
+			prefix = true
+			p.pos++
+			break
+		} else if unicode.IsSpace(r) || strings.ContainsRune("()|{}[]\"@", r) {
+			break
+		}
+		word.WriteRune(r)
+		p.pos++
+	}
+	term := word.String()
+	if value, ok := p.params[strings.TrimPrefix(term, "$")]; ok && strings.HasPrefix(term, "$") {
+		term = value
+	}
+	return p.termsNode(field, tokenizeSearch(term), prefix)
+}
+
+func (p *searchParser) parsePhrase(field string) (searchNode, error) {
+	p.pos++
+	var phrase strings.Builder
+	for !p.eof() && p.peek() != '"' {
+		if p.peek() == '\\' && p.pos+1 < len(p.input) {
+			p.pos++
+		}
+		phrase.WriteRune(p.peek())
+		p.pos++
+	}
+	if p.eof() {
+		return nil, p.syntaxError()
+	}
+	p.pos++
+	return p.termsNode(field, tokenizeSearch(phrase.String()), false)
+}
+
+func (p *searchParser) termsNode(field string, terms []string, prefix bool) (searchNode, error) {
+	if len(terms) == 0 {
+		return nil, p.syntaxError()
+	}
+	p.terms = append(p.terms, terms...)
+	return searchTerms{field: field, terms: terms, prefix: prefix}, nil
+}
+
+func (p *searchParser) parseTags(field string) (searchNode, error) {
+	p.pos++
+	node := searchTags{field: field}
+	var value strings.Builder
+	for {
+		if p.eof() {
+			return nil, p.syntaxError()
+		}
+		r := p.peek()
+		p.pos++
+		if r == '\\' && !p.eof() {
+			value.WriteRune(p.peek())
+			p.pos++
+			continue
+		} else if r == '|' || r == '}' {
+			if tag := strings.TrimSpace(value.String()); tag != "" {
+				node.values = append(node.values, tag)
+			}
+			value.Reset()
+			if r == '}' {
+				return node, nil
+			}
+			continue
+		}
+		value.WriteRune(r)
+	}
+}
+
+func (p *searchParser) parseBrackets(field string) (searchNode, error) {
+	end := p.pos
+	for end < len(p.input) && p.input[end] != ']' {
+		end++
+	}
+	if end == len(p.input) {
+		return nil, p.syntaxError()
+	}
+	parts := strings.Fields(string(p.input[p.pos+1 : end]))
+	p.pos = end + 1
+	for i, part := range parts {
+		if value, ok := p.params[strings.TrimPrefix(part, "$")]; ok && strings.HasPrefix(part, "$") {
+			parts[i] = value
+		}
+	}
+	switch len(parts) {
+	case 2:
+		node := searchRange{field: field}
+		var err error
+		if node.min, node.minExclusive, err = parseSearchNumber(parts[0]); err != nil {
+			return nil, err
+		} else if node.max, node.maxExclusive, err = parseSearchNumber(parts[1]); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case 4:
+		node := searchGeoRadius{field: field}
+		var err error
+		if node.longitude, err = parseFloat(parts[0]); err != nil {
+			return nil, err
+		} else if node.latitude, err = parseFloat(parts[1]); err != nil {
+			return nil, err
+		} else if node.radius, err = parseFloat(parts[2]); err != nil {
+			return nil, err
+		}
+		unit, err := parseGeoUnit(parts[3])
+		if err != nil {
+			return nil, err
+		}
+		node.radius *= unit
+		return node, nil
+	}
+	return nil, p.syntaxError()
+}
+
+func (p *searchParser) readName() string {
+	start := p.pos
+	for !p.eof() && (unicode.IsLetter(p.peek()) || unicode.IsDigit(p.peek()) || p.peek() == '_') {
+		p.pos++
+	}
+	return string(p.input[start:p.pos])
+}
+
+func (p *searchParser) skipSpaces() {
+	for !p.eof() && unicode.IsSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+func (p *searchParser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *searchParser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *searchParser) syntaxError() error {
+	return replyError(fmt.Sprintf("Syntax error at offset %d near %s", p.pos, string(p.input[min(p.pos,
+		len(p.input)):])))
+}
+
+// parseSearchNumber parses a limit of a numeric range, ( means exclusive.
+func parseSearchNumber(s string) (float64, bool, error) {
+	exclusive := strings.HasPrefix(s, "(")
+	s = strings.TrimPrefix(s, "(")
+	switch strings.ToLower(s) {
+	case "inf", "+inf":
+		return math.Inf(1), exclusive, nil
+	case "-inf":
+		return math.Inf(-1), exclusive, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false, replyError("Bad range: " + s)
+	}
+	return f, exclusive, nil
+}
+
+// tokenizeSearch splits the text in lower case terms, separated by the spaces and the punctuation.
+func tokenizeSearch(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+}
+
+func (searchAll) match(*searchIndex, *searchDoc) bool {
+	return true
+}
+
+func (n searchTerms) match(idx *searchIndex, doc *searchDoc) bool {
+	for _, field := range idx.textFields(n.field) {
+		for _, value := range doc.values[field.name] {
+			if containsTerms(tokenizeSearch(value), n.terms, n.prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (n searchTags) match(idx *searchIndex, doc *searchDoc) bool {
+	field := idx.field(n.field)
+	if field == nil || field.typ != "TAG" {
+		return false
+	}
+	for _, tag := range doc.tags(field) {
+		for _, value := range n.values {
+			if tag == value || (!field.caseSensitive && strings.EqualFold(tag, value)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (n searchRange) match(idx *searchIndex, doc *searchDoc) bool {
+	field := idx.field(n.field)
+	if field == nil || field.typ != "NUMERIC" {
+		return false
+	}
+	for _, value := range doc.values[field.name] {
+		f, err := strconv.ParseFloat(value, 64)
+		if err == nil && (f > n.min || (f == n.min && !n.minExclusive)) &&
+			(f < n.max || (f == n.max && !n.maxExclusive)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n searchGeoRadius) match(idx *searchIndex, doc *searchDoc) bool {
+	field := idx.field(n.field)
+	if field == nil || field.typ != "GEO" {
+		return false
+	}
+	for _, value := range doc.values[field.name] {
+		longitude, latitude, ok := strings.Cut(value, ",")
+		lon, errLon := strconv.ParseFloat(strings.TrimSpace(longitude), 64)
+		lat, errLat := strconv.ParseFloat(strings.TrimSpace(latitude), 64)
+		if ok && errLon == nil && errLat == nil && geoDistance(n.longitude, n.latitude, lon, lat) <= n.radius {
+			return true
+		}
+	}
+	return false
+}
+
+func (n searchNot) match(idx *searchIndex, doc *searchDoc) bool {
+	return !n.node.match(idx, doc)
+}
+
+func (n searchAnd) match(idx *searchIndex, doc *searchDoc) bool {
+	for _, node := range n {
+		if !node.match(idx, doc) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n searchOr) match(idx *searchIndex, doc *searchDoc) bool {
+	for _, node := range n {
+		if node.match(idx, doc) {
+			return true
+		}
+	}
+	return false
+}
+
+// nearest returns the k documents nearest to the vector of the search, sorted by the distance and the key, with
+// the distance as the value of the alias.
+func (knn *searchKNN) nearest(idx *searchIndex, docs []*searchDoc) ([]*searchDoc, error) {
+	field := idx.field(knn.field)
+	if field == nil || field.typ != "VECTOR" {
+		return nil, replyError("Unknown field `" + knn.field + "`")
+	} else if field.dim > 0 && len(knn.vector) != field.dim {
+		return nil, replyError("Error parsing vector similarity query: query vector blob size (" +
+			strconv.Itoa(4*len(knn.vector)) + ") does not match index's expected size (" + strconv.Itoa(4*field.dim) +
+			").")
+	}
+	var nearest []*searchDoc
+	distances := map[*searchDoc]float64{}
+	for _, doc := range docs {
+		vector := doc.vectors[field.name]
+		if len(vector) != len(knn.vector) {
+			continue
+		}
+		distances[doc] = vectorDistance(field.distance, knn.vector, vector)
+		nearest = append(nearest, doc)
+	}
+	sort.SliceStable(nearest, func(i, j int) bool {
+		if distances[nearest[i]] != distances[nearest[j]] {
+			return distances[nearest[i]] < distances[nearest[j]]
+		}
+		return nearest[i].key < nearest[j].key
+	})
+	nearest = nearest[:min(knn.k, len(nearest))]
+	for _, doc := range nearest {
+		doc.values[knn.alias] = []string{formatFloat(distances[doc])}
+	}
+	return nearest, nil
+}
+
+// vectorDistance returns the distance of the vectors of RediSearch, the squared euclidean distance for L2, and 1
+// minus the inner product or the cosine similarity for IP and COSINE.
+func vectorDistance(metric string, a, b []float32) float64 {
+	var dot, normA, normB, l2 float64
+	for i := range a {
+		x, y := float64(a[i]), float64(b[i])
+		dot += x * y
+		normA += x * x
+		normB += y * y
+		l2 += (x - y) * (x - y)
+	}
+	switch metric {
+	case "L2":
+		return l2
+	case "IP":
+		return 1 - dot
+	}
+	if normA == 0 || normB == 0 {
+		return 1
+	}
+	return 1 - dot/math.Sqrt(normA*normB)
+}
+
+// containsTerms returns true if the terms are in sequence in the tokens.
+func containsTerms(tokens, terms []string, prefix bool) bool {
+	for i := 0; i+len(terms) <= len(tokens); i++ {
+		found := true
+		for j, term := range terms {
+			last := j == len(terms)-1
+			if tokens[i+j] != term && !(last && prefix && strings.HasPrefix(tokens[i+j], term)) {
+				found = false
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
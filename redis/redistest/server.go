@@ -0,0 +1,481 @@
+package redistest
+
+import (
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Fault is injected by the Server before a command is executed, see Server.SetFaultHook.
+type Fault struct {
+	// Latency waited before the command is executed.
+	Latency time.Duration
+	// Drop closes the connection without answering the command.
+	Drop bool
+	// Err is answered instead of executing the command, the first word is the error prefix, e.g. "LOADING Redis is
+	// loading the dataset in memory".
+	Err string
+}
+
+// FaultHook is called before each command received by the Server, args contains the command name followed by
+// its arguments, return the zero Fault to execute the command normally.
+type FaultHook func(args []string) Fault
+
+// Server is an in-process redis server speaking RESP2 and RESP3, it supports the strings, keys, hashes, lists,
+// sets, sorted sets, pub/sub and transaction commands, so the redis.Template and the go-redis client can be tested
+// without a real redis.
+//
+// The Lua scripts are emulated by Go functions, see SetScript.
+//
+// Faults can be injected with SetLatency, SetError, SetReadOnly, DropConnections and SetFaultHook.
+type Server struct {
+	listener  net.Listener
+	store     *store
+	startedAt time.Time
+	wait      sync.WaitGroup
+	// mutex guards the connections and the faults.
+	mutex     sync.Mutex
+	conns     map[*conn]struct{}
+	nextID    int64
+	closed    bool
+	latency   time.Duration
+	errs      map[string]string
+	readOnly  bool
+	version   string
+	faultHook FaultHook
+	// channels and patterns are the pub/sub subscriptions, guarded by the store mutex as the commands.
+	channels map[string]map[*conn]struct{}
+	patterns map[string]map[*conn]struct{}
+	// scripts are the functions emulating the Lua scripts and loaded are the scripts cached by EVAL and SCRIPT LOAD,
+	// guarded by the store mutex as the commands.
+	scripts map[string]ScriptFunc
+	loaded  map[string]struct{}
+}
+
+// NewServer starts a new server listening on a random local port, the server is closed when the test finishes.
+//
+// To customize the server, use the opts parameter (Options).
+func NewServer(t testing.TB, opts ...*Options) *Server {
+	t.Helper()
+	var clock Clock
+	for _, opt := range opts {
+		if helper.IsNotNil(opt) && helper.IsNotNil(opt.Clock) {
+			clock = opt.Clock
+		}
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if helper.IsNotNil(err) {
+		t.Fatal("redistest: listen err =", err)
+	}
+	s := &Server{
+		listener:  listener,
+		store:     newStore(clock),
+		startedAt: time.Now(),
+		conns:     map[*conn]struct{}{},
+		errs:      map[string]string{},
+		channels:  map[string]map[*conn]struct{}{},
+		patterns:  map[string]map[*conn]struct{}{},
+		scripts:   map[string]ScriptFunc{},
+		loaded:    map[string]struct{}{},
+	}
+	s.wait.Add(1)
+	go s.serve()
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Addr returns the host:port address of the server.
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// ClientOptions returns the options to connect redis.NewTemplate to the server.
+func (s *Server) ClientOptions() option.Client {
+	return option.Client{
+		Addr: s.Addr(),
+	}
+}
+
+// Close stops the server and closes all connections.
+func (s *Server) Close() {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return
+	}
+	s.closed = true
+	_ = s.listener.Close()
+	for c := range s.conns {
+		c.close()
+	}
+	s.mutex.Unlock()
+	s.wait.Wait()
+}
+
+// Flush removes all keys of all databases.
+func (s *Server) Flush() {
+	s.store.mutex.Lock()
+	defer s.store.mutex.Unlock()
+	for _, ks := range s.store.databases {
+		ks.flush()
+	}
+	s.store.notify()
+}
+
+// Connections returns the number of open connections.
+func (s *Server) Connections() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.conns)
+}
+
+// SetLatency sets the latency waited before each command is executed, zero removes the latency.
+func (s *Server) SetLatency(latency time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.latency = latency
+}
+
+// SetError answers msg instead of executing the command, e.g. SetError("get", "ERR boom"), an empty command
+// applies to all commands except the connection handshake (HELLO, AUTH, CLIENT and SELECT), an empty msg removes
+// the error.
+func (s *Server) SetError(command, msg string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	command = strings.ToLower(command)
+	if helper.IsEmpty(msg) {
+		delete(s.errs, command)
+	} else {
+		s.errs[command] = msg
+	}
+}
+
+// SetReadOnly makes the server behave as a replica, the write commands are answered with the READONLY error and
+// the INFO command reports the slave role.
+func (s *Server) SetReadOnly(readOnly bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.readOnly = readOnly
+}
+
+// SetVersion sets the redis version reported by the HELLO and INFO commands, to test the behavior with older
+// servers, the commands are still executed as the default version.
+// Default is 7.2.0.
+func (s *Server) SetVersion(version string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.version = version
+}
+
+// SetFaultHook sets the hook called before each command, nil removes the hook.
+func (s *Server) SetFaultHook(hook FaultHook) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.faultHook = hook
+}
+
+// DropConnections closes all open connections, the clients must reconnect.
+func (s *Server) DropConnections() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for c := range s.conns {
+		c.close()
+	}
+}
+
+func (s *Server) serve() {
+	defer s.wait.Done()
+	for {
+		netConn, err := s.listener.Accept()
+		if helper.IsNotNil(err) {
+			return
+		}
+		s.mutex.Lock()
+		if s.closed {
+			s.mutex.Unlock()
+			_ = netConn.Close()
+			return
+		}
+		s.nextID++
+		c := newConn(s, netConn, s.nextID)
+		s.conns[c] = struct{}{}
+		s.wait.Add(1)
+		s.mutex.Unlock()
+		go c.serve()
+	}
+}
+
+func (s *Server) removeConn(c *conn) {
+	s.mutex.Lock()
+	delete(s.conns, c)
+	s.mutex.Unlock()
+	s.store.mutex.Lock()
+	s.unsubscribeAll(c, s.channels)
+	s.unsubscribeAll(c, s.patterns)
+	s.store.mutex.Unlock()
+}
+
+// fault returns the fault injected before the command, combining the hook and the static faults.
+func (s *Server) fault(args []string, cmd *command) Fault {
+	s.mutex.Lock()
+	hook := s.faultHook
+	fault := Fault{Latency: s.latency}
+	name := strings.ToLower(args[0])
+	if msg, ok := s.errs[name]; ok {
+		fault.Err = msg
+	} else if msg, ok = s.errs[""]; ok && (cmd == nil || !cmd.has(flagHandshake)) {
+		fault.Err = msg
+	}
+	if helper.IsEmpty(fault.Err) && s.readOnly && cmd != nil && cmd.has(flagWrite) {
+		fault.Err = "READONLY You can't write against a read only replica."
+	}
+	s.mutex.Unlock()
+	if hook != nil {
+		hooked := hook(args)
+		fault.Latency += hooked.Latency
+		fault.Drop = fault.Drop || hooked.Drop
+		if helper.IsNotEmpty(hooked.Err) {
+			fault.Err = hooked.Err
+		}
+	}
+	return fault
+}
+
+func (s *Server) isReadOnly() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.readOnly
+}
+
+func (s *Server) redisVersion() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return helper.IfEmptyReturns(s.version, defaultVersion)
+}
+
+// execute runs the command received by the connection, returning false when the connection must be closed.
+func (s *Server) execute(c *conn, args []string) bool {
+	name := strings.ToLower(args[0])
+	cmd := commands[name]
+	fault := s.fault(args, cmd)
+	if fault.Latency > 0 {
+		time.Sleep(fault.Latency)
+	}
+	if fault.Drop {
+		return false
+	} else if helper.IsNotEmpty(fault.Err) {
+		c.abortMulti()
+		c.out.writeError(fault.Err)
+		return true
+	} else if cmd == nil {
+		c.abortMulti()
+		c.out.writeError("ERR unknown command '" + args[0] + "', with args beginning with: " +
+			formatArgs(args[1:]))
+		return true
+	} else if !cmd.checkArity(len(args)) {
+		c.abortMulti()
+		c.out.writeError(errWrongArgs(name).Error())
+		return true
+	} else if c.multi && !cmd.has(flagNoQueue) {
+		c.queued = append(c.queued, args)
+		c.out.writeSimple("QUEUED")
+		return true
+	} else if c.subscribed() && !c.out.resp3() && !cmd.has(flagPubSub) {
+		c.out.writeError("ERR Can't execute '" + name + "': only (P|S)SUBSCRIBE / (P|S)UNSUBSCRIBE / PING / QUIT / " +
+			"RESET are allowed in this context")
+		return true
+	} else if cmd.block != nil {
+		return s.executeBlocking(c, cmd, args)
+	}
+	s.store.mutex.Lock()
+	s.call(c, cmd, args)
+	s.store.mutex.Unlock()
+	return !c.quit
+}
+
+// executeBlocking runs the blocking command until it is served, the timeout expires or the connection is closed,
+// the store is unlocked while waiting for a change.
+func (s *Server) executeBlocking(c *conn, cmd *command, args []string) bool {
+	timeout, err := parseTimeout(args[len(args)-1])
+	if helper.IsNotNil(err) {
+		c.out.writeError(err.Error())
+		return true
+	}
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	for {
+		s.store.mutex.Lock()
+		changed := s.store.changed
+		served := s.call(c, cmd, args)
+		s.store.mutex.Unlock()
+		if served {
+			return true
+		}
+		select {
+		case <-changed:
+		case <-expired:
+			cmd.timeout(c.out)
+			return true
+		case <-c.done:
+			return false
+		}
+	}
+}
+
+// call executes the command with the store locked, the keys of the write commands are touched for the WATCH
+// command and the blocking commands are notified, returns false if a blocking command has nothing to serve.
+func (s *Server) call(c *conn, cmd *command, args []string) bool {
+	if cmd.block != nil {
+		if !cmd.block(c, args) {
+			return false
+		}
+	} else {
+		cmd.handler(c, args)
+	}
+	if cmd.has(flagWrite) {
+		ks := c.db()
+		for _, key := range cmd.keys(args) {
+			ks.touch(key)
+		}
+		s.store.notify()
+	}
+	return true
+}
+
+func (s *Server) unsubscribeAll(c *conn, subscriptions map[string]map[*conn]struct{}) {
+	for name, conns := range subscriptions {
+		delete(conns, c)
+		if helper.IsEmpty(conns) {
+			delete(subscriptions, name)
+		}
+	}
+}
+
+// conn is a client connection, its state is only changed by its goroutine or with the store locked.
+type conn struct {
+	server    *Server
+	netConn   net.Conn
+	id        int64
+	reader    *respReader
+	out       *respWriter
+	sendMutex sync.Mutex
+	closeOnce sync.Once
+	done      chan struct{}
+	index     int
+	name      string
+	quit      bool
+	multi     bool
+	multiErr  bool
+	queued    [][]string
+	watched   map[watchKey]uint64
+	channels  map[string]struct{}
+	patterns  map[string]struct{}
+}
+
+// watchKey identifies a key watched by the WATCH command.
+type watchKey struct {
+	index int
+	key   string
+}
+
+func newConn(s *Server, netConn net.Conn, id int64) *conn {
+	return &conn{
+		server:   s,
+		netConn:  netConn,
+		id:       id,
+		reader:   newRespReader(netConn),
+		out:      newRespWriter(2),
+		done:     make(chan struct{}),
+		watched:  map[watchKey]uint64{},
+		channels: map[string]struct{}{},
+		patterns: map[string]struct{}{},
+	}
+}
+
+func (c *conn) serve() {
+	defer c.server.wait.Done()
+	defer c.server.removeConn(c)
+	defer c.close()
+	for {
+		args, err := c.reader.readCommand()
+		if errors.Is(err, errProtocol) {
+			c.out.writeError(err.Error())
+			_ = c.flush()
+			return
+		} else if helper.IsNotNil(err) {
+			return
+		} else if helper.IsEmpty(args) {
+			continue
+		}
+		ok := c.server.execute(c, args)
+		if c.reader.buffered() == 0 || !ok {
+			if helper.IsNotNil(c.flush()) {
+				return
+			}
+		}
+		if !ok {
+			return
+		}
+	}
+}
+
+func (c *conn) flush() error {
+	return c.send(c.out.take())
+}
+
+// send writes the replies to the connection, it is called by the publishers to deliver the pub/sub messages.
+func (c *conn) send(b []byte) error {
+	if helper.IsEmpty(b) {
+		return nil
+	}
+	c.sendMutex.Lock()
+	defer c.sendMutex.Unlock()
+	_, err := c.netConn.Write(b)
+	return err
+}
+
+func (c *conn) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		_ = c.netConn.Close()
+	})
+}
+
+func (c *conn) db() *keyspace {
+	return c.server.store.db(c.index)
+}
+
+func (c *conn) subscribed() bool {
+	return helper.IsNotEmpty(c.channels) || helper.IsNotEmpty(c.patterns)
+}
+
+// abortMulti flags the transaction to be discarded by EXEC, as redis does when a command fails to be queued.
+func (c *conn) abortMulti() {
+	if c.multi {
+		c.multiErr = true
+	}
+}
+
+func (c *conn) resetMulti() {
+	c.multi = false
+	c.multiErr = false
+	c.queued = nil
+	c.watched = map[watchKey]uint64{}
+}
+
+func formatArgs(args []string) string {
+	var quoted []string
+	for _, arg := range args {
+		quoted = append(quoted, "'"+arg+"'")
+	}
+	return strings.Join(quoted, " ") + " "
+}
@@ -0,0 +1,293 @@
+package redistest
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	redisdriver "github.com/redis/go-redis/v9"
+	"strings"
+	"testing"
+	"time"
+)
+
+func initServerClient(t *testing.T, server *Server, protocol int) *redisdriver.Client {
+	opts := server.ClientOptions()
+	opts.Protocol = protocol
+	client := redisdriver.NewClient(opts.ParseToRedisOptions())
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+	return client
+}
+
+func TestServerTemplate(t *testing.T) {
+	clock := NewManualClock(time.Now())
+	server := NewServer(t, NewOptions().SetClock(clock))
+	for _, protocol := range []int{2, 3} {
+		server.Flush()
+		opts := server.ClientOptions()
+		opts.Protocol = protocol
+		redisTemplate := redis.NewTemplate(opts)
+		ctx := context.TODO()
+		err := redisTemplate.Set(ctx, "test-key", initTestStruct(), option.NewSet().SetTTL(time.Minute))
+		if helper.IsNotNil(err) {
+			logger.Error("Set() err =", err)
+			t.Fail()
+			return
+		}
+		var dest testStruct
+		err = redisTemplate.Get(ctx, "test-key", &dest)
+		if helper.IsNotNil(err) || helper.IsNotEqualTo(dest, initTestStruct()) {
+			logger.Errorf("Get() resp%d result = %v err = %v", protocol, dest, err)
+			t.Fail()
+			return
+		}
+		var old testStruct
+		err = redisTemplate.SetGet(ctx, "test-key", "v2", &old, option.NewSet().SetKeepTTL(true))
+		if helper.IsNotNil(err) || helper.IsNotEqualTo(old, initTestStruct()) {
+			logger.Errorf("SetGet() resp%d result = %v err = %v", protocol, old, err)
+			t.Fail()
+			return
+		}
+		clock.Advance(time.Minute)
+		exists, err := redisTemplate.Exists(ctx, "test-key")
+		if helper.IsNotNil(err) || exists {
+			logger.Errorf("Exists() resp%d expired result = %v err = %v", protocol, exists, err)
+			t.Fail()
+			return
+		}
+		redisTemplate.MSet(ctx, redis.MSetInput{Key: "user:1", Value: 1}, redis.MSetInput{Key: "user:2", Value: 2})
+		keys, err := redisTemplate.Keys(ctx, "user:*")
+		if helper.IsNotNil(err) || helper.IsNotEqualTo(keys, []string{"user:1", "user:2"}) {
+			logger.Errorf("Keys() resp%d result = %v err = %v", protocol, keys, err)
+			t.Fail()
+			return
+		}
+		output, err := redisTemplate.Scan(ctx, 0, "user:*", 10)
+		if helper.IsNotNil(err) || helper.IsNotEqualTo(output.Page, []string{"user:1", "user:2"}) {
+			logger.Errorf("Scan() resp%d result = %v err = %v", protocol, output, err)
+			t.Fail()
+			return
+		}
+		err = redisTemplate.Del(ctx, "user:1", "user:2")
+		if helper.IsNotNil(err) {
+			logger.Errorf("Del() resp%d err = %v", protocol, err)
+			t.Fail()
+			return
+		}
+		health, err := redisTemplate.HealthCheck(ctx)
+		if helper.IsNotNil(err) || helper.IsNotEqualTo(health.Status, redis.HealthStatusUp) ||
+			helper.IsNotEqualTo(health.Role, "master") {
+			logger.Errorf("HealthCheck() resp%d result = %v err = %v", protocol, health, err)
+			t.Fail()
+			return
+		}
+		redisTemplate.SimpleDisconnect()
+	}
+}
+
+func TestServerDataTypes(t *testing.T) {
+	server := NewServer(t)
+	ctx := context.TODO()
+	for _, protocol := range []int{2, 3} {
+		server.Flush()
+		client := initServerClient(t, server, protocol)
+		client.HSet(ctx, "hash", "a", "1", "b", "2")
+		client.HIncrBy(ctx, "hash", "a", 10)
+		hash, err := client.HGetAll(ctx, "hash").Result()
+		if helper.IsNotNil(err) || helper.IsNotEqualTo(hash, map[string]string{"a": "11", "b": "2"}) {
+			logger.Errorf("HGetAll() resp%d result = %v err = %v", protocol, hash, err)
+			t.Fail()
+		}
+		client.RPush(ctx, "list", "a", "b", "c")
+		client.LPush(ctx, "list", "z")
+		list, err := client.LRange(ctx, "list", 0, -1).Result()
+		if helper.IsNotNil(err) || helper.IsNotEqualTo(list, []string{"z", "a", "b", "c"}) {
+			logger.Errorf("LRange() resp%d result = %v err = %v", protocol, list, err)
+			t.Fail()
+		}
+		moved, err := client.LMove(ctx, "list", "other", "RIGHT", "LEFT").Result()
+		if helper.IsNotNil(err) || helper.IsNotEqualTo(moved, "c") {
+			logger.Errorf("LMove() resp%d result = %v err = %v", protocol, moved, err)
+			t.Fail()
+		}
+		client.SAdd(ctx, "set", "b", "a", "c")
+		members, err := client.SMembers(ctx, "set").Result()
+		if helper.IsNotNil(err) || helper.IsNotEqualTo(members, []string{"a", "b", "c"}) {
+			logger.Errorf("SMembers() resp%d result = %v err = %v", protocol, members, err)
+			t.Fail()
+		}
+		client.ZAdd(ctx, "zset", redisdriver.Z{Score: 2, Member: "b"}, redisdriver.Z{Score: 1, Member: "a"},
+			redisdriver.Z{Score: 3, Member: "c"})
+		scoreRange := &redisdriver.ZRangeBy{Min: "(1", Max: "+inf"}
+		scored, err := client.ZRangeByScoreWithScores(ctx, "zset", scoreRange).Result()
+		if helper.IsNotNil(err) || helper.IsNotEqualTo(scored, []redisdriver.Z{{Score: 2, Member: "b"},
+			{Score: 3, Member: "c"}}) {
+			logger.Errorf("ZRangeByScoreWithScores() resp%d result = %v err = %v", protocol, scored, err)
+			t.Fail()
+		}
+		score, err := client.ZIncrBy(ctx, "zset", 1.5, "a").Result()
+		if helper.IsNotNil(err) || helper.IsNotEqualTo(score, 2.5) {
+			logger.Errorf("ZIncrBy() resp%d result = %v err = %v", protocol, score, err)
+			t.Fail()
+		}
+		popped, err := client.ZPopMax(ctx, "zset").Result()
+		if helper.IsNotNil(err) || helper.IsNotEqualTo(popped, []redisdriver.Z{{Score: 3, Member: "c"}}) {
+			logger.Errorf("ZPopMax() resp%d result = %v err = %v", protocol, popped, err)
+			t.Fail()
+		}
+		err = client.Get(ctx, "hash").Err()
+		if helper.IsNil(err) || !strings.HasPrefix(err.Error(), "WRONGTYPE") {
+			logger.Errorf("Get() resp%d wrong type err = %v", protocol, err)
+			t.Fail()
+		}
+	}
+}
+
+func TestServerTransaction(t *testing.T) {
+	server := NewServer(t)
+	client := initServerClient(t, server, 3)
+	ctx := context.TODO()
+	cmds, err := client.TxPipelined(ctx, func(pipe redisdriver.Pipeliner) error {
+		pipe.Set(ctx, "counter", 1, 0)
+		pipe.Incr(ctx, "counter")
+		return nil
+	})
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(cmds[1].(*redisdriver.IntCmd).Val(), 2) {
+		logger.Errorf("TxPipelined() result = %v err = %v", cmds, err)
+		t.Fail()
+		return
+	}
+	other := initServerClient(t, server, 2)
+	err = client.Watch(ctx, func(tx *redisdriver.Tx) error {
+		other.Incr(ctx, "counter")
+		_, err := tx.TxPipelined(ctx, func(pipe redisdriver.Pipeliner) error {
+			pipe.Set(ctx, "counter", 0, 0)
+			return nil
+		})
+		return err
+	}, "counter")
+	if !errors.Is(err, redisdriver.TxFailedErr) {
+		logger.Errorf("Watch() err = %v, want = %v", err, redisdriver.TxFailedErr)
+		t.Fail()
+		return
+	}
+	value, _ := client.Get(ctx, "counter").Int()
+	if helper.IsNotEqualTo(value, 3) {
+		logger.Errorf("Get() result = %v, want = 3", value)
+		t.Fail()
+	}
+}
+
+func TestServerPubSub(t *testing.T) {
+	server := NewServer(t)
+	ctx := context.TODO()
+	for _, protocol := range []int{2, 3} {
+		client := initServerClient(t, server, protocol)
+		pubSub := client.PSubscribe(ctx, "news.*")
+		_, err := pubSub.Receive(ctx)
+		if helper.IsNotNil(err) {
+			logger.Errorf("Receive() resp%d err = %v", protocol, err)
+			t.Fail()
+			return
+		}
+		n, err := client.Publish(ctx, "news.tech", "hello").Result()
+		if helper.IsNotNil(err) || helper.IsNotEqualTo(n, 1) {
+			logger.Errorf("Publish() resp%d result = %v err = %v", protocol, n, err)
+			t.Fail()
+			return
+		}
+		select {
+		case msg := <-pubSub.Channel():
+			if helper.IsNotEqualTo(msg.Channel, "news.tech") || helper.IsNotEqualTo(msg.Payload, "hello") {
+				logger.Errorf("Channel() resp%d result = %v", protocol, msg)
+				t.Fail()
+			}
+		case <-time.After(time.Second):
+			logger.Errorf("Channel() resp%d message not received", protocol)
+			t.Fail()
+		}
+		_ = pubSub.Close()
+	}
+}
+
+func TestServerBlocking(t *testing.T) {
+	server := NewServer(t)
+	client := initServerClient(t, server, 3)
+	ctx := context.TODO()
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		client.RPush(ctx, "jobs", "job-1")
+	}()
+	result, err := client.BLMove(ctx, "jobs", "processing", "LEFT", "RIGHT", time.Second).Result()
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(result, "job-1") {
+		logger.Errorf("BLMove() result = %v err = %v", result, err)
+		t.Fail()
+		return
+	}
+	err = client.BLPop(ctx, time.Second, "jobs").Err()
+	if !errors.Is(err, redisdriver.Nil) {
+		logger.Errorf("BLPop() err = %v, want = %v", err, redisdriver.Nil)
+		t.Fail()
+	}
+}
+
+func TestServerFaults(t *testing.T) {
+	server := NewServer(t)
+	opts := server.ClientOptions()
+	opts.MaxRetries = -1
+	opts.ContextTimeoutEnabled = true
+	redisTemplate := redis.NewTemplate(opts)
+	ctx := context.TODO()
+	server.SetError("get", "ERR boom")
+	var dest string
+	err := redisTemplate.Get(ctx, "test-key", &dest)
+	var opErr *redis.OpError
+	if !errors.As(err, &opErr) || helper.IsNotEqualTo(opErr.Key, "test-key") ||
+		helper.IsNotEqualTo(opErr.Err.Error(), "ERR boom") {
+		logger.Errorf("Get() err = %v, want = ERR boom", err)
+		t.Fail()
+	}
+	server.SetError("get", "")
+	server.SetReadOnly(true)
+	err = redisTemplate.Set(ctx, "test-key", "value")
+	if !errors.Is(err, redis.ErrReadOnly) || !redis.IsRetryable(err) {
+		logger.Errorf("Set() err = %v, want = %v", err, redis.ErrReadOnly)
+		t.Fail()
+	}
+	health, _ := redisTemplate.HealthCheck(ctx)
+	if helper.IsNil(health) || helper.IsNotEqualTo(health.Role, "slave") {
+		logger.Errorf("HealthCheck() result = %v, want role = slave", health)
+		t.Fail()
+	}
+	server.SetReadOnly(false)
+	server.SetLatency(100 * time.Millisecond)
+	timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	err = redisTemplate.Ping(timeoutCtx)
+	if helper.IsNil(err) {
+		logger.Error("Ping() with latency err = nil")
+		t.Fail()
+	}
+	server.SetLatency(0)
+	server.SetFaultHook(func(args []string) Fault {
+		return Fault{Drop: strings.EqualFold(args[0], "exists")}
+	})
+	_, err = redisTemplate.Exists(ctx, "test-key")
+	if helper.IsNil(err) {
+		logger.Error("Exists() with dropped connection err = nil")
+		t.Fail()
+	}
+	server.SetFaultHook(nil)
+	server.DropConnections()
+	err = redisTemplate.Ping(ctx)
+	if helper.IsNotNil(err) {
+		logger.Error("Ping() after reconnect err =", err)
+		t.Fail()
+	}
+	logger.Info("Faults tested, connections =", server.Connections())
+	redisTemplate.SimpleDisconnect()
+}
@@ -0,0 +1,362 @@
+package redistest
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+var errSyntax = replyError("ERR syntax error")
+var errNoSuchKey = replyError("ERR no such key")
+var errWrongType = replyError("WRONGTYPE Operation against a key holding the wrong kind of value")
+
+// replyError is an error reply of redis, it implements the redis.Error interface of the go-redis driver, like the
+// errors read from a real server, so the in-memory Template returns errors of the same class.
+type replyError string
+
+func (e replyError) Error() string {
+	return string(e)
+}
+
+// RedisError implements redis.Error.
+func (replyError) RedisError() {}
+
+type store struct {
+	mutex     sync.Mutex
+	clock     Clock
+	databases map[int]*keyspace
+	// changed is closed and replaced every time a key is written, waking up the blocking commands.
+	changed chan struct{}
+}
+
+type keyspace struct {
+	store   *store
+	entries map[string]*entry
+	// versions is incremented every time a key is written, used by the WATCH command.
+	versions map[string]uint64
+	// indexes are the RediSearch indexes of the keys, they are kept by FLUSHDB, as redis does.
+	indexes map[string]*searchIndex
+}
+
+// hashValue, listValue, setValue and zsetValue are the values of the entries that are not strings, empty values
+// are removed from the keyspace, as redis does.
+type hashValue map[string]string
+
+type listValue struct {
+	items []string
+}
+
+type setValue map[string]struct{}
+
+type zsetValue map[string]float64
+
+type entry struct {
+	value    any
+	expireAt time.Time
+}
+
+type setArgs struct {
+	nx       bool
+	xx       bool
+	get      bool
+	keepTTL  bool
+	expireAt time.Time
+}
+
+func newStore(clock Clock) *store {
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return &store{
+		clock:     clock,
+		databases: map[int]*keyspace{},
+		changed:   make(chan struct{}),
+	}
+}
+
+func (s *store) db(index int) *keyspace {
+	ks, ok := s.databases[index]
+	if !ok {
+		ks = &keyspace{store: s, entries: map[string]*entry{}, versions: map[string]uint64{},
+			indexes: map[string]*searchIndex{}}
+		s.databases[index] = ks
+	}
+	return ks
+}
+
+func (s *store) now() time.Time {
+	return s.clock.Now()
+}
+
+// notify wakes up the commands waiting for a change.
+func (s *store) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (k *keyspace) touch(key string) {
+	k.versions[key]++
+}
+
+func (k *keyspace) version(key string) uint64 {
+	return k.versions[key]
+}
+
+// lookup returns the value of the key, creating it with create when the key does not exist and create is not nil.
+func (k *keyspace) lookup(key string, create func() any) any {
+	e := k.get(key)
+	if e != nil {
+		return e.value
+	} else if create == nil {
+		return nil
+	}
+	value := create()
+	k.entries[key] = &entry{value: value}
+	return value
+}
+
+func (k *keyspace) getHash(key string, create bool) (hashValue, error) {
+	value := k.lookup(key, creator(create, func() any { return hashValue{} }))
+	if value == nil {
+		return nil, nil
+	}
+	hash, ok := value.(hashValue)
+	if !ok {
+		return nil, errWrongType
+	}
+	return hash, nil
+}
+
+func (k *keyspace) getList(key string, create bool) (*listValue, error) {
+	value := k.lookup(key, creator(create, func() any { return &listValue{} }))
+	if value == nil {
+		return nil, nil
+	}
+	list, ok := value.(*listValue)
+	if !ok {
+		return nil, errWrongType
+	}
+	return list, nil
+}
+
+func (k *keyspace) getSet(key string, create bool) (setValue, error) {
+	value := k.lookup(key, creator(create, func() any { return setValue{} }))
+	if value == nil {
+		return nil, nil
+	}
+	set, ok := value.(setValue)
+	if !ok {
+		return nil, errWrongType
+	}
+	return set, nil
+}
+
+func (k *keyspace) getZSet(key string, create bool) (zsetValue, error) {
+	value := k.lookup(key, creator(create, func() any { return zsetValue{} }))
+	if value == nil {
+		return nil, nil
+	}
+	zset, ok := value.(zsetValue)
+	if !ok {
+		return nil, errWrongType
+	}
+	return zset, nil
+}
+
+// removeIfEmpty removes the key when its hash, list, set or sorted set has no elements.
+func (k *keyspace) removeIfEmpty(key string) {
+	e, ok := k.entries[key]
+	if !ok {
+		return
+	}
+	size := -1
+	switch value := e.value.(type) {
+	case hashValue:
+		size = len(value)
+	case *listValue:
+		size = len(value.items)
+	case setValue:
+		size = len(value)
+	case zsetValue:
+		size = len(value)
+	}
+	if size == 0 {
+		delete(k.entries, key)
+	}
+}
+
+func (k *keyspace) typeOf(key string) string {
+	switch k.lookup(key, nil).(type) {
+	case string:
+		return "string"
+	case hashValue:
+		return "hash"
+	case *listValue:
+		return "list"
+	case setValue:
+		return "set"
+	case zsetValue:
+		return "zset"
+	case *jsonValue:
+		return "ReJSON-RL"
+	case *timeSeries:
+		return "TSDB-TYPE"
+	case *bloomFilter:
+		return "MBbloom--"
+	case *cuckooFilter:
+		return "MBbloomCF"
+	case *countMinSketch:
+		return "CMSk-TYPE"
+	case *topK:
+		return "TopK-TYPE"
+	}
+	return "none"
+}
+
+func (k *keyspace) get(key string) *entry {
+	e, ok := k.entries[key]
+	if !ok {
+		return nil
+	} else if !e.expireAt.IsZero() && !k.store.now().Before(e.expireAt) {
+		delete(k.entries, key)
+		return nil
+	}
+	return e
+}
+
+func (k *keyspace) getString(key string) (string, bool, error) {
+	e := k.get(key)
+	if e == nil {
+		return "", false, nil
+	}
+	s, ok := e.value.(string)
+	if !ok {
+		return "", false, errWrongType
+	}
+	return s, true, nil
+}
+
+// set executes the SET command, returning the previous value when args.get is true and if the value was written.
+func (k *keyspace) set(key, value string, args setArgs) (old string, hadOld bool, written bool, err error) {
+	e := k.get(key)
+	if args.get && e != nil {
+		var ok bool
+		if old, ok = e.value.(string); !ok {
+			return "", false, false, errWrongType
+		}
+		hadOld = true
+	}
+	if (args.nx && e != nil) || (args.xx && e == nil) {
+		return old, hadOld, false, nil
+	}
+	expireAt := args.expireAt
+	if args.keepTTL && e != nil {
+		expireAt = e.expireAt
+	}
+	k.entries[key] = &entry{value: value, expireAt: expireAt}
+	return old, hadOld, true, nil
+}
+
+func (k *keyspace) exists(key string) bool {
+	return k.get(key) != nil
+}
+
+func (k *keyspace) del(key string) bool {
+	if k.get(key) == nil {
+		return false
+	}
+	delete(k.entries, key)
+	return true
+}
+
+func (k *keyspace) rename(key, newKey string) error {
+	e := k.get(key)
+	if e == nil {
+		return errNoSuchKey
+	}
+	delete(k.entries, key)
+	k.entries[newKey] = e
+	return nil
+}
+
+func (k *keyspace) keys(pattern string) []string {
+	var result []string
+	for _, key := range k.sortedKeys() {
+		if matchGlob(pattern, key) {
+			result = append(result, key)
+		}
+	}
+	return result
+}
+
+// scan iterates the keys in lexicographic order, the cursor is the position of the next key to be scanned.
+func (k *keyspace) scan(cursor uint64, match string, count int64) ([]string, uint64) {
+	if count <= 0 {
+		count = 10
+	}
+	keys := k.sortedKeys()
+	var page []string
+	i := cursor
+	for ; i < uint64(len(keys)) && i < cursor+uint64(count); i++ {
+		if match == "" || matchGlob(match, keys[i]) {
+			page = append(page, keys[i])
+		}
+	}
+	if i >= uint64(len(keys)) {
+		i = 0
+	}
+	return page, i
+}
+
+func (k *keyspace) ttl(key string) (time.Duration, bool) {
+	e := k.get(key)
+	if e == nil {
+		return 0, false
+	} else if e.expireAt.IsZero() {
+		return -1, true
+	}
+	return e.expireAt.Sub(k.store.now()), true
+}
+
+// expire sets the expiration time of the key, the key is removed if the time has already passed, returns false if
+// the key does not exist.
+func (k *keyspace) expire(key string, expireAt time.Time) bool {
+	e := k.get(key)
+	if e == nil {
+		return false
+	} else if !expireAt.IsZero() && !k.store.now().Before(expireAt) {
+		delete(k.entries, key)
+		return true
+	}
+	e.expireAt = expireAt
+	return true
+}
+
+func (k *keyspace) flush() {
+	for key := range k.entries {
+		k.touch(key)
+	}
+	k.entries = map[string]*entry{}
+}
+
+func (k *keyspace) size() int {
+	return len(k.sortedKeys())
+}
+
+func (k *keyspace) sortedKeys() []string {
+	keys := make([]string, 0, len(k.entries))
+	for key := range k.entries {
+		if k.get(key) != nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func creator(create bool, fn func() any) func() any {
+	if !create {
+		return nil
+	}
+	return fn
+}
@@ -0,0 +1,500 @@
+package redistest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	redisdriver "github.com/redis/go-redis/v9"
+	"net/http"
+	"time"
+)
+
+// Options represents options that can be used to configure the in-memory template (NewTemplate).
+type Options struct {
+	// Clock used to evaluate the key expirations.
+	// Default is the system clock, use NewManualClock to control the time in tests.
+	Clock Clock
+}
+
+// Template is an in-memory implementation of redis.Interface, following the same conversions, options and errors
+// of redis.Template, so it can replace the template in unit tests without a redis server.
+type Template struct {
+	store  *store
+	closed bool
+	hooks  []redis.Hook
+}
+
+var _ redis.Interface = (*Template)(nil)
+
+// NewOptions creates a new Options instance.
+func NewOptions() *Options {
+	return &Options{}
+}
+
+// SetClock sets value for the Clock field.
+func (o *Options) SetClock(clock Clock) *Options {
+	o.Clock = clock
+	return o
+}
+
+// NewTemplate creates a new in-memory template instance.
+//
+// To customize the template, use the opts parameter (Options).
+func NewTemplate(opts ...*Options) *Template {
+	var clock Clock
+	for _, opt := range opts {
+		if helper.IsNotNil(opt) && helper.IsNotNil(opt.Clock) {
+			clock = opt.Clock
+		}
+	}
+	return &Template{
+		store: newStore(clock),
+	}
+}
+
+// Set supports the same options as redis.Template.Set.
+func (t *Template) Set(ctx context.Context, key, value any, opts ...*option.Set) error {
+	op := redis.Operation{Name: "Set", Keys: []any{key}, Value: value, Idempotent: isIdempotentSet(opts...)}
+	return t.process(ctx, op, func() error {
+		_, err := t.set(key, value, false, opts...)
+		return err
+	})
+}
+
+// MSet defines N values, following redis.Template.MSet.
+func (t *Template) MSet(ctx context.Context, values ...redis.MSetInput) []redis.MSetOutput {
+	var output []redis.MSetOutput
+	op := redis.Operation{Name: "MSet", Idempotent: true}
+	for _, v := range values {
+		op.Keys = append(op.Keys, v.Key)
+		op.Idempotent = op.Idempotent && isIdempotentSet(v.Opt)
+	}
+	err := t.process(ctx, op, func() error {
+		var errs []error
+		for _, v := range values {
+			_, err := t.set(v.Key, v.Value, false, v.Opt)
+			err = redis.NewOpError(op.Name, v.Key, err)
+			output = append(output, redis.MSetOutput{
+				Key: v.Key,
+				Err: err,
+			})
+			errs = append(errs, err)
+		}
+		return errors.Join(errs...)
+	})
+	if helper.IsNil(output) && helper.IsNotNil(err) {
+		for _, v := range values {
+			output = append(output, redis.MSetOutput{
+				Key: v.Key,
+				Err: err,
+			})
+		}
+	}
+	return output
+}
+
+// SetGet supports the same options as redis.Template.SetGet.
+func (t *Template) SetGet(ctx context.Context, key, value, dest any, opts ...*option.Set) error {
+	op := redis.Operation{Name: "SetGet", Keys: []any{key}, Value: value}
+	return t.process(ctx, op, func() error {
+		old, err := t.set(key, value, true, opts...)
+		if helper.IsNil(err) {
+			err = helper.ConvertToDest(old, dest)
+		}
+		return err
+	})
+}
+
+// Rename key, following redis.Template.Rename.
+func (t *Template) Rename(ctx context.Context, key, newKey any) error {
+	op := redis.Operation{Name: "Rename", Keys: []any{key, newKey}}
+	return t.process(ctx, op, func() error {
+		return t.rename(key, newKey)
+	})
+}
+
+// Get value by key, following redis.Template.Get.
+func (t *Template) Get(ctx context.Context, key, dest any) error {
+	op := redis.Operation{Name: "Get", Keys: []any{key}, Idempotent: true}
+	return t.process(ctx, op, func() error {
+		return t.get(key, dest, false)
+	})
+}
+
+// GetDel get and delete value by key, following redis.Template.GetDel.
+func (t *Template) GetDel(ctx context.Context, key, dest any) error {
+	op := redis.Operation{Name: "GetDel", Keys: []any{key}}
+	return t.process(ctx, op, func() error {
+		return t.get(key, dest, true)
+	})
+}
+
+func (t *Template) rename(key, newKey any) error {
+	sKey, err := helper.ConvertToString(key)
+	if helper.IsNotNil(err) {
+		return redis.ErrConvertKey
+	}
+	sNewKey, err := helper.ConvertToString(newKey)
+	if helper.IsNotNil(err) {
+		return redis.ErrConvertNewKey
+	}
+	return t.do(func(ks *keyspace) error {
+		return ks.rename(sKey, sNewKey)
+	})
+}
+
+func (t *Template) get(key, dest any, del bool) error {
+	if !helper.IsPointerType(dest) {
+		return redis.ErrDestIsNotPointer
+	}
+	sKey, err := helper.ConvertToString(key)
+	if helper.IsNotNil(err) {
+		return redis.ErrConvertKey
+	}
+	var result string
+	err = t.do(func(ks *keyspace) error {
+		value, ok, err := ks.getString(sKey)
+		if helper.IsNotNil(err) {
+			return err
+		} else if !ok {
+			return redis.ErrKeyNotFound
+		} else if del {
+			ks.del(sKey)
+		}
+		result = value
+		return nil
+	})
+	if helper.IsNotNil(err) {
+		return err
+	}
+	return helper.ConvertToDest(result, dest)
+}
+
+// Exists key, following redis.Template.Exists.
+func (t *Template) Exists(ctx context.Context, key any) (bool, error) {
+	var exists bool
+	op := redis.Operation{Name: "Exists", Keys: []any{key}, Idempotent: true}
+	err := t.process(ctx, op, func() error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return redis.ErrConvertKey
+		}
+		return t.do(func(ks *keyspace) error {
+			exists = ks.exists(sKey)
+			return nil
+		})
+	})
+	return exists, err
+}
+
+// Keys return list of keys by the glob-style pattern.
+func (t *Template) Keys(ctx context.Context, pattern string) ([]string, error) {
+	var keys []string
+	op := redis.Operation{Name: "Keys", Keys: []any{pattern}, Idempotent: true}
+	err := t.process(ctx, op, func() error {
+		return t.do(func(ks *keyspace) error {
+			keys = ks.keys(pattern)
+			return nil
+		})
+	})
+	return keys, err
+}
+
+// Scan return list keys pageable by the glob-style match, the keys are iterated in lexicographic order and the
+// cursor is the position of the next key.
+func (t *Template) Scan(ctx context.Context, cursor uint64, match string, count int64) (redis.ScanOutput, error) {
+	var output redis.ScanOutput
+	op := redis.Operation{Name: "Scan", Keys: []any{match}, Idempotent: true}
+	err := t.process(ctx, op, func() error {
+		return t.do(func(ks *keyspace) error {
+			output.Page, output.Cursor = ks.scan(cursor, match, count)
+			return nil
+		})
+	})
+	return output, err
+}
+
+// Del delete keys, following redis.Template.Del.
+func (t *Template) Del(ctx context.Context, keys ...any) error {
+	op := redis.Operation{Name: "Del", Keys: keys, Idempotent: true}
+	return t.process(ctx, op, func() error {
+		return t.del(keys)
+	})
+}
+
+// SprintKey format values as prefix in string for a future redis key, see redis.SprintKey.
+func (t *Template) SprintKey(vs ...any) string {
+	return redis.SprintKey(vs...)
+}
+
+// Ping returns nil while the template is not disconnected.
+func (t *Template) Ping(ctx context.Context) error {
+	op := redis.Operation{Name: "Ping", Idempotent: true}
+	return t.process(ctx, op, func() error {
+		return t.do(func(*keyspace) error {
+			return nil
+		})
+	})
+}
+
+// HealthCheck returns the status up, as a master without latency, while the template is not disconnected, the
+// thresholds of the opts parameter (option.Health) are not evaluated.
+func (t *Template) HealthCheck(ctx context.Context, _ ...*option.Health) (*redis.Health, error) {
+	if err := t.Ping(ctx); helper.IsNotNil(err) {
+		return nil, err
+	}
+	return &redis.Health{Status: redis.HealthStatusUp, Role: "master"}, nil
+}
+
+// Info returns the keyspace of the template, as a master with the role in the replication section, the sections
+// parameter is ignored.
+func (t *Template) Info(ctx context.Context, _ ...string) (*redis.Info, error) {
+	info := &redis.Info{
+		Replication: redis.InfoReplication{Role: "master"},
+		Keyspace:    map[int]redis.InfoKeyspace{},
+		Sections:    map[string]map[string]string{"replication": {"role": "master"}},
+	}
+	op := redis.Operation{Name: "Info", Idempotent: true}
+	err := t.process(ctx, op, func() error {
+		return t.do(func(ks *keyspace) error {
+			var keyspaceInfo redis.InfoKeyspace
+			for _, key := range ks.sortedKeys() {
+				keyspaceInfo.Keys++
+				if !ks.entries[key].expireAt.IsZero() {
+					keyspaceInfo.Expires++
+				}
+			}
+			if helper.IsGreaterThan(keyspaceInfo.Keys, 0) {
+				info.Keyspace[0] = keyspaceInfo
+			}
+			return nil
+		})
+	})
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return info, nil
+}
+
+// AddHook registers a hook on the template, called on each operation like on redis.Template, as a template
+// operation (redis.OperationTypeTemplate) with a single attempt, since there are no commands sent to a server.
+func (t *Template) AddHook(hook redis.Hook) {
+	if helper.IsNil(hook) {
+		return
+	}
+	t.store.mutex.Lock()
+	defer t.store.mutex.Unlock()
+	hooks := make([]redis.Hook, len(t.hooks), len(t.hooks)+1)
+	copy(hooks, t.hooks)
+	t.hooks = append(hooks, hook)
+}
+
+// PoolStats returns empty stats, the template has no connection pool.
+func (t *Template) PoolStats() redis.PoolStats {
+	return redis.PoolStats{}
+}
+
+// LivenessHandler returns a http.Handler that answers like redis.Template.LivenessHandler, with the result of Ping.
+func (t *Template) LivenessHandler(_ ...*option.Health) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		health := &redis.Health{Status: redis.HealthStatusUp}
+		if err := t.Ping(r.Context()); helper.IsNotNil(err) {
+			health = &redis.Health{Status: redis.HealthStatusDown, Reasons: []string{err.Error()}}
+		}
+		writeHealth(w, health)
+	})
+}
+
+// ReadinessHandler returns a http.Handler that answers like redis.Template.ReadinessHandler, with the result of
+// HealthCheck.
+func (t *Template) ReadinessHandler(opts ...*option.Health) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		health, err := t.HealthCheck(r.Context(), opts...)
+		if helper.IsNotNil(err) {
+			health = &redis.Health{Status: redis.HealthStatusDown, Reasons: []string{err.Error()}}
+		}
+		writeHealth(w, health)
+	})
+}
+
+func (t *Template) del(keys []any) error {
+	var sKeys []string
+	for _, key := range keys {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return redis.ErrConvertKey
+		}
+		sKeys = append(sKeys, sKey)
+	}
+	if helper.IsEmpty(sKeys) {
+		return replyError("ERR wrong number of arguments for 'del' command")
+	}
+	return t.do(func(ks *keyspace) error {
+		for _, sKey := range sKeys {
+			ks.del(sKey)
+		}
+		return nil
+	})
+}
+
+// Disconnect closes the template, the next operations return redis.ErrClosed from the go-redis driver.
+func (t *Template) Disconnect() error {
+	t.store.mutex.Lock()
+	defer t.store.mutex.Unlock()
+	if t.closed {
+		return redisdriver.ErrClosed
+	}
+	t.closed = true
+	return nil
+}
+
+// SimpleDisconnect closes the template without error.
+func (t *Template) SimpleDisconnect() {
+	err := t.Disconnect()
+	if helper.IsNotNil(err) {
+		logger.ErrorSkipCaller(2, "Error disconnect:", err)
+		return
+	}
+	logger.InfoSkipCaller(2, "Connection to redis closed.")
+}
+
+// TTL returns the remaining time to live of the key, -1 if the key has no expiration, and false if the key
+// does not exist.
+func (t *Template) TTL(key any) (time.Duration, bool) {
+	sKey := helper.SimpleConvertToString(key)
+	t.store.mutex.Lock()
+	defer t.store.mutex.Unlock()
+	return t.store.db(0).ttl(sKey)
+}
+
+// Flush removes all keys.
+func (t *Template) Flush() {
+	t.store.mutex.Lock()
+	defer t.store.mutex.Unlock()
+	t.store.db(0).flush()
+}
+
+func (t *Template) set(key, value any, get bool, opts ...*option.Set) (string, error) {
+	if err := option.ValidateSetByParams(opts); helper.IsNotNil(err) {
+		return "", err
+	}
+	opt := option.GetOptionSetByParams(opts)
+	sKey, err := helper.ConvertToString(key)
+	if helper.IsNotNil(err) {
+		return "", redis.ErrConvertKey
+	}
+	sValue, err := helper.ConvertToString(value)
+	if helper.IsNotNil(err) {
+		return "", redis.ErrConvertValue
+	}
+	args, err := parseOptionSet(opt, t.store.now())
+	if helper.IsNotNil(err) {
+		return "", err
+	}
+	args.get = get
+	var old string
+	err = t.do(func(ks *keyspace) error {
+		var hadOld, written bool
+		old, hadOld, written, err = ks.set(sKey, sValue, args)
+		if helper.IsNotNil(err) {
+			return err
+		} else if (get && !hadOld) || (!get && !written) {
+			return redisdriver.Nil
+		}
+		return nil
+	})
+	return old, err
+}
+
+// process runs the operation with the hooks, in the order of redis.Template, wrapping the error in a redis.OpError
+// with the name and the first key of the operation.
+func (t *Template) process(ctx context.Context, op redis.Operation, fn func() error) error {
+	t.store.mutex.Lock()
+	hooks := t.hooks
+	t.store.mutex.Unlock()
+	op.Type = redis.OperationTypeTemplate
+	op.Attempt = 1
+	var key any
+	if helper.IsNotEmpty(op.Keys) {
+		key = op.Keys[0]
+	}
+	var err error
+	called := 0
+	for _, hook := range hooks {
+		var hookCtx context.Context
+		hookCtx, err = hook.Before(ctx, op)
+		if helper.IsNotNil(err) {
+			break
+		} else if helper.IsNotNil(hookCtx) {
+			ctx = hookCtx
+		}
+		called++
+	}
+	startedAt := time.Now()
+	if helper.IsNil(err) {
+		err = fn()
+	}
+	err = redis.NewOpError(op.Name, key, err)
+	op.Duration = time.Since(startedAt)
+	op.Err = err
+	for i := called - 1; i >= 0; i-- {
+		hooks[i].After(ctx, op)
+	}
+	return err
+}
+
+func (t *Template) do(fn func(ks *keyspace) error) error {
+	t.store.mutex.Lock()
+	defer t.store.mutex.Unlock()
+	if t.closed {
+		return redisdriver.ErrClosed
+	}
+	return fn(t.store.db(0))
+}
+
+// isIdempotentSet returns false if the Set has the mode SetModeNx, like redis.Template.
+func isIdempotentSet(opts ...*option.Set) bool {
+	return *option.GetOptionSetByParams(opts).Mode != option.SetModeNx
+}
+
+func writeHealth(w http.ResponseWriter, health *redis.Health) {
+	w.Header().Set("Content-Type", "application/json")
+	if helper.IsNotEqualTo(health.Status, redis.HealthStatusUp) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	_ = json.NewEncoder(w).Encode(health)
+}
+
+// parseOptionSet converts the option.Set to the SET arguments, returning the same syntax error as redis when the
+// options conflict.
+func parseOptionSet(opt *option.Set, now time.Time) (setArgs, error) {
+	args := setArgs{
+		nx:      helper.Equals(*opt.Mode, option.SetModeNx),
+		xx:      helper.Equals(*opt.Mode, option.SetModeXx),
+		keepTTL: helper.IfNilReturns(opt.KeepTTL, false),
+	}
+	ttl := helper.IfNilReturns(opt.TTL, 0)
+	expireAt := helper.IfNilReturns(opt.ExpireAt, time.Time{})
+	expirations := 0
+	if args.keepTTL {
+		expirations++
+	}
+	if !expireAt.IsZero() {
+		expirations++
+		args.expireAt = time.Unix(expireAt.Unix(), 0)
+	}
+	if ttl > 0 {
+		expirations++
+		args.expireAt = now.Add(ttl)
+	}
+	if expirations > 1 {
+		return args, errSyntax
+	}
+	return args, nil
+}
@@ -0,0 +1,238 @@
+package redistest
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	redisdriver "github.com/redis/go-redis/v9"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type testStruct struct {
+	Name    string
+	Emails  []string
+	Balance float64
+}
+
+func initTestStruct() testStruct {
+	return testStruct{
+		Name:    "Foo Bar",
+		Emails:  []string{"foobar@gmail.com"},
+		Balance: 231.123,
+	}
+}
+
+func TestTemplateSetTTL(t *testing.T) {
+	clock := NewManualClock(time.Now())
+	var redisTemplate redis.Interface = NewTemplate(NewOptions().SetClock(clock))
+	ctx := context.TODO()
+	err := redisTemplate.Set(ctx, "test-key", initTestStruct(), option.NewSet().SetTTL(time.Minute))
+	if helper.IsNotNil(err) {
+		logger.Error("Set() err =", err)
+		t.Fail()
+		return
+	}
+	var dest testStruct
+	err = redisTemplate.Get(ctx, "test-key", &dest)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(dest, initTestStruct()) {
+		logger.Errorf("Get() result = %v err = %v", dest, err)
+		t.Fail()
+		return
+	}
+	clock.Advance(time.Minute)
+	err = redisTemplate.Get(ctx, "test-key", &dest)
+	if !errors.Is(err, redis.ErrKeyNotFound) {
+		logger.Errorf("Get() err = %v, want = %v", err, redis.ErrKeyNotFound)
+		t.Fail()
+		return
+	}
+	logger.Info("Get() expired err =", err)
+}
+
+func TestTemplateSetMode(t *testing.T) {
+	clock := NewManualClock(time.Now())
+	redisTemplate := NewTemplate(NewOptions().SetClock(clock))
+	ctx := context.TODO()
+	err := redisTemplate.Set(ctx, "test-key", "v1", option.NewSet().SetMode(option.SetModeXx))
+	if !errors.Is(err, redisdriver.Nil) {
+		logger.Errorf("Set() xx err = %v, want = %v", err, redisdriver.Nil)
+		t.Fail()
+		return
+	}
+	err = redisTemplate.Set(ctx, "test-key", "v1", option.NewSet().SetMode(option.SetModeNx).SetTTL(time.Hour))
+	if helper.IsNotNil(err) {
+		logger.Error("Set() nx err =", err)
+		t.Fail()
+		return
+	}
+	err = redisTemplate.Set(ctx, "test-key", "v2", option.NewSet().SetMode(option.SetModeNx))
+	if !errors.Is(err, redisdriver.Nil) {
+		logger.Errorf("Set() nx err = %v, want = %v", err, redisdriver.Nil)
+		t.Fail()
+		return
+	}
+	var old string
+	err = redisTemplate.SetGet(ctx, "test-key", "v3", &old, option.NewSet().SetMode(option.SetModeXx).SetKeepTTL(true))
+	ttl, _ := redisTemplate.TTL("test-key")
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(old, "v1") || helper.IsNotEqualTo(ttl, time.Hour) {
+		logger.Errorf("SetGet() result = %v ttl = %v err = %v", old, ttl, err)
+		t.Fail()
+		return
+	}
+	err = redisTemplate.Set(ctx, "test-key", "v4", option.NewSet().SetKeepTTL(true).SetExpireAt(time.Now()))
+	if !errors.Is(err, option.ErrConflictingExpiry) {
+		logger.Errorf("Set() conflicting options err = %v, want = %v", err, option.ErrConflictingExpiry)
+		t.Fail()
+	}
+}
+
+func TestTemplateGetDel(t *testing.T) {
+	redisTemplate := NewTemplate()
+	ctx := context.TODO()
+	_ = redisTemplate.Set(ctx, "test-key", 10)
+	var dest int
+	err := redisTemplate.GetDel(ctx, "test-key", &dest)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(dest, 10) {
+		logger.Errorf("GetDel() result = %v err = %v", dest, err)
+		t.Fail()
+		return
+	}
+	exists, err := redisTemplate.Exists(ctx, "test-key")
+	if helper.IsNotNil(err) || exists {
+		logger.Errorf("Exists() result = %v err = %v", exists, err)
+		t.Fail()
+		return
+	}
+	err = redisTemplate.GetDel(ctx, "test-key", &dest)
+	if !errors.Is(err, redis.ErrKeyNotFound) {
+		logger.Errorf("GetDel() err = %v, want = %v", err, redis.ErrKeyNotFound)
+		t.Fail()
+		return
+	}
+	logger.Info("GetDel() err =", err)
+}
+
+func TestTemplateKeys(t *testing.T) {
+	redisTemplate := NewTemplate()
+	ctx := context.TODO()
+	for _, key := range []string{"user:1", "user:2", "user:10", "order:1", "hello", "hallo", "hxllo"} {
+		_ = redisTemplate.Set(ctx, key, key)
+	}
+	for _, tt := range []struct {
+		pattern string
+		want    []string
+	}{
+		{pattern: "user:*", want: []string{"user:1", "user:10", "user:2"}},
+		{pattern: "user:?", want: []string{"user:1", "user:2"}},
+		{pattern: "h[ae]llo", want: []string{"hallo", "hello"}},
+		{pattern: "h[^e]llo", want: []string{"hallo", "hxllo"}},
+		{pattern: "h[a-b]llo", want: []string{"hallo"}},
+		{pattern: "*:1", want: []string{"order:1", "user:1"}},
+	} {
+		result, err := redisTemplate.Keys(ctx, tt.pattern)
+		if helper.IsNotNil(err) || helper.IsNotEqualTo(result, tt.want) {
+			logger.Errorf("Keys(%s) result = %v, want = %v", tt.pattern, result, tt.want)
+			t.Fail()
+		}
+	}
+	var page []string
+	output := redis.ScanOutput{}
+	for {
+		var err error
+		output, err = redisTemplate.Scan(ctx, output.Cursor, "user:*", 2)
+		if helper.IsNotNil(err) {
+			logger.Error("Scan() err =", err)
+			t.Fail()
+			return
+		}
+		page = append(page, output.Page...)
+		if helper.IsEmpty(output.Cursor) {
+			break
+		}
+	}
+	if helper.IsNotEqualTo(page, []string{"user:1", "user:10", "user:2"}) {
+		logger.Errorf("Scan() result = %v", page)
+		t.Fail()
+	}
+}
+
+func TestTemplateDisconnect(t *testing.T) {
+	redisTemplate := NewTemplate()
+	ctx := context.TODO()
+	_ = redisTemplate.Set(ctx, "test-key", "value")
+	err := redisTemplate.Rename(ctx, "test-key", "test-rename")
+	if helper.IsNotNil(err) {
+		logger.Error("Rename() err =", err)
+		t.Fail()
+		return
+	}
+	redisTemplate.SimpleDisconnect()
+	err = redisTemplate.Ping(ctx)
+	if !errors.Is(err, redisdriver.ErrClosed) {
+		logger.Errorf("Ping() err = %v, want = %v", err, redisdriver.ErrClosed)
+		t.Fail()
+		return
+	}
+	redisTemplate.SimpleDisconnect()
+}
+
+type namesHook struct {
+	names []string
+}
+
+func (h *namesHook) Before(ctx context.Context, op redis.Operation) (context.Context, error) {
+	if helper.Equals(op.Name, "Del") {
+		return ctx, errors.New("fault injection")
+	}
+	return ctx, nil
+}
+
+func (h *namesHook) After(_ context.Context, op redis.Operation) {
+	h.names = append(h.names, op.Name)
+}
+
+func TestTemplateHooks(t *testing.T) {
+	var redisTemplate redis.Interface = NewTemplate()
+	hook := &namesHook{}
+	redisTemplate.AddHook(hook)
+	ctx := context.TODO()
+	_ = redisTemplate.Set(ctx, "test-key", "value")
+	err := redisTemplate.Del(ctx, "test-key")
+	exists, _ := redisTemplate.Exists(ctx, "test-key")
+	if helper.IsNil(err) || !exists || helper.IsNotEqualTo(hook.names, []string{"Set", "Exists"}) {
+		logger.Errorf("AddHook() names = %v exists = %v err = %v", hook.names, exists, err)
+		t.Fail()
+	}
+}
+
+func TestTemplateHealth(t *testing.T) {
+	redisTemplate := NewTemplate()
+	ctx := context.TODO()
+	_ = redisTemplate.Set(ctx, "test-key", "value", option.NewSet().SetTTL(time.Minute))
+	health, err := redisTemplate.HealthCheck(ctx)
+	info, _ := redisTemplate.Info(ctx)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(health.Status, redis.HealthStatusUp) ||
+		helper.IsNotEqualTo(info.Keyspace[0], redis.InfoKeyspace{Keys: 1, Expires: 1}) {
+		logger.Errorf("HealthCheck() result = %v info = %v err = %v", health, info, err)
+		t.Fail()
+	}
+	recorder := httptest.NewRecorder()
+	redisTemplate.ReadinessHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if helper.IsNotEqualTo(recorder.Code, http.StatusOK) {
+		logger.Errorf("ReadinessHandler() code = %d", recorder.Code)
+		t.Fail()
+	}
+	redisTemplate.SimpleDisconnect()
+	recorder = httptest.NewRecorder()
+	redisTemplate.LivenessHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/live", nil))
+	if helper.IsNotEqualTo(recorder.Code, http.StatusServiceUnavailable) {
+		logger.Errorf("LivenessHandler() code = %d", recorder.Code)
+		t.Fail()
+	}
+}
@@ -0,0 +1,198 @@
+package redis
+
+import (
+	"errors"
+	"fmt"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"sort"
+	"sync"
+)
+
+// Registry keeps the named templates of an application that uses several redis instances or databases, ex: cache,
+// sessions and queues. The templates are created on the first Get, with the hooks registered in the registry, and
+// are closed together with CloseAll.
+type Registry struct {
+	mutex     sync.Mutex
+	configs   map[string]option.Client
+	templates map[string]*Template
+	selected  map[selectKey]*Template
+	hooks     []Hook
+	onCreate  func(name string, t *Template)
+	closed    bool
+}
+
+// selectKey identifies a template returned by Registry.Select, kept apart from the registered names, so a name like
+// "cache/1" does not collide with the database 1 of "cache".
+type selectKey struct {
+	name string
+	db   int
+}
+
+// NewRegistry creates a new registry with the settings of each template by name, no connection is established
+// until the template is used.
+func NewRegistry(configs map[string]option.Client) *Registry {
+	r := &Registry{
+		configs:   map[string]option.Client{},
+		templates: map[string]*Template{},
+		selected:  map[selectKey]*Template{},
+	}
+	for name, config := range configs {
+		r.configs[name] = config
+	}
+	return r
+}
+
+// Register adds the settings of a new named template, if the name is already registered, the error
+// ErrTemplateAlreadyRegistered is returned.
+func (r *Registry) Register(name string, opts option.Client) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.closed {
+		return ErrRegistryClosed
+	} else if _, ok := r.configs[name]; ok {
+		return ErrTemplateAlreadyRegistered
+	}
+	r.configs[name] = opts
+	return nil
+}
+
+// AddHook registers a hook on all templates of the registry, the ones already created and the next ones, so the
+// same hook, ex: tracing, receives the operations of all templates.
+func (r *Registry) AddHook(hook Hook) {
+	if helper.IsNil(hook) {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.hooks = append(r.hooks, hook)
+	for _, t := range r.templates {
+		t.AddHook(hook)
+	}
+	for _, t := range r.selected {
+		t.AddHook(hook)
+	}
+}
+
+// OnCreate sets the function called when a template is created, after the registration of the hooks, it can be
+// used to register the metrics of each template, ex: a prometheus collector with the name as label. The function is
+// called without the lock of the registry, so it can use the registry, and the template can be returned by Get to
+// other goroutines before the function returns.
+func (r *Registry) OnCreate(f func(name string, t *Template)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.onCreate = f
+}
+
+// Get returns the template by name, creating it on the first call, if the name is not registered, the error
+// ErrTemplateNotFound is returned.
+func (r *Registry) Get(name string) (*Template, error) {
+	t, onCreate, err := r.get(name)
+	if onCreate != nil {
+		onCreate(name, t)
+	}
+	return t, err
+}
+
+// Select returns the template by name connected to another database number of the same redis, with the same
+// settings and schemas, the template is created on the first call, with the hooks of the registry, and closed by
+// CloseAll.
+func (r *Registry) Select(name string, db int) (*Template, error) {
+	t, err := r.Get(name)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	selected, onCreate, err := r.selectDB(t, selectKey{name: name, db: db})
+	if onCreate != nil {
+		onCreate(fmt.Sprintf("%s/%d", name, db), selected)
+	}
+	return selected, err
+}
+
+// Names returns the names of the registered templates, in lexicographic order.
+func (r *Registry) Names() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var names []string
+	for name := range r.configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CloseAll disconnects all templates created by the registry, the errors of each template are joined in the
+// return, after it, the registry can no longer be used.
+func (r *Registry) CloseAll() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.closed = true
+	var errs []error
+	for name, t := range r.templates {
+		if err := t.Disconnect(); helper.IsNotNil(err) {
+			errs = append(errs, fmt.Errorf("redis: close template %s: %w", name, err))
+		}
+	}
+	for key, t := range r.selected {
+		if err := t.Disconnect(); helper.IsNotNil(err) {
+			errs = append(errs, fmt.Errorf("redis: close template %s/%d: %w", key.name, key.db, err))
+		}
+	}
+	r.templates = map[string]*Template{}
+	r.selected = map[selectKey]*Template{}
+	return errors.Join(errs...)
+}
+
+// SimpleCloseAll disconnects all templates created by the registry without error.
+func (r *Registry) SimpleCloseAll() {
+	err := r.CloseAll()
+	if helper.IsNotNil(err) {
+		logger.ErrorSkipCaller(2, "Error close registry:", err)
+		return
+	}
+	logger.InfoSkipCaller(2, "Connections to redis closed.")
+}
+
+// get returns the template by name, creating it if needed, the function OnCreate is returned when the template is
+// created, to be called after the unlock.
+func (r *Registry) get(name string) (*Template, func(name string, t *Template), error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.closed {
+		return nil, nil, ErrRegistryClosed
+	} else if t, ok := r.templates[name]; ok {
+		return t, nil, nil
+	}
+	config, ok := r.configs[name]
+	if !ok {
+		return nil, nil, ErrTemplateNotFound
+	}
+	t := r.create(NewTemplate(config))
+	r.templates[name] = t
+	return t, r.onCreate, nil
+}
+
+// selectDB returns the template of the database of t, creating it if needed, like get.
+func (r *Registry) selectDB(t *Template, key selectKey) (*Template, func(name string, t *Template), error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.closed {
+		return nil, nil, ErrRegistryClosed
+	} else if selected, ok := r.selected[key]; ok {
+		return selected, nil, nil
+	} else if helper.Equals(t.opts.DB, key.db) {
+		return t, nil, nil
+	}
+	selected := r.create(t.selectDB(key.db))
+	r.selected[key] = selected
+	return selected, r.onCreate, nil
+}
+
+// create registers the hooks of the registry on the new template.
+func (r *Registry) create(t *Template) *Template {
+	for _, hook := range r.hooks {
+		t.AddHook(hook)
+	}
+	return t
+}
@@ -0,0 +1,195 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"github.com/GabrielHCataldo/go-redis-template/redis/redistest"
+	"testing"
+	"time"
+)
+
+func TestRegistry(t *testing.T) {
+	cacheServer := redistest.NewServer(t)
+	sessionServer := redistest.NewServer(t)
+	registry := redis.NewRegistry(map[string]option.Client{
+		"cache":    cacheServer.ClientOptions(),
+		"sessions": sessionServer.ClientOptions(),
+	})
+	hook := &attemptsHook{}
+	registry.AddHook(hook)
+	var created []string
+	registry.OnCreate(func(name string, _ *redis.Template) {
+		created = append(created, name)
+	})
+	if helper.IsNotEqualTo(cacheServer.Connections(), 0) || helper.IsNotEqualTo(sessionServer.Connections(), 0) {
+		logger.Error("NewRegistry() connected before the first Get")
+		t.Fail()
+	}
+	ctx := context.TODO()
+	cache, err := registry.Get("cache")
+	if helper.IsNotNil(err) {
+		logger.Error("Get() err =", err)
+		t.Fail()
+		return
+	}
+	_ = cache.Set(ctx, "test-key", "cache")
+	sessions, _ := registry.Get("sessions")
+	_ = sessions.Set(ctx, "test-key", "session")
+	again, _ := registry.Get("cache")
+	var dest string
+	_ = again.Get(ctx, "test-key", &dest)
+	if again != cache || helper.IsNotEqualTo(dest, "cache") {
+		logger.Errorf("Get() again result = %v", dest)
+		t.Fail()
+	}
+	if helper.IsNotEqualTo(len(hook.reset()), 3) || helper.IsNotEqualTo(created, []string{"cache", "sessions"}) {
+		logger.Errorf("AddHook() created = %v", created)
+		t.Fail()
+	}
+	_, err = registry.Get("queues")
+	if !errors.Is(err, redis.ErrTemplateNotFound) {
+		logger.Errorf("Get() err = %v, want = %v", err, redis.ErrTemplateNotFound)
+		t.Fail()
+	}
+	err = registry.Register("cache", cacheServer.ClientOptions())
+	if !errors.Is(err, redis.ErrTemplateAlreadyRegistered) {
+		logger.Errorf("Register() err = %v, want = %v", err, redis.ErrTemplateAlreadyRegistered)
+		t.Fail()
+	}
+	_ = registry.Register("queues", cacheServer.ClientOptions())
+	if helper.IsNotEqualTo(registry.Names(), []string{"cache", "queues", "sessions"}) {
+		logger.Errorf("Names() result = %v", registry.Names())
+		t.Fail()
+	}
+	err = registry.CloseAll()
+	if helper.IsNotNil(err) {
+		logger.Error("CloseAll() err =", err)
+		t.Fail()
+	}
+	err = cache.Set(ctx, "test-key", "value")
+	_, getErr := registry.Get("cache")
+	if helper.IsNil(err) || !errors.Is(getErr, redis.ErrRegistryClosed) {
+		logger.Errorf("Set() after CloseAll err = %v, Get() err = %v", err, getErr)
+		t.Fail()
+	}
+}
+
+func TestRegistrySelect(t *testing.T) {
+	server := redistest.NewServer(t)
+	registry := redis.NewRegistry(map[string]option.Client{"cache": server.ClientOptions()})
+	defer registry.SimpleCloseAll()
+	hook := &attemptsHook{}
+	registry.AddHook(hook)
+	ctx := context.TODO()
+	cache, _ := registry.Get("cache")
+	cache.RegisterSchema(userV2{}, redis.NewSchema(1))
+	selected, err := registry.Select("cache", 1)
+	if helper.IsNotNil(err) {
+		logger.Error("Select() err =", err)
+		t.Fail()
+		return
+	}
+	_ = selected.Set(ctx, "test-key", userV2{FirstName: "Foo"})
+	exists, _ := cache.Exists(ctx, "test-key")
+	var dest userV2
+	err = selected.Get(ctx, "test-key", &dest)
+	if exists || helper.IsNotNil(err) || helper.IsNotEqualTo(dest.FirstName, "Foo") {
+		logger.Errorf("Select() exists in db 0 = %v result = %v err = %v", exists, dest, err)
+		t.Fail()
+	}
+	again, _ := registry.Select("cache", 1)
+	same, _ := registry.Select("cache", 0)
+	if again != selected || same != cache || helper.IsNotEqualTo(len(hook.reset()), 3) {
+		logger.Error("Select() did not reuse the templates")
+		t.Fail()
+	}
+	clone := cache.Select(2)
+	defer clone.SimpleDisconnect()
+	_ = clone.Set(ctx, "test-key", "value")
+	if helper.IsNotEqualTo(len(hook.reset()), 1) {
+		logger.Error("Template.Select() did not copy the hooks")
+		t.Fail()
+	}
+}
+
+func TestRegistryOnCreate(t *testing.T) {
+	server := redistest.NewServer(t)
+	registry := redis.NewRegistry(map[string]option.Client{"cache": server.ClientOptions()})
+	defer registry.SimpleCloseAll()
+	var created []string
+	registry.OnCreate(func(name string, _ *redis.Template) {
+		created = append(created, name)
+		if helper.Equals(name, "cache") {
+			_, _ = registry.Select("cache", 1)
+		}
+	})
+	done := make(chan struct{})
+	go func() {
+		_, _ = registry.Get("cache")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		logger.Error("Get() deadlock in OnCreate")
+		t.FailNow()
+	}
+	if helper.IsNotEqualTo(created, []string{"cache", "cache/1"}) {
+		logger.Errorf("OnCreate() created = %v", created)
+		t.Fail()
+	}
+}
+
+func TestRegistryAddHookConcurrent(t *testing.T) {
+	server := redistest.NewServer(t)
+	registry := redis.NewRegistry(map[string]option.Client{"cache": server.ClientOptions()})
+	defer registry.SimpleCloseAll()
+	cache, _ := registry.Get("cache")
+	ctx := context.TODO()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			_ = cache.Set(ctx, "test-key", i)
+		}
+	}()
+	hooks := make([]*attemptsHook, 10)
+	for i := range hooks {
+		hooks[i] = &attemptsHook{}
+		registry.AddHook(hooks[i])
+	}
+	<-done
+	_ = cache.Set(ctx, "test-key", "last")
+	for i, hook := range hooks {
+		if helper.IsEmpty(hook.reset()) {
+			logger.Errorf("AddHook() hook %d not called", i)
+			t.Fail()
+		}
+	}
+}
+
+func TestRegistrySelectName(t *testing.T) {
+	cacheServer := redistest.NewServer(t)
+	otherServer := redistest.NewServer(t)
+	registry := redis.NewRegistry(map[string]option.Client{
+		"cache":   cacheServer.ClientOptions(),
+		"cache/1": otherServer.ClientOptions(),
+	})
+	defer registry.SimpleCloseAll()
+	selected, _ := registry.Select("cache", 1)
+	other, err := registry.Get("cache/1")
+	if helper.IsNotNil(err) || selected == other {
+		logger.Errorf("Get() returned the selected template, err = %v", err)
+		t.Fail()
+		return
+	}
+	_ = other.Set(context.TODO(), "test-key", "other")
+	if helper.IsNotEqualTo(otherServer.Connections(), 1) {
+		logger.Errorf("Get() connections = %d, want = 1", otherServer.Connections())
+		t.Fail()
+	}
+}
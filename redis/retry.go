@@ -0,0 +1,65 @@
+package redis
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"math/rand"
+	"time"
+)
+
+type retryPolicyKey struct{}
+
+// WithRetryPolicy returns a copy of the context with the retry policy, the template operations called with it
+// use the fields informed in the policy instead of the ones of the template (option.Client.RetryPolicy), ex:
+//
+//	ctx = redis.WithRetryPolicy(ctx, option.NewRetryPolicy().SetMaxAttempts(1))
+func WithRetryPolicy(ctx context.Context, policy *option.RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
+// retryPolicy returns the policy of the template merged with the policy of the context, nil if none of them is
+// informed.
+func (t *Template) retryPolicy(ctx context.Context) *option.RetryPolicy {
+	policy, _ := ctx.Value(retryPolicyKey{}).(*option.RetryPolicy)
+	if helper.IsNil(t.retry) && helper.IsNil(policy) {
+		return nil
+	}
+	return option.GetOptionRetryPolicyByParams([]*option.RetryPolicy{t.retry, policy})
+}
+
+// shouldRetry returns true if the operation can be attempted again after the error.
+func shouldRetry(ctx context.Context, policy *option.RetryPolicy, op *Operation, err error) bool {
+	if helper.IsNil(policy) || helper.IsNil(err) || op.Attempt >= *policy.MaxAttempts ||
+		(!op.Idempotent && !*policy.RetryNonIdempotent) || helper.IsNotNil(ctx.Err()) {
+		return false
+	} else if policy.RetryOn != nil {
+		return policy.RetryOn(err)
+	}
+	return IsRetryable(err)
+}
+
+// retryBackoff returns the backoff after the attempt, doubled at each attempt up to the MaxBackoff, with the
+// Jitter fraction randomized.
+func retryBackoff(policy *option.RetryPolicy, attempt int) time.Duration {
+	backoff := *policy.MinBackoff
+	for i := 1; i < attempt && backoff < *policy.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > *policy.MaxBackoff {
+		backoff = *policy.MaxBackoff
+	}
+	jitter := min(max(*policy.Jitter, 0), 1)
+	return backoff - time.Duration(rand.Float64()*jitter*float64(backoff))
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,158 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"github.com/GabrielHCataldo/go-redis-template/redis/redistest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type attemptsHook struct {
+	mutex    sync.Mutex
+	attempts []int
+}
+
+func (h *attemptsHook) Before(ctx context.Context, _ redis.Operation) (context.Context, error) {
+	return ctx, nil
+}
+
+func (h *attemptsHook) After(_ context.Context, op redis.Operation) {
+	if helper.IsNotEqualTo(op.Type, redis.OperationTypeTemplate) {
+		return
+	}
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.attempts = append(h.attempts, op.Attempt)
+}
+
+func (h *attemptsHook) reset() []int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	attempts := h.attempts
+	h.attempts = nil
+	return attempts
+}
+
+// failCommand makes the server answer the error to the first n calls of the command.
+func failCommand(server *redistest.Server, command, err string, n int) {
+	var mutex sync.Mutex
+	server.SetFaultHook(func(args []string) redistest.Fault {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if strings.EqualFold(args[0], command) && n > 0 {
+			n--
+			return redistest.Fault{Err: err}
+		}
+		return redistest.Fault{}
+	})
+}
+
+func TestTemplateRetryPolicy(t *testing.T) {
+	server := redistest.NewServer(t)
+	opts := server.ClientOptions()
+	opts.MaxRetries = -1
+	opts.RetryPolicy = option.NewRetryPolicy().SetMaxAttempts(3).SetMinBackoff(time.Millisecond)
+	redisTemplate := redis.NewTemplate(opts)
+	defer redisTemplate.SimpleDisconnect()
+	hook := &attemptsHook{}
+	redisTemplate.AddHook(hook)
+	ctx := context.TODO()
+	_ = redisTemplate.Set(ctx, "test-key", "value")
+	hook.reset()
+	failCommand(server, "get", "LOADING Redis is loading the dataset in memory", 2)
+	var dest string
+	err := redisTemplate.Get(ctx, "test-key", &dest)
+	attempts := hook.reset()
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(dest, "value") || helper.IsNotEqualTo(attempts, []int{1, 2, 3}) {
+		logger.Errorf("Get() result = %v attempts = %v err = %v", dest, attempts, err)
+		t.Fail()
+	}
+	failCommand(server, "get", "LOADING Redis is loading the dataset in memory", 5)
+	err = redisTemplate.Get(ctx, "test-key", &dest)
+	attempts = hook.reset()
+	if !redis.IsRetryable(err) || helper.IsNotEqualTo(attempts, []int{1, 2, 3}) {
+		logger.Errorf("Get() attempts = %v err = %v", attempts, err)
+		t.Fail()
+	}
+	failCommand(server, "set", "READONLY You can't write against a read only replica.", 1)
+	err = redisTemplate.Set(ctx, "nx-key", "value", option.NewSet().SetMode(option.SetModeNx))
+	attempts = hook.reset()
+	if !errors.Is(err, redis.ErrReadOnly) || helper.IsNotEqualTo(attempts, []int{1}) {
+		logger.Errorf("Set() nx attempts = %v err = %v", attempts, err)
+		t.Fail()
+	}
+	failCommand(server, "set", "READONLY You can't write against a read only replica.", 1)
+	err = redisTemplate.Set(ctx, "xx-key", "value")
+	attempts = hook.reset()
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(attempts, []int{1, 2}) {
+		logger.Errorf("Set() attempts = %v err = %v", attempts, err)
+		t.Fail()
+	}
+	failCommand(server, "get", "WRONGTYPE Operation against a key holding the wrong kind of value", 1)
+	err = redisTemplate.Get(ctx, "test-key", &dest)
+	attempts = hook.reset()
+	if !errors.Is(err, redis.ErrWrongType) || helper.IsNotEqualTo(attempts, []int{1}) {
+		logger.Errorf("Get() wrong type attempts = %v err = %v", attempts, err)
+		t.Fail()
+	}
+}
+
+func TestTemplateRetryPolicyOverride(t *testing.T) {
+	server := redistest.NewServer(t)
+	opts := server.ClientOptions()
+	opts.MaxRetries = -1
+	redisTemplate := redis.NewTemplate(opts)
+	defer redisTemplate.SimpleDisconnect()
+	hook := &attemptsHook{}
+	redisTemplate.AddHook(hook)
+	ctx := context.TODO()
+	failCommand(server, "set", "READONLY You can't write against a read only replica.", 1)
+	err := redisTemplate.Set(ctx, "test-key", "value")
+	attempts := hook.reset()
+	if !errors.Is(err, redis.ErrReadOnly) || helper.IsNotEqualTo(attempts, []int{1}) {
+		logger.Errorf("Set() without policy attempts = %v err = %v", attempts, err)
+		t.Fail()
+	}
+	policy := option.NewRetryPolicy().
+		SetMinBackoff(time.Millisecond).
+		SetRetryNonIdempotent(true).
+		SetRetryOn(func(err error) bool {
+			return errors.Is(err, redis.ErrReadOnly)
+		})
+	retryCtx := redis.WithRetryPolicy(ctx, policy)
+	_ = redisTemplate.Set(ctx, "test-key", "value")
+	hook.reset()
+	failCommand(server, "set", "READONLY You can't write against a read only replica.", 1)
+	var old string
+	err = redisTemplate.SetGet(retryCtx, "test-key", "new value", &old)
+	attempts = hook.reset()
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(attempts, []int{1, 2}) {
+		logger.Errorf("SetGet() attempts = %v err = %v", attempts, err)
+		t.Fail()
+	}
+	failCommand(server, "get", "LOADING Redis is loading the dataset in memory", 1)
+	err = redisTemplate.Get(retryCtx, "test-key", &old)
+	attempts = hook.reset()
+	if helper.IsNil(err) || helper.IsNotEqualTo(attempts, []int{1}) {
+		logger.Errorf("Get() retry on attempts = %v err = %v", attempts, err)
+		t.Fail()
+	}
+	canceledCtx, cancel := context.WithCancel(redis.WithRetryPolicy(ctx, option.NewRetryPolicy().
+		SetMinBackoff(time.Hour).
+		SetMaxBackoff(time.Hour)))
+	failCommand(server, "get", "LOADING Redis is loading the dataset in memory", 1)
+	time.AfterFunc(50*time.Millisecond, cancel)
+	startedAt := time.Now()
+	err = redisTemplate.Get(canceledCtx, "test-key", &old)
+	if helper.IsNil(err) || time.Since(startedAt) > time.Second {
+		logger.Errorf("Get() canceled backoff err = %v duration = %v", err, time.Since(startedAt))
+		t.Fail()
+	}
+}
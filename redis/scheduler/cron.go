@@ -0,0 +1,191 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MsgErrInvalidCron message of the ErrInvalidCron error.
+var MsgErrInvalidCron = "scheduler: invalid cron expression"
+
+// ErrInvalidCron is returned by ParseCron and Scheduler.ScheduleCron when the expression cannot be parsed.
+var ErrInvalidCron = errors.New(MsgErrInvalidCron)
+
+// cronSearchLimit is the maximum period searched by Cron.Next, for the expressions that never match, ex: 30 of
+// February.
+const cronSearchLimit = 5
+
+// cronDescriptors are the predefined expressions.
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var cronMonths = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var cronWeekdays = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// Cron is a parsed cron expression, with the 5 standard fields: minute, hour, day of month, month and day of week.
+// The fields accept "*", "?", values, names (JAN-DEC and SUN-SAT), ranges ("1-5"), steps ("*/15", "10-50/10") and
+// lists ("1,15"), 0 and 7 are Sunday, and when both the day of month and the day of week are restricted, a day
+// matching any of them matches, as the Vixie cron does. The descriptors @yearly, @annually, @monthly, @weekly,
+// @daily, @midnight, @hourly and "@every <duration>", ex: "@every 1h30m", are also accepted.
+type Cron struct {
+	spec    string
+	minute  uint64
+	hour    uint64
+	dom     uint64
+	month   uint64
+	dow     uint64
+	domStar bool
+	dowStar bool
+	every   time.Duration
+}
+
+// ParseCron parses the cron expression, if it is invalid, the error returned is ErrInvalidCron.
+func ParseCron(spec string) (*Cron, error) {
+	c := &Cron{spec: spec}
+	expression := strings.TrimSpace(spec)
+	if strings.HasPrefix(expression, "@every ") {
+		every, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(expression, "@every ")))
+		if helper.IsNotNil(err) || every < time.Second {
+			return nil, fmt.Errorf("%w %q: the interval of @every must be a duration of at least 1s", ErrInvalidCron,
+				spec)
+		}
+		c.every = every
+		return c, nil
+	} else if descriptor, ok := cronDescriptors[strings.ToLower(expression)]; ok {
+		expression = descriptor
+	}
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w %q: expected 5 fields, got %d", ErrInvalidCron, spec, len(fields))
+	}
+	var err error
+	if c.minute, err = parseCronField(fields[0], 0, 59, nil); helper.IsNotNil(err) {
+		return nil, fmt.Errorf("%w %q: minute: %s", ErrInvalidCron, spec, err)
+	} else if c.hour, err = parseCronField(fields[1], 0, 23, nil); helper.IsNotNil(err) {
+		return nil, fmt.Errorf("%w %q: hour: %s", ErrInvalidCron, spec, err)
+	} else if c.dom, err = parseCronField(fields[2], 1, 31, nil); helper.IsNotNil(err) {
+		return nil, fmt.Errorf("%w %q: day of month: %s", ErrInvalidCron, spec, err)
+	} else if c.month, err = parseCronField(fields[3], 1, 12, cronMonths); helper.IsNotNil(err) {
+		return nil, fmt.Errorf("%w %q: month: %s", ErrInvalidCron, spec, err)
+	} else if c.dow, err = parseCronField(fields[4], 0, 7, cronWeekdays); helper.IsNotNil(err) {
+		return nil, fmt.Errorf("%w %q: day of week: %s", ErrInvalidCron, spec, err)
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow = c.dow&^(1<<7) | 1
+	}
+	c.domStar = isCronStar(fields[2])
+	c.dowStar = isCronStar(fields[4])
+	return c, nil
+}
+
+// String returns the expression parsed.
+func (c *Cron) String() string {
+	return c.spec
+}
+
+// Next returns the first time matching the expression after t, in the location of t, with the seconds truncated,
+// or the zero time if the expression matches no time in the next 5 years. The expressions "@every" return t plus
+// the interval.
+func (c *Cron) Next(t time.Time) time.Time {
+	if c.every > 0 {
+		return t.Add(c.every)
+	}
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(cronSearchLimit, 0, 0)
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		} else if !c.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		} else if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		} else if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+		} else {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// matchDay returns true if the day of t matches the day of month and the day of week of the expression.
+func (c *Cron) matchDay(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// parseCronField parses a field of the expression as a bit set of the values between minimum and maximum.
+func parseCronField(field string, minimum, maximum int, names map[string]int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		expression, stepArg, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepArg); helper.IsNotNil(err) || step < 1 {
+				return 0, fmt.Errorf("invalid step %q", stepArg)
+			}
+		}
+		low, high := minimum, maximum
+		if !isCronStar(expression) {
+			lowArg, highArg, isRange := strings.Cut(expression, "-")
+			var err error
+			if low, err = parseCronValue(lowArg, names); helper.IsNotNil(err) {
+				return 0, err
+			}
+			high = low
+			if isRange {
+				if high, err = parseCronValue(highArg, names); helper.IsNotNil(err) {
+					return 0, err
+				}
+			} else if hasStep {
+				high = maximum
+			}
+		}
+		if low < minimum || high > maximum || low > high {
+			return 0, fmt.Errorf("%q out of range %d-%d", part, minimum, maximum)
+		}
+		for value := low; value <= high; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+	return bits, nil
+}
+
+// parseCronValue parses a number or a name of a field.
+func parseCronValue(value string, names map[string]int) (int, error) {
+	if n, ok := names[strings.ToLower(value)]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(value)
+	if helper.IsNotNil(err) {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+	return n, nil
+}
+
+func isCronStar(field string) bool {
+	return field == "*" || field == "?" || strings.HasPrefix(field, "*/")
+}
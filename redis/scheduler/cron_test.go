@@ -0,0 +1,59 @@
+package scheduler_test
+
+import (
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis/scheduler"
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	from := time.Date(2024, time.January, 31, 10, 7, 30, 0, time.UTC)
+	for _, tt := range []struct {
+		spec string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, time.January, 31, 10, 15, 0, 0, time.UTC)},
+		{"0 9-17/4 * * MON-FRI", time.Date(2024, time.January, 31, 13, 0, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2024, time.February, 1, 2, 30, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * 7", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * 5", time.Date(2024, time.February, 2, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, time.January, 31, 11, 0, 0, 0, time.UTC)},
+		{"@every 90m", time.Date(2024, time.January, 31, 11, 37, 30, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	} {
+		cron, err := scheduler.ParseCron(tt.spec)
+		if helper.IsNotNil(err) {
+			logger.Errorf("ParseCron(%q) err = %v", tt.spec, err)
+			t.Fail()
+			continue
+		}
+		if next := cron.Next(from); !next.Equal(tt.want) {
+			logger.Errorf("Next(%q) result = %v, want = %v", tt.spec, next, tt.want)
+			t.Fail()
+		}
+	}
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	if helper.IsNil(err) {
+		cron, _ := scheduler.ParseCron("0 8 * * *")
+		next := cron.Next(from.In(saoPaulo))
+		if !next.Equal(time.Date(2024, time.January, 31, 11, 0, 0, 0, time.UTC)) {
+			logger.Errorf("Next() location result = %v", next)
+			t.Fail()
+		}
+	}
+}
+
+func TestParseCron(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *",
+		"* * * * 8", "*/0 * * * *", "5-1 * * * *", "* * * foo *", "@every 1ms", "@every x"} {
+		if _, err := scheduler.ParseCron(spec); !errors.Is(err, scheduler.ErrInvalidCron) {
+			logger.Errorf("ParseCron(%q) err = %v, want = %v", spec, err, scheduler.ErrInvalidCron)
+			t.Fail()
+		}
+	}
+}
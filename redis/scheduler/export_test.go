@@ -0,0 +1,11 @@
+package scheduler
+
+// The Lua scripts of the scheduler, exported to the tests that emulate them in the redistest.Server and run them on
+// the redis server of REDIS_URL.
+var (
+	ScheduleScript = scheduleScript
+	CancelScript   = cancelScript
+	ClaimScript    = claimScript
+	AckScript      = ackScript
+	LeadScript     = leadScript
+)
@@ -0,0 +1,131 @@
+package scheduler
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"time"
+)
+
+// Options represents options that can be used to configure the scheduler (New) and its runners.
+type Options struct {
+	// Concurrency number of jobs executed at the same time by each runner (Scheduler.Start).
+	// Default is 10.
+	Concurrency *int
+	// PollInterval interval in which the leader claims the due jobs and the other runners try to take the
+	// leadership, it is the maximum delay of an execution after its due time.
+	// Default is 1 second.
+	PollInterval *time.Duration
+	// LeaseTimeout time a claimed job is reserved to its runner, if the execution is not finished in time, ex: the
+	// process crashed, the job is claimed again, so the executions longer than the lease can happen twice.
+	// Default is 1 minute.
+	LeaseTimeout *time.Duration
+	// LeaderTTL time the leadership is kept without being renewed by the leader, must be greater than the
+	// PollInterval, which is the renewal interval.
+	// Default is 10 seconds, or three times the PollInterval if greater.
+	LeaderTTL *time.Duration
+	// Location time zone of the cron expressions.
+	// Default is time.UTC, so that all the instances agree on the times.
+	Location *time.Location
+	// Clock provides the current time used to schedule and claim the jobs, the redistest.ManualClock implements it.
+	// Default is the system clock.
+	Clock Clock
+	// OnError is called with the errors of the handlers and of the background operations of the runners, ex: a
+	// claim failed because the connection was lost (not required).
+	OnError func(err error)
+}
+
+// NewOptions creates a new Options instance.
+func NewOptions() *Options {
+	return &Options{}
+}
+
+// SetConcurrency sets value for the Concurrency field.
+func (o *Options) SetConcurrency(concurrency int) *Options {
+	o.Concurrency = &concurrency
+	return o
+}
+
+// SetPollInterval sets value for the PollInterval field.
+func (o *Options) SetPollInterval(pollInterval time.Duration) *Options {
+	o.PollInterval = &pollInterval
+	return o
+}
+
+// SetLeaseTimeout sets value for the LeaseTimeout field.
+func (o *Options) SetLeaseTimeout(leaseTimeout time.Duration) *Options {
+	o.LeaseTimeout = &leaseTimeout
+	return o
+}
+
+// SetLeaderTTL sets value for the LeaderTTL field.
+func (o *Options) SetLeaderTTL(leaderTTL time.Duration) *Options {
+	o.LeaderTTL = &leaderTTL
+	return o
+}
+
+// SetLocation sets value for the Location field.
+func (o *Options) SetLocation(location *time.Location) *Options {
+	o.Location = location
+	return o
+}
+
+// SetClock sets value for the Clock field.
+func (o *Options) SetClock(clock Clock) *Options {
+	o.Clock = clock
+	return o
+}
+
+// SetOnError sets value for the OnError field.
+func (o *Options) SetOnError(f func(err error)) *Options {
+	o.OnError = f
+	return o
+}
+
+// GetOptionsByParams assembles the Options object from optional parameters.
+func GetOptionsByParams(opts []*Options) *Options {
+	result := &Options{}
+	for _, opt := range opts {
+		if helper.IsNil(opt) {
+			continue
+		}
+		if helper.IsNotNil(opt.Concurrency) {
+			result.Concurrency = opt.Concurrency
+		}
+		if helper.IsNotNil(opt.PollInterval) {
+			result.PollInterval = opt.PollInterval
+		}
+		if helper.IsNotNil(opt.LeaseTimeout) {
+			result.LeaseTimeout = opt.LeaseTimeout
+		}
+		if helper.IsNotNil(opt.LeaderTTL) {
+			result.LeaderTTL = opt.LeaderTTL
+		}
+		if helper.IsNotNil(opt.Location) {
+			result.Location = opt.Location
+		}
+		if helper.IsNotNil(opt.Clock) {
+			result.Clock = opt.Clock
+		}
+		if opt.OnError != nil {
+			result.OnError = opt.OnError
+		}
+	}
+	if helper.IsNil(result.Concurrency) || *result.Concurrency < 1 {
+		result.Concurrency = helper.ConvertToPointer(10)
+	}
+	if helper.IsNil(result.PollInterval) || *result.PollInterval <= 0 {
+		result.PollInterval = helper.ConvertToPointer(time.Second)
+	}
+	if helper.IsNil(result.LeaseTimeout) || *result.LeaseTimeout <= 0 {
+		result.LeaseTimeout = helper.ConvertToPointer(time.Minute)
+	}
+	if helper.IsNil(result.LeaderTTL) || *result.LeaderTTL <= *result.PollInterval {
+		result.LeaderTTL = helper.ConvertToPointer(max(10*time.Second, 3*(*result.PollInterval)))
+	}
+	if helper.IsNil(result.Location) {
+		result.Location = time.UTC
+	}
+	if helper.IsNil(result.Clock) {
+		result.Clock = systemClock{}
+	}
+	return result
+}
@@ -0,0 +1,220 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Handler executes a job of the scheduler, the errors and the panics are passed to Options.OnError. The context is
+// canceled if the Runner.Shutdown times out.
+type Handler[T any] func(ctx context.Context, job *Job[T]) error
+
+// Runner polls and executes the due jobs of a scheduler while it is the leader, started by Scheduler.Start.
+type Runner[T any] struct {
+	scheduler    *Scheduler[T]
+	handler      Handler[T]
+	id           string
+	leader       atomic.Bool
+	slots        chan struct{}
+	stopPoll     context.CancelFunc
+	pollCtx      context.Context
+	cancelJobs   context.CancelFunc
+	jobCtx       context.Context
+	pollGroup    sync.WaitGroup
+	jobsGroup    sync.WaitGroup
+	shutdownOnce sync.Once
+	shutdownErr  error
+}
+
+// claim is a job claimed by the runner, with its due time and the lease time that identifies the claim.
+type claim struct {
+	id    string
+	runAt time.Time
+	raw   string
+	lease int64
+}
+
+// Start starts a runner that competes for the leadership of the scheduler every Options.PollInterval, and, while
+// it is the leader, executes the due jobs with the handler, up to Options.Concurrency at the same time. Stop the
+// runner with Runner.Shutdown.
+func (s *Scheduler[T]) Start(handler Handler[T]) *Runner[T] {
+	r := &Runner[T]{scheduler: s, handler: handler, slots: make(chan struct{}, *s.opt.Concurrency)}
+	id, err := newID()
+	if helper.IsNotNil(err) {
+		id = strconv.FormatInt(time.Now().UnixNano(), 10)
+	}
+	r.id = id
+	r.pollCtx, r.stopPoll = context.WithCancel(context.Background())
+	r.jobCtx, r.cancelJobs = context.WithCancel(context.Background())
+	r.pollGroup.Add(1)
+	go r.loop()
+	return r
+}
+
+// IsLeader returns true if the runner was the leader on its last poll.
+func (r *Runner[T]) IsLeader() bool {
+	return r.leader.Load()
+}
+
+// Shutdown stops polling, resigns the leadership so that another runner takes it on its next poll, and waits for
+// the executions in progress to finish. If the context is done first, the contexts of the handlers are canceled,
+// the error of the context is returned, and the unfinished jobs are executed again after Options.LeaseTimeout.
+func (r *Runner[T]) Shutdown(ctx context.Context) error {
+	r.shutdownOnce.Do(func() {
+		r.stopPoll()
+		r.pollGroup.Wait()
+		if r.leader.Swap(false) {
+			s := r.scheduler
+			if _, err := s.template.RunScript(ctx, leadScript, []any{s.leaderKey()}, r.id, 0); helper.IsNotNil(err) {
+				r.report(err)
+			}
+		}
+		done := make(chan struct{})
+		go func() {
+			r.jobsGroup.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			r.shutdownErr = ctx.Err()
+		}
+		r.cancelJobs()
+	})
+	return r.shutdownErr
+}
+
+// loop polls immediately and then every interval until the runner stops polling.
+func (r *Runner[T]) loop() {
+	defer r.pollGroup.Done()
+	ticker := time.NewTicker(*r.scheduler.opt.PollInterval)
+	defer ticker.Stop()
+	for {
+		r.poll()
+		select {
+		case <-r.pollCtx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll takes or renews the leadership, and, if the runner is the leader, claims and executes the due jobs while
+// there are free slots.
+func (r *Runner[T]) poll() {
+	s := r.scheduler
+	leader, err := s.template.RunScript(r.pollCtx, leadScript, []any{s.leaderKey()}, r.id,
+		s.opt.LeaderTTL.Milliseconds())
+	if helper.IsNotNil(err) {
+		r.leader.Store(false)
+		if helper.IsNil(r.pollCtx.Err()) {
+			r.report(err)
+		}
+		return
+	}
+	r.leader.Store(helper.Equals(leader, int64(1)))
+	for r.IsLeader() {
+		free := cap(r.slots) - len(r.slots)
+		if helper.IsEmpty(free) {
+			return
+		}
+		now := s.opt.Clock.Now()
+		lease := now.Add(*s.opt.LeaseTimeout).UnixMilli()
+		reply, err := s.template.RunScript(r.pollCtx, claimScript, append(s.keys(), s.leaderKey()), now.UnixMilli(),
+			lease, free, r.id)
+		if helper.IsNotNil(err) {
+			if helper.IsNil(r.pollCtx.Err()) {
+				r.report(err)
+			}
+			return
+		} else if helper.IsNil(reply) {
+			// the leadership expired, ex: the renewal took longer than the TTL.
+			r.leader.Store(false)
+			return
+		}
+		items, _ := reply.([]any)
+		for i := 0; i+2 < len(items); i += 3 {
+			id, _ := items[i].(string)
+			raw, _ := items[i+2].(string)
+			runAt, _ := strconv.ParseFloat(helper.SimpleConvertToString(items[i+1]), 64)
+			r.execute(claim{id: id, runAt: time.UnixMilli(int64(runAt)), raw: raw, lease: lease})
+		}
+		if len(items)/3 < free {
+			return
+		}
+	}
+}
+
+// execute runs the job in a slot of the runner.
+func (r *Runner[T]) execute(c claim) {
+	r.slots <- struct{}{}
+	r.jobsGroup.Add(1)
+	go func() {
+		defer func() {
+			<-r.slots
+			r.jobsGroup.Done()
+		}()
+		s := r.scheduler
+		var next string
+		job := &Job[T]{}
+		if err := json.Unmarshal([]byte(c.raw), job); helper.IsNotNil(err) {
+			r.report(fmt.Errorf("scheduler: job %s: %w", c.id, err))
+		} else {
+			job.RunAt = c.runAt
+			if err = r.run(job); helper.IsNotNil(err) {
+				r.report(fmt.Errorf("scheduler: job %s: %w", c.id, err))
+			}
+			next = r.next(job)
+		}
+		if _, err := s.template.RunScript(context.Background(), ackScript, s.keys(), c.id, c.lease, c.raw,
+			next); helper.IsNotNil(err) {
+			r.report(err)
+		}
+	}()
+}
+
+// run calls the handler, converting the panics to errors.
+func (r *Runner[T]) run(job *Job[T]) (err error) {
+	defer func() {
+		if p := recover(); helper.IsNotNil(p) {
+			err = fmt.Errorf("scheduler: handler panic: %v", p)
+		}
+	}()
+	return r.handler(r.jobCtx, job)
+}
+
+// next returns the next due time of the recurring job in Unix milliseconds, after the due time of the execution
+// and the current time, so the executions missed while no runner was active happen only once. It returns empty for
+// the jobs executed once.
+func (r *Runner[T]) next(job *Job[T]) string {
+	if helper.IsEmpty(job.Cron) {
+		return ""
+	}
+	cron, err := ParseCron(job.Cron)
+	if helper.IsNotNil(err) {
+		r.report(fmt.Errorf("scheduler: job %s: %w", job.ID, err))
+		return ""
+	}
+	from := r.scheduler.opt.Clock.Now()
+	if job.RunAt.After(from) {
+		from = job.RunAt
+	}
+	next := cron.Next(from.In(r.scheduler.opt.Location))
+	if next.IsZero() {
+		return ""
+	}
+	return strconv.FormatInt(next.UnixMilli(), 10)
+}
+
+// report passes the error to Options.OnError.
+func (r *Runner[T]) report(err error) {
+	if r.scheduler.opt.OnError != nil {
+		r.scheduler.opt.OnError(err)
+	}
+}
@@ -0,0 +1,233 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"time"
+)
+
+// scheduleScript saves the job and schedules it, unless the same job is already scheduled or executing, so that
+// the recurring jobs registered again on each start of the application keep their next execution.
+//
+// KEYS: due, claimed, jobs. ARGV: id, job, due time.
+var scheduleScript = redis.NewScript(`
+local job = redis.call('HGET', KEYS[3], ARGV[1])
+if job == ARGV[2] and (redis.call('ZSCORE', KEYS[1], ARGV[1]) or redis.call('ZSCORE', KEYS[2], ARGV[1])) then
+	return 0
+end
+redis.call('HSET', KEYS[3], ARGV[1], ARGV[2])
+redis.call('ZADD', KEYS[1], ARGV[3], ARGV[1])
+return 1
+`)
+
+// cancelScript removes the job, the execution in progress, if any, is not acknowledged.
+//
+// KEYS: due, claimed, jobs. ARGV: id.
+var cancelScript = redis.NewScript(`
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('ZREM', KEYS[2], ARGV[1])
+return redis.call('HDEL', KEYS[3], ARGV[1])
+`)
+
+// claimScript returns nil if the runner is not the leader, otherwise returns the due jobs as id, due time and job
+// triples, up to the limit, reserving them to the runner until the lease time. The jobs whose lease has expired are
+// due again.
+//
+// KEYS: due, claimed, jobs, leader. ARGV: now, lease time, limit, runner.
+var claimScript = redis.NewScript(`
+if redis.call('GET', KEYS[4]) ~= ARGV[4] then
+	return false
+end
+for _, id in ipairs(redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ARGV[1], 'LIMIT', 0, ARGV[3])) do
+	redis.call('ZREM', KEYS[2], id)
+	redis.call('ZADD', KEYS[1], ARGV[1], id)
+end
+local claimed = {}
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'WITHSCORES', 'LIMIT', 0, ARGV[3])
+for i = 1, #due, 2 do
+	redis.call('ZREM', KEYS[1], due[i])
+	local job = redis.call('HGET', KEYS[3], due[i])
+	if job then
+		redis.call('ZADD', KEYS[2], ARGV[2], due[i])
+		table.insert(claimed, due[i])
+		table.insert(claimed, due[i + 1])
+		table.insert(claimed, job)
+	end
+end
+return claimed
+`)
+
+// ackScript finishes the execution of the job if its lease was not lost, removing the job, or scheduling its next
+// execution if it is recurring, unless the job was changed or canceled during the execution.
+//
+// KEYS: due, claimed, jobs. ARGV: id, lease time, job, next due time or empty.
+var ackScript = redis.NewScript(`
+local lease = redis.call('ZSCORE', KEYS[2], ARGV[1])
+if not lease or tonumber(lease) ~= tonumber(ARGV[2]) then
+	return 0
+end
+redis.call('ZREM', KEYS[2], ARGV[1])
+if redis.call('HGET', KEYS[3], ARGV[1]) == ARGV[3] then
+	if ARGV[4] == '' then
+		redis.call('HDEL', KEYS[3], ARGV[1])
+	else
+		redis.call('ZADD', KEYS[1], ARGV[4], ARGV[1])
+	end
+end
+return 1
+`)
+
+// leadScript takes or renews the leadership for the runner until the TTL, returning 1 if the runner is the leader,
+// a zero TTL resigns the leadership of the runner.
+//
+// KEYS: leader. ARGV: runner, TTL in milliseconds.
+var leadScript = redis.NewScript(`
+local leader = redis.call('GET', KEYS[1])
+if ARGV[2] == '0' then
+	if leader == ARGV[1] then
+		redis.call('DEL', KEYS[1])
+	end
+	return 0
+elseif leader and leader ~= ARGV[1] then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+return 1
+`)
+
+// Clock provides the current time of the scheduler.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Job is a job of the scheduler with its typed payload, stored as JSON.
+type Job[T any] struct {
+	// ID unique identifier of the job, generated by Schedule or informed to ScheduleCron.
+	ID string `json:"id"`
+	// Payload of the job, must be compatible with the encoding/json package.
+	Payload T `json:"payload"`
+	// Cron expression of the recurring jobs, empty for the jobs executed once.
+	Cron string `json:"cron,omitempty"`
+	// RunAt time the execution was due.
+	RunAt time.Time `json:"-"`
+}
+
+// Scheduler executes jobs at a time, or recurring with cron expressions, on the sorted sets of a template. The jobs
+// are saved in a hash and scheduled in a sorted set scored by the due time, so they survive the restarts of the
+// application. The runners of all the instances elect a leader, which claims the due jobs atomically with a Lua
+// script and executes them, so each execution happens once even with many instances, and the other runners take the
+// leadership if the leader stops.
+//
+// A job is executed again if its runner does not finish it within Options.LeaseTimeout, ex: the process crashed,
+// so the handlers must be idempotent. The failed executions are not retried, the recurring jobs are executed again
+// at their next time, and the handlers can enqueue the jobs that need retries in a queue.Queue.
+//
+// The jobs are stored as JSON in plain text, even if the template has encryption (option.Client.Encryption), since
+// the scripts compare them by their exact value, so encrypt the sensitive fields of the payload before scheduling.
+type Scheduler[T any] struct {
+	template *redis.Template
+	name     string
+	opt      *Options
+}
+
+// New creates a new scheduler of the template, with the name as prefix of its keys, ex: "reports:due",
+// "reports:claimed", "reports:jobs" and "reports:leader".
+//
+// On redis cluster, use a hash tag in the name, ex: "{reports}", so that the scripts can access all the keys.
+//
+// To customize the scheduler and its runners, use the opts parameter (Options).
+func New[T any](t *redis.Template, name string, opts ...*Options) *Scheduler[T] {
+	return &Scheduler[T]{
+		template: t,
+		name:     name,
+		opt:      GetOptionsByParams(opts),
+	}
+}
+
+// Name returns the name of the scheduler.
+func (s *Scheduler[T]) Name() string {
+	return s.name
+}
+
+// Schedule saves a job with the payload to be executed once at the time, returning the ID of the job.
+func (s *Scheduler[T]) Schedule(ctx context.Context, payload T, at time.Time) (string, error) {
+	id, err := newID()
+	if helper.IsNil(err) {
+		err = s.schedule(ctx, &Job[T]{ID: id, Payload: payload}, at)
+	}
+	return id, err
+}
+
+// ScheduleIn saves a job with the payload to be executed once after the delay, returning the ID of the job.
+func (s *Scheduler[T]) ScheduleIn(ctx context.Context, payload T, delay time.Duration) (string, error) {
+	return s.Schedule(ctx, payload, s.opt.Clock.Now().Add(delay))
+}
+
+// ScheduleCron saves a recurring job with the ID and the payload, executed at the times of the cron expression in
+// Options.Location, see Cron. If the expression is invalid, the error returned is ErrInvalidCron.
+//
+// Registering the same job again, ex: on each start of the application, keeps its next execution, and a job
+// with the same ID and a different expression or payload replaces it, the execution in progress, if any, is not
+// interrupted.
+func (s *Scheduler[T]) ScheduleCron(ctx context.Context, id, spec string, payload T) error {
+	cron, err := ParseCron(spec)
+	if helper.IsNotNil(err) {
+		return err
+	}
+	next := cron.Next(s.opt.Clock.Now().In(s.opt.Location))
+	if next.IsZero() {
+		return fmt.Errorf("%w %q: the expression never matches", ErrInvalidCron, spec)
+	}
+	return s.schedule(ctx, &Job[T]{ID: id, Payload: payload, Cron: spec}, next)
+}
+
+// Cancel removes the job, returning false if it does not exist. The execution in progress, if any, is not
+// interrupted.
+func (s *Scheduler[T]) Cancel(ctx context.Context, id string) (bool, error) {
+	removed, err := s.template.RunScript(ctx, cancelScript, s.keys(), id)
+	return helper.Equals(removed, int64(1)), err
+}
+
+// schedule saves the job and schedules it at the time.
+func (s *Scheduler[T]) schedule(ctx context.Context, job *Job[T], at time.Time) error {
+	raw, err := json.Marshal(job)
+	if helper.IsNotNil(err) {
+		return errors.Join(redis.ErrConvertValue, err)
+	}
+	_, err = s.template.RunScript(ctx, scheduleScript, s.keys(), job.ID, string(raw), at.UnixMilli())
+	return err
+}
+
+// keys returns the due, claimed and jobs keys, the keys of the scripts.
+func (s *Scheduler[T]) keys() []any {
+	return []any{
+		redis.SprintKey(s.name, "due"),
+		redis.SprintKey(s.name, "claimed"),
+		redis.SprintKey(s.name, "jobs"),
+	}
+}
+
+func (s *Scheduler[T]) leaderKey() string {
+	return redis.SprintKey(s.name, "leader")
+}
+
+// newID returns a random identifier for the jobs and the runners.
+func newID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); helper.IsNotNil(err) {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,398 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"github.com/GabrielHCataldo/go-redis-template/redis/redistest"
+	"github.com/GabrielHCataldo/go-redis-template/redis/scheduler"
+	redisdriver "github.com/redis/go-redis/v9"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+type report struct {
+	Name string `json:"name"`
+}
+
+// execution is an execution of a job observed by a handler of the tests.
+type execution struct {
+	runner string
+	job    scheduler.Job[report]
+}
+
+// recorder records the executions of the handlers of the tests.
+type recorder struct {
+	mutex      sync.Mutex
+	executions []execution
+	errs       []error
+}
+
+func (r *recorder) handler(runner string) scheduler.Handler[report] {
+	return func(ctx context.Context, job *scheduler.Job[report]) error {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		r.executions = append(r.executions, execution{runner: runner, job: *job})
+		if helper.Equals(job.Payload.Name, "broken") {
+			panic("template not found")
+		}
+		return nil
+	}
+}
+
+func (r *recorder) onError(err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.errs = append(r.errs, err)
+}
+
+func (r *recorder) count() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.executions)
+}
+
+func (r *recorder) last() execution {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.executions[len(r.executions)-1]
+}
+
+// newServer starts a server emulating the Lua scripts of the scheduler, and a clock at 00:05 of a Monday.
+func newServer(t *testing.T) (*redistest.Server, *redistest.ManualClock) {
+	server := redistest.NewServer(t)
+	server.SetScript(scheduler.ScheduleScript.Source(), emulateSchedule)
+	server.SetScript(scheduler.CancelScript.Source(), emulateCancel)
+	server.SetScript(scheduler.ClaimScript.Source(), emulateClaim)
+	server.SetScript(scheduler.AckScript.Source(), emulateAck)
+	server.SetScript(scheduler.LeadScript.Source(), emulateLead)
+	return server, redistest.NewManualClock(time.Date(2024, time.January, 1, 0, 5, 0, 0, time.UTC))
+}
+
+// scriptCalls runs the commands of an emulated script, the first error aborts the script as it does in redis.call,
+// the next calls return nil and the error is returned by the emulation.
+type scriptCalls struct {
+	call redistest.ScriptCall
+	err  error
+}
+
+func (c *scriptCalls) do(args ...string) any {
+	if helper.IsNotNil(c.err) {
+		return nil
+	}
+	reply, err := c.call(args...)
+	c.err = err
+	return reply
+}
+
+func emulateSchedule(call redistest.ScriptCall, keys, args []string) (any, error) {
+	c := &scriptCalls{call: call}
+	if job := c.do("HGET", keys[2], args[0]); helper.Equals(job, args[1]) {
+		if helper.IsNotNil(c.do("ZSCORE", keys[0], args[0])) || helper.IsNotNil(c.do("ZSCORE", keys[1], args[0])) {
+			return int64(0), c.err
+		}
+	}
+	c.do("HSET", keys[2], args[0], args[1])
+	c.do("ZADD", keys[0], args[2], args[0])
+	return int64(1), c.err
+}
+
+func emulateCancel(call redistest.ScriptCall, keys, args []string) (any, error) {
+	c := &scriptCalls{call: call}
+	c.do("ZREM", keys[0], args[0])
+	c.do("ZREM", keys[1], args[0])
+	return c.do("HDEL", keys[2], args[0]), c.err
+}
+
+func emulateClaim(call redistest.ScriptCall, keys, args []string) (any, error) {
+	c := &scriptCalls{call: call}
+	if leader := c.do("GET", keys[3]); helper.IsNotEqualTo(leader, args[3]) {
+		return false, c.err
+	}
+	expired, _ := c.do("ZRANGEBYSCORE", keys[1], "-inf", args[0], "LIMIT", "0", args[2]).([]any)
+	for _, id := range expired {
+		c.do("ZREM", keys[1], id.(string))
+		c.do("ZADD", keys[0], args[0], id.(string))
+	}
+	claimed := []any{}
+	due, _ := c.do("ZRANGEBYSCORE", keys[0], "-inf", args[0], "WITHSCORES", "LIMIT", "0", args[2]).([]any)
+	for i := 0; i+1 < len(due); i += 2 {
+		id := due[i].(string)
+		c.do("ZREM", keys[0], id)
+		if job := c.do("HGET", keys[2], id); helper.IsNotNil(job) {
+			c.do("ZADD", keys[1], args[1], id)
+			claimed = append(claimed, id, due[i+1], job)
+		}
+	}
+	return claimed, c.err
+}
+
+func emulateAck(call redistest.ScriptCall, keys, args []string) (any, error) {
+	c := &scriptCalls{call: call}
+	lease := c.do("ZSCORE", keys[1], args[0])
+	if helper.IsNil(lease) || helper.IsNotEqualTo(parseFloat(lease), parseFloat(args[1])) {
+		return int64(0), c.err
+	}
+	c.do("ZREM", keys[1], args[0])
+	if job := c.do("HGET", keys[2], args[0]); helper.Equals(job, args[2]) {
+		if helper.IsEmpty(args[3]) {
+			c.do("HDEL", keys[2], args[0])
+		} else {
+			c.do("ZADD", keys[0], args[3], args[0])
+		}
+	}
+	return int64(1), c.err
+}
+
+func emulateLead(call redistest.ScriptCall, keys, args []string) (any, error) {
+	c := &scriptCalls{call: call}
+	leader := c.do("GET", keys[0])
+	if helper.Equals(args[1], "0") {
+		if helper.Equals(leader, args[0]) {
+			c.do("DEL", keys[0])
+		}
+		return int64(0), c.err
+	} else if helper.IsNotNil(leader) && helper.IsNotEqualTo(leader, args[0]) {
+		return int64(0), c.err
+	}
+	c.do("SET", keys[0], args[0], "PX", args[1])
+	return int64(1), c.err
+}
+
+func parseFloat(v any) float64 {
+	f, _ := strconv.ParseFloat(v.(string), 64)
+	return f
+}
+
+// waitFor polls the condition until it is true or the timeout expires.
+func waitFor(timeout time.Duration, condition func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return condition()
+}
+
+func TestSchedulerSchedule(t *testing.T) {
+	server, clock := newServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	client := redisdriver.NewClient(server.ClientOptions().ParseToRedisOptions())
+	defer client.Close()
+	ctx := context.TODO()
+	rec := &recorder{}
+	reports := scheduler.New[report](redisTemplate, "reports", scheduler.NewOptions().SetClock(clock).
+		SetPollInterval(10*time.Millisecond).SetOnError(rec.onError))
+	at := clock.Now().Add(time.Hour)
+	hourly, err := reports.Schedule(ctx, report{Name: "hourly"}, at)
+	soon, _ := reports.ScheduleIn(ctx, report{Name: "soon"}, 30*time.Minute)
+	canceled, _ := reports.ScheduleIn(ctx, report{Name: "canceled"}, time.Minute)
+	if helper.IsNotNil(err) || helper.IsEmpty(hourly) || helper.Equals(hourly, soon) {
+		logger.Errorf("Schedule() result = %v err = %v", hourly, err)
+		t.Fail()
+	}
+	removed, err := reports.Cancel(ctx, canceled)
+	removedAgain, _ := reports.Cancel(ctx, canceled)
+	if helper.IsNotNil(err) || !removed || removedAgain {
+		logger.Errorf("Cancel() result = %v again = %v err = %v", removed, removedAgain, err)
+		t.Fail()
+	}
+	runner := reports.Start(rec.handler("a"))
+	defer func() { _ = runner.Shutdown(ctx) }()
+	time.Sleep(50 * time.Millisecond)
+	if !runner.IsLeader() || helper.IsNotEqualTo(rec.count(), 0) {
+		logger.Errorf("Start() leader = %v executions = %v", runner.IsLeader(), rec.count())
+		t.Fail()
+	}
+	clock.Advance(30 * time.Minute)
+	if !waitFor(2*time.Second, func() bool { return helper.Equals(rec.count(), 1) }) ||
+		helper.IsNotEqualTo(rec.last().job.ID, soon) {
+		logger.Errorf("Start() executions = %v", rec.executions)
+		t.FailNow()
+	}
+	clock.Advance(30 * time.Minute)
+	if !waitFor(2*time.Second, func() bool { return helper.Equals(rec.count(), 2) }) ||
+		helper.IsNotEqualTo(rec.last().job.Payload.Name, "hourly") || !rec.last().job.RunAt.Equal(at) {
+		logger.Errorf("Start() executions = %v", rec.executions)
+		t.FailNow()
+	}
+	clock.Advance(time.Hour)
+	time.Sleep(50 * time.Millisecond)
+	jobs := client.HLen(ctx, "reports:jobs").Val()
+	if helper.IsNotEqualTo(rec.count(), 2) || helper.IsNotEqualTo(jobs, int64(0)) || helper.IsNotEmpty(rec.errs) {
+		logger.Errorf("Start() executions = %v jobs = %v errs = %v", rec.executions, jobs, rec.errs)
+		t.Fail()
+	}
+}
+
+func TestSchedulerScheduleCron(t *testing.T) {
+	server, clock := newServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	client := redisdriver.NewClient(server.ClientOptions().ParseToRedisOptions())
+	defer client.Close()
+	ctx := context.TODO()
+	rec := &recorder{}
+	reports := scheduler.New[report](redisTemplate, "reports", scheduler.NewOptions().SetClock(clock).
+		SetPollInterval(10*time.Millisecond).SetOnError(rec.onError))
+	due := func() time.Time {
+		return time.UnixMilli(int64(client.ZScore(ctx, "reports:due", "sales").Val())).UTC()
+	}
+	err := reports.ScheduleCron(ctx, "sales", "*/15 * * * *", report{Name: "sales"})
+	if helper.IsNotNil(err) || !due().Equal(time.Date(2024, time.January, 1, 0, 15, 0, 0, time.UTC)) {
+		logger.Errorf("ScheduleCron() due = %v err = %v", due(), err)
+		t.Fail()
+	}
+	clock.Advance(5 * time.Minute)
+	_ = reports.ScheduleCron(ctx, "sales", "*/15 * * * *", report{Name: "sales"})
+	if !due().Equal(time.Date(2024, time.January, 1, 0, 15, 0, 0, time.UTC)) {
+		logger.Errorf("ScheduleCron() again due = %v", due())
+		t.Fail()
+	}
+	err = reports.ScheduleCron(ctx, "invalid", "* * * *", report{})
+	if !errors.Is(err, scheduler.ErrInvalidCron) {
+		logger.Errorf("ScheduleCron() err = %v, want = %v", err, scheduler.ErrInvalidCron)
+		t.Fail()
+	}
+	runner := reports.Start(rec.handler("a"))
+	defer func() { _ = runner.Shutdown(ctx) }()
+	clock.Advance(5 * time.Minute)
+	if !waitFor(2*time.Second, func() bool {
+		return helper.Equals(rec.count(), 1) && due().Equal(time.Date(2024, time.January, 1, 0, 30, 0, 0, time.UTC))
+	}) || !rec.last().job.RunAt.Equal(time.Date(2024, time.January, 1, 0, 15, 0, 0, time.UTC)) {
+		logger.Errorf("Start() executions = %v due = %v", rec.executions, due())
+		t.FailNow()
+	}
+	// the executions missed while the runner was behind happen once.
+	clock.Advance(52 * time.Minute)
+	if !waitFor(2*time.Second, func() bool {
+		return helper.Equals(rec.count(), 2) && due().Equal(time.Date(2024, time.January, 1, 1, 15, 0, 0, time.UTC))
+	}) || helper.IsNotEqualTo(rec.last().job.Cron, "*/15 * * * *") {
+		logger.Errorf("Start() executions = %v due = %v", rec.executions, due())
+		t.Fail()
+	}
+	err = reports.ScheduleCron(ctx, "sales", "0 * * * *", report{Name: "broken"})
+	clock.Advance(2 * time.Hour)
+	if !waitFor(2*time.Second, func() bool {
+		return helper.Equals(rec.count(), 3) && due().Equal(time.Date(2024, time.January, 1, 4, 0, 0, 0, time.UTC))
+	}) || helper.IsNotNil(err) {
+		logger.Errorf("ScheduleCron() replaced executions = %v due = %v err = %v", rec.executions, due(), err)
+		t.FailNow()
+	}
+	rec.mutex.Lock()
+	defer rec.mutex.Unlock()
+	if helper.IsNotEqualTo(len(rec.errs), 1) ||
+		helper.IsNotEqualTo(rec.errs[0].Error(), "scheduler: job sales: scheduler: handler panic: template not found") {
+		logger.Errorf("Start() errs = %v", rec.errs)
+		t.Fail()
+	}
+}
+
+func TestSchedulerLeader(t *testing.T) {
+	server, clock := newServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	rec := &recorder{}
+	reports := scheduler.New[report](redisTemplate, "reports", scheduler.NewOptions().SetClock(clock).
+		SetPollInterval(10*time.Millisecond).SetConcurrency(3).SetOnError(rec.onError))
+	ids := map[string]bool{}
+	for i := 0; i < 20; i++ {
+		id, _ := reports.ScheduleIn(ctx, report{Name: strconv.Itoa(i)}, time.Minute)
+		ids[id] = true
+	}
+	runnerA := reports.Start(rec.handler("a"))
+	runnerB := reports.Start(rec.handler("b"))
+	defer func() { _ = runnerB.Shutdown(ctx) }()
+	time.Sleep(50 * time.Millisecond)
+	if helper.Equals(runnerA.IsLeader(), runnerB.IsLeader()) {
+		logger.Errorf("Start() leaderA = %v leaderB = %v", runnerA.IsLeader(), runnerB.IsLeader())
+		t.Fail()
+	}
+	leader, follower := "a", "b"
+	if runnerB.IsLeader() {
+		leader, follower = "b", "a"
+		runnerA, runnerB = runnerB, runnerA
+	}
+	clock.Advance(time.Minute)
+	waitFor(2*time.Second, func() bool { return helper.Equals(rec.count(), 20) })
+	time.Sleep(50 * time.Millisecond)
+	rec.mutex.Lock()
+	executed := map[string]bool{}
+	for _, e := range rec.executions {
+		if executed[e.job.ID] || !ids[e.job.ID] || helper.IsNotEqualTo(e.runner, leader) {
+			logger.Errorf("Start() duplicated execution = %v", e)
+			t.Fail()
+		}
+		executed[e.job.ID] = true
+	}
+	rec.mutex.Unlock()
+	if helper.IsNotEqualTo(len(executed), 20) {
+		logger.Errorf("Start() executions = %v", len(executed))
+		t.Fail()
+	}
+	if err := runnerA.Shutdown(ctx); helper.IsNotNil(err) || runnerA.IsLeader() ||
+		!waitFor(2*time.Second, runnerB.IsLeader) {
+		logger.Errorf("Shutdown() leaderA = %v leaderB = %v err = %v", runnerA.IsLeader(), runnerB.IsLeader(), err)
+		t.FailNow()
+	}
+	_, _ = reports.ScheduleIn(ctx, report{Name: "failover"}, time.Minute)
+	clock.Advance(time.Minute)
+	if !waitFor(2*time.Second, func() bool { return helper.Equals(rec.count(), 21) }) ||
+		helper.IsNotEqualTo(rec.last().runner, follower) {
+		logger.Errorf("Start() failover executions = %v", rec.count())
+		t.Fail()
+	}
+}
+
+func TestSchedulerLease(t *testing.T) {
+	server, clock := newServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	client := redisdriver.NewClient(server.ClientOptions().ParseToRedisOptions())
+	defer client.Close()
+	ctx := context.TODO()
+	reports := scheduler.New[report](redisTemplate, "reports", scheduler.NewOptions().SetClock(clock).
+		SetPollInterval(10*time.Millisecond).SetLeaseTimeout(time.Minute))
+	id, _ := reports.ScheduleIn(ctx, report{Name: "slow"}, 0)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	stuck := reports.Start(func(ctx context.Context, job *scheduler.Job[report]) error {
+		close(started)
+		<-release
+		return nil
+	})
+	<-started
+	shutdownCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if err := stuck.Shutdown(shutdownCtx); !errors.Is(err, context.DeadlineExceeded) {
+		logger.Errorf("Shutdown() err = %v, want = %v", err, context.DeadlineExceeded)
+		t.Fail()
+	}
+	rec := &recorder{}
+	runner := reports.Start(rec.handler("b"))
+	defer func() { _ = runner.Shutdown(ctx) }()
+	time.Sleep(50 * time.Millisecond)
+	if helper.IsNotEqualTo(rec.count(), 0) {
+		logger.Errorf("Start() executions before the lease expired = %v", rec.count())
+		t.Fail()
+	}
+	clock.Advance(2 * time.Minute)
+	if !waitFor(2*time.Second, func() bool { return helper.Equals(rec.count(), 1) }) ||
+		helper.IsNotEqualTo(rec.last().job.ID, id) {
+		logger.Errorf("Start() executions after the lease expired = %v", rec.count())
+		t.FailNow()
+	}
+	close(release)
+	if !waitFor(2*time.Second, func() bool { return helper.IsEmpty(client.HLen(ctx, "reports:jobs").Val()) }) {
+		logger.Errorf("Start() job not removed")
+		t.Fail()
+	}
+}
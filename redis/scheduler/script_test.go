@@ -0,0 +1,97 @@
+package scheduler_test
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"github.com/GabrielHCataldo/go-redis-template/redis/scheduler"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// scriptStep is a run of a script of the scheduler with the reply expected.
+type scriptStep struct {
+	name   string
+	script *redis.Script
+	keys   []any
+	args   []any
+	want   any
+}
+
+// initScriptTemplates returns the templates the scripts are tested on: the server emulating the scripts, and a
+// redis server running the Lua source when the REDIS_URL environment variable is informed, so the emulations are
+// checked against the scripts.
+func initScriptTemplates(t *testing.T) map[string]*redis.Template {
+	server, _ := newServer(t)
+	templates := map[string]*redis.Template{"emulated": redis.NewTemplate(server.ClientOptions())}
+	if helper.IsNotEmpty(os.Getenv("REDIS_URL")) {
+		templates["lua"] = redis.NewTemplate(option.Client{
+			Addr:     os.Getenv("REDIS_URL"),
+			Password: os.Getenv("REDIS_PASSWORD"),
+		})
+	} else {
+		logger.Info("REDIS_URL not informed, the Lua scripts run only emulated")
+	}
+	return templates
+}
+
+func initScriptSteps(name string) []scriptStep {
+	due := redis.SprintKey(name, "due")
+	claimed := redis.SprintKey(name, "claimed")
+	jobs := redis.SprintKey(name, "jobs")
+	leader := redis.SprintKey(name, "leader")
+	keys := []any{due, claimed, jobs}
+	claimKeys := []any{due, claimed, jobs, leader}
+	job := `{"id":"j1","payload":{"name":"sales"}}`
+	changed := `{"id":"j1","payload":{"name":"changed"}}`
+	return []scriptStep{
+		{"schedule", scheduler.ScheduleScript, keys, []any{"j1", job, 1000}, int64(1)},
+		{"schedule again", scheduler.ScheduleScript, keys, []any{"j1", job, 2000}, int64(0)},
+		{"claim without leader", scheduler.ClaimScript, claimKeys, []any{1000, 61000, 10, "r1"}, nil},
+		{"lead", scheduler.LeadScript, []any{leader}, []any{"r1", 60000}, int64(1)},
+		{"lead taken", scheduler.LeadScript, []any{leader}, []any{"r2", 60000}, int64(0)},
+		{"claim not due", scheduler.ClaimScript, claimKeys, []any{999, 60999, 10, "r1"}, []any{}},
+		{"claim", scheduler.ClaimScript, claimKeys, []any{1000, 61000, 10, "r1"}, []any{"j1", "1000", job}},
+		{"claim claimed", scheduler.ClaimScript, claimKeys, []any{2000, 62000, 10, "r1"}, []any{}},
+		{"ack lost lease", scheduler.AckScript, keys, []any{"j1", 999, job, ""}, int64(0)},
+		{"ack recurring", scheduler.AckScript, keys, []any{"j1", 61000, job, 120000}, int64(1)},
+		{"claim next", scheduler.ClaimScript, claimKeys, []any{120000, 180000, 10, "r1"},
+			[]any{"j1", "120000", job}},
+		{"claim expired lease", scheduler.ClaimScript, claimKeys, []any{180000, 240000, 10, "r1"},
+			[]any{"j1", "180000", job}},
+		{"schedule changed", scheduler.ScheduleScript, keys, []any{"j1", changed, 300000}, int64(1)},
+		{"ack changed", scheduler.AckScript, keys, []any{"j1", 240000, job, ""}, int64(1)},
+		{"claim changed", scheduler.ClaimScript, claimKeys, []any{300000, 360000, 10, "r1"},
+			[]any{"j1", "300000", changed}},
+		{"ack once", scheduler.AckScript, keys, []any{"j1", 360000, changed, ""}, int64(1)},
+		{"cancel acked", scheduler.CancelScript, keys, []any{"j1"}, int64(0)},
+		{"schedule canceled", scheduler.ScheduleScript, keys, []any{"j2", job, 1000}, int64(1)},
+		{"cancel", scheduler.CancelScript, keys, []any{"j2"}, int64(1)},
+		{"resign other", scheduler.LeadScript, []any{leader}, []any{"r2", 0}, int64(0)},
+		{"renew", scheduler.LeadScript, []any{leader}, []any{"r1", 60000}, int64(1)},
+		{"resign", scheduler.LeadScript, []any{leader}, []any{"r1", 0}, int64(0)},
+		{"lead after resign", scheduler.LeadScript, []any{leader}, []any{"r2", 60000}, int64(1)},
+	}
+}
+
+func TestSchedulerScripts(t *testing.T) {
+	ctx := context.TODO()
+	name := "scheduler-scripts-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	for backend, redisTemplate := range initScriptTemplates(t) {
+		for _, step := range initScriptSteps(name) {
+			reply, err := redisTemplate.RunScript(ctx, step.script, step.keys, step.args...)
+			if helper.IsNotNil(err) || helper.IsNotEqualTo(reply, step.want) {
+				logger.Errorf("RunScript() %s %s result = %v err = %v, want = %v", backend, step.name, reply, err,
+					step.want)
+				t.Fail()
+			}
+		}
+		_ = redisTemplate.Del(ctx, redis.SprintKey(name, "due"), redis.SprintKey(name, "claimed"),
+			redis.SprintKey(name, "jobs"), redis.SprintKey(name, "leader"))
+		redisTemplate.SimpleDisconnect()
+	}
+}
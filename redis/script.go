@@ -0,0 +1,106 @@
+package redis
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/redis/go-redis/v9"
+)
+
+// Script is a Lua script with its SHA1 digest, run by Template.RunScript, declare it once as a package variable,
+// ex:
+//
+//	var incrScript = redis.NewScript(`return redis.call('INCRBY', KEYS[1], ARGV[1])`)
+type Script struct {
+	source string
+	hash   string
+}
+
+// NewScript creates a new Script with the Lua source.
+func NewScript(source string) *Script {
+	sum := sha1.Sum([]byte(source))
+	return &Script{source: source, hash: hex.EncodeToString(sum[:])}
+}
+
+// Source returns the Lua source of the script.
+func (s *Script) Source() string {
+	return s.source
+}
+
+// Hash returns the SHA1 digest of the script, used by EvalSha.
+func (s *Script) Hash() string {
+	return s.hash
+}
+
+// Eval redis `EVAL script numkeys [key [key ...]] [arg [arg ...]]` command, runs the Lua script atomically with the
+// keys and the args, returning the reply converted as the go-redis client does: int64, string, []any or nil when the
+// script returns nil or false. The error replies of the script, ex: redis.error_reply, are returned as errors.
+//
+// The keys parameter can be of any type, but cannot be null, in case an error occurs when converting, the error
+// returned is ErrConvertKey. On redis cluster, the keys must have the same hash slot. The args are converted like
+// the members of PFAdd, without compression and encryption, if an error occurs when converting, the error returned
+// is ErrConvertValue.
+func (t *Template) Eval(ctx context.Context, script string, keys []any, args ...any) (any, error) {
+	return t.eval(ctx, "Eval", script, "", keys, args)
+}
+
+// EvalSha redis `EVALSHA sha1 numkeys [key [key ...]] [arg [arg ...]]` command, runs the script loaded with the
+// SHA1 digest, if the script is not in the cache of the server, the error returned is ErrNoScript.
+//
+// The parameters and the return follow the Eval documentation.
+func (t *Template) EvalSha(ctx context.Context, sha1 string, keys []any, args ...any) (any, error) {
+	return t.eval(ctx, "EvalSha", "", sha1, keys, args)
+}
+
+// ScriptLoad redis `SCRIPT LOAD script` command, loads the Lua script in the cache of the server without running
+// it, returning its SHA1 digest.
+func (t *Template) ScriptLoad(ctx context.Context, script string) (string, error) {
+	var hash string
+	op := &Operation{Name: "ScriptLoad", Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		var err error
+		op.ValueSize = len(script)
+		hash, err = t.client.ScriptLoad(ctx, script).Result()
+		return err
+	})
+	return hash, err
+}
+
+// RunScript runs the script with EVALSHA, and with EVAL if it is not in the cache of the server yet, which loads
+// it for the next runs, so the source is sent only once per server.
+//
+// The parameters and the return follow the Eval documentation.
+func (t *Template) RunScript(ctx context.Context, script *Script, keys []any, args ...any) (any, error) {
+	return t.eval(ctx, "RunScript", script.source, script.hash, keys, args)
+}
+
+// eval runs EVALSHA when the hash is informed, falling back to EVAL when the script is not in the cache and the
+// source is informed, or runs EVAL with the source.
+func (t *Template) eval(ctx context.Context, name, source, hash string, keys, args []any) (any, error) {
+	var reply any
+	op := &Operation{Name: name, Keys: keys, Value: args}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKeys, err := convertKeys(keys)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		sArgs, err := convertMembers(op, args)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		if helper.IsNotEmpty(hash) {
+			reply, err = t.client.EvalSha(ctx, hash, sKeys, sArgs...).Result()
+		}
+		if helper.IsEmpty(hash) || (helper.IsNotEmpty(source) && errors.Is(ErrorKind(err), ErrNoScript)) {
+			op.ValueSize += len(source)
+			reply, err = t.client.Eval(ctx, source, sKeys, sArgs...).Result()
+		}
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		return err
+	})
+	return reply, err
+}
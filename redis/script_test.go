@@ -0,0 +1,62 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"github.com/GabrielHCataldo/go-redis-template/redis/redistest"
+	"testing"
+)
+
+var incrScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return false
+end
+return redis.call('INCRBY', KEYS[1], ARGV[1])
+`)
+
+func TestTemplateScript(t *testing.T) {
+	server := redistest.NewServer(t)
+	server.SetScript(incrScript.Source(), func(call redistest.ScriptCall, keys, args []string) (any, error) {
+		if exists, _ := call("EXISTS", keys[0]); helper.Equals(exists, int64(0)) {
+			return false, nil
+		}
+		return call("INCRBY", keys[0], args[0])
+	})
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	reply, err := redisTemplate.RunScript(ctx, incrScript, []any{"counter"}, 2)
+	if helper.IsNotNil(err) || helper.IsNotNil(reply) {
+		logger.Errorf("RunScript() result = %v err = %v", reply, err)
+		t.Fail()
+	}
+	_ = redisTemplate.Set(ctx, "counter", 1)
+	reply, err = redisTemplate.RunScript(ctx, incrScript, []any{"counter"}, 2)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(reply, int64(3)) {
+		logger.Errorf("RunScript() loaded result = %v err = %v", reply, err)
+		t.Fail()
+	}
+	reply, err = redisTemplate.EvalSha(ctx, incrScript.Hash(), []any{"counter"}, 4)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(reply, int64(7)) {
+		logger.Errorf("EvalSha() result = %v err = %v", reply, err)
+		t.Fail()
+	}
+	hash, err := redisTemplate.ScriptLoad(ctx, "return 1")
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(hash, redis.NewScript("return 1").Hash()) {
+		logger.Errorf("ScriptLoad() result = %v err = %v", hash, err)
+		t.Fail()
+	}
+	_, err = redisTemplate.EvalSha(ctx, redis.NewScript("return 2").Hash(), nil)
+	if !errors.Is(err, redis.ErrNoScript) {
+		logger.Errorf("EvalSha() err = %v, want = %v", err, redis.ErrNoScript)
+		t.Fail()
+	}
+	_, err = redisTemplate.Eval(ctx, incrScript.Source(), []any{"counter"}, nil)
+	if !errors.Is(err, redis.ErrConvertValue) {
+		logger.Errorf("Eval() err = %v, want = %v", err, redis.ErrConvertValue)
+		t.Fail()
+	}
+}
@@ -0,0 +1,591 @@
+package redis
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// The special names of the search tag, the fields with them are filled by FTSearch with the key of the document
+// and its score (SearchQuery.WithScores).
+const (
+	searchTagID    = "@id"
+	searchTagScore = "@score"
+)
+
+// SearchIndex is the definition of a RediSearch index, parsed from the search tags of a struct by
+// ParseSearchIndex. The index is declared by the tag of a blank field, with its name and the options "on" (hash or
+// json) and "prefix" (repeatable), and each indexed field by its tag, with the attribute name, default is the json
+// name of the field, the type and the options, ex:
+//
+//	type Product struct {
+//		_     struct{} `search:"idx:product,on=json,prefix=product:"`
+//		ID    string   `json:"id" search:"@id"`
+//		Name  string   `json:"name" search:",text,sortable,weight=2"`
+//		Tags  []string `json:"tags" search:",tag,separator=;"`
+//		Price float64  `json:"price" search:",numeric,sortable"`
+//		Place string   `json:"place" search:"location,geo"`
+//		Embed []float32 `json:"embed" search:",vector,algorithm=hnsw,dim=384,distance=cosine"`
+//	}
+type SearchIndex struct {
+	// Name of the index.
+	Name string
+	// On type of the keys indexed, option.SearchOnHash or option.SearchOnJSON.
+	On option.SearchOn
+	// Prefixes of the keys indexed, empty indexes all the keys of the type.
+	Prefixes []string
+	// Fields indexed.
+	Fields []SearchField
+}
+
+// SearchField is a field of a SearchIndex, the options are used according to its type.
+type SearchField struct {
+	// Name of the attribute, used by the queries, ex: @name.
+	Name string
+	// Identifier of the field in the hash, or the JSONPath of the value in the document, ex: "$.name".
+	Identifier string
+	// Type of the field.
+	Type option.SearchFieldType
+	// Sortable allows sorting the results by the field.
+	Sortable bool
+	// NoIndex the field is not queried, only sortable.
+	NoIndex bool
+	// NoStem disables the stemming of the terms of a text field.
+	NoStem bool
+	// Weight of the terms of a text field in the score, zero means the redis default (1).
+	Weight float64
+	// Separator of the values of a tag field of a hash, empty means the redis default (,).
+	Separator string
+	// CaseSensitive keeps the case of the values of a tag field.
+	CaseSensitive bool
+	// Algorithm of the vector field, default is option.VectorAlgorithmFlat.
+	Algorithm option.VectorAlgorithm
+	// Dim number of dimensions of the vector field, required.
+	Dim int
+	// Distance metric of the vector field, default is option.VectorDistanceCosine.
+	Distance option.VectorDistance
+	// VectorType type of the elements of the vector field, default is FLOAT32.
+	VectorType string
+}
+
+// searchDocument is a document of the results of FTSearch, or a row of the results of FTAggregate.
+type searchDocument struct {
+	id     string
+	score  float64
+	fields map[string]string
+}
+
+// ParseSearchIndex parses the search tags of the model, a struct or a pointer to a struct, see SearchIndex. If a
+// tag is invalid, the error returned is ErrInvalidSearchTag.
+func ParseSearchIndex(model any) (*SearchIndex, error) {
+	modelType := reflect.TypeOf(model)
+	for helper.IsNotNil(modelType) && modelType.Kind() == reflect.Pointer {
+		modelType = modelType.Elem()
+	}
+	if helper.IsNil(modelType) || modelType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: the model must be a struct", ErrInvalidSearchTag)
+	}
+	index := &SearchIndex{On: option.SearchOnHash}
+	var fields []reflect.StructField
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		tag, ok := field.Tag.Lookup("search")
+		if !ok {
+			continue
+		} else if helper.Equals(field.Name, "_") {
+			if err := parseSearchIndexTag(index, tag); helper.IsNotNil(err) {
+				return nil, err
+			}
+			continue
+		}
+		fields = append(fields, field)
+	}
+	if helper.IsEmpty(index.Name) {
+		return nil, fmt.Errorf("%w: the index name must be declared by the tag of a blank field", ErrInvalidSearchTag)
+	}
+	for _, field := range fields {
+		searchField, ok, err := parseSearchFieldTag(index.On, field)
+		if helper.IsNotNil(err) {
+			return nil, err
+		} else if ok {
+			index.Fields = append(index.Fields, searchField)
+		}
+	}
+	if helper.IsEmpty(index.Fields) {
+		return nil, fmt.Errorf("%w: the index %s has no fields", ErrInvalidSearchTag, index.Name)
+	}
+	return index, nil
+}
+
+// FTCreate redis `FT.CREATE index ON HASH | JSON PREFIX count prefix ... SCHEMA ...` command, creates the RediSearch
+// index declared by the search tags of the model, see SearchIndex.
+//
+// If a tag is invalid, the error returned is ErrInvalidSearchTag, and if the index already exists, the error
+// returned is ErrIndexAlreadyExists.
+func (t *Template) FTCreate(ctx context.Context, model any) error {
+	op := &Operation{Name: "FTCreate"}
+	return t.process(ctx, op, func(ctx context.Context) error {
+		index, err := ParseSearchIndex(model)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		return searchError(t.client.Do(ctx, index.args()...).Err())
+	})
+}
+
+// FTDropIndex redis `FT.DROPINDEX index [DD]` command, drops the index, and deletes the keys indexed with
+// option.FTDropIndex.DeleteDocs. If the index does not exist, the error returned is ErrIndexNotFound.
+func (t *Template) FTDropIndex(ctx context.Context, index string, opts ...*option.FTDropIndex) error {
+	op := &Operation{Name: "FTDropIndex"}
+	return t.process(ctx, op, func(ctx context.Context) error {
+		opt := option.GetOptionFTDropIndexByParams(opts)
+		args := []any{"FT.DROPINDEX", index}
+		if *opt.DeleteDocs {
+			args = append(args, "DD")
+		}
+		return searchError(t.client.Do(ctx, args...).Err())
+	})
+}
+
+// FTSearch redis `FT.SEARCH index query ...` command, searches the documents of the index with the query, see
+// SearchQuery, converting them to dest and returning the total of documents found, regardless of the limit.
+//
+// The dest parameter must be a pointer to a slice, if its elements are structs, the JSON documents are decoded with
+// the option.Client.JSONCodec of the template, and the fields of the hashes or returned (SearchQuery.Return) are
+// set to the struct fields of the same attribute name, or json name. The fields with the search tags "@id" and
+// "@score" are filled with the key and the score of the document. Elements of type string are filled with the keys,
+// and maps with the fields.
+//
+// If the index does not exist, the error returned is ErrIndexNotFound.
+func (t *Template) FTSearch(ctx context.Context, index string, query *SearchQuery, dest any) (int64, error) {
+	var total int64
+	op := &Operation{Name: "FTSearch", Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		if !isSlicePointer(dest) {
+			return ErrDestIsNotSlicePointer
+		} else if helper.IsNil(query) {
+			query = NewSearchQuery()
+		}
+		reply, err := t.client.Do(ctx, query.args(index)...).Result()
+		if helper.IsNotNil(err) {
+			return searchError(err)
+		}
+		var docs []searchDocument
+		total, docs, err = parseSearchReply(reply, query.withScores, query.noContent)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		return t.convertSearchDocuments(docs, dest)
+	})
+	return total, err
+}
+
+// FTAggregate redis `FT.AGGREGATE index query ...` command, runs the aggregation pipeline on the documents of the
+// index, see SearchAggregate, converting the rows to dest.
+//
+// The dest parameter follows the FTSearch documentation, the rows are converted like the fields of the hashes. If
+// the index does not exist, the error returned is ErrIndexNotFound.
+func (t *Template) FTAggregate(ctx context.Context, index string, aggregate *SearchAggregate, dest any) error {
+	op := &Operation{Name: "FTAggregate", Idempotent: true}
+	return t.process(ctx, op, func(ctx context.Context) error {
+		if !isSlicePointer(dest) {
+			return ErrDestIsNotSlicePointer
+		} else if helper.IsNil(aggregate) {
+			aggregate = NewSearchAggregate(nil)
+		}
+		reply, err := t.client.Do(ctx, aggregate.args(index)...).Result()
+		if helper.IsNotNil(err) {
+			return searchError(err)
+		}
+		rows, err := parseAggregateReply(reply)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		return t.convertSearchDocuments(rows, dest)
+	})
+}
+
+// args returns the arguments of the FT.CREATE command of the index.
+func (s *SearchIndex) args() []any {
+	args := []any{"FT.CREATE", s.Name, "ON", s.On.String()}
+	if helper.IsNotEmpty(s.Prefixes) {
+		args = append(args, "PREFIX", len(s.Prefixes))
+		for _, prefix := range s.Prefixes {
+			args = append(args, prefix)
+		}
+	}
+	args = append(args, "SCHEMA")
+	for _, field := range s.Fields {
+		args = append(args, field.Identifier)
+		if helper.IsNotEqualTo(field.Identifier, field.Name) {
+			args = append(args, "AS", field.Name)
+		}
+		args = append(args, field.Type.String())
+		switch field.Type {
+		case option.SearchFieldText:
+			if field.Weight > 0 {
+				args = append(args, "WEIGHT", field.Weight)
+			}
+			if field.NoStem {
+				args = append(args, "NOSTEM")
+			}
+		case option.SearchFieldTag:
+			if helper.IsNotEmpty(field.Separator) {
+				args = append(args, "SEPARATOR", field.Separator)
+			}
+			if field.CaseSensitive {
+				args = append(args, "CASESENSITIVE")
+			}
+		case option.SearchFieldVector:
+			args = append(args, field.Algorithm.String(), 6, "TYPE", field.VectorType, "DIM", field.Dim,
+				"DISTANCE_METRIC", field.Distance.String())
+		}
+		if field.Sortable {
+			args = append(args, "SORTABLE")
+		}
+		if field.NoIndex {
+			args = append(args, "NOINDEX")
+		}
+	}
+	return args
+}
+
+func parseSearchIndexTag(index *SearchIndex, tag string) error {
+	parts := strings.Split(tag, ",")
+	index.Name = strings.TrimSpace(parts[0])
+	for _, part := range parts[1:] {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch strings.ToLower(name) {
+		case "on":
+			index.On = option.SearchOn(strings.ToUpper(value))
+			if index.On != option.SearchOnHash && index.On != option.SearchOnJSON {
+				return fmt.Errorf("%w: unknown index type %q", ErrInvalidSearchTag, value)
+			}
+		case "prefix":
+			index.Prefixes = append(index.Prefixes, value)
+		default:
+			return fmt.Errorf("%w: unknown index option %q", ErrInvalidSearchTag, part)
+		}
+	}
+	return nil
+}
+
+func parseSearchFieldTag(on option.SearchOn, field reflect.StructField) (SearchField, bool, error) {
+	parts := strings.Split(field.Tag.Get("search"), ",")
+	name := strings.TrimSpace(parts[0])
+	if helper.Equals(name, "-") || strings.HasPrefix(name, "@") {
+		return SearchField{}, false, nil
+	} else if len(parts) < 2 {
+		return SearchField{}, false, fmt.Errorf("%w: field %s has no type", ErrInvalidSearchTag, field.Name)
+	}
+	jsonName := searchJSONName(field)
+	searchField := SearchField{
+		Name:       helper.IfEmptyReturns(name, jsonName),
+		Identifier: jsonName,
+		Type:       option.SearchFieldType(strings.ToUpper(strings.TrimSpace(parts[1]))),
+		Algorithm:  option.VectorAlgorithmFlat,
+		Distance:   option.VectorDistanceCosine,
+		VectorType: "FLOAT32",
+	}
+	switch searchField.Type {
+	case option.SearchFieldText, option.SearchFieldTag, option.SearchFieldNumeric, option.SearchFieldGeo,
+		option.SearchFieldVector:
+	default:
+		return searchField, false, fmt.Errorf("%w: field %s has unknown type %q", ErrInvalidSearchTag, field.Name,
+			parts[1])
+	}
+	searchField.Identifier = searchFieldIdentifier(on, jsonName)
+	if on == option.SearchOnJSON && searchField.Type == option.SearchFieldTag && field.Type.Kind() == reflect.Slice {
+		searchField.Identifier += "[*]"
+	}
+	for _, part := range parts[2:] {
+		if err := parseSearchFieldOption(&searchField, strings.TrimSpace(part)); helper.IsNotNil(err) {
+			return searchField, false, fmt.Errorf("%w: field %s: %s", ErrInvalidSearchTag, field.Name, err)
+		}
+	}
+	if searchField.Type == option.SearchFieldVector && searchField.Dim <= 0 {
+		return searchField, false, fmt.Errorf("%w: vector field %s requires dim", ErrInvalidSearchTag, field.Name)
+	}
+	return searchField, true, nil
+}
+
+func parseSearchFieldOption(field *SearchField, part string) error {
+	name, value, _ := strings.Cut(part, "=")
+	var err error
+	switch strings.ToLower(name) {
+	case "sortable":
+		field.Sortable = true
+	case "noindex":
+		field.NoIndex = true
+	case "nostem":
+		field.NoStem = true
+	case "casesensitive":
+		field.CaseSensitive = true
+	case "weight":
+		field.Weight, err = strconv.ParseFloat(value, 64)
+	case "separator":
+		field.Separator = value
+	case "algorithm":
+		field.Algorithm = option.VectorAlgorithm(strings.ToUpper(value))
+		if field.Algorithm != option.VectorAlgorithmFlat && field.Algorithm != option.VectorAlgorithmHNSW {
+			err = fmt.Errorf("unknown algorithm %q", value)
+		}
+	case "dim":
+		field.Dim, err = strconv.Atoi(value)
+	case "distance":
+		field.Distance = option.VectorDistance(strings.ToUpper(value))
+		switch field.Distance {
+		case option.VectorDistanceL2, option.VectorDistanceIP, option.VectorDistanceCosine:
+		default:
+			err = fmt.Errorf("unknown distance %q", value)
+		}
+	case "type":
+		field.VectorType = strings.ToUpper(value)
+	default:
+		err = fmt.Errorf("unknown option %q", part)
+	}
+	return err
+}
+
+// searchFieldIdentifier returns the identifier of the field of the hashes, or the JSONPath of the documents.
+func searchFieldIdentifier(on option.SearchOn, name string) string {
+	if on == option.SearchOnJSON {
+		return "$." + name
+	}
+	return name
+}
+
+// searchJSONName returns the name of the field in the json tag, or the name of the field.
+func searchJSONName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if helper.IsEmpty(name) || helper.Equals(name, "-") {
+		return field.Name
+	}
+	return name
+}
+
+// searchError converts the errors of the RediSearch index names to ErrIndexNotFound and ErrIndexAlreadyExists.
+func searchError(err error) error {
+	if helper.IsNil(err) {
+		return nil
+	}
+	msg := strings.ToLower(err.Error())
+	if strings.Contains(msg, "no such index") || strings.Contains(msg, "unknown index name") {
+		return ErrIndexNotFound
+	} else if strings.Contains(msg, "index already exists") {
+		return ErrIndexAlreadyExists
+	}
+	return err
+}
+
+// parseSearchReply parses the reply of FT.SEARCH, an array with the total followed by the documents in RESP2, or
+// a map with the total_results and results in RESP3.
+func parseSearchReply(reply any, withScores, noContent bool) (int64, []searchDocument, error) {
+	if result, ok := reply.(map[any]any); ok {
+		total, _ := result["total_results"].(int64)
+		results, _ := result["results"].([]any)
+		docs := make([]searchDocument, 0, len(results))
+		for _, item := range results {
+			fields, _ := item.(map[any]any)
+			doc := searchDocument{fields: searchFields(fields["extra_attributes"])}
+			doc.id, _ = fields["id"].(string)
+			doc.score = replyFloat(fields["score"])
+			docs = append(docs, doc)
+		}
+		return total, docs, nil
+	}
+	result, ok := reply.([]any)
+	if !ok || helper.IsEmpty(result) {
+		return 0, nil, fmt.Errorf("redis: unexpected search reply %T", reply)
+	}
+	total, _ := result[0].(int64)
+	var docs []searchDocument
+	for i := 1; i < len(result); i++ {
+		doc := searchDocument{fields: map[string]string{}}
+		doc.id, _ = result[i].(string)
+		if withScores && i+1 < len(result) {
+			i++
+			doc.score = replyFloat(result[i])
+		}
+		if !noContent && i+1 < len(result) {
+			i++
+			doc.fields = searchFields(result[i])
+		}
+		docs = append(docs, doc)
+	}
+	return total, docs, nil
+}
+
+// parseAggregateReply parses the reply of FT.AGGREGATE, like parseSearchReply, returning the rows.
+func parseAggregateReply(reply any) ([]searchDocument, error) {
+	var rows []searchDocument
+	if result, ok := reply.(map[any]any); ok {
+		results, _ := result["results"].([]any)
+		for _, item := range results {
+			fields, _ := item.(map[any]any)
+			rows = append(rows, searchDocument{fields: searchFields(fields["extra_attributes"])})
+		}
+		return rows, nil
+	}
+	result, ok := reply.([]any)
+	if !ok || helper.IsEmpty(result) {
+		return nil, fmt.Errorf("redis: unexpected aggregate reply %T", reply)
+	}
+	for _, item := range result[1:] {
+		rows = append(rows, searchDocument{fields: searchFields(item)})
+	}
+	return rows, nil
+}
+
+// searchFields converts the fields of a document, an array of names and values in RESP2 or a map in RESP3.
+func searchFields(value any) map[string]string {
+	fields := map[string]string{}
+	switch value := value.(type) {
+	case []any:
+		for i := 0; i+1 < len(value); i += 2 {
+			fields[fmt.Sprint(value[i])] = fmt.Sprint(value[i+1])
+		}
+	case map[any]any:
+		for name, field := range value {
+			fields[fmt.Sprint(name)] = fmt.Sprint(field)
+		}
+	}
+	return fields
+}
+
+// replyFloat converts a float of a reply, a double in RESP3 or a string in RESP2.
+func replyFloat(value any) float64 {
+	switch value := value.(type) {
+	case float64:
+		return value
+	case int64:
+		return float64(value)
+	case string:
+		f, _ := strconv.ParseFloat(value, 64)
+		return f
+	}
+	return 0
+}
+
+// convertSearchDocuments converts the documents to the elements of the dest slice, see Template.FTSearch.
+func (t *Template) convertSearchDocuments(docs []searchDocument, dest any) error {
+	slice := reflect.ValueOf(dest).Elem()
+	elemType := slice.Type().Elem()
+	result := reflect.MakeSlice(slice.Type(), 0, len(docs))
+	for _, doc := range docs {
+		elem := reflect.New(elemType).Elem()
+		if err := t.convertSearchDocument(doc, elem); helper.IsNotNil(err) {
+			return err
+		}
+		result = reflect.Append(result, elem)
+	}
+	slice.Set(result)
+	return nil
+}
+
+func (t *Template) convertSearchDocument(doc searchDocument, elem reflect.Value) error {
+	target := elem
+	if elem.Kind() == reflect.Pointer {
+		elem.Set(reflect.New(elem.Type().Elem()))
+		target = elem.Elem()
+	}
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(doc.id)
+		return nil
+	case reflect.Map:
+		target.Set(reflect.MakeMap(target.Type()))
+		for name, value := range doc.fields {
+			item := reflect.New(target.Type().Elem())
+			if err := setSearchValue(item.Elem(), value, ""); helper.IsNotNil(err) {
+				return err
+			}
+			target.SetMapIndex(reflect.ValueOf(name).Convert(target.Type().Key()), item.Elem())
+		}
+		return nil
+	case reflect.Struct:
+	default:
+		return helper.ConvertToDest(doc.fields, target.Addr().Interface())
+	}
+	if document, ok := doc.fields["$"]; ok {
+		if err := t.jsonCodec.Unmarshal([]byte(document), target.Addr().Interface()); helper.IsNotNil(err) {
+			return err
+		}
+	}
+	for i := 0; i < target.NumField(); i++ {
+		structField := target.Type().Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+		field := target.Field(i)
+		parts := strings.Split(structField.Tag.Get("search"), ",")
+		name := strings.TrimSpace(parts[0])
+		var separator string
+		for _, part := range parts[1:] {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(part), "separator="); ok {
+				separator = value
+			}
+		}
+		var value string
+		var ok bool
+		switch name {
+		case searchTagID:
+			value, ok = doc.id, true
+		case searchTagScore:
+			value, ok = strconv.FormatFloat(doc.score, 'f', -1, 64), true
+		default:
+			value, ok = doc.fields[name]
+			if !ok {
+				value, ok = doc.fields[searchJSONName(structField)]
+			}
+		}
+		if !ok {
+			continue
+		} else if err := setSearchValue(field, value, separator); helper.IsNotNil(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// setSearchValue sets the value of a field of a document, the tags of the hashes are split by the separator.
+func setSearchValue(field reflect.Value, value, separator string) error {
+	switch {
+	case field.Kind() == reflect.Interface || field.Kind() == reflect.String:
+		field.Set(reflect.ValueOf(value).Convert(field.Type()))
+		return nil
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String &&
+		!strings.HasPrefix(value, "["):
+		values := reflect.MakeSlice(field.Type(), 0, 0)
+		for _, item := range strings.Split(value, helper.IfEmptyReturns(separator, ",")) {
+			values = reflect.Append(values, reflect.ValueOf(strings.TrimSpace(item)).Convert(field.Type().Elem()))
+		}
+		field.Set(values)
+		return nil
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Float32 &&
+		!strings.HasPrefix(value, "["):
+		vector := decodeVector([]byte(value))
+		values := reflect.MakeSlice(field.Type(), len(vector), len(vector))
+		for i, item := range vector {
+			values.Index(i).SetFloat(float64(item))
+		}
+		field.Set(values)
+		return nil
+	}
+	return helper.ConvertToDest(value, field.Addr().Interface())
+}
+
+// decodeVector decodes the FLOAT32 vectors of RediSearch, little-endian.
+func decodeVector(data []byte) []float32 {
+	vector := make([]float32, len(data)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return vector
+}
@@ -0,0 +1,311 @@
+package redis
+
+import (
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"strings"
+	"unicode"
+)
+
+// SearchQuery builds the query and the options of the redis FT.SEARCH command, executed by Template.FTSearch, the
+// filters are combined with AND, and no filter matches all the documents, ex:
+//
+//	NewSearchQuery().Text("wireless keyboard").Tag("tags", "usb", "bluetooth").Range("price", 10, 50).
+//		SortBy("price", option.SortOrderAsc).Limit(0, 20)
+type SearchQuery struct {
+	filters         []string
+	returns         []string
+	sortBy          string
+	sortOrder       option.SortOrder
+	offset          int
+	num             int
+	limit           bool
+	highlight       bool
+	highlightFields []string
+	highlightOpen   string
+	highlightClose  string
+	params          []any
+	noContent       bool
+	withScores      bool
+}
+
+// SearchAggregate builds the pipeline of the redis FT.AGGREGATE command, executed by Template.FTAggregate, the
+// steps are executed in the order they were added, ex:
+//
+//	NewSearchAggregate(NewSearchQuery().Tag("tags", "usb")).GroupBy("brand").
+//		Reduce(option.SearchReducerCount, "", "total").SortBy("total", option.SortOrderDesc).Limit(0, 5)
+type SearchAggregate struct {
+	query *SearchQuery
+	loads []string
+	steps []searchStep
+}
+
+// searchStep is a GROUPBY, SORTBY or LIMIT step of a SearchAggregate, the reducers are added to the GROUPBY.
+type searchStep struct {
+	name string
+	args []any
+	more []any
+}
+
+// NewSearchQuery creates a new SearchQuery instance.
+func NewSearchQuery() *SearchQuery {
+	return &SearchQuery{}
+}
+
+// Text adds the filter of the documents with all the terms of the text in any text field, the punctuation of the
+// terms is escaped.
+func (q *SearchQuery) Text(text string) *SearchQuery {
+	return q.Raw(escapeSearchTerms(text))
+}
+
+// TextIn adds the filter of the documents with all the terms of the text in the text field.
+func (q *SearchQuery) TextIn(field, text string) *SearchQuery {
+	return q.Raw("@" + field + ":(" + escapeSearchTerms(text) + ")")
+}
+
+// Tag adds the filter of the documents with any of the values in the tag field.
+func (q *SearchQuery) Tag(field string, values ...string) *SearchQuery {
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = escapeSearch(value, true)
+	}
+	return q.Raw("@" + field + ":{" + strings.Join(escaped, " | ") + "}")
+}
+
+// Range adds the filter of the documents with the numeric field between min and max, inclusive, use math.Inf for
+// open ranges.
+func (q *SearchQuery) Range(field string, min, max float64) *SearchQuery {
+	return q.Raw("@" + field + ":[" + formatNumber(min) + " " + formatNumber(max) + "]")
+}
+
+// GeoRadius adds the filter of the documents with the geo field in the radius of the position.
+func (q *SearchQuery) GeoRadius(field string, longitude, latitude, radius float64, unit option.GeoUnit) *SearchQuery {
+	return q.Raw("@" + field + ":[" + formatNumber(longitude) + " " + formatNumber(latitude) + " " +
+		formatNumber(radius) + " " + unit.String() + "]")
+}
+
+// Raw adds the filter written in the RediSearch query syntax, ex: "-@status:{archived}".
+func (q *SearchQuery) Raw(expression string) *SearchQuery {
+	if helper.IsNotEmpty(strings.TrimSpace(expression)) {
+		q.filters = append(q.filters, expression)
+	}
+	return q
+}
+
+// SortBy sorts the results by the sortable field, instead of the score.
+func (q *SearchQuery) SortBy(field string, order option.SortOrder) *SearchQuery {
+	q.sortBy = field
+	q.sortOrder = order
+	return q
+}
+
+// Limit returns num results from the offset, redis returns 10 results by default.
+func (q *SearchQuery) Limit(offset, num int) *SearchQuery {
+	q.offset = offset
+	q.num = num
+	q.limit = true
+	return q
+}
+
+// Return returns only the fields of the documents, instead of the entire hash or JSON document.
+func (q *SearchQuery) Return(fields ...string) *SearchQuery {
+	q.returns = append(q.returns, fields...)
+	return q
+}
+
+// Highlight wraps the terms found in the text fields with the open and close tags, empty means the redis default
+// (<b> and </b>), and no fields means all the text fields returned.
+func (q *SearchQuery) Highlight(open, close string, fields ...string) *SearchQuery {
+	q.highlight = true
+	q.highlightOpen = open
+	q.highlightClose = close
+	q.highlightFields = fields
+	return q
+}
+
+// Param adds the parameter referenced by the query as $name, ex: the vector of a KNN query.
+func (q *SearchQuery) Param(name string, value any) *SearchQuery {
+	q.params = append(q.params, name, value)
+	return q
+}
+
+// NoContent returns only the keys of the documents.
+func (q *SearchQuery) NoContent() *SearchQuery {
+	q.noContent = true
+	return q
+}
+
+// WithScores returns the scores of the documents, filled in the fields with the search tag "@score".
+func (q *SearchQuery) WithScores() *SearchQuery {
+	q.withScores = true
+	return q
+}
+
+// String returns the query expression, "*" when there is no filter.
+func (q *SearchQuery) String() string {
+	if helper.IsNil(q) || helper.IsEmpty(q.filters) {
+		return "*"
+	} else if len(q.filters) == 1 {
+		return q.filters[0]
+	}
+	filters := make([]string, len(q.filters))
+	for i, filter := range q.filters {
+		filters[i] = "(" + filter + ")"
+	}
+	return strings.Join(filters, " ")
+}
+
+// args returns the arguments of the FT.SEARCH command of the query.
+func (q *SearchQuery) args(index string) []any {
+	args := []any{"FT.SEARCH", index, q.String()}
+	if q.noContent {
+		args = append(args, "NOCONTENT")
+	}
+	if q.withScores {
+		args = append(args, "WITHSCORES")
+	}
+	if helper.IsNotEmpty(q.returns) {
+		args = append(args, "RETURN", len(q.returns))
+		for _, field := range q.returns {
+			args = append(args, field)
+		}
+	}
+	if q.highlight {
+		args = append(args, "HIGHLIGHT")
+		if helper.IsNotEmpty(q.highlightFields) {
+			args = append(args, "FIELDS", len(q.highlightFields))
+			for _, field := range q.highlightFields {
+				args = append(args, field)
+			}
+		}
+		if helper.IsNotEmpty(q.highlightOpen) || helper.IsNotEmpty(q.highlightClose) {
+			args = append(args, "TAGS", q.highlightOpen, q.highlightClose)
+		}
+	}
+	if helper.IsNotEmpty(q.sortBy) {
+		args = append(args, "SORTBY", q.sortBy)
+		if helper.IsNotEmpty(q.sortOrder) {
+			args = append(args, q.sortOrder.String())
+		}
+	}
+	if q.limit {
+		args = append(args, "LIMIT", q.offset, q.num)
+	}
+	return append(args, q.paramsArgs()...)
+}
+
+func (q *SearchQuery) paramsArgs() []any {
+	if helper.IsNil(q) || helper.IsEmpty(q.params) {
+		return nil
+	}
+	args := append([]any{"PARAMS", len(q.params)}, q.params...)
+	return append(args, "DIALECT", 2)
+}
+
+// NewSearchAggregate creates a new SearchAggregate instance with the documents of the query, nil means all the
+// documents, only the filters and the params of the query are used.
+func NewSearchAggregate(query *SearchQuery) *SearchAggregate {
+	return &SearchAggregate{query: query}
+}
+
+// Load loads the fields of the documents, to be used by the steps and returned, the fields grouped and reduced are
+// returned without being loaded.
+func (a *SearchAggregate) Load(fields ...string) *SearchAggregate {
+	a.loads = append(a.loads, fields...)
+	return a
+}
+
+// GroupBy adds the step that groups the rows by the fields, reduced by the Reduce that follow it.
+func (a *SearchAggregate) GroupBy(fields ...string) *SearchAggregate {
+	args := []any{len(fields)}
+	for _, field := range fields {
+		args = append(args, searchProperty(field))
+	}
+	a.steps = append(a.steps, searchStep{name: "GROUPBY", args: args})
+	return a
+}
+
+// Reduce adds the reducer of the field to the last GroupBy, returned as the field "as", empty field for
+// option.SearchReducerCount. Without GroupBy, all the rows are reduced to one.
+func (a *SearchAggregate) Reduce(reducer option.SearchReducer, field, as string) *SearchAggregate {
+	if helper.IsEmpty(a.steps) || helper.IsNotEqualTo(a.steps[len(a.steps)-1].name, "GROUPBY") {
+		a.GroupBy()
+	}
+	step := &a.steps[len(a.steps)-1]
+	step.more = append(step.more, "REDUCE", reducer.String())
+	if helper.IsEmpty(field) {
+		step.more = append(step.more, 0)
+	} else {
+		step.more = append(step.more, 1, searchProperty(field))
+	}
+	if helper.IsNotEmpty(as) {
+		step.more = append(step.more, "AS", as)
+	}
+	return a
+}
+
+// SortBy adds the step that sorts the rows by the field, consecutive calls sort by multiple fields.
+func (a *SearchAggregate) SortBy(field string, order option.SortOrder) *SearchAggregate {
+	if helper.IsEmpty(a.steps) || helper.IsNotEqualTo(a.steps[len(a.steps)-1].name, "SORTBY") {
+		a.steps = append(a.steps, searchStep{name: "SORTBY"})
+	}
+	step := &a.steps[len(a.steps)-1]
+	step.more = append(step.more, searchProperty(field))
+	if helper.IsNotEmpty(order) {
+		step.more = append(step.more, order.String())
+	}
+	step.args = []any{len(step.more)}
+	return a
+}
+
+// Limit adds the step that keeps num rows from the offset.
+func (a *SearchAggregate) Limit(offset, num int) *SearchAggregate {
+	a.steps = append(a.steps, searchStep{name: "LIMIT", args: []any{offset, num}})
+	return a
+}
+
+// args returns the arguments of the FT.AGGREGATE command of the pipeline.
+func (a *SearchAggregate) args(index string) []any {
+	args := []any{"FT.AGGREGATE", index, a.query.String()}
+	if helper.IsNotEmpty(a.loads) {
+		args = append(args, "LOAD", len(a.loads))
+		for _, field := range a.loads {
+			args = append(args, searchProperty(field))
+		}
+	}
+	for _, step := range a.steps {
+		args = append(args, step.name)
+		args = append(args, step.args...)
+		args = append(args, step.more...)
+	}
+	return append(args, a.query.paramsArgs()...)
+}
+
+// searchProperty returns the field as a property of the aggregation, ex: "@price".
+func searchProperty(field string) string {
+	if strings.HasPrefix(field, "@") {
+		return field
+	}
+	return "@" + field
+}
+
+// escapeSearchTerms escapes the punctuation of each term of the text.
+func escapeSearchTerms(text string) string {
+	terms := strings.Fields(text)
+	for i, term := range terms {
+		terms[i] = escapeSearch(term, false)
+	}
+	return strings.Join(terms, " ")
+}
+
+// escapeSearch escapes the characters that are not letters, digits or underscore, and the spaces of the tags.
+func escapeSearch(value string, spaces bool) string {
+	var builder strings.Builder
+	for _, r := range value {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && (spaces || !unicode.IsSpace(r)) {
+			builder.WriteRune('\\')
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
@@ -0,0 +1,216 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"github.com/GabrielHCataldo/go-redis-template/redis/redistest"
+	redisdriver "github.com/redis/go-redis/v9"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type searchProduct struct {
+	_     struct{} `search:"idx:product,on=json,prefix=product:"`
+	Key   string   `json:"-" search:"@id"`
+	Name  string   `json:"name" search:",text,sortable,weight=2"`
+	Brand string   `json:"brand" search:",tag,sortable"`
+	Tags  []string `json:"tags" search:",tag"`
+	Price float64  `json:"price" search:",numeric,sortable"`
+	Store string   `json:"store" search:"location,geo"`
+}
+
+type searchStore struct {
+	_     struct{} `search:"idx:store,prefix=store:"`
+	Key   string   `search:"@id"`
+	Score float64  `search:"@score"`
+	Name  string   `json:"name" search:",text"`
+	Tags  []string `json:"tags" search:",tag,separator=;"`
+	Rank  int      `json:"rank" search:",numeric,sortable"`
+}
+
+type brandSummary struct {
+	Brand string  `json:"brand"`
+	Count int     `json:"count"`
+	Total float64 `json:"total"`
+}
+
+func initSearchProducts(t *testing.T, redisTemplate *redis.Template) {
+	ctx := context.TODO()
+	if err := redisTemplate.FTCreate(ctx, searchProduct{}); helper.IsNotNil(err) {
+		logger.Errorf("FTCreate() err = %v", err)
+		t.Fail()
+	}
+	products := map[string]searchProduct{
+		"product:1": {Name: "Wireless keyboard", Brand: "acme", Tags: []string{"usb", "bluetooth"}, Price: 49.9,
+			Store: "-46.6333,-23.5505"},
+		"product:2": {Name: "Mechanical keyboard", Brand: "acme", Tags: []string{"usb"}, Price: 89.9,
+			Store: "-43.1729,-22.9068"},
+		"product:3": {Name: "Wireless mouse", Brand: "globex", Tags: []string{"bluetooth"}, Price: 19.9,
+			Store: "-46.6333,-23.5505"},
+	}
+	for key, product := range products {
+		_, _ = redisTemplate.JSONSet(ctx, key, "", product)
+	}
+}
+
+func TestTemplateFTSearch(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	initSearchProducts(t, redisTemplate)
+	var products []searchProduct
+	total, err := redisTemplate.FTSearch(ctx, "idx:product", redis.NewSearchQuery().Text("keyboard").
+		SortBy("price", option.SortOrderAsc), &products)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(total, int64(2)) || helper.IsNotEqualTo(len(products), 2) ||
+		helper.IsNotEqualTo(products[0].Key, "product:1") || helper.IsNotEqualTo(products[1].Price, 89.9) ||
+		!reflect.DeepEqual(products[0].Tags, []string{"usb", "bluetooth"}) {
+		logger.Errorf("FTSearch() total = %v result = %v err = %v", total, products, err)
+		t.Fail()
+	}
+	total, err = redisTemplate.FTSearch(ctx, "idx:product", redis.NewSearchQuery().Tag("tags", "bluetooth").
+		Range("price", math.Inf(-1), 30), &products)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(total, int64(1)) || helper.IsNotEqualTo(products[0].Name,
+		"Wireless mouse") {
+		logger.Errorf("FTSearch() tag total = %v result = %v err = %v", total, products, err)
+		t.Fail()
+	}
+	var keys []string
+	total, err = redisTemplate.FTSearch(ctx, "idx:product", redis.NewSearchQuery().
+		GeoRadius("location", -46.63, -23.55, 10, option.GeoUnitKilometers).SortBy("price", option.SortOrderDesc).
+		NoContent(), &keys)
+	if helper.IsNotNil(err) || !reflect.DeepEqual(keys, []string{"product:1", "product:3"}) {
+		logger.Errorf("FTSearch() geo total = %v result = %v err = %v", total, keys, err)
+		t.Fail()
+	}
+	total, err = redisTemplate.FTSearch(ctx, "idx:product", redis.NewSearchQuery().Text("wireless").
+		Return("name", "price").Highlight("[", "]", "name").SortBy("price", option.SortOrderAsc).Limit(1, 1),
+		&products)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(total, int64(2)) || helper.IsNotEqualTo(len(products), 1) ||
+		helper.IsNotEqualTo(products[0].Name, "[Wireless] keyboard") || helper.IsNotEqualTo(products[0].Price, 49.9) ||
+		helper.IsNotEmpty(products[0].Brand) {
+		logger.Errorf("FTSearch() return total = %v result = %v err = %v", total, products, err)
+		t.Fail()
+	}
+	var all []map[string]string
+	total, err = redisTemplate.FTSearch(ctx, "idx:product", redis.NewSearchQuery().Raw("-@brand:{acme}"), &all)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(total, int64(1)) || !strings.Contains(all[0]["$"], "globex") {
+		logger.Errorf("FTSearch() raw total = %v result = %v err = %v", total, all, err)
+		t.Fail()
+	}
+}
+
+func TestTemplateFTSearchHash(t *testing.T) {
+	server := redistest.NewServer(t)
+	opts := server.ClientOptions()
+	opts.Protocol = 2
+	redisTemplate := redis.NewTemplate(opts)
+	defer redisTemplate.SimpleDisconnect()
+	client := redisdriver.NewClient(opts.ParseToRedisOptions())
+	defer client.Close()
+	ctx := context.TODO()
+	if err := redisTemplate.FTCreate(ctx, &searchStore{}); helper.IsNotNil(err) {
+		logger.Errorf("FTCreate() err = %v", err)
+		t.Fail()
+	}
+	client.HSet(ctx, "store:1", "name", "Downtown store", "tags", "open;parking", "rank", 2)
+	client.HSet(ctx, "store:2", "name", "Airport store", "tags", "open", "rank", 1)
+	client.HSet(ctx, "other:1", "name", "Downtown store", "tags", "open", "rank", 3)
+	var stores []*searchStore
+	total, err := redisTemplate.FTSearch(ctx, "idx:store", redis.NewSearchQuery().Tag("tags", "open").
+		WithScores().SortBy("rank", option.SortOrderAsc), &stores)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(total, int64(2)) || helper.IsNotEqualTo(stores[0].Key, "store:2") ||
+		helper.IsNotEqualTo(stores[1].Rank, 2) || !reflect.DeepEqual(stores[1].Tags, []string{"open", "parking"}) {
+		logger.Errorf("FTSearch() total = %v result = %v err = %v", total, stores, err)
+		t.Fail()
+	}
+	total, err = redisTemplate.FTSearch(ctx, "idx:store", redis.NewSearchQuery().TextIn("name", "downtown").WithScores(),
+		&stores)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(total, int64(1)) || stores[0].Score <= 0 {
+		logger.Errorf("FTSearch() text total = %v result = %v err = %v", total, stores, err)
+		t.Fail()
+	}
+}
+
+func TestTemplateFTAggregate(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	initSearchProducts(t, redisTemplate)
+	var summaries []brandSummary
+	err := redisTemplate.FTAggregate(ctx, "idx:product", redis.NewSearchAggregate(nil).GroupBy("brand").
+		Reduce(option.SearchReducerCount, "", "count").Reduce(option.SearchReducerSum, "price", "total").
+		SortBy("count", option.SortOrderDesc), &summaries)
+	if helper.IsNotNil(err) || !reflect.DeepEqual(summaries, []brandSummary{
+		{Brand: "acme", Count: 2, Total: 139.8},
+		{Brand: "globex", Count: 1, Total: 19.9},
+	}) {
+		logger.Errorf("FTAggregate() result = %v err = %v", summaries, err)
+		t.Fail()
+	}
+	var rows []map[string]any
+	err = redisTemplate.FTAggregate(ctx, "idx:product", redis.NewSearchAggregate(redis.NewSearchQuery().
+		Tag("tags", "usb")).Load("name", "price").SortBy("price", option.SortOrderDesc).Limit(0, 1), &rows)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(len(rows), 1) || helper.IsNotEqualTo(rows[0]["name"],
+		"Mechanical keyboard") {
+		logger.Errorf("FTAggregate() load result = %v err = %v", rows, err)
+		t.Fail()
+	}
+}
+
+func TestTemplateFTErrors(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	initSearchProducts(t, redisTemplate)
+	err := redisTemplate.FTCreate(ctx, searchProduct{})
+	if !errors.Is(err, redis.ErrIndexAlreadyExists) {
+		logger.Errorf("FTCreate() err = %v, want = %v", err, redis.ErrIndexAlreadyExists)
+		t.Fail()
+	}
+	err = redisTemplate.FTCreate(ctx, struct {
+		Name string `search:",unknown"`
+	}{})
+	if !errors.Is(err, redis.ErrInvalidSearchTag) {
+		logger.Errorf("FTCreate() err = %v, want = %v", err, redis.ErrInvalidSearchTag)
+		t.Fail()
+	}
+	err = redisTemplate.FTDropIndex(ctx, "idx:product", option.NewFTDropIndex().SetDeleteDocs(true))
+	exists, _ := redisTemplate.Exists(ctx, "product:1")
+	if helper.IsNotNil(err) || exists {
+		logger.Errorf("FTDropIndex() exists = %v err = %v", exists, err)
+		t.Fail()
+	}
+	var products []searchProduct
+	_, err = redisTemplate.FTSearch(ctx, "idx:product", nil, &products)
+	if !errors.Is(err, redis.ErrIndexNotFound) {
+		logger.Errorf("FTSearch() err = %v, want = %v", err, redis.ErrIndexNotFound)
+		t.Fail()
+	}
+}
+
+func TestParseSearchIndex(t *testing.T) {
+	index, err := redis.ParseSearchIndex(searchProduct{})
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(index.On, option.SearchOnJSON) ||
+		helper.IsNotEqualTo(len(index.Fields), 5) || helper.IsNotEqualTo(index.Fields[2].Identifier, "$.tags[*]") ||
+		helper.IsNotEqualTo(index.Fields[4].Name, "location") || helper.IsNotEqualTo(index.Fields[0].Weight, 2.0) {
+		logger.Errorf("ParseSearchIndex() result = %v err = %v", index, err)
+		t.Fail()
+	}
+	_, err = redis.ParseSearchIndex(struct {
+		_      struct{}  `search:"idx:vector"`
+		Vector []float32 `search:",vector,algorithm=hnsw"`
+	}{})
+	if !errors.Is(err, redis.ErrInvalidSearchTag) {
+		logger.Errorf("ParseSearchIndex() err = %v, want = %v", err, redis.ErrInvalidSearchTag)
+		t.Fail()
+	}
+}
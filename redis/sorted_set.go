@@ -0,0 +1,110 @@
+package redis
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/redis/go-redis/v9"
+)
+
+// ZMember is a member of a sorted set with its score, added by ZAdd.
+type ZMember struct {
+	// Member can be of any type, but cannot be null, and must be compatible with conversion to string
+	// (helper.ConvertToString).
+	Member any
+	// Score of the member, the members are ordered by the score ascending, and by the member for equal scores.
+	Score float64
+}
+
+// ZAdd redis `ZADD key score member [score member ...]` command, adds the members with their scores to the sorted
+// set of the key, or updates their scores, returning the number of members added.
+//
+// The key parameter can be of any type, but cannot be null, in case an error occurs when converting, the error
+// returned is ErrConvertKey. The members are converted like the members of PFAdd, without compression and
+// encryption, since ZRem finds them by their exact value (see option.Client.Encryption), if an error occurs when
+// converting, the error returned is ErrConvertValue.
+func (t *Template) ZAdd(ctx context.Context, key any, members ...ZMember) (int64, error) {
+	var added int64
+	values := make([]any, len(members))
+	for i, member := range members {
+		values[i] = member.Member
+	}
+	op := &Operation{Name: "ZAdd", Keys: []any{key}, Value: members, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, sMembers, err := convertKeyAndMembers(op, key, values)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		zMembers := make([]redis.Z, len(sMembers))
+		for i, sMember := range sMembers {
+			zMembers[i] = redis.Z{Score: members[i].Score, Member: sMember}
+		}
+		added, err = t.client.ZAdd(ctx, sKey, zMembers...).Result()
+		return err
+	})
+	return added, err
+}
+
+// ZRem redis `ZREM key member [member ...]` command, removes the members from the sorted set of the key, returning
+// the number of members removed.
+//
+// The key and members parameters follow the ZAdd documentation.
+func (t *Template) ZRem(ctx context.Context, key any, members ...any) (int64, error) {
+	var removed int64
+	op := &Operation{Name: "ZRem", Keys: []any{key}, Value: members, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, sMembers, err := convertKeyAndMembers(op, key, members)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		removed, err = t.client.ZRem(ctx, sKey, sMembers...).Result()
+		return err
+	})
+	return removed, err
+}
+
+// ZRangeByScore redis `ZRANGEBYSCORE key min max [LIMIT offset count]` command, returns the members of the sorted
+// set of the key with the scores between minScore and maxScore, inclusive, ordered by the score ascending. Use
+// math.Inf for unbounded ranges, and a zero count to return all the members after the offset. If the key does not
+// exist, the return is empty.
+//
+// The key parameter follows the ZAdd documentation.
+func (t *Template) ZRangeByScore(ctx context.Context, key any, minScore, maxScore float64, offset, count int64) (
+	[]string, error) {
+	var members []string
+	op := &Operation{Name: "ZRangeByScore", Keys: []any{key}, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		if offset > 0 && count <= 0 {
+			count = -1
+		}
+		members, err = t.client.ZRangeByScore(ctx, sKey, &redis.ZRangeBy{
+			Min:    formatNumber(minScore),
+			Max:    formatNumber(maxScore),
+			Offset: offset,
+			Count:  count,
+		}).Result()
+		return err
+	})
+	return members, err
+}
+
+// ZCard redis `ZCARD key` command, returns the number of members of the sorted set of the key, zero if the key does
+// not exist.
+//
+// The key parameter follows the ZAdd documentation.
+func (t *Template) ZCard(ctx context.Context, key any) (int64, error) {
+	var card int64
+	op := &Operation{Name: "ZCard", Keys: []any{key}, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		card, err = t.client.ZCard(ctx, sKey).Result()
+		return err
+	})
+	return card, err
+}
@@ -0,0 +1,46 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"github.com/GabrielHCataldo/go-redis-template/redis/redistest"
+	"math"
+	"testing"
+)
+
+func TestTemplateSortedSet(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	added, err := redisTemplate.ZAdd(ctx, "ranking", redis.ZMember{Member: "a", Score: 3},
+		redis.ZMember{Member: "b", Score: 1}, redis.ZMember{Member: 7, Score: 2})
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(added, int64(3)) {
+		logger.Errorf("ZAdd() result = %v err = %v", added, err)
+		t.Fail()
+	}
+	members, err := redisTemplate.ZRangeByScore(ctx, "ranking", math.Inf(-1), math.Inf(1), 0, 0)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(members, []string{"b", "7", "a"}) {
+		logger.Errorf("ZRangeByScore() result = %v err = %v", members, err)
+		t.Fail()
+	}
+	members, err = redisTemplate.ZRangeByScore(ctx, "ranking", 2, math.Inf(1), 1, 1)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(members, []string{"a"}) {
+		logger.Errorf("ZRangeByScore() limit result = %v err = %v", members, err)
+		t.Fail()
+	}
+	removed, err := redisTemplate.ZRem(ctx, "ranking", "a", "missing")
+	count, _ := redisTemplate.ZCard(ctx, "ranking")
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(removed, int64(1)) || helper.IsNotEqualTo(count, int64(2)) {
+		logger.Errorf("ZRem() result = %v count = %v err = %v", removed, count, err)
+		t.Fail()
+	}
+	_, err = redisTemplate.ZAdd(ctx, "ranking", redis.ZMember{Score: 1})
+	if !errors.Is(err, redis.ErrConvertValue) {
+		logger.Errorf("ZAdd() err = %v, want = %v", err, redis.ErrConvertValue)
+		t.Fail()
+	}
+}
@@ -8,7 +8,12 @@ import (
 	"github.com/GabrielHCataldo/go-logger/logger"
 	"github.com/GabrielHCataldo/go-redis-template/redis/option"
 	"github.com/redis/go-redis/v9"
+	"math"
+	"reflect"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -31,16 +36,62 @@ type ScanOutput struct {
 	Page   []string
 }
 
+// PoolStats contains the connection pool stats of the template.
+type PoolStats struct {
+	// Hits number of times a free connection was found in the pool.
+	Hits uint32
+	// Misses number of times a free connection was not found in the pool.
+	Misses uint32
+	// Timeouts number of times a wait timeout occurred.
+	Timeouts uint32
+	// TotalConns number of total connections in the pool.
+	TotalConns uint32
+	// IdleConns number of idle connections in the pool.
+	IdleConns uint32
+	// StaleConns number of stale connections removed from the pool.
+	StaleConns uint32
+}
+
 type Template struct {
-	client *redis.Client
+	opts                 option.Client
+	client               *redis.Client
+	hooks                atomic.Pointer[[]Hook]
+	hooksMutex           sync.Mutex
+	compression          option.Compression
+	compressionThreshold int
+	keyring              *keyring
+	retry                *option.RetryPolicy
+	serverVersion        serverVersion
+	schemas              map[reflect.Type]*Schema
+	schemasMutex         sync.RWMutex
+	jsonCodec            option.JSONCodec
 }
 
 // NewTemplate create a new template instance
 func NewTemplate(opts option.Client) *Template {
 	client := redis.NewClient(opts.ParseToRedisOptions())
-	return &Template{
-		client: client,
+	t := &Template{
+		opts:                 opts,
+		client:               client,
+		compression:          opts.Compression,
+		compressionThreshold: helper.IfEmptyReturns(opts.CompressionThreshold, 1024),
+		keyring:              newKeyring(opts.Encryption),
+		retry:                opts.RetryPolicy,
+		jsonCodec:            newJSONCodec(opts.JSONCodec),
+	}
+	client.AddHook(redisHook{template: t})
+	return t
+}
+
+// Select creates a new template with the same settings, hooks and schemas of the template, connected to another
+// database number of the same redis, the new template has its own connection pool, and must be disconnected
+// separately.
+func (t *Template) Select(db int) *Template {
+	clone := t.selectDB(db)
+	for _, hook := range t.loadHooks() {
+		clone.AddHook(hook)
 	}
+	return clone
 }
 
 // Set supports all options that the SET command supports.
@@ -48,15 +99,21 @@ func NewTemplate(opts option.Client) *Template {
 // The key and value parameters can be of any type, but cannot be nil, if an error occurs when converting the key
 // or value, the error returned is ErrConvertKey or ErrConvertValue respectively.
 //
+// The options are validated before the command is sent, the conflicting options return the errors of
+// option.Set.Validate, and the options not supported by the server version return ErrUnsupportedOption.
+//
 // If the return is nil, the operation was carried out successfully, otherwise an error occurred in the operation.
 //
 // To customize the operation, use the opts parameter (option.Set).
 func (t *Template) Set(ctx context.Context, key, value any, opts ...*option.Set) error {
-	result, err := t.set(ctx, key, value, false, opts...)
-	if helper.IsNil(err) {
-		err = result.Err()
-	}
-	return err
+	op := &Operation{Name: "Set", Keys: []any{key}, Value: value, Idempotent: isIdempotentSet(opts...)}
+	return t.process(ctx, op, func(ctx context.Context) error {
+		result, err := t.set(ctx, op, key, value, false, opts...)
+		if helper.IsNil(err) {
+			err = result.Err()
+		}
+		return err
+	})
 }
 
 // MSet defines N values. (Multiple Set)
@@ -68,15 +125,35 @@ func (t *Template) Set(ctx context.Context, key, value any, opts ...*option.Set)
 // it failed .
 func (t *Template) MSet(ctx context.Context, values ...MSetInput) []MSetOutput {
 	var output []MSetOutput
+	op := &Operation{Name: "MSet", Idempotent: true}
 	for _, v := range values {
-		result, err := t.set(ctx, v.Key, v.Value, false, v.Opt)
-		if helper.IsNil(err) {
-			err = result.Err()
+		op.Keys = append(op.Keys, v.Key)
+		op.Idempotent = op.Idempotent && isIdempotentSet(v.Opt)
+	}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		output = nil
+		var errs []error
+		for _, v := range values {
+			result, err := t.set(ctx, op, v.Key, v.Value, false, v.Opt)
+			if helper.IsNil(err) {
+				err = result.Err()
+			}
+			err = NewOpError(op.Name, v.Key, err)
+			output = append(output, MSetOutput{
+				Key: v.Key,
+				Err: err,
+			})
+			errs = append(errs, err)
+		}
+		return errors.Join(errs...)
+	})
+	if helper.IsNil(output) && helper.IsNotNil(err) {
+		for _, v := range values {
+			output = append(output, MSetOutput{
+				Key: v.Key,
+				Err: err,
+			})
 		}
-		output = append(output, MSetOutput{
-			Key: v.Key,
-			Err: err,
-		})
 	}
 	return output
 }
@@ -89,17 +166,31 @@ func (t *Template) MSet(ctx context.Context, values ...MSetInput) []MSetOutput {
 // The dest parameter must be a pointer, not null, if we do not find a predecessor value to the set, dest will not
 // have any modification
 //
+// The options are validated as in Set, and the GET option requires redis >= 6.2, or redis >= 7.0 with SetModeNx,
+// otherwise ErrUnsupportedOption is returned.
+//
 // If the return is null, the operation was performed successfully, otherwise an error occurred in the operation.
 //
 // To customize the operation, use the opts parameter (option.Set).
 func (t *Template) SetGet(ctx context.Context, key, value, dest any, opts ...*option.Set) error {
-	result, err := t.set(ctx, key, value, true, opts...)
-	if helper.IsNotNil(err) {
-		return err
-	} else if helper.IsNotNil(result.Err()) {
-		return result.Err()
-	}
-	return helper.ConvertToDest(result.Val(), dest)
+	op := &Operation{Name: "SetGet", Keys: []any{key}, Value: value}
+	return t.process(ctx, op, func(ctx context.Context) error {
+		result, err := t.set(ctx, op, key, value, true, opts...)
+		if helper.IsNotNil(err) {
+			return err
+		} else if helper.IsNotNil(result.Err()) {
+			return result.Err()
+		}
+		old, err := t.decodeValue(helper.SimpleConvertToString(key), result.Val())
+		if helper.IsNotNil(err) {
+			return err
+		}
+		old, _, err = t.upgradeValue(dest, old)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		return helper.ConvertToDest(old, dest)
+	})
 }
 
 // Rename redis key.
@@ -109,15 +200,18 @@ func (t *Template) SetGet(ctx context.Context, key, value, dest any, opts ...*op
 //
 // If the return is null, the operation was performed successfully, otherwise an error occurred in the operation.
 func (t *Template) Rename(ctx context.Context, key, newKey any) error {
-	sKey, err := helper.ConvertToString(key)
-	if helper.IsNotNil(err) {
-		return ErrConvertKey
-	}
-	sNewKey, err := helper.ConvertToString(newKey)
-	if helper.IsNotNil(err) {
-		return ErrConvertNewKey
-	}
-	return t.client.Rename(ctx, sKey, sNewKey).Err()
+	op := &Operation{Name: "Rename", Keys: []any{key, newKey}}
+	return t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		sNewKey, err := helper.ConvertToString(newKey)
+		if helper.IsNotNil(err) {
+			return ErrConvertNewKey
+		}
+		return t.client.Rename(ctx, sKey, sNewKey).Err()
+	})
 }
 
 // Get redis `GET key` command.
@@ -125,22 +219,44 @@ func (t *Template) Rename(ctx context.Context, key, newKey any) error {
 // The key parameter can be of any type, but cannot be null, in case an error occurs when converting, the error
 // returned is ErrConvertKey. If no registered key is found, the error ErrKeyNotFound is returned.
 //
-// The dest parameter must be a pointer.
+// The dest parameter must be a pointer. If the schema of the dest type is registered (Template.RegisterSchema), the
+// value is upgraded to the current version, and written back keeping its TTL if Schema.WriteBack is true.
 //
 // If the return is null, the operation was performed successfully, otherwise an error occurred in the operation.
 func (t *Template) Get(ctx context.Context, key, dest any) error {
-	if !helper.IsPointerType(dest) {
-		return ErrDestIsNotPointer
-	}
-	sKey, err := helper.ConvertToString(key)
-	if helper.IsNotNil(err) {
-		return ErrConvertKey
-	}
-	result, err := t.client.Get(ctx, sKey).Result()
-	if errors.Is(err, redis.Nil) {
-		return ErrKeyNotFound
+	var sKey, raw, payload string
+	var upgraded bool
+	op := &Operation{Name: "Get", Keys: []any{key}, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		if !helper.IsPointerType(dest) {
+			return ErrDestIsNotPointer
+		}
+		var err error
+		sKey, err = helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		raw, err = t.client.Get(ctx, sKey).Result()
+		if errors.Is(err, redis.Nil) {
+			return ErrKeyNotFound
+		} else if helper.IsNotNil(err) {
+			return err
+		}
+		op.ValueSize = len(raw)
+		result, err := t.decodeValue(sKey, raw)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		payload, upgraded, err = t.upgradeValue(dest, result)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		return helper.ConvertToDest(payload, dest)
+	})
+	if helper.IsNil(err) && upgraded {
+		t.writeBackValue(ctx, sKey, raw, payload, dest)
 	}
-	return helper.ConvertToDest(result, dest)
+	return err
 }
 
 // GetDel get and delete value by key.
@@ -152,19 +268,34 @@ func (t *Template) Get(ctx context.Context, key, dest any) error {
 //
 // If the return is null, the operation was performed successfully, otherwise an error occurred in the operation.
 func (t *Template) GetDel(ctx context.Context, key, dest any) error {
-	sKey, err := helper.ConvertToString(key)
-	if helper.IsNotNil(err) {
-		return ErrConvertKey
-	}
-	result := t.client.GetDel(ctx, sKey)
-	if helper.IsNotNil(result.Err()) {
-		err = result.Err()
-		if errors.Is(result.Err(), redis.Nil) {
-			err = ErrKeyNotFound
+	op := &Operation{Name: "GetDel", Keys: []any{key}}
+	return t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
 		}
-		return err
-	}
-	return helper.ConvertToDest(result.Val(), dest)
+		if err = t.checkCapability(ctx, capabilityGetDel); helper.IsNotNil(err) {
+			return err
+		}
+		result := t.client.GetDel(ctx, sKey)
+		if helper.IsNotNil(result.Err()) {
+			err = result.Err()
+			if errors.Is(result.Err(), redis.Nil) {
+				err = ErrKeyNotFound
+			}
+			return err
+		}
+		op.ValueSize = len(result.Val())
+		value, err := t.decodeValue(sKey, result.Val())
+		if helper.IsNotNil(err) {
+			return err
+		}
+		value, _, err = t.upgradeValue(dest, value)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		return helper.ConvertToDest(value, dest)
+	})
 }
 
 // Exists redis values by key.
@@ -175,27 +306,46 @@ func (t *Template) GetDel(ctx context.Context, key, dest any) error {
 // The return if true means that the key exists, otherwise it returns false, and if an error occurs in the operation
 // we return false with the second return parameter filled in
 func (t *Template) Exists(ctx context.Context, key any) (bool, error) {
-	sKey, err := helper.ConvertToString(key)
-	if helper.IsNotNil(err) {
-		return false, ErrConvertKey
-	}
-	result := t.client.Exists(ctx, sKey)
-	return helper.IsGreaterThan(result.Val(), 0), result.Err()
+	var exists bool
+	op := &Operation{Name: "Exists", Keys: []any{key}, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		result := t.client.Exists(ctx, sKey)
+		exists = helper.IsGreaterThan(result.Val(), 0)
+		return result.Err()
+	})
+	return exists, err
 }
 
 // Keys return list of keys by pattern.
 func (t *Template) Keys(ctx context.Context, pattern string) ([]string, error) {
-	return t.client.Keys(ctx, pattern).Result()
+	var keys []string
+	op := &Operation{Name: "Keys", Keys: []any{pattern}, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		var err error
+		keys, err = t.client.Keys(ctx, pattern).Result()
+		return err
+	})
+	return keys, err
 }
 
-// Scan return list keys pageable by match
-func (t *Template) Scan(ctx context.Context, cursor uint64, match string, count int64) ScanOutput {
-	result := t.client.Scan(ctx, cursor, match, count)
-	keys, c := result.Val()
-	return ScanOutput{
-		Cursor: c,
-		Page:   keys,
-	}
+// Scan return list keys pageable by match, if an error occurs in the operation, it is returned with an empty page.
+func (t *Template) Scan(ctx context.Context, cursor uint64, match string, count int64) (ScanOutput, error) {
+	var output ScanOutput
+	op := &Operation{Name: "Scan", Keys: []any{match}, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		result := t.client.Scan(ctx, cursor, match, count)
+		keys, c := result.Val()
+		output = ScanOutput{
+			Cursor: c,
+			Page:   keys,
+		}
+		return result.Err()
+	})
+	return output, err
 }
 
 // Del delete redis keys.
@@ -205,19 +355,23 @@ func (t *Template) Scan(ctx context.Context, cursor uint64, match string, count
 //
 // If the return is null, the operation was performed successfully, otherwise an error occurred in the operation.
 func (t *Template) Del(ctx context.Context, keys ...any) error {
-	var sKeys []string
-	for _, key := range keys {
-		sKey, err := helper.ConvertToString(key)
+	op := &Operation{Name: "Del", Keys: keys, Idempotent: true}
+	return t.process(ctx, op, func(ctx context.Context) error {
+		sKeys, err := convertKeys(keys)
 		if helper.IsNotNil(err) {
-			return ErrConvertKey
+			return err
 		}
-		sKeys = append(sKeys, sKey)
-	}
-	return t.client.Del(ctx, sKeys...).Err()
+		return t.client.Del(ctx, sKeys...).Err()
+	})
 }
 
 // SprintKey format values as prefix in string for a future redis key, ex: "test", "test2" -> "test:test2"
 func (t *Template) SprintKey(vs ...any) string {
+	return SprintKey(vs...)
+}
+
+// SprintKey format values as prefix in string for a future redis key, ex: "test", "test2" -> "test:test2"
+func SprintKey(vs ...any) string {
 	var builder strings.Builder
 	for _, v := range vs {
 		s, err := helper.ConvertToString(v)
@@ -232,6 +386,19 @@ func (t *Template) SprintKey(vs ...any) string {
 	return builder.String()
 }
 
+// PoolStats returns the connection pool stats of the underlying client.
+func (t *Template) PoolStats() PoolStats {
+	stats := t.client.PoolStats()
+	return PoolStats{
+		Hits:       stats.Hits,
+		Misses:     stats.Misses,
+		Timeouts:   stats.Timeouts,
+		TotalConns: stats.TotalConns,
+		IdleConns:  stats.IdleConns,
+		StaleConns: stats.StaleConns,
+	}
+}
+
 // Disconnect close connection to redis
 func (t *Template) Disconnect() error {
 	return t.client.Close()
@@ -249,20 +416,43 @@ func (t *Template) SimpleDisconnect() {
 
 func (t *Template) set(
 	ctx context.Context,
+	op *Operation,
 	key,
 	value any,
 	get bool,
 	opts ...*option.Set,
 ) (*redis.StatusCmd, error) {
+	if err := option.ValidateSetByParams(opts); helper.IsNotNil(err) {
+		return nil, err
+	}
 	opt := option.GetOptionSetByParams(opts)
 	sKey, err := helper.ConvertToString(key)
 	if helper.IsNotNil(err) {
 		return nil, ErrConvertKey
 	}
+	if helper.IfNilReturns(opt.KeepTTL, false) {
+		if err = t.checkCapability(ctx, capabilityKeepTTL); helper.IsNotNil(err) {
+			return nil, err
+		}
+	}
+	if get {
+		c := capabilityGet
+		if helper.IsNotNil(opt.Mode) && helper.Equals(*opt.Mode, option.SetModeNx) {
+			c = capabilityNxGet
+		}
+		if err = t.checkCapability(ctx, c); helper.IsNotNil(err) {
+			return nil, err
+		}
+	}
 	sValue, err := helper.ConvertToString(value)
 	if helper.IsNotNil(err) {
 		return nil, ErrConvertValue
 	}
+	sValue, err = t.encodeValue(sKey, t.versionValue(value, sValue), opt)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	op.ValueSize += len(sValue)
 	return t.client.SetArgs(ctx, sKey, sValue, redis.SetArgs{
 		Mode:     opt.Mode.String(),
 		TTL:      helper.IfNilReturns(opt.TTL, 0),
@@ -271,3 +461,78 @@ func (t *Template) set(
 		KeepTTL:  helper.IfNilReturns(opt.KeepTTL, false),
 	}), nil
 }
+
+// convertKeys converts the keys to string, returning ErrConvertKey if any of them fails.
+func convertKeys(keys []any) ([]string, error) {
+	var sKeys []string
+	for _, key := range keys {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return nil, ErrConvertKey
+		}
+		sKeys = append(sKeys, sKey)
+	}
+	return sKeys, nil
+}
+
+// convertMembers converts the members to string, returning ErrConvertValue if any of them fails, and adds their size
+// to the operation.
+func convertMembers(op *Operation, members []any) ([]any, error) {
+	var sMembers []any
+	for _, member := range members {
+		sMember, err := helper.ConvertToString(member)
+		if helper.IsNotNil(err) {
+			return nil, ErrConvertValue
+		}
+		op.ValueSize += len(sMember)
+		sMembers = append(sMembers, sMember)
+	}
+	return sMembers, nil
+}
+
+// convertKeyAndMembers converts the key and the members of the commands of the data structures, returning
+// ErrConvertKey, or ErrConvertValue if any member fails or none is informed.
+func convertKeyAndMembers(op *Operation, key any, members []any) (string, []any, error) {
+	sKey, err := helper.ConvertToString(key)
+	if helper.IsNotNil(err) {
+		return "", nil, ErrConvertKey
+	}
+	sMembers, err := convertMembers(op, members)
+	if helper.IsNotNil(err) {
+		return "", nil, err
+	} else if helper.IsEmpty(sMembers) {
+		return "", nil, ErrConvertValue
+	}
+	return sKey, sMembers, nil
+}
+
+// formatNumber formats the float as the redis arguments of scores and ranges, with +inf and -inf for the infinities.
+func formatNumber(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+inf"
+	} else if math.IsInf(f, -1) {
+		return "-inf"
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// isIdempotentSet returns false if the Set has the mode SetModeNx, since a retry after a successful attempt would
+// not write the value.
+func isIdempotentSet(opts ...*option.Set) bool {
+	return *option.GetOptionSetByParams(opts).Mode != option.SetModeNx
+}
+
+// selectDB creates a new template with the settings and schemas of the template connected to the database number,
+// without the hooks.
+func (t *Template) selectDB(db int) *Template {
+	opts := t.opts
+	opts.DB = db
+	clone := NewTemplate(opts)
+	t.schemasMutex.RLock()
+	defer t.schemasMutex.RUnlock()
+	clone.schemas = map[reflect.Type]*Schema{}
+	for typ, schema := range t.schemas {
+		clone.schemas[typ] = schema
+	}
+	return clone
+}
@@ -145,7 +145,12 @@ func TestTemplateScan(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
 			defer cancel()
-			result := redisTemplate.Scan(ctx, 0, tt.patten, 10)
+			result, err := redisTemplate.Scan(ctx, 0, tt.patten, 10)
+			if helper.IsNotNil(err) {
+				logger.Error("Scan() err =", err)
+				t.Fail()
+				return
+			}
 			logger.Infof("Scan() result = %v", result)
 		})
 	}
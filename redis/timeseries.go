@@ -0,0 +1,411 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TSSample is a sample of a RedisTimeSeries key, the timestamps have milliseconds precision.
+type TSSample struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// TSSeries is a time series returned by Template.TSMRange.
+type TSSeries struct {
+	// Key of the time series, or "label=value" for the groups of option.TSMRange.GroupBy.
+	Key string
+	// Labels returned with option.TSMRange.WithLabels or option.TSMRange.SelectedLabels.
+	Labels map[string]string
+	// Samples of the range.
+	Samples []TSSample
+}
+
+type TSMAddInput struct {
+	// Key can be of any type, but cannot be null, and must be compatible with conversion to string (helper.ConvertToString).
+	Key any
+	// Timestamp of the sample, zero means the current time of the server.
+	Timestamp time.Time
+	// Value of the sample.
+	Value float64
+}
+
+type TSMAddOutput struct {
+	Key       any
+	Timestamp time.Time
+	Err       error
+}
+
+// TSCreate redis `TS.CREATE key [RETENTION retentionPeriod] [DUPLICATE_POLICY policy] [LABELS label value ...]`
+// command, creates the time series of the key.
+//
+// The key parameter can be of any type, but cannot be null, in case an error occurs when converting the key, the
+// error returned is ErrConvertKey. If the key already exists, the error returned is ErrKeyAlreadyExists.
+//
+// To customize the time series, use the opts parameter (option.TSCreate).
+func (t *Template) TSCreate(ctx context.Context, key any, opts ...*option.TSCreate) error {
+	op := &Operation{Name: "TSCreate", Keys: []any{key}}
+	return t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		opt := option.GetOptionTSCreateByParams(opts)
+		args := []any{"TS.CREATE", sKey, "RETENTION", opt.Retention.Milliseconds()}
+		if helper.IsNotNil(opt.DuplicatePolicy) {
+			args = append(args, "DUPLICATE_POLICY", opt.DuplicatePolicy.String())
+		}
+		args = append(args, tsLabelsArgs(opt.Labels)...)
+		return tsError(t.client.Do(ctx, args...).Err())
+	})
+}
+
+// TSAdd redis `TS.ADD key timestamp value [RETENTION retentionPeriod] [ON_DUPLICATE policy] [LABELS label value
+// ...]` command, adds the sample to the time series of the key, creating it if it does not exist, and returns the
+// timestamp of the sample. The zero timestamp means the current time of the server.
+//
+// The key parameter follows the TSCreate documentation. If the time series already has a sample with the timestamp
+// and its duplicate policy is option.TSDuplicatePolicyBlock, the error returned is ErrDuplicateSample.
+//
+// To customize the operation, use the opts parameter (option.TSAdd).
+func (t *Template) TSAdd(ctx context.Context, key any, timestamp time.Time, value float64, opts ...*option.TSAdd) (
+	time.Time, error) {
+	var result time.Time
+	opt := option.GetOptionTSAddByParams(opts)
+	op := &Operation{Name: "TSAdd", Keys: []any{key}, Value: value, Idempotent: helper.IsNotNil(opt.OnDuplicate) &&
+		*opt.OnDuplicate != option.TSDuplicatePolicySum}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		args := []any{"TS.ADD", sKey, tsTimestamp(timestamp), value, "RETENTION", opt.Retention.Milliseconds()}
+		if helper.IsNotNil(opt.OnDuplicate) {
+			args = append(args, "ON_DUPLICATE", opt.OnDuplicate.String())
+		}
+		args = append(args, tsLabelsArgs(opt.Labels)...)
+		milliseconds, err := t.client.Do(ctx, args...).Int64()
+		if helper.IsNotNil(err) {
+			return tsError(err)
+		}
+		result = time.UnixMilli(milliseconds)
+		return nil
+	})
+	return result, err
+}
+
+// TSMAdd redis `TS.MADD key timestamp value [key timestamp value ...]` command, adds the samples to the time series
+// of the keys, which must exist.
+//
+// The return will have a list of TSMAddOutput with each key, the timestamp and the error that occurred, following
+// the TSAdd documentation, if the TSMAddOutput.Err field is nil, it means that the sample was added successfully.
+func (t *Template) TSMAdd(ctx context.Context, samples ...TSMAddInput) []TSMAddOutput {
+	var output []TSMAddOutput
+	op := &Operation{Name: "TSMAdd"}
+	for _, sample := range samples {
+		op.Keys = append(op.Keys, sample.Key)
+	}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		output = nil
+		args := []any{"TS.MADD"}
+		for _, sample := range samples {
+			sKey, err := helper.ConvertToString(sample.Key)
+			if helper.IsNotNil(err) {
+				return ErrConvertKey
+			}
+			args = append(args, sKey, tsTimestamp(sample.Timestamp), sample.Value)
+		}
+		results, err := t.client.Do(ctx, args...).Slice()
+		if helper.IsNotNil(err) {
+			return tsError(err)
+		}
+		var errs []error
+		for i, sample := range samples {
+			item := TSMAddOutput{Key: sample.Key}
+			if i >= len(results) {
+				item.Err = NewOpError(op.Name, sample.Key, errors.New("redis: missing reply"))
+			} else if itemErr, ok := results[i].(error); ok {
+				item.Err = NewOpError(op.Name, sample.Key, tsError(itemErr))
+			} else {
+				milliseconds, _ := results[i].(int64)
+				item.Timestamp = time.UnixMilli(milliseconds)
+			}
+			output = append(output, item)
+			errs = append(errs, item.Err)
+		}
+		return errors.Join(errs...)
+	})
+	if helper.IsNil(output) && helper.IsNotNil(err) {
+		for _, sample := range samples {
+			output = append(output, TSMAddOutput{Key: sample.Key, Err: err})
+		}
+	}
+	return output
+}
+
+// TSRange redis `TS.RANGE key fromTimestamp toTimestamp [COUNT count] [AGGREGATION aggregator bucketDuration
+// [EMPTY]]` command, returns the samples of the time series of the key between from and to, inclusive, in
+// ascending order. The zero from and to mean the first and the last samples of the time series.
+//
+// The key parameter follows the TSCreate documentation. If the key does not exist, the error returned is
+// ErrKeyNotFound.
+//
+// To aggregate the samples in buckets, use the opts parameter (option.TSRange).
+func (t *Template) TSRange(ctx context.Context, key any, from, to time.Time, opts ...*option.TSRange) ([]TSSample,
+	error) {
+	return t.tsRange(ctx, "TSRange", "TS.RANGE", key, from, to, opts...)
+}
+
+// TSRevRange redis `TS.REVRANGE key fromTimestamp toTimestamp ...` command, returns the samples of the range in
+// descending order, see TSRange.
+func (t *Template) TSRevRange(ctx context.Context, key any, from, to time.Time, opts ...*option.TSRange) ([]TSSample,
+	error) {
+	return t.tsRange(ctx, "TSRevRange", "TS.REVRANGE", key, from, to, opts...)
+}
+
+// TSMRange redis `TS.MRANGE fromTimestamp toTimestamp [WITHLABELS | SELECTED_LABELS label ...] [COUNT count]
+// [AGGREGATION aggregator bucketDuration [EMPTY]] FILTER filterExpr ... [GROUPBY label REDUCE reducer]` command,
+// returns the samples between from and to of the time series with the labels that match all the filters, sorted by
+// the key.
+//
+// The filters follow the RedisTimeSeries syntax, at least one must be label=value or label=(value1,value2), ex:
+// "sensor=temperature", "room!=(garage,attic)", "area=" (without the label) and "area!=" (with the label).
+//
+// To aggregate, return the labels or group the time series, use the opts parameter (option.TSMRange).
+func (t *Template) TSMRange(ctx context.Context, from, to time.Time, filters []string, opts ...*option.TSMRange) (
+	[]TSSeries, error) {
+	var result []TSSeries
+	op := &Operation{Name: "TSMRange", Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		opt := option.GetOptionTSMRangeByParams(opts)
+		args := []any{"TS.MRANGE", tsFromTimestamp(from), tsToTimestamp(to)}
+		if *opt.WithLabels {
+			args = append(args, "WITHLABELS")
+		} else if helper.IsNotEmpty(opt.SelectedLabels) {
+			args = append(args, "SELECTED_LABELS")
+			for _, label := range opt.SelectedLabels {
+				args = append(args, label)
+			}
+		}
+		args = append(args, tsAggregationArgs(opt.Aggregation, *opt.Bucket, *opt.Empty, *opt.Count)...)
+		args = append(args, "FILTER")
+		for _, filter := range filters {
+			args = append(args, filter)
+		}
+		if helper.IsNotNil(opt.GroupBy) {
+			args = append(args, "GROUPBY", *opt.GroupBy, "REDUCE", opt.Reducer.String())
+		}
+		reply, err := t.client.Do(ctx, args...).Result()
+		if helper.IsNotNil(err) {
+			return tsError(err)
+		}
+		result, err = parseTSSeries(reply)
+		return err
+	})
+	return result, err
+}
+
+// TSCreateRule redis `TS.CREATERULE sourceKey destKey AGGREGATION aggregator bucketDuration` command, creates the
+// compaction rule that aggregates the samples of the source time series in buckets, adding each bucket closed to
+// the dest time series, ex: the average per hour of the samples per second, with a longer retention.
+//
+// The sourceKey and destKey parameters follow the TSCreate documentation, and both must exist, otherwise the error
+// returned is ErrKeyNotFound.
+func (t *Template) TSCreateRule(ctx context.Context, sourceKey, destKey any, aggregation option.TSAggregation,
+	bucket time.Duration) error {
+	op := &Operation{Name: "TSCreateRule", Keys: []any{sourceKey, destKey}}
+	return t.process(ctx, op, func(ctx context.Context) error {
+		sKeys, err := convertKeys(op.Keys)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		return tsError(t.client.Do(ctx, "TS.CREATERULE", sKeys[0], sKeys[1], "AGGREGATION", aggregation.String(),
+			bucket.Milliseconds()).Err())
+	})
+}
+
+// TSDeleteRule redis `TS.DELETERULE sourceKey destKey` command, deletes the compaction rule, the samples of the
+// dest time series are kept.
+func (t *Template) TSDeleteRule(ctx context.Context, sourceKey, destKey any) error {
+	op := &Operation{Name: "TSDeleteRule", Keys: []any{sourceKey, destKey}, Idempotent: true}
+	return t.process(ctx, op, func(ctx context.Context) error {
+		sKeys, err := convertKeys(op.Keys)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		return tsError(t.client.Do(ctx, "TS.DELETERULE", sKeys[0], sKeys[1]).Err())
+	})
+}
+
+func (t *Template) tsRange(ctx context.Context, name, command string, key any, from, to time.Time,
+	opts ...*option.TSRange) ([]TSSample, error) {
+	var result []TSSample
+	op := &Operation{Name: name, Keys: []any{key}, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		sKey, err := helper.ConvertToString(key)
+		if helper.IsNotNil(err) {
+			return ErrConvertKey
+		}
+		opt := option.GetOptionTSRangeByParams(opts)
+		args := []any{command, sKey, tsFromTimestamp(from), tsToTimestamp(to)}
+		args = append(args, tsAggregationArgs(opt.Aggregation, *opt.Bucket, *opt.Empty, *opt.Count)...)
+		reply, err := t.client.Do(ctx, args...).Slice()
+		if helper.IsNotNil(err) {
+			return tsError(err)
+		}
+		result = parseTSSamples(reply)
+		return nil
+	})
+	return result, err
+}
+
+// tsError converts the errors of the RedisTimeSeries keys to ErrKeyNotFound, ErrKeyAlreadyExists and
+// ErrDuplicateSample.
+func tsError(err error) error {
+	if helper.IsNil(err) {
+		return nil
+	}
+	msg := strings.ToLower(err.Error())
+	if strings.Contains(msg, "tsdb: the key does not exist") {
+		return ErrKeyNotFound
+	} else if strings.Contains(msg, "tsdb: key already exists") {
+		return ErrKeyAlreadyExists
+	} else if strings.Contains(msg, "duplicate_policy is set to block") {
+		return ErrDuplicateSample
+	}
+	return err
+}
+
+func tsLabelsArgs(labels map[string]string) []any {
+	if helper.IsEmpty(labels) {
+		return nil
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	args := []any{"LABELS"}
+	for _, name := range names {
+		args = append(args, name, labels[name])
+	}
+	return args
+}
+
+func tsAggregationArgs(aggregation *option.TSAggregation, bucket time.Duration, empty bool, count int64) []any {
+	var args []any
+	if count > 0 {
+		args = append(args, "COUNT", count)
+	}
+	if helper.IsNotNil(aggregation) {
+		args = append(args, "AGGREGATION", aggregation.String(), bucket.Milliseconds())
+		if empty {
+			args = append(args, "EMPTY")
+		}
+	}
+	return args
+}
+
+// tsTimestamp returns the timestamp of a sample in milliseconds, "*" for the zero time.
+func tsTimestamp(timestamp time.Time) any {
+	if timestamp.IsZero() {
+		return "*"
+	}
+	return timestamp.UnixMilli()
+}
+
+func tsFromTimestamp(from time.Time) any {
+	if from.IsZero() {
+		return "-"
+	}
+	return from.UnixMilli()
+}
+
+func tsToTimestamp(to time.Time) any {
+	if to.IsZero() {
+		return "+"
+	}
+	return to.UnixMilli()
+}
+
+// parseTSSamples parses the samples of a reply, arrays with the timestamp and the value.
+func parseTSSamples(reply []any) []TSSample {
+	samples := make([]TSSample, 0, len(reply))
+	for _, item := range reply {
+		pair, ok := item.([]any)
+		if !ok || len(pair) < 2 {
+			continue
+		}
+		milliseconds, _ := pair[0].(int64)
+		samples = append(samples, TSSample{Timestamp: time.UnixMilli(milliseconds), Value: replyFloat(pair[1])})
+	}
+	return samples
+}
+
+// parseTSSeries parses the reply of TS.MRANGE, an array of the key, the labels and the samples in RESP2, or a map
+// of the keys to the labels, the metadata and the samples in RESP3.
+func parseTSSeries(reply any) ([]TSSeries, error) {
+	var result []TSSeries
+	switch reply := reply.(type) {
+	case []any:
+		for _, item := range reply {
+			fields, ok := item.([]any)
+			if !ok || len(fields) < 3 {
+				return nil, fmt.Errorf("redis: unexpected time series reply %T", item)
+			}
+			series := TSSeries{Key: fmt.Sprint(fields[0]), Labels: parseTSLabels(fields[1])}
+			samples, _ := fields[len(fields)-1].([]any)
+			series.Samples = parseTSSamples(samples)
+			result = append(result, series)
+		}
+	case map[any]any:
+		for key, item := range reply {
+			fields, ok := item.([]any)
+			if !ok || len(fields) < 2 {
+				return nil, fmt.Errorf("redis: unexpected time series reply %T", item)
+			}
+			series := TSSeries{Key: fmt.Sprint(key), Labels: parseTSLabels(fields[0])}
+			samples, _ := fields[len(fields)-1].([]any)
+			series.Samples = parseTSSamples(samples)
+			result = append(result, series)
+		}
+		sort.Slice(result, func(i, j int) bool {
+			return result[i].Key < result[j].Key
+		})
+	default:
+		return nil, fmt.Errorf("redis: unexpected time series reply %T", reply)
+	}
+	return result, nil
+}
+
+// parseTSLabels parses the labels of a time series, pairs of name and value in RESP2 or a map in RESP3.
+func parseTSLabels(value any) map[string]string {
+	labels := map[string]string{}
+	switch value := value.(type) {
+	case []any:
+		for _, item := range value {
+			if pair, ok := item.([]any); ok && len(pair) == 2 {
+				labels[fmt.Sprint(pair[0])] = replyString(pair[1])
+			}
+		}
+	case map[any]any:
+		for name, label := range value {
+			labels[fmt.Sprint(name)] = replyString(label)
+		}
+	}
+	return labels
+}
+
+// replyString converts a string of a reply, empty for the null.
+func replyString(value any) string {
+	if helper.IsNil(value) {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,230 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"github.com/GabrielHCataldo/go-redis-template/redis/redistest"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var tsStart = time.UnixMilli(1704067200000)
+
+func initTimeSeries(t *testing.T, redisTemplate *redis.Template) {
+	ctx := context.TODO()
+	series := map[string]map[string]string{
+		"temperature:kitchen": {"sensor": "temperature", "room": "kitchen"},
+		"temperature:garage":  {"sensor": "temperature", "room": "garage"},
+		"humidity:kitchen":    {"sensor": "humidity", "room": "kitchen"},
+	}
+	for key, labels := range series {
+		if err := redisTemplate.TSCreate(ctx, key, option.NewTSCreate().SetLabels(labels)); helper.IsNotNil(err) {
+			logger.Errorf("TSCreate() err = %v", err)
+			t.Fail()
+		}
+	}
+	var samples []redis.TSMAddInput
+	for i := 0; i < 4; i++ {
+		at := tsStart.Add(time.Duration(i) * 30 * time.Second)
+		samples = append(samples,
+			redis.TSMAddInput{Key: "temperature:kitchen", Timestamp: at, Value: 20 + float64(i)},
+			redis.TSMAddInput{Key: "temperature:garage", Timestamp: at, Value: 10 + float64(i)},
+			redis.TSMAddInput{Key: "humidity:kitchen", Timestamp: at, Value: 50})
+	}
+	for _, output := range redisTemplate.TSMAdd(ctx, samples...) {
+		if helper.IsNotNil(output.Err) {
+			logger.Errorf("TSMAdd() err = %v", output.Err)
+			t.Fail()
+		}
+	}
+}
+
+func TestTemplateTSRange(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	initTimeSeries(t, redisTemplate)
+	samples, err := redisTemplate.TSRange(ctx, "temperature:kitchen", time.Time{}, time.Time{})
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(len(samples), 4) ||
+		!samples[1].Timestamp.Equal(tsStart.Add(30*time.Second)) || helper.IsNotEqualTo(samples[3].Value, 23.0) {
+		logger.Errorf("TSRange() result = %v err = %v", samples, err)
+		t.Fail()
+	}
+	samples, err = redisTemplate.TSRange(ctx, "temperature:kitchen", tsStart, tsStart.Add(2*time.Minute),
+		option.NewTSRange().SetAggregation(option.TSAggregationAvg).SetBucket(time.Minute))
+	if helper.IsNotNil(err) || !reflect.DeepEqual(samples, []redis.TSSample{
+		{Timestamp: tsStart, Value: 20.5},
+		{Timestamp: tsStart.Add(time.Minute), Value: 22.5},
+	}) {
+		logger.Errorf("TSRange() aggregation result = %v err = %v", samples, err)
+		t.Fail()
+	}
+	samples, err = redisTemplate.TSRevRange(ctx, "temperature:kitchen", time.Time{}, time.Time{},
+		option.NewTSRange().SetCount(2))
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(len(samples), 2) || helper.IsNotEqualTo(samples[0].Value, 23.0) {
+		logger.Errorf("TSRevRange() result = %v err = %v", samples, err)
+		t.Fail()
+	}
+	_, _ = redisTemplate.TSAdd(ctx, "temperature:kitchen", tsStart.Add(5*time.Minute), 30)
+	samples, err = redisTemplate.TSRange(ctx, "temperature:kitchen", time.Time{}, time.Time{},
+		option.NewTSRange().SetAggregation(option.TSAggregationCount).SetBucket(time.Minute).SetEmpty(true))
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(len(samples), 6) || helper.IsNotEqualTo(samples[3].Value, 0.0) {
+		logger.Errorf("TSRange() empty result = %v err = %v", samples, err)
+		t.Fail()
+	}
+	_, err = redisTemplate.TSRange(ctx, "temperature:attic", time.Time{}, time.Time{})
+	if !errors.Is(err, redis.ErrKeyNotFound) {
+		logger.Errorf("TSRange() err = %v, want = %v", err, redis.ErrKeyNotFound)
+		t.Fail()
+	}
+}
+
+func TestTemplateTSAdd(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	timestamp, err := redisTemplate.TSAdd(ctx, "requests", tsStart, 1, option.NewTSAdd().
+		SetRetention(time.Hour).SetLabels(map[string]string{"service": "api"}))
+	if helper.IsNotNil(err) || !timestamp.Equal(tsStart) {
+		logger.Errorf("TSAdd() result = %v err = %v", timestamp, err)
+		t.Fail()
+	}
+	_, err = redisTemplate.TSAdd(ctx, "requests", tsStart, 2)
+	if !errors.Is(err, redis.ErrDuplicateSample) {
+		logger.Errorf("TSAdd() err = %v, want = %v", err, redis.ErrDuplicateSample)
+		t.Fail()
+	}
+	_, err = redisTemplate.TSAdd(ctx, "requests", tsStart, 2, option.NewTSAdd().
+		SetOnDuplicate(option.TSDuplicatePolicySum))
+	samples, _ := redisTemplate.TSRange(ctx, "requests", time.Time{}, time.Time{})
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(len(samples), 1) || helper.IsNotEqualTo(samples[0].Value, 3.0) {
+		logger.Errorf("TSAdd() on duplicate result = %v err = %v", samples, err)
+		t.Fail()
+	}
+	_, _ = redisTemplate.TSAdd(ctx, "requests", tsStart.Add(2*time.Hour), 1)
+	samples, _ = redisTemplate.TSRange(ctx, "requests", time.Time{}, time.Time{})
+	if helper.IsNotEqualTo(len(samples), 1) {
+		logger.Errorf("TSAdd() retention result = %v", samples)
+		t.Fail()
+	}
+	timestamp, err = redisTemplate.TSAdd(ctx, "requests", time.Time{}, 1)
+	if helper.IsNotNil(err) || time.Since(timestamp) > time.Minute {
+		logger.Errorf("TSAdd() now result = %v err = %v", timestamp, err)
+		t.Fail()
+	}
+	err = redisTemplate.TSCreate(ctx, "requests")
+	if !errors.Is(err, redis.ErrKeyAlreadyExists) {
+		logger.Errorf("TSCreate() err = %v, want = %v", err, redis.ErrKeyAlreadyExists)
+		t.Fail()
+	}
+	err = redisTemplate.TSCreate(ctx, "counter", option.NewTSCreate().SetDuplicatePolicy(option.TSDuplicatePolicyMax))
+	_, _ = redisTemplate.TSAdd(ctx, "counter", tsStart, 5)
+	_, _ = redisTemplate.TSAdd(ctx, "counter", tsStart, 3)
+	samples, _ = redisTemplate.TSRange(ctx, "counter", time.Time{}, time.Time{})
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(len(samples), 1) || helper.IsNotEqualTo(samples[0].Value, 5.0) {
+		logger.Errorf("TSCreate() duplicate policy result = %v err = %v", samples, err)
+		t.Fail()
+	}
+	outputs := redisTemplate.TSMAdd(ctx, redis.TSMAddInput{Key: "counter", Timestamp: tsStart.Add(time.Second),
+		Value: 1}, redis.TSMAddInput{Key: "unknown", Timestamp: tsStart, Value: 1})
+	if helper.IsNotEqualTo(len(outputs), 2) || helper.IsNotNil(outputs[0].Err) ||
+		!outputs[0].Timestamp.Equal(tsStart.Add(time.Second)) || !errors.Is(outputs[1].Err, redis.ErrKeyNotFound) {
+		logger.Errorf("TSMAdd() result = %v", outputs)
+		t.Fail()
+	}
+}
+
+func TestTemplateTSMRange(t *testing.T) {
+	for _, protocol := range []int{2, 3} {
+		server := redistest.NewServer(t)
+		opts := server.ClientOptions()
+		opts.Protocol = protocol
+		redisTemplate := redis.NewTemplate(opts)
+		ctx := context.TODO()
+		initTimeSeries(t, redisTemplate)
+		series, err := redisTemplate.TSMRange(ctx, time.Time{}, time.Time{}, []string{"sensor=temperature"},
+			option.NewTSMRange().SetWithLabels(true).SetAggregation(option.TSAggregationMax).SetBucket(time.Hour))
+		if helper.IsNotNil(err) || !reflect.DeepEqual(series, []redis.TSSeries{
+			{
+				Key:     "temperature:garage",
+				Labels:  map[string]string{"sensor": "temperature", "room": "garage"},
+				Samples: []redis.TSSample{{Timestamp: tsStart, Value: 13}},
+			},
+			{
+				Key:     "temperature:kitchen",
+				Labels:  map[string]string{"sensor": "temperature", "room": "kitchen"},
+				Samples: []redis.TSSample{{Timestamp: tsStart, Value: 23}},
+			},
+		}) {
+			logger.Errorf("TSMRange() protocol = %v result = %v err = %v", protocol, series, err)
+			t.Fail()
+		}
+		series, err = redisTemplate.TSMRange(ctx, tsStart, tsStart, []string{"room=kitchen", "sensor!=(pressure)"},
+			option.NewTSMRange().SetSelectedLabels("sensor", "area"))
+		if helper.IsNotNil(err) || helper.IsNotEqualTo(len(series), 2) ||
+			!reflect.DeepEqual(series[0].Labels, map[string]string{"sensor": "humidity", "area": ""}) ||
+			helper.IsNotEqualTo(len(series[1].Samples), 1) {
+			logger.Errorf("TSMRange() protocol = %v selected result = %v err = %v", protocol, series, err)
+			t.Fail()
+		}
+		series, err = redisTemplate.TSMRange(ctx, tsStart, tsStart, []string{"sensor=(temperature,humidity)"},
+			option.NewTSMRange().SetGroupBy("room").SetReducer(option.TSAggregationMax))
+		if helper.IsNotNil(err) || helper.IsNotEqualTo(len(series), 2) ||
+			helper.IsNotEqualTo(series[0].Key, "room=garage") || helper.IsNotEqualTo(series[1].Samples[0].Value, 50.0) {
+			logger.Errorf("TSMRange() protocol = %v group result = %v err = %v", protocol, series, err)
+			t.Fail()
+		}
+		redisTemplate.SimpleDisconnect()
+	}
+}
+
+func TestTemplateTSCreateRule(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	_ = redisTemplate.TSCreate(ctx, "cpu")
+	_ = redisTemplate.TSCreate(ctx, "cpu:avg:1m", option.NewTSCreate().SetRetention(24*time.Hour))
+	if err := redisTemplate.TSCreateRule(ctx, "cpu", "cpu:avg:1m", option.TSAggregationAvg,
+		time.Minute); helper.IsNotNil(err) {
+		logger.Errorf("TSCreateRule() err = %v", err)
+		t.Fail()
+	}
+	for i, value := range []float64{10, 20, 30, 40, 50} {
+		_, _ = redisTemplate.TSAdd(ctx, "cpu", tsStart.Add(time.Duration(i)*20*time.Second), value)
+	}
+	samples, err := redisTemplate.TSRange(ctx, "cpu:avg:1m", time.Time{}, time.Time{})
+	if helper.IsNotNil(err) || !reflect.DeepEqual(samples, []redis.TSSample{{Timestamp: tsStart, Value: 20}}) {
+		logger.Errorf("TSCreateRule() compaction result = %v err = %v", samples, err)
+		t.Fail()
+	}
+	if err = redisTemplate.TSDeleteRule(ctx, "cpu", "cpu:avg:1m"); helper.IsNotNil(err) {
+		logger.Errorf("TSDeleteRule() err = %v", err)
+		t.Fail()
+	}
+	_, _ = redisTemplate.TSAdd(ctx, "cpu", tsStart.Add(5*time.Minute), 60)
+	samples, _ = redisTemplate.TSRange(ctx, "cpu:avg:1m", time.Time{}, time.Time{})
+	if helper.IsNotEqualTo(len(samples), 1) {
+		logger.Errorf("TSDeleteRule() compaction result = %v", samples)
+		t.Fail()
+	}
+	err = redisTemplate.TSCreateRule(ctx, "cpu", "unknown", option.TSAggregationAvg, time.Minute)
+	if !errors.Is(err, redis.ErrKeyNotFound) {
+		logger.Errorf("TSCreateRule() err = %v, want = %v", err, redis.ErrKeyNotFound)
+		t.Fail()
+	}
+	samples, _ = redisTemplate.TSRange(ctx, "cpu", time.Time{}, time.Time{}, option.NewTSRange().
+		SetAggregation(option.TSAggregationStdP).SetBucket(time.Minute))
+	if helper.IsNotEqualTo(len(samples), 3) || math.Abs(samples[0].Value-math.Sqrt(200.0/3)) > 1e-9 {
+		logger.Errorf("TSRange() std.p result = %v", samples)
+		t.Fail()
+	}
+}
@@ -0,0 +1,99 @@
+package redis
+
+import (
+	"context"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"time"
+)
+
+// UniqueCounter counts the unique members, ex: the visitors of a page, per time bucket, using a HyperLogLog key per
+// bucket named with SprintKey, ex: "visitors:home:1704067200", the prefix followed by the Unix time of the start
+// of the bucket. The unique members over several buckets are counted by merging their keys.
+type UniqueCounter struct {
+	template *Template
+	prefix   []any
+	bucket   time.Duration
+	ttl      time.Duration
+}
+
+// NewUniqueCounter creates a new unique counter of the template, with the prefix of the bucket keys, ex:
+// NewUniqueCounter(template, []any{"visitors", "home"}, option.NewUniqueCounter().SetBucket(24 * time.Hour)).
+//
+// On redis cluster, use a hash tag in the prefix, ex: "{visitors:home}", so that the buckets can be merged.
+//
+// To customize the counter, use the opts parameter (option.UniqueCounter).
+func NewUniqueCounter(t *Template, prefix []any, opts ...*option.UniqueCounter) *UniqueCounter {
+	opt := option.GetOptionUniqueCounterByParams(opts)
+	return &UniqueCounter{
+		template: t,
+		prefix:   append([]any{}, prefix...),
+		bucket:   *opt.Bucket,
+		ttl:      *opt.TTL,
+	}
+}
+
+// Key returns the key of the bucket of the time.
+func (u *UniqueCounter) Key(at time.Time) string {
+	return SprintKey(append(append([]any{}, u.prefix...), at.Truncate(u.bucket).Unix())...)
+}
+
+// Add adds the members to the bucket of the current time, returning true if the estimated count of the bucket
+// changed. The members follow the Template.PFAdd documentation.
+func (u *UniqueCounter) Add(ctx context.Context, members ...any) (bool, error) {
+	return u.AddAt(ctx, time.Now(), members...)
+}
+
+// AddAt adds the members to the bucket of the time, renewing the TTL of the bucket key, see Add.
+func (u *UniqueCounter) AddAt(ctx context.Context, at time.Time, members ...any) (bool, error) {
+	var changed bool
+	key := u.Key(at)
+	op := &Operation{Name: "UniqueCounterAdd", Keys: []any{key}, Value: members, Idempotent: true}
+	err := u.template.process(ctx, op, func(ctx context.Context) error {
+		sMembers, err := convertMembers(op, members)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		pipe := u.template.client.TxPipeline()
+		result := pipe.PFAdd(ctx, key, sMembers...)
+		if u.ttl > 0 {
+			pipe.Expire(ctx, key, u.ttl)
+		}
+		_, err = pipe.Exec(ctx)
+		changed = helper.Equals(result.Val(), int64(1))
+		return err
+	})
+	return changed, err
+}
+
+// Count returns the estimated number of unique members over the last buckets, including the bucket of the current
+// time, ex: Count(ctx, 7) with daily buckets are the uniques of the last 7 days.
+func (u *UniqueCounter) Count(ctx context.Context, buckets int) (int64, error) {
+	return u.CountAt(ctx, time.Now(), buckets)
+}
+
+// CountAt returns the estimated number of unique members over the buckets ending in the bucket of the time, see
+// Count.
+func (u *UniqueCounter) CountAt(ctx context.Context, at time.Time, buckets int) (int64, error) {
+	return u.template.PFCount(ctx, u.keys(at, buckets)...)
+}
+
+// Merge stores in the destKey the union of the last buckets, including the bucket of the current time, ex: to keep
+// the uniques of a closed week after its daily buckets expire.
+func (u *UniqueCounter) Merge(ctx context.Context, destKey any, buckets int) error {
+	return u.MergeAt(ctx, time.Now(), destKey, buckets)
+}
+
+// MergeAt stores in the destKey the union of the buckets ending in the bucket of the time, see Merge.
+func (u *UniqueCounter) MergeAt(ctx context.Context, at time.Time, destKey any, buckets int) error {
+	return u.template.PFMerge(ctx, destKey, u.keys(at, buckets)...)
+}
+
+// keys returns the keys of the buckets ending in the bucket of the time, at least one.
+func (u *UniqueCounter) keys(at time.Time, buckets int) []any {
+	var keys []any
+	for i := 0; i < max(buckets, 1); i++ {
+		keys = append(keys, u.Key(at.Add(-time.Duration(i)*u.bucket)))
+	}
+	return keys
+}
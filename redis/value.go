@@ -0,0 +1,67 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"github.com/redis/go-redis/v9"
+)
+
+// encodeValue applies the value pipeline of the writes, the value is compressed and then encrypted, since the
+// encrypted bytes cannot be compressed.
+func (t *Template) encodeValue(key, value string, opt *option.Set) (string, error) {
+	value, err := t.compressValue(value, opt)
+	if helper.IsNotNil(err) {
+		return "", err
+	}
+	return t.encryptValue(key, value, opt)
+}
+
+// decodeValue reverts the value pipeline of the writes, each step is identified by the header of the value, so
+// values written with other options, or without any, are read as well.
+func (t *Template) decodeValue(key, value string) (string, error) {
+	value, err := t.keyring.decrypt(key, value)
+	if helper.IsNotNil(err) {
+		return "", err
+	}
+	return decompressValue(value)
+}
+
+// rewrite replaces the string value of the key by the result of fn, keeping its TTL, in a transaction watching the
+// key. The value is not written if fn returns false, or if the key does not exist, is not a string or is written
+// concurrently, in these cases the return is false without error.
+func (t *Template) rewrite(
+	ctx context.Context,
+	name,
+	key string,
+	fn func(value string) (string, bool, error),
+) (bool, error) {
+	var rewritten bool
+	op := &Operation{Name: name, Keys: []any{key}, Idempotent: true}
+	err := t.process(ctx, op, func(ctx context.Context) error {
+		return t.client.Watch(ctx, func(tx *redis.Tx) error {
+			value, err := tx.Get(ctx, key).Result()
+			if errors.Is(err, redis.Nil) || ErrorKind(err) == ErrWrongType {
+				return nil
+			} else if helper.IsNotNil(err) {
+				return err
+			}
+			newValue, ok, err := fn(value)
+			if helper.IsNotNil(err) || !ok {
+				return err
+			}
+			op.ValueSize = len(newValue)
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.SetArgs(ctx, key, newValue, redis.SetArgs{Mode: option.SetModeXx.String(), KeepTTL: true})
+				return nil
+			})
+			rewritten = helper.IsNil(err)
+			return err
+		}, key)
+	})
+	if errors.Is(err, redis.TxFailedErr) {
+		return false, nil
+	}
+	return rewritten, err
+}
@@ -0,0 +1,307 @@
+package redis
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"math"
+	"strconv"
+	"time"
+)
+
+// searchVectorAlias is the name of the distance returned by the KNN queries of VectorIndex.
+const searchVectorAlias = "__vector_distance"
+
+// VectorIndex is a RediSearch index of vectors, ex: the embeddings of the documents, stored in hashes or RedisJSON
+// documents with their metadata, searched by similarity with KNN.
+type VectorIndex struct {
+	template *Template
+	index    *SearchIndex
+	field    SearchField
+	prefix   string
+}
+
+// VectorMatch is a result of VectorIndex.KNN.
+type VectorMatch struct {
+	// Key of the hash or document.
+	Key string
+	// Distance of the vector to the vector searched, according to the distance metric of the index: the squared
+	// euclidean distance for L2, 1 minus the inner product for IP, and 1 minus the cosine similarity for COSINE.
+	Distance float64
+	// Score is the similarity of the vector to the vector searched, the higher the more similar: 1 minus the
+	// distance for COSINE and IP, and 1 / (1 + distance) for L2.
+	Score float64
+	// Metadata of the hash or document, without the vector, the values of the hashes are strings.
+	Metadata map[string]any
+}
+
+// SemanticCache caches the answers, ex: of a LLM, by the embeddings of the prompts, returning a cached answer when
+// the prompt searched is similar enough to a prompt cached, by the cosine similarity of their embeddings.
+type SemanticCache struct {
+	index     *VectorIndex
+	threshold float64
+	ttl       time.Duration
+}
+
+// NewVectorIndex creates a new vector index of the template, with the name and the number of dimensions of the
+// vectors, ex: NewVectorIndex(template, "idx:docs", 384, option.NewVectorIndex().SetTags("lang")). The index is
+// created in redis by VectorIndex.Create.
+//
+// To customize the index, use the opts parameter (option.VectorIndex).
+func NewVectorIndex(t *Template, name string, dim int, opts ...*option.VectorIndex) *VectorIndex {
+	opt := option.GetOptionVectorIndexByParams(opts)
+	prefix := helper.IfNilReturns(opt.Prefix, name+":")
+	index := &SearchIndex{Name: name, On: *opt.On, Prefixes: []string{prefix}}
+	field := SearchField{
+		Name:       *opt.Field,
+		Identifier: searchFieldIdentifier(*opt.On, *opt.Field),
+		Type:       option.SearchFieldVector,
+		Algorithm:  *opt.Algorithm,
+		Dim:        dim,
+		Distance:   *opt.Distance,
+		VectorType: "FLOAT32",
+	}
+	index.Fields = append(index.Fields, field)
+	for _, tag := range opt.Tags {
+		identifier := searchFieldIdentifier(*opt.On, tag)
+		if *opt.On == option.SearchOnJSON {
+			identifier += "[*]"
+		}
+		index.Fields = append(index.Fields, SearchField{Name: tag, Identifier: identifier,
+			Type: option.SearchFieldTag})
+	}
+	for _, numeric := range opt.Numerics {
+		index.Fields = append(index.Fields, SearchField{Name: numeric,
+			Identifier: searchFieldIdentifier(*opt.On, numeric), Type: option.SearchFieldNumeric})
+	}
+	return &VectorIndex{template: t, index: index, field: field, prefix: prefix}
+}
+
+// Name returns the name of the index.
+func (v *VectorIndex) Name() string {
+	return v.index.Name
+}
+
+// Key returns the key of the id in the prefix of the index, ex: Key(10) -> "idx:docs:10".
+func (v *VectorIndex) Key(id any) string {
+	return v.prefix + helper.SimpleConvertToString(id)
+}
+
+// Create creates the index in redis, see Template.FTCreate. If the index already exists, the error returned is
+// ErrIndexAlreadyExists.
+func (v *VectorIndex) Create(ctx context.Context) error {
+	op := &Operation{Name: "VectorIndexCreate"}
+	return v.template.process(ctx, op, func(ctx context.Context) error {
+		return searchError(v.template.client.Do(ctx, v.index.args()...).Err())
+	})
+}
+
+// Drop drops the index, see Template.FTDropIndex.
+func (v *VectorIndex) Drop(ctx context.Context, opts ...*option.FTDropIndex) error {
+	return v.template.FTDropIndex(ctx, v.index.Name, opts...)
+}
+
+// UpsertVector stores the vector and the metadata in the key, replacing its previous value. The vector is stored as
+// a FLOAT32 little-endian blob in the hashes, the metadata values converted to strings, or as an array in the JSON
+// documents, encoded with the option.Client.JSONCodec of the template.
+//
+// The key must start with the prefix of the index to be indexed, see Key. If the length of the vector is not the
+// dimension of the index, the error returned is ErrVectorDimension.
+func (v *VectorIndex) UpsertVector(ctx context.Context, key any, vector []float32, metadata map[string]any) error {
+	op := &Operation{Name: "UpsertVector", Keys: []any{key}, Value: metadata, Idempotent: true}
+	return v.template.process(ctx, op, func(ctx context.Context) error {
+		return v.upsert(ctx, op, key, vector, metadata, 0)
+	})
+}
+
+// KNN returns the k hashes or documents with the vectors most similar to the vector, sorted by the distance, among
+// the documents of the filter, nil means all the documents of the index. Only the filters and the params of the
+// filter are used, and its fields must be indexed, see option.VectorIndex.Tags and option.VectorIndex.Numerics.
+//
+// If the length of the vector is not the dimension of the index, the error returned is ErrVectorDimension, and if
+// the index does not exist, the error returned is ErrIndexNotFound.
+func (v *VectorIndex) KNN(ctx context.Context, vector []float32, k int, filter *SearchQuery) ([]VectorMatch, error) {
+	var matches []VectorMatch
+	op := &Operation{Name: "KNN", Idempotent: true}
+	err := v.template.process(ctx, op, func(ctx context.Context) error {
+		if helper.IsNotEqualTo(len(vector), v.field.Dim) {
+			return ErrVectorDimension
+		}
+		expression := "*"
+		if helper.IsNotNil(filter) && helper.IsNotEmpty(filter.filters) {
+			expression = "(" + filter.String() + ")"
+		}
+		query := NewSearchQuery().
+			Raw(expression+"=>[KNN "+strconv.Itoa(k)+" @"+v.field.Name+" $vector AS "+searchVectorAlias+"]").
+			SortBy(searchVectorAlias, option.SortOrderAsc).
+			Limit(0, k).
+			Param("vector", encodeVector(vector))
+		if helper.IsNotNil(filter) {
+			query.params = append(query.params, filter.params...)
+		}
+		reply, err := v.template.client.Do(ctx, query.args(v.index.Name)...).Result()
+		if helper.IsNotNil(err) {
+			return searchError(err)
+		}
+		_, docs, err := parseSearchReply(reply, false, false)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		matches = make([]VectorMatch, 0, len(docs))
+		for _, doc := range docs {
+			match, err := v.match(doc)
+			if helper.IsNotNil(err) {
+				return err
+			}
+			matches = append(matches, match)
+		}
+		return nil
+	})
+	return matches, err
+}
+
+// NewSemanticCache creates a new semantic cache of the template, with the name of its index, also the prefix of its
+// keys, and the number of dimensions of the embeddings, ex: NewSemanticCache(template, "llm-cache", 1536,
+// option.NewSemanticCache().SetThreshold(0.95).SetTTL(24 * time.Hour)). The index is created in redis by
+// SemanticCache.Create.
+//
+// To customize the cache, use the opts parameter (option.SemanticCache).
+func NewSemanticCache(t *Template, name string, dim int, opts ...*option.SemanticCache) *SemanticCache {
+	opt := option.GetOptionSemanticCacheByParams(opts)
+	return &SemanticCache{
+		index: NewVectorIndex(t, name, dim, option.NewVectorIndex().SetAlgorithm(*opt.Algorithm).
+			SetDistance(option.VectorDistanceCosine)),
+		threshold: *opt.Threshold,
+		ttl:       *opt.TTL,
+	}
+}
+
+// Index returns the vector index of the cache.
+func (s *SemanticCache) Index() *VectorIndex {
+	return s.index
+}
+
+// Create creates the index of the cache in redis, if it does not exist.
+func (s *SemanticCache) Create(ctx context.Context) error {
+	err := s.index.Create(ctx)
+	if errors.Is(err, ErrIndexAlreadyExists) {
+		return nil
+	}
+	return err
+}
+
+// Get decodes into dest the answer cached of the prompt most similar to the embedding, returning their similarity,
+// with the option.Client.JSONCodec of the template. If no prompt cached has the similarity of the threshold, the
+// error returned is ErrKeyNotFound.
+//
+// The dest parameter must be a pointer, ex: a pointer to a string.
+func (s *SemanticCache) Get(ctx context.Context, embedding []float32, dest any) (float64, error) {
+	if !helper.IsPointerType(dest) {
+		return 0, ErrDestIsNotPointer
+	}
+	matches, err := s.index.KNN(ctx, embedding, 1, nil)
+	if helper.IsNotNil(err) {
+		return 0, err
+	} else if helper.IsEmpty(matches) || matches[0].Score < s.threshold {
+		return 0, ErrKeyNotFound
+	}
+	answer, _ := matches[0].Metadata["answer"].(string)
+	if err = s.index.template.jsonCodec.Unmarshal([]byte(answer), dest); helper.IsNotNil(err) {
+		return 0, err
+	}
+	return matches[0].Score, nil
+}
+
+// Set caches the answer of the prompt, by its embedding, encoded with the option.Client.JSONCodec of the template.
+// The key of the entry is derived from the embedding, so the same embedding replaces its answer.
+func (s *SemanticCache) Set(ctx context.Context, embedding []float32, prompt string, answer any) error {
+	sum := sha1.Sum(encodeVector(embedding))
+	key := s.index.Key(hex.EncodeToString(sum[:]))
+	op := &Operation{Name: "SemanticCacheSet", Keys: []any{key}, Value: answer, Idempotent: true}
+	return s.index.template.process(ctx, op, func(ctx context.Context) error {
+		sAnswer, err := s.index.template.encodeJSON(op, answer)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		metadata := map[string]any{"prompt": prompt, "answer": sAnswer}
+		return s.index.upsert(ctx, op, key, embedding, metadata, s.ttl)
+	})
+}
+
+// upsert replaces the value of the key in a transaction, with the expiration time when ttl is greater than zero.
+func (v *VectorIndex) upsert(ctx context.Context, op *Operation, key any, vector []float32, metadata map[string]any,
+	ttl time.Duration) error {
+	sKey, err := helper.ConvertToString(key)
+	if helper.IsNotNil(err) {
+		return ErrConvertKey
+	} else if helper.IsNotEqualTo(len(vector), v.field.Dim) {
+		return ErrVectorDimension
+	}
+	pipe := v.template.client.TxPipeline()
+	if v.index.On == option.SearchOnJSON {
+		document := map[string]any{}
+		for name, value := range metadata {
+			document[name] = value
+		}
+		document[v.field.Name] = vector
+		sDocument, err := v.template.encodeJSON(op, document)
+		if helper.IsNotNil(err) {
+			return err
+		}
+		pipe.JSONSet(ctx, sKey, jsonRootPath, sDocument)
+	} else {
+		blob := encodeVector(vector)
+		values := []any{v.field.Name, blob}
+		for name, value := range metadata {
+			sValue, err := helper.ConvertToString(value)
+			if helper.IsNotNil(err) {
+				return ErrConvertValue
+			}
+			values = append(values, name, sValue)
+			op.ValueSize += len(sValue)
+		}
+		op.ValueSize += len(blob)
+		pipe.Del(ctx, sKey)
+		pipe.HSet(ctx, sKey, values...)
+	}
+	if ttl > 0 {
+		pipe.Expire(ctx, sKey, ttl)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// match converts the document of a KNN query, removing the distance and the vector from its metadata.
+func (v *VectorIndex) match(doc searchDocument) (VectorMatch, error) {
+	match := VectorMatch{Key: doc.id, Distance: replyFloat(doc.fields[searchVectorAlias]), Metadata: map[string]any{}}
+	delete(doc.fields, searchVectorAlias)
+	if document, ok := doc.fields["$"]; ok {
+		if err := v.template.jsonCodec.Unmarshal([]byte(document), &match.Metadata); helper.IsNotNil(err) {
+			return match, err
+		}
+	} else {
+		for name, value := range doc.fields {
+			match.Metadata[name] = value
+		}
+	}
+	delete(match.Metadata, v.field.Name)
+	if v.field.Distance == option.VectorDistanceL2 {
+		match.Score = 1 / (1 + match.Distance)
+	} else {
+		match.Score = 1 - match.Distance
+	}
+	return match, nil
+}
+
+// encodeVector encodes the FLOAT32 vectors of RediSearch, little-endian.
+func encodeVector(vector []float32) []byte {
+	data := make([]byte, 4*len(vector))
+	for i, value := range vector {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(value))
+	}
+	return data
+}
@@ -0,0 +1,161 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"github.com/GabrielHCataldo/go-redis-template/redis/redistest"
+	redisdriver "github.com/redis/go-redis/v9"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type vectorDocument struct {
+	Key       string    `search:"@id"`
+	Lang      string    `json:"lang" search:",tag"`
+	Embedding []float32 `json:"embedding" search:",vector,dim=3"`
+}
+
+func TestVectorIndexKNN(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	index := redis.NewVectorIndex(redisTemplate, "idx:vector", 3, option.NewVectorIndex().SetTags("lang").
+		SetNumerics("year"))
+	if err := index.Create(ctx); helper.IsNotNil(err) {
+		logger.Errorf("Create() err = %v", err)
+		t.Fail()
+	}
+	documents := map[int][]float32{1: {1, 0, 0}, 2: {0.9, 0.1, 0}, 3: {0, 1, 0}, 4: {0, 0, 1}}
+	for id, vector := range documents {
+		lang := "en"
+		if id%2 == 0 {
+			lang = "pt"
+		}
+		err := index.UpsertVector(ctx, index.Key(id), vector, map[string]any{"lang": lang, "year": 2020 + id})
+		if helper.IsNotNil(err) {
+			logger.Errorf("UpsertVector() err = %v", err)
+			t.Fail()
+		}
+	}
+	matches, err := index.KNN(ctx, []float32{1, 0, 0}, 2, nil)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(len(matches), 2) ||
+		helper.IsNotEqualTo(matches[0].Key, "idx:vector:1") || helper.IsNotEqualTo(matches[1].Key, "idx:vector:2") ||
+		math.Abs(matches[0].Score-1) > 1e-6 || matches[1].Score >= matches[0].Score ||
+		!reflect.DeepEqual(matches[0].Metadata, map[string]any{"lang": "en", "year": "2021"}) {
+		logger.Errorf("KNN() result = %v err = %v", matches, err)
+		t.Fail()
+	}
+	matches, err = index.KNN(ctx, []float32{1, 0, 0}, 2, redis.NewSearchQuery().Tag("lang", "pt"))
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(len(matches), 2) ||
+		helper.IsNotEqualTo(matches[0].Key, "idx:vector:2") || helper.IsNotEqualTo(matches[1].Key, "idx:vector:4") {
+		logger.Errorf("KNN() filter result = %v err = %v", matches, err)
+		t.Fail()
+	}
+	matches, err = index.KNN(ctx, []float32{0, 1, 0}, 5, redis.NewSearchQuery().Range("year", 2023, math.Inf(1)))
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(len(matches), 2) ||
+		helper.IsNotEqualTo(matches[0].Key, "idx:vector:3") {
+		logger.Errorf("KNN() range result = %v err = %v", matches, err)
+		t.Fail()
+	}
+	var documentsFound []vectorDocument
+	_, err = redisTemplate.FTSearch(ctx, "idx:vector", redis.NewSearchQuery().Tag("lang", "en").
+		SortBy("year", option.SortOrderAsc), &documentsFound)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(len(documentsFound), 2) ||
+		!reflect.DeepEqual(documentsFound[1].Embedding, []float32{0, 1, 0}) {
+		logger.Errorf("FTSearch() result = %v err = %v", documentsFound, err)
+		t.Fail()
+	}
+	err = index.UpsertVector(ctx, index.Key(5), []float32{1, 0}, nil)
+	if !errors.Is(err, redis.ErrVectorDimension) {
+		logger.Errorf("UpsertVector() err = %v, want = %v", err, redis.ErrVectorDimension)
+		t.Fail()
+	}
+	_, err = index.KNN(ctx, []float32{1, 0}, 1, nil)
+	if !errors.Is(err, redis.ErrVectorDimension) {
+		logger.Errorf("KNN() err = %v, want = %v", err, redis.ErrVectorDimension)
+		t.Fail()
+	}
+}
+
+func TestVectorIndexJSON(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	index := redis.NewVectorIndex(redisTemplate, "idx:json", 2, option.NewVectorIndex().SetOn(option.SearchOnJSON).
+		SetPrefix("doc:").SetField("vector").SetDistance(option.VectorDistanceL2).SetTags("tags"))
+	if err := index.Create(ctx); helper.IsNotNil(err) {
+		logger.Errorf("Create() err = %v", err)
+		t.Fail()
+	}
+	_ = index.UpsertVector(ctx, "doc:1", []float32{0, 0}, map[string]any{"tags": []string{"a", "b"}})
+	_ = index.UpsertVector(ctx, "doc:2", []float32{3, 4}, map[string]any{"tags": []string{"b"}})
+	matches, err := index.KNN(ctx, []float32{0, 0}, 10, redis.NewSearchQuery().Tag("tags", "b"))
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(len(matches), 2) || helper.IsNotEqualTo(matches[1].Key, "doc:2") ||
+		helper.IsNotEqualTo(matches[1].Distance, 25.0) || helper.IsNotEqualTo(matches[1].Score, 1/26.0) ||
+		!reflect.DeepEqual(matches[0].Metadata, map[string]any{"tags": []any{"a", "b"}}) {
+		logger.Errorf("KNN() result = %v err = %v", matches, err)
+		t.Fail()
+	}
+	if err = index.Drop(ctx); helper.IsNotNil(err) {
+		logger.Errorf("Drop() err = %v", err)
+		t.Fail()
+	}
+	_, err = index.KNN(ctx, []float32{0, 0}, 1, nil)
+	if !errors.Is(err, redis.ErrIndexNotFound) {
+		logger.Errorf("KNN() err = %v, want = %v", err, redis.ErrIndexNotFound)
+		t.Fail()
+	}
+}
+
+func TestSemanticCache(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	client := redisdriver.NewClient(server.ClientOptions().ParseToRedisOptions())
+	defer client.Close()
+	ctx := context.TODO()
+	cache := redis.NewSemanticCache(redisTemplate, "llm-cache", 3, option.NewSemanticCache().SetThreshold(0.95).
+		SetTTL(time.Hour))
+	for i := 0; i < 2; i++ {
+		if err := cache.Create(ctx); helper.IsNotNil(err) {
+			logger.Errorf("Create() err = %v", err)
+			t.Fail()
+		}
+	}
+	var answer string
+	if _, err := cache.Get(ctx, []float32{1, 0, 0}, &answer); !errors.Is(err, redis.ErrKeyNotFound) {
+		logger.Errorf("Get() err = %v, want = %v", err, redis.ErrKeyNotFound)
+		t.Fail()
+	}
+	if err := cache.Set(ctx, []float32{1, 0, 0}, "what is redis?", "an in-memory data store"); helper.IsNotNil(err) {
+		logger.Errorf("Set() err = %v", err)
+		t.Fail()
+	}
+	similarity, err := cache.Get(ctx, []float32{0.99, 0.05, 0}, &answer)
+	if helper.IsNotNil(err) || similarity < 0.95 || helper.IsNotEqualTo(answer, "an in-memory data store") {
+		logger.Errorf("Get() similarity = %v answer = %v err = %v", similarity, answer, err)
+		t.Fail()
+	}
+	if _, err = cache.Get(ctx, []float32{0.5, 0.5, 0}, &answer); !errors.Is(err, redis.ErrKeyNotFound) {
+		logger.Errorf("Get() err = %v, want = %v", err, redis.ErrKeyNotFound)
+		t.Fail()
+	}
+	matches, _ := cache.Index().KNN(ctx, []float32{1, 0, 0}, 1, nil)
+	ttl := client.TTL(ctx, matches[0].Key).Val()
+	if helper.IsNotEqualTo(matches[0].Metadata["prompt"], "what is redis?") || ttl <= 0 {
+		logger.Errorf("KNN() result = %v ttl = %v", matches, ttl)
+		t.Fail()
+	}
+	if _, err = cache.Get(ctx, []float32{1, 0, 0}, answer); !errors.Is(err, redis.ErrDestIsNotPointer) {
+		logger.Errorf("Get() err = %v, want = %v", err, redis.ErrDestIsNotPointer)
+		t.Fail()
+	}
+}
@@ -0,0 +1,157 @@
+package redis
+
+import (
+	"context"
+	"encoding/binary"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"reflect"
+)
+
+// headerVersion prefixes the versioned values, it is followed by the schema version as uvarint and the payload:
+// 0xFE "RTV" | uvarint(version) | payload.
+const headerVersion = "\xfeRTV"
+
+// UpgradeFunc converts the payload of a value, usually JSON, from a schema version to the next one.
+type UpgradeFunc func(payload string) (string, error)
+
+// Schema is the current version of the values of a type registered in the template (Template.RegisterSchema),
+// with the functions that upgrade the values written with the previous versions.
+type Schema struct {
+	// Version current version, written in the envelope of the values.
+	Version uint64
+	// Upgrades functions by the version they upgrade from, the upgrade from N returns the payload of N+1, values
+	// written before the registration of the schema have the version 0.
+	Upgrades map[uint64]UpgradeFunc
+	// WriteBack writes the upgraded value back to redis on Get, keeping its TTL, so the upgrades run only once.
+	WriteBack bool
+}
+
+// NewSchema creates a new Schema instance with the current version.
+func NewSchema(version uint64) *Schema {
+	return &Schema{
+		Version:  version,
+		Upgrades: map[uint64]UpgradeFunc{},
+	}
+}
+
+// AddUpgrade adds the function that upgrades the payload from the version to the next one.
+func (s *Schema) AddUpgrade(from uint64, fn UpgradeFunc) *Schema {
+	s.Upgrades[from] = fn
+	return s
+}
+
+// SetWriteBack sets value for the WriteBack field.
+func (s *Schema) SetWriteBack(writeBack bool) *Schema {
+	s.WriteBack = writeBack
+	return s
+}
+
+// RegisterSchema registers the schema of the type of the model, the values of this type written by Set, MSet and
+// SetGet are wrapped in an envelope with the schema version, and the values read into a dest of this type by Get,
+// GetDel and SetGet are upgraded to the current version before the conversion.
+//
+// The model can be a value or a pointer of the type, ex: RegisterSchema(User{}, NewSchema(2)).
+func (t *Template) RegisterSchema(model any, schema *Schema) {
+	t.schemasMutex.Lock()
+	defer t.schemasMutex.Unlock()
+	if helper.IsNil(t.schemas) {
+		t.schemas = map[reflect.Type]*Schema{}
+	}
+	t.schemas[baseType(model)] = schema
+}
+
+func (t *Template) schemaOf(v any) *Schema {
+	t.schemasMutex.RLock()
+	defer t.schemasMutex.RUnlock()
+	return t.schemas[baseType(v)]
+}
+
+// versionValue wraps the value in the envelope of the schema of its type, if registered.
+func (t *Template) versionValue(value any, sValue string) string {
+	schema := t.schemaOf(value)
+	if helper.IsNil(schema) {
+		return sValue
+	}
+	return encodeVersion(schema.Version, sValue)
+}
+
+// upgradeValue unwraps the envelope and upgrades the payload to the current version of the schema of the dest
+// type, returning true if it was upgraded. Values without envelope have the version 0.
+func (t *Template) upgradeValue(dest any, value string) (string, bool, error) {
+	version, payload := decodeVersion(value)
+	schema := t.schemaOf(dest)
+	if helper.IsNil(schema) || helper.Equals(version, schema.Version) {
+		return payload, false, nil
+	} else if version > schema.Version {
+		return "", false, ErrSchemaVersion
+	}
+	for ; version < schema.Version; version++ {
+		upgrade, ok := schema.Upgrades[version]
+		if !ok || upgrade == nil {
+			return "", false, ErrUpgradeNotFound
+		}
+		var err error
+		if payload, err = upgrade(payload); helper.IsNotNil(err) {
+			return "", false, err
+		}
+	}
+	return payload, true, nil
+}
+
+// writeBackValue rewrites the upgraded value, keeping its TTL, if the value stored is still the raw value read,
+// the errors are reported to the hooks of the "WriteBack" operation. The value is written with the compression
+// and the encryption of the raw value, not with the ones of the template, since it may have been written with the
+// options of the call.
+func (t *Template) writeBackValue(ctx context.Context, key, raw, payload string, dest any) {
+	schema := t.schemaOf(dest)
+	if helper.IsNil(schema) || !schema.WriteBack {
+		return
+	}
+	_, _ = t.rewrite(ctx, "WriteBack", key, func(value string) (string, bool, error) {
+		if helper.IsNotEqualTo(value, raw) {
+			return "", false, nil
+		}
+		opt, err := t.encodingOf(key, raw)
+		if helper.IsNotNil(err) {
+			return "", false, err
+		}
+		encoded, err := t.encodeValue(key, encodeVersion(schema.Version, payload), opt)
+		return encoded, helper.IsNil(err), err
+	})
+}
+
+// encodingOf returns the options that encode a value like the raw value was encoded.
+func (t *Template) encodingOf(key, raw string) (*option.Set, error) {
+	_, encrypted := encryptionKeyID(raw)
+	value, err := t.keyring.decrypt(key, raw)
+	if helper.IsNotNil(err) {
+		return nil, err
+	}
+	return option.NewSet().SetEncrypt(encrypted).SetCompression(compressionOf(value)).SetCompressionThreshold(0), nil
+}
+
+func encodeVersion(version uint64, payload string) string {
+	envelope := binary.AppendUvarint([]byte(headerVersion), version)
+	return string(append(envelope, payload...))
+}
+
+// decodeVersion returns the version and the payload of the envelope, values without envelope have the version 0.
+func decodeVersion(value string) (uint64, string) {
+	if len(value) <= len(headerVersion) || helper.IsNotEqualTo(value[:len(headerVersion)], headerVersion) {
+		return 0, value
+	}
+	version, n := binary.Uvarint([]byte(value[len(headerVersion):]))
+	if n <= 0 {
+		return 0, value
+	}
+	return version, value[len(headerVersion)+n:]
+}
+
+func baseType(v any) reflect.Type {
+	typ := reflect.TypeOf(v)
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return typ
+}
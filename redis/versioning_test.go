@@ -0,0 +1,154 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"github.com/GabrielHCataldo/go-helper/helper"
+	"github.com/GabrielHCataldo/go-logger/logger"
+	"github.com/GabrielHCataldo/go-redis-template/redis"
+	"github.com/GabrielHCataldo/go-redis-template/redis/option"
+	"github.com/GabrielHCataldo/go-redis-template/redis/redistest"
+	redisdriver "github.com/redis/go-redis/v9"
+	"strings"
+	"testing"
+	"time"
+)
+
+type userV0 struct {
+	Name string
+}
+
+type userV2 struct {
+	FirstName string
+	LastName  string
+	Active    bool
+}
+
+func initUserSchema(writeBack bool) *redis.Schema {
+	return redis.NewSchema(2).
+		AddUpgrade(0, func(payload string) (string, error) {
+			var old userV0
+			if err := helper.ConvertToDest(payload, &old); helper.IsNotNil(err) {
+				return "", err
+			}
+			names := strings.SplitN(old.Name, " ", 2)
+			return helper.ConvertToString(map[string]any{"FirstName": names[0], "LastName": names[1]})
+		}).
+		AddUpgrade(1, func(payload string) (string, error) {
+			return strings.Replace(payload, "{", `{"Active":true,`, 1), nil
+		}).
+		SetWriteBack(writeBack)
+}
+
+func TestTemplateVersioning(t *testing.T) {
+	server := redistest.NewServer(t)
+	client := redisdriver.NewClient(server.ClientOptions().ParseToRedisOptions())
+	defer client.Close()
+	opts := server.ClientOptions()
+	opts.Compression = option.CompressionGzip
+	opts.CompressionThreshold = 1
+	oldTemplate := redis.NewTemplate(opts)
+	defer oldTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	_ = oldTemplate.Set(ctx, "user:1", userV0{Name: "Foo Bar"}, option.NewSet().SetTTL(time.Hour))
+	_ = oldTemplate.Set(ctx, "user:2", userV0{Name: "Foo Bar"})
+	redisTemplate := redis.NewTemplate(opts)
+	defer redisTemplate.SimpleDisconnect()
+	redisTemplate.RegisterSchema(userV2{}, initUserSchema(true))
+	want := userV2{FirstName: "Foo", LastName: "Bar", Active: true}
+	var dest userV2
+	err := redisTemplate.Get(ctx, "user:1", &dest)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(dest, want) {
+		logger.Errorf("Get() result = %v err = %v", dest, err)
+		t.Fail()
+	}
+	stored, _ := client.Get(ctx, "user:1").Result()
+	ttl := client.TTL(ctx, "user:1").Val()
+	if !strings.HasPrefix(stored, string([]byte{0xF9})) || ttl <= 0 {
+		logger.Errorf("Get() write back stored = %q ttl = %v", stored, ttl)
+		t.Fail()
+	}
+	upgrades := 0
+	noWriteBack := redis.NewTemplate(opts)
+	defer noWriteBack.SimpleDisconnect()
+	noWriteBack.RegisterSchema(&userV2{}, redis.NewSchema(2).AddUpgrade(1, func(payload string) (string, error) {
+		upgrades++
+		return payload, nil
+	}))
+	dest = userV2{}
+	err = noWriteBack.Get(ctx, "user:1", &dest)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(dest, want) || helper.IsNotEqualTo(upgrades, 0) {
+		logger.Errorf("Get() after write back result = %v upgrades = %v err = %v", dest, upgrades, err)
+		t.Fail()
+	}
+	var old userV2
+	err = redisTemplate.GetDel(ctx, "user:2", &old)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(old, want) {
+		logger.Errorf("GetDel() result = %v err = %v", old, err)
+		t.Fail()
+	}
+	_ = redisTemplate.Set(ctx, "user:3", want)
+	var raw map[string]any
+	err = oldTemplate.Get(ctx, "user:3", &raw)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(raw["FirstName"], "Foo") {
+		logger.Errorf("Get() unregistered result = %v err = %v", raw, err)
+		t.Fail()
+	}
+	binaryValue := string([]byte{0xFE, 0x02}) + "payload"
+	client.Set(ctx, "binary-key", binaryValue, 0)
+	var binaryDest string
+	err = redisTemplate.Get(ctx, "binary-key", &binaryDest)
+	if helper.IsNotNil(err) || helper.IsNotEqualTo(binaryDest, binaryValue) {
+		logger.Errorf("Get() binary result = %v err = %v", []byte(binaryDest), err)
+		t.Fail()
+	}
+}
+
+func TestTemplateVersioningWriteBackEncoding(t *testing.T) {
+	server := redistest.NewServer(t)
+	client := redisdriver.NewClient(server.ClientOptions().ParseToRedisOptions())
+	defer client.Close()
+	opts := server.ClientOptions()
+	opts.Compression = option.CompressionGzip
+	opts.CompressionThreshold = 1
+	redisTemplate := redis.NewTemplate(opts)
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	_ = redisTemplate.Set(ctx, "user:lz4", userV0{Name: "Foo Bar"}, option.NewSet().SetCompression(option.CompressionLz4))
+	_ = redisTemplate.Set(ctx, "user:plain", userV0{Name: "Foo Bar"},
+		option.NewSet().SetCompression(option.CompressionNone))
+	redisTemplate.RegisterSchema(userV2{}, initUserSchema(true))
+	for key, header := range map[string]string{"user:lz4": "\xf9RTC\x04", "user:plain": "\xfeRTV"} {
+		var dest userV2
+		err := redisTemplate.Get(ctx, key, &dest)
+		stored, _ := client.Get(ctx, key).Result()
+		if helper.IsNotNil(err) || !strings.HasPrefix(stored, header) {
+			logger.Errorf("Get() %s write back stored = %q, want prefix = %q, err = %v", key, stored, header, err)
+			t.Fail()
+		}
+	}
+}
+
+func TestTemplateVersioningErrors(t *testing.T) {
+	server := redistest.NewServer(t)
+	redisTemplate := redis.NewTemplate(server.ClientOptions())
+	defer redisTemplate.SimpleDisconnect()
+	ctx := context.TODO()
+	redisTemplate.RegisterSchema(userV2{}, redis.NewSchema(3))
+	_ = redisTemplate.Set(ctx, "user:new", userV2{FirstName: "Foo"})
+	_ = redisTemplate.Set(ctx, "user:old", userV0{Name: "Foo Bar"})
+	rollback := redis.NewTemplate(server.ClientOptions())
+	defer rollback.SimpleDisconnect()
+	rollback.RegisterSchema(userV2{}, initUserSchema(false))
+	var dest userV2
+	err := rollback.Get(ctx, "user:new", &dest)
+	if !errors.Is(err, redis.ErrSchemaVersion) {
+		logger.Errorf("Get() err = %v, want = %v", err, redis.ErrSchemaVersion)
+		t.Fail()
+	}
+	err = redisTemplate.Get(ctx, "user:old", &dest)
+	if !errors.Is(err, redis.ErrUpgradeNotFound) {
+		logger.Errorf("Get() err = %v, want = %v", err, redis.ErrUpgradeNotFound)
+		t.Fail()
+	}
+}